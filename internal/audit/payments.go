@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PaymentAction represents what happened to a scheduled payment
+type PaymentAction string
+
+const (
+	PaymentActionScheduled PaymentAction = "scheduled"
+	PaymentActionUpdated   PaymentAction = "updated"
+	PaymentActionRemoved   PaymentAction = "removed"
+	PaymentActionDue       PaymentAction = "due"
+	PaymentActionMissed    PaymentAction = "missed"
+	PaymentActionSent      PaymentAction = "sent"
+	PaymentActionRejected  PaymentAction = "rejected"
+	PaymentActionFailed    PaymentAction = "failed"
+)
+
+// PaymentLog represents a single scheduled payment audit entry
+type PaymentLog struct {
+	Action     PaymentAction          `json:"action"`
+	Timestamp  time.Time              `json:"timestamp"`
+	ScheduleID string                 `json:"schedule_id"`
+	RunID      string                 `json:"run_id,omitempty"`
+	WalletID   string                 `json:"wallet_id"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+}
+
+// PaymentAuditor records scheduled payments. Entries are written straight
+// away rather than batched, since a payment must not go unrecorded if the
+// process stops.
+type PaymentAuditor struct {
+	logDir string
+	mu     sync.Mutex
+}
+
+// NewPaymentAuditor creates a new PaymentAuditor writing to logDir
+func NewPaymentAuditor(logDir string) (*PaymentAuditor, error) {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	return &PaymentAuditor{logDir: logDir}, nil
+}
+
+// LogPayment appends an entry to the day's payment audit log
+func (a *PaymentAuditor) LogPayment(action PaymentAction, scheduleID, runID, walletID string, details map[string]interface{}) error {
+	log := PaymentLog{
+		Action:     action,
+		Timestamp:  time.Now(),
+		ScheduleID: scheduleID,
+		RunID:      runID,
+		WalletID:   walletID,
+		Details:    details,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return appendDailyLog(a.logDir, "payment_audit", log.Timestamp, log)
+}
+
+// appendDailyLog writes entry as one JSON line to the prefix's log file
+// for the day of at
+func appendDailyLog(logDir, prefix string, at time.Time, entry interface{}) error {
+	logJSON, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit log: %w", err)
+	}
+
+	logFile := filepath.Join(logDir, fmt.Sprintf("%s_%s.log", prefix, at.Format("2006-01-02")))
+	file, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(logJSON, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+
+	return nil
+}
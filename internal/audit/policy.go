@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// PolicyAction represents the outcome of a spending policy check, or a
+// change to a policy
+type PolicyAction string
+
+const (
+	PolicyActionUpdated              PolicyAction = "updated"
+	PolicyActionAllowed              PolicyAction = "allowed"
+	PolicyActionBlocked              PolicyAction = "blocked"
+	PolicyActionConfirmationRequired PolicyAction = "confirmation_required"
+)
+
+// PolicyLog represents a single spending policy audit entry
+type PolicyLog struct {
+	Action    PolicyAction           `json:"action"`
+	Timestamp time.Time              `json:"timestamp"`
+	WalletID  string                 `json:"wallet_id"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// PolicyAuditor records spending policy decisions and changes. Like
+// payments, entries are written straight away.
+type PolicyAuditor struct {
+	logDir string
+	mu     sync.Mutex
+}
+
+// NewPolicyAuditor creates a new PolicyAuditor writing to logDir
+func NewPolicyAuditor(logDir string) (*PolicyAuditor, error) {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	return &PolicyAuditor{logDir: logDir}, nil
+}
+
+// LogPolicy appends an entry to the day's policy audit log
+func (a *PolicyAuditor) LogPolicy(action PolicyAction, walletID string, details map[string]interface{}) error {
+	log := PolicyLog{
+		Action:    action,
+		Timestamp: time.Now(),
+		WalletID:  walletID,
+		Details:   details,
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return appendDailyLog(a.logDir, "policy_audit", log.Timestamp, log)
+}
@@ -3,8 +3,11 @@ package blockchain
 import (
 	"context"
 	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"sync"
 	"time"
 
@@ -51,13 +54,16 @@ func NewClient(config Config) (*Client, error) {
 	if config.RetryDelay == 0 {
 		config.RetryDelay = DefaultRetryDelay
 	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = DefaultCacheTTL
+	}
 
-	thorClient := thorest.NewClientFromURL(config.NodeURL)
+	thorClient := thorest.NewClient(config.NodeURL, &http.Client{Timeout: config.Timeout})
 
 	c := &Client{
 		thorClient: thorClient,
 		config:     config,
-		cache:      NewBalanceCache(DefaultCacheTTL),
+		cache:      NewBalanceCache(config.CacheTTL),
 		status: NetworkStatus{
 			NodeURL:     config.NodeURL,
 			Connected:   false,
@@ -116,6 +122,74 @@ func (c *Client) AddressExists(address string) (bool, error) {
 	return true, nil
 }
 
+// AddressUsed reports whether an address holds VET or VTHO or has ever sent
+// or received a VET transfer. Account discovery uses it to find derived
+// addresses that were used.
+func (c *Client) AddressUsed(address string) (bool, error) {
+	balance, err := c.GetBalance(address)
+	if err != nil {
+		return false, err
+	}
+	if balance.VET.Sign() > 0 || balance.VTHO.Sign() > 0 {
+		return true, nil
+	}
+
+	addr := common.HexToAddress(address)
+	limit := int64(1)
+	filter := &thorest.TransferFilter{
+		Options: &thorest.LogOptions{Limit: &limit},
+		Criteria: &[]thorest.TransferCriteria{
+			{TxOrigin: &addr},
+			{Sender: &addr},
+			{Recipient: &addr},
+		},
+	}
+
+	transfers, err := c.thorClient.FilterTransfers(filter)
+	if err != nil {
+		return false, NewNetworkError("failed to check address history", err)
+	}
+	return len(transfers) > 0, nil
+}
+
+// IncomingTransfers returns up to limit of the most recent VET transfers
+// received by address, newest first
+func (c *Client) IncomingTransfers(address string, limit int) ([]Transfer, error) {
+	addr := common.HexToAddress(address)
+	count := int64(limit)
+	order := "desc"
+	filter := &thorest.TransferFilter{
+		Options:  &thorest.LogOptions{Limit: &count},
+		Criteria: &[]thorest.TransferCriteria{{Recipient: &addr}},
+		Order:    &order,
+	}
+
+	logs, err := c.thorClient.FilterTransfers(filter)
+	if err != nil {
+		return nil, NewNetworkError("failed to fetch incoming transfers", err)
+	}
+
+	transfers := make([]Transfer, 0, len(logs))
+	for _, log := range logs {
+		transfer := Transfer{
+			Sender:    log.Sender.Hex(),
+			Recipient: log.Recipient.Hex(),
+			Amount:    new(big.Int),
+		}
+		if log.Amount != nil {
+			transfer.Amount = log.Amount.ToInt()
+		}
+		if log.Meta != nil {
+			transfer.TxID = log.Meta.TxID.Hex()
+			transfer.ClauseIndex = int(log.Meta.ClauseIndex)
+			transfer.BlockNumber = uint64(log.Meta.BlockNumber)
+			transfer.Timestamp = time.Unix(log.Meta.BlockTime, 0)
+		}
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
 func (c *Client) GetBalance(address string) (*Balance, error) {
 	if cached, found := c.cache.Get(address); found {
 		return cached, nil
@@ -272,6 +346,57 @@ func (c *Client) BuildTransaction(from, to string, amount *big.Int, asset AssetT
 }
 
 func (c *Client) SignTransaction(transaction *Transaction, privateKey *ecdsa.PrivateKey) (*tx.Transaction, error) {
+	if privateKey == nil {
+		return nil, NewBlockchainError(ErrTransactionFailed, "failed to sign transaction", fmt.Errorf("no signing key available"))
+	}
+
+	thorTx, err := c.buildThorTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign the transaction
+	signedTx, err := tx.Sign(thorTx, privateKey)
+	if err != nil {
+		return nil, NewBlockchainError(ErrTransactionFailed, "failed to sign transaction", err)
+	}
+
+	return signedTx, nil
+}
+
+// BuildUnsignedTransaction encodes a transaction without signing it, so it
+// can be carried to the machine that holds the sender's key
+func (c *Client) BuildUnsignedTransaction(transaction *Transaction) (*UnsignedTransaction, error) {
+	thorTx, err := c.buildThorTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := thorTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	blockRef := thorTx.BlockRef()
+	return &UnsignedTransaction{
+		From:         transaction.From,
+		To:           transaction.To,
+		Amount:       transaction.Amount.String(),
+		Asset:        transaction.Asset,
+		ChainTag:     thorTx.ChainTag(),
+		BlockRef:     "0x" + hex.EncodeToString(blockRef[:]),
+		Expiration:   thorTx.Expiration(),
+		Gas:          thorTx.Gas(),
+		GasPriceCoef: thorTx.GasPriceCoef(),
+		SigningHash:  thorTx.SigningHash().Hex(),
+		RawTx:        "0x" + hex.EncodeToString(raw),
+		CreatedAt:    time.Now(),
+	}, nil
+}
+
+// buildThorTransaction turns a transaction into its unsigned on-chain form
+// referencing the current best block
+func (c *Client) buildThorTransaction(transaction *Transaction) (*tx.Transaction, error) {
 	// Get chain tag and best block for transaction construction
 	chainTag, err := c.thorClient.ChainTag()
 	if err != nil {
@@ -298,22 +423,14 @@ func (c *Client) SignTransaction(transaction *Transaction, privateKey *ecdsa.Pri
 	}
 
 	// Build the transaction
-	thorTx := tx.NewBuilder(tx.TypeLegacy).
+	return tx.NewBuilder(tx.TypeLegacy).
 		ChainTag(chainTag).
 		BlockRef(blockRef).
 		Expiration(32). // 32 blocks expiration
 		Gas(transaction.GasLimit.Uint64()).
 		GasPriceCoef(0).
 		Clause(clause).
-		Build()
-
-	// Sign the transaction
-	signedTx, err := tx.Sign(thorTx, privateKey)
-	if err != nil {
-		return nil, NewBlockchainError(ErrTransactionFailed, "failed to sign transaction", err)
-	}
-
-	return signedTx, nil
+		Build(), nil
 }
 
 func (c *Client) BroadcastTransaction(signedTx *tx.Transaction) (string, error) {
@@ -328,6 +445,11 @@ func (c *Client) BroadcastTransaction(signedTx *tx.Transaction) (string, error)
 func (c *Client) GetTransactionStatus(txID string) (*TransactionStatus, error) {
 	txHash := common.HexToHash(txID)
 	receipt, err := c.thorClient.TransactionReceipt(txHash)
+	if errors.Is(err, thorest.ErrNotFound) {
+		// No receipt yet means the transaction has not been included in a block
+		status := StatusPending
+		return &status, nil
+	}
 	if err != nil {
 		return nil, NewNetworkError("failed to get transaction status", err)
 	}
@@ -1,9 +1,16 @@
 package blockchain
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/darrenvechain/thorgo/thorest"
 )
 
 func TestNewClientConfig(t *testing.T) {
@@ -170,3 +177,57 @@ func TestNetworkStatusStructure(t *testing.T) {
 		t.Error("Expected Connected to be false for mock status")
 	}
 }
+
+func TestIncomingTransfers(t *testing.T) {
+	const recipient = "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed"
+	var filter thorest.TransferFilter
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/blocks/"):
+			fmt.Fprintf(w, `{"number":1,"id":"0x%064x"}`, 0x27)
+		case r.URL.Path == "/logs/transfer":
+			if err := json.NewDecoder(r.Body).Decode(&filter); err != nil {
+				t.Errorf("Failed to decode filter: %v", err)
+			}
+			fmt.Fprintf(w, `[{"sender":"0x7567d800000000000000000000000000b364ffed","recipient":"%s","amount":"0x1",
+				"meta":{"blockID":"0x%064x","blockNumber":20,"blockTimestamp":1700000000,"txID":"0x%064x","txOrigin":"0x7567d800000000000000000000000000b364ffed","clauseIndex":1}}]`,
+				recipient, 0x14, 0x99)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer node.Close()
+
+	client, err := NewClient(Config{Network: TestNet, NodeURL: node.URL})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	transfers, err := client.IncomingTransfers(recipient, 50)
+	if err != nil {
+		t.Fatalf("Failed to fetch transfers: %v", err)
+	}
+	if filter.Criteria == nil || len(*filter.Criteria) != 1 || (*filter.Criteria)[0].Recipient == nil ||
+		!strings.EqualFold((*filter.Criteria)[0].Recipient.Hex(), recipient) {
+		t.Errorf("Expected transfers filtered by recipient, got %+v", filter.Criteria)
+	}
+	if filter.Options == nil || filter.Options.Limit == nil || *filter.Options.Limit != 50 {
+		t.Errorf("Expected the limit to be sent, got %+v", filter.Options)
+	}
+
+	if len(transfers) != 1 {
+		t.Fatalf("Expected one transfer, got %d", len(transfers))
+	}
+	transfer := transfers[0]
+	if transfer.Amount.Cmp(big.NewInt(1)) != 0 || transfer.ClauseIndex != 1 || transfer.BlockNumber != 20 {
+		t.Errorf("Unexpected transfer %+v", transfer)
+	}
+	if !strings.EqualFold(transfer.Sender, "0x7567d800000000000000000000000000b364ffed") || !strings.EqualFold(transfer.Recipient, recipient) {
+		t.Errorf("Unexpected addresses %s -> %s", transfer.Sender, transfer.Recipient)
+	}
+	if transfer.TxID != fmt.Sprintf("0x%064x", 0x99) || !transfer.Timestamp.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Unexpected transaction %s at %s", transfer.TxID, transfer.Timestamp)
+	}
+}
@@ -19,6 +19,7 @@ type Config struct {
 	Timeout    time.Duration
 	RetryCount int
 	RetryDelay time.Duration
+	CacheTTL   time.Duration
 }
 
 type Balance struct {
@@ -33,6 +34,17 @@ type BalanceCache struct {
 	ttl      time.Duration
 }
 
+// Transfer is a VET transfer found in the chain's transfer logs
+type Transfer struct {
+	TxID        string
+	ClauseIndex int
+	Sender      string
+	Recipient   string
+	Amount      *big.Int
+	BlockNumber uint64
+	Timestamp   time.Time
+}
+
 type AssetType string
 
 const (
@@ -53,6 +65,24 @@ type Transaction struct {
 	Status    TransactionStatus
 }
 
+// UnsignedTransaction is a built but unsigned transaction exported for
+// signing on another machine. Amount is in wei; RawTx is the RLP encoding
+// and SigningHash the hash the sender's key must sign.
+type UnsignedTransaction struct {
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	Amount       string    `json:"amount"`
+	Asset        AssetType `json:"asset"`
+	ChainTag     byte      `json:"chain_tag"`
+	BlockRef     string    `json:"block_ref"`
+	Expiration   uint32    `json:"expiration"`
+	Gas          uint64    `json:"gas"`
+	GasPriceCoef uint8     `json:"gas_price_coef"`
+	SigningHash  string    `json:"signing_hash"`
+	RawTx        string    `json:"raw_tx"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
 type TransactionStatus string
 
 const (
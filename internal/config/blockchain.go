@@ -3,7 +3,6 @@ package config
 import (
 	"fmt"
 	"os"
-	"strconv"
 	"time"
 
 	"rhystmorgan/veWallet/internal/blockchain"
@@ -14,25 +13,10 @@ type BlockchainConfig struct {
 	NodeURL    string        `json:"node_url"`
 	Timeout    time.Duration `json:"timeout"`
 	RetryCount int           `json:"retry_count"`
+	RetryDelay time.Duration `json:"retry_delay"`
 	CacheTTL   time.Duration `json:"cache_ttl"`
 }
 
-func LoadBlockchainConfig() (*BlockchainConfig, error) {
-	config := &BlockchainConfig{
-		Network:    getEnvOrDefault("VETERM_NETWORK", "mainnet"),
-		NodeURL:    getEnvOrDefault("VETERM_NODE_URL", ""),
-		Timeout:    parseDurationOrDefault("VETERM_TIMEOUT", 30*time.Second),
-		RetryCount: parseIntOrDefault("VETERM_RETRY_COUNT", 3),
-		CacheTTL:   parseDurationOrDefault("VETERM_CACHE_TTL", 30*time.Second),
-	}
-
-	if err := config.Validate(); err != nil {
-		return nil, err
-	}
-
-	return config, nil
-}
-
 func (c *BlockchainConfig) Validate() error {
 	switch c.Network {
 	case "mainnet", "testnet":
@@ -49,6 +33,10 @@ func (c *BlockchainConfig) Validate() error {
 		return fmt.Errorf("retry count must be non-negative, got: %d", c.RetryCount)
 	}
 
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("retry delay must be non-negative, got: %v", c.RetryDelay)
+	}
+
 	if c.CacheTTL <= 0 {
 		return fmt.Errorf("cache TTL must be positive, got: %v", c.CacheTTL)
 	}
@@ -67,46 +55,28 @@ func (c *BlockchainConfig) ToBlockchainConfig() blockchain.Config {
 		network = blockchain.MainNet // Default fallback
 	}
 
+	retryDelay := c.RetryDelay
+	if retryDelay == 0 {
+		retryDelay = 2 * time.Second
+	}
+
 	return blockchain.Config{
 		Network:    network,
 		NodeURL:    c.NodeURL,
 		Timeout:    c.Timeout,
 		RetryCount: c.RetryCount,
-		RetryDelay: 2 * time.Second,
+		RetryDelay: retryDelay,
+		CacheTTL:   c.CacheTTL,
 	}
 }
 
-func getEnvOrDefault(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func parseIntOrDefault(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.Atoi(value); err == nil {
-			return parsed
-		}
-	}
-	return defaultValue
-}
-
-func parseDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := time.ParseDuration(value); err == nil {
-			return parsed
-		}
-	}
-	return defaultValue
-}
-
 func GetDefaultConfig() *BlockchainConfig {
 	return &BlockchainConfig{
 		Network:    "mainnet",
 		NodeURL:    "",
 		Timeout:    30 * time.Second,
 		RetryCount: 3,
+		RetryDelay: 2 * time.Second,
 		CacheTTL:   30 * time.Second,
 	}
 }
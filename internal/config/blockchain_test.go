@@ -8,84 +8,6 @@ import (
 	"rhystmorgan/veWallet/internal/blockchain"
 )
 
-func TestLoadBlockchainConfig(t *testing.T) {
-	// Clear environment variables
-	os.Unsetenv("VETERM_NETWORK")
-	os.Unsetenv("VETERM_NODE_URL")
-	os.Unsetenv("VETERM_TIMEOUT")
-	os.Unsetenv("VETERM_RETRY_COUNT")
-	os.Unsetenv("VETERM_CACHE_TTL")
-
-	config, err := LoadBlockchainConfig()
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
-
-	// Test defaults
-	if config.Network != "mainnet" {
-		t.Errorf("Expected default network 'mainnet', got '%s'", config.Network)
-	}
-
-	if config.NodeURL != "" {
-		t.Errorf("Expected empty NodeURL by default, got '%s'", config.NodeURL)
-	}
-
-	if config.Timeout != 30*time.Second {
-		t.Errorf("Expected default timeout 30s, got %v", config.Timeout)
-	}
-
-	if config.RetryCount != 3 {
-		t.Errorf("Expected default retry count 3, got %d", config.RetryCount)
-	}
-
-	if config.CacheTTL != 30*time.Second {
-		t.Errorf("Expected default cache TTL 30s, got %v", config.CacheTTL)
-	}
-}
-
-func TestLoadBlockchainConfigWithEnv(t *testing.T) {
-	// Set environment variables
-	os.Setenv("VETERM_NETWORK", "testnet")
-	os.Setenv("VETERM_NODE_URL", "http://localhost:8669")
-	os.Setenv("VETERM_TIMEOUT", "60s")
-	os.Setenv("VETERM_RETRY_COUNT", "5")
-	os.Setenv("VETERM_CACHE_TTL", "60s")
-
-	defer func() {
-		// Clean up
-		os.Unsetenv("VETERM_NETWORK")
-		os.Unsetenv("VETERM_NODE_URL")
-		os.Unsetenv("VETERM_TIMEOUT")
-		os.Unsetenv("VETERM_RETRY_COUNT")
-		os.Unsetenv("VETERM_CACHE_TTL")
-	}()
-
-	config, err := LoadBlockchainConfig()
-	if err != nil {
-		t.Fatalf("Failed to load config: %v", err)
-	}
-
-	if config.Network != "testnet" {
-		t.Errorf("Expected network 'testnet', got '%s'", config.Network)
-	}
-
-	if config.NodeURL != "http://localhost:8669" {
-		t.Errorf("Expected NodeURL 'http://localhost:8669', got '%s'", config.NodeURL)
-	}
-
-	if config.Timeout != 60*time.Second {
-		t.Errorf("Expected timeout 60s, got %v", config.Timeout)
-	}
-
-	if config.RetryCount != 5 {
-		t.Errorf("Expected retry count 5, got %d", config.RetryCount)
-	}
-
-	if config.CacheTTL != 60*time.Second {
-		t.Errorf("Expected cache TTL 60s, got %v", config.CacheTTL)
-	}
-}
-
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -284,47 +206,3 @@ func TestIsDebugEnabled(t *testing.T) {
 	// Clean up
 	os.Unsetenv("VETERM_DEBUG")
 }
-
-func TestParseHelpers(t *testing.T) {
-	// Test parseIntOrDefault
-	os.Setenv("TEST_INT", "42")
-	result := parseIntOrDefault("TEST_INT", 10)
-	if result != 42 {
-		t.Errorf("Expected 42, got %d", result)
-	}
-
-	result = parseIntOrDefault("NONEXISTENT_INT", 10)
-	if result != 10 {
-		t.Errorf("Expected default 10, got %d", result)
-	}
-
-	os.Setenv("TEST_INVALID_INT", "not_a_number")
-	result = parseIntOrDefault("TEST_INVALID_INT", 10)
-	if result != 10 {
-		t.Errorf("Expected default 10 for invalid int, got %d", result)
-	}
-
-	// Test parseDurationOrDefault
-	os.Setenv("TEST_DURATION", "5m")
-	duration := parseDurationOrDefault("TEST_DURATION", 1*time.Minute)
-	if duration != 5*time.Minute {
-		t.Errorf("Expected 5m, got %v", duration)
-	}
-
-	duration = parseDurationOrDefault("NONEXISTENT_DURATION", 1*time.Minute)
-	if duration != 1*time.Minute {
-		t.Errorf("Expected default 1m, got %v", duration)
-	}
-
-	os.Setenv("TEST_INVALID_DURATION", "not_a_duration")
-	duration = parseDurationOrDefault("TEST_INVALID_DURATION", 1*time.Minute)
-	if duration != 1*time.Minute {
-		t.Errorf("Expected default 1m for invalid duration, got %v", duration)
-	}
-
-	// Clean up
-	os.Unsetenv("TEST_INT")
-	os.Unsetenv("TEST_INVALID_INT")
-	os.Unsetenv("TEST_DURATION")
-	os.Unsetenv("TEST_INVALID_DURATION")
-}
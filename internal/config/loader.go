@@ -0,0 +1,422 @@
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+// Source records which layer supplied an effective setting
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// setting describes one tunable and where it can be set. Key matches the
+// field's name in the config file.
+type setting struct {
+	Key   string
+	Env   string
+	Flag  string
+	Usage string
+	get   func(c *storage.Config) string
+	set   func(c *storage.Config, value string) error
+}
+
+const sessionTimeoutKey = "session_timeout_minutes"
+
+var settings = []setting{
+	{
+		Key: "network", Env: "VETERM_NETWORK", Flag: "network",
+		Usage: "network to connect to (mainnet or testnet)",
+		get:   func(c *storage.Config) string { return c.Network },
+		set:   func(c *storage.Config, v string) error { c.Network = v; return nil },
+	},
+	{
+		Key: "node_url", Env: "VETERM_NODE_URL", Flag: "node-url",
+		Usage: "custom node URL (defaults to the network's public node)",
+		get:   func(c *storage.Config) string { return c.NodeURL },
+		set:   func(c *storage.Config, v string) error { c.NodeURL = v; return nil },
+	},
+	{
+		Key: "timeout", Env: "VETERM_TIMEOUT", Flag: "timeout",
+		Usage: "node request timeout",
+		get:   func(c *storage.Config) string { return c.Timeout.String() },
+		set:   func(c *storage.Config, v string) error { return setDuration(&c.Timeout, v) },
+	},
+	{
+		Key: "retry_count", Env: "VETERM_RETRY_COUNT", Flag: "retry-count",
+		Usage: "number of attempts for node requests",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.RetryCount) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.RetryCount, v) },
+	},
+	{
+		Key: "retry_delay", Env: "VETERM_RETRY_DELAY", Flag: "retry-delay",
+		Usage: "delay between node request attempts",
+		get:   func(c *storage.Config) string { return c.RetryDelay.String() },
+		set:   func(c *storage.Config, v string) error { return setDuration(&c.RetryDelay, v) },
+	},
+	{
+		Key: "cache_ttl", Env: "VETERM_CACHE_TTL", Flag: "cache-ttl",
+		Usage: "how long balances are cached",
+		get:   func(c *storage.Config) string { return c.CacheTTL.String() },
+		set:   func(c *storage.Config, v string) error { return setDuration(&c.CacheTTL, v) },
+	},
+	{
+		Key: "theme", Env: "VETERM_THEME", Flag: "theme",
+		Usage: "colour theme",
+		get:   func(c *storage.Config) string { return c.Theme },
+		set:   func(c *storage.Config, v string) error { c.Theme = v; return nil },
+	},
+	{
+		Key: "security_level", Env: "VETERM_SECURITY_LEVEL", Flag: "security-level",
+		Usage: "security level (low, medium, high or max)",
+		get:   func(c *storage.Config) string { return c.SecurityLevel },
+		set:   func(c *storage.Config, v string) error { c.SecurityLevel = v; return nil },
+	},
+	{
+		Key: sessionTimeoutKey, Env: "VETERM_SESSION_TIMEOUT", Flag: "session-timeout",
+		Usage: "session timeout in minutes (defaults to the security level's)",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.SessionTimeout) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.SessionTimeout, v) },
+	},
+	{
+		Key: "max_sessions", Env: "VETERM_MAX_SESSIONS", Flag: "max-sessions",
+		Usage: "maximum concurrent sessions (0 uses the security level default)",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.MaxSessions) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.MaxSessions, v) },
+	},
+	{
+		Key: "auto_refresh_seconds", Env: "VETERM_AUTO_REFRESH", Flag: "auto-refresh",
+		Usage: "balance auto-refresh interval in seconds (0 disables)",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.AutoRefresh) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.AutoRefresh, v) },
+	},
+	{
+		Key: "display_precision", Env: "VETERM_DISPLAY_PRECISION", Flag: "display-precision",
+		Usage: "decimal places shown for balances",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.DisplayPrecision) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.DisplayPrecision, v) },
+	},
+	{
+		Key: "account_gap_limit", Env: "VETERM_ACCOUNT_GAP_LIMIT", Flag: "account-gap-limit",
+		Usage: "unused addresses scanned before account discovery stops",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.AccountGapLimit) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.AccountGapLimit, v) },
+	},
+	{
+		Key: "kdf_time", Env: "VETERM_KDF_TIME", Flag: "kdf-time",
+		Usage: "argon2id passes when encrypting wallets (see veterm calibrate)",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.KDFTime) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.KDFTime, v) },
+	},
+	{
+		Key: "kdf_memory_kib", Env: "VETERM_KDF_MEMORY", Flag: "kdf-memory",
+		Usage: "argon2id memory in KiB when encrypting wallets",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.KDFMemory) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.KDFMemory, v) },
+	},
+	{
+		Key: "kdf_threads", Env: "VETERM_KDF_THREADS", Flag: "kdf-threads",
+		Usage: "argon2id parallelism when encrypting wallets",
+		get:   func(c *storage.Config) string { return strconv.Itoa(c.KDFThreads) },
+		set:   func(c *storage.Config, v string) error { return setInt(&c.KDFThreads, v) },
+	},
+	{
+		Key: "contact_encryption", Env: "VETERM_CONTACT_ENCRYPTION", Flag: "contact-encryption",
+		Usage: "encrypt the whole contact book (file) or only notes and totals (fields)",
+		get:   func(c *storage.Config) string { return c.ContactEncryption },
+		set:   func(c *storage.Config, v string) error { c.ContactEncryption = v; return nil },
+	},
+	{
+		Key: "schedule_budget_vet", Env: "VETERM_SCHEDULE_BUDGET_VET", Flag: "schedule-budget-vet",
+		Usage: "VET each wallet's automatic scheduled payments may spend in any 30 days (0 requires approval)",
+		get:   func(c *storage.Config) string { return c.ScheduleBudgetVET },
+		set:   func(c *storage.Config, v string) error { c.ScheduleBudgetVET = v; return nil },
+	},
+	{
+		Key: "schedule_budget_vtho", Env: "VETERM_SCHEDULE_BUDGET_VTHO", Flag: "schedule-budget-vtho",
+		Usage: "VTHO each wallet's automatic scheduled payments may spend in any 30 days (0 requires approval)",
+		get:   func(c *storage.Config) string { return c.ScheduleBudgetVTHO },
+		set:   func(c *storage.Config, v string) error { c.ScheduleBudgetVTHO = v; return nil },
+	},
+	{
+		Key: "default_wallet", Env: "VETERM_DEFAULT_WALLET", Flag: "default-wallet",
+		Usage: "ID of the wallet selected on startup",
+		get:   func(c *storage.Config) string { return c.DefaultWallet },
+		set:   func(c *storage.Config, v string) error { c.DefaultWallet = v; return nil },
+	},
+}
+
+func setInt(target *int, value string) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid integer: %s", value)
+	}
+	*target = parsed
+	return nil
+}
+
+func setDuration(target *storage.Duration, value string) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid duration: %s", value)
+	}
+	*target = storage.Duration(parsed)
+	return nil
+}
+
+// Loader resolves the effective configuration from, in increasing order of
+// precedence, built-in defaults, the config file, environment variables and
+// command-line flags.
+type Loader struct {
+	storage *storage.Storage
+	flags   map[string]string
+}
+
+// NewLoader creates a loader. flags maps flag names (as registered by
+// RegisterFlags) to the values given on the command line.
+func NewLoader(storage *storage.Storage, flags map[string]string) *Loader {
+	if flags == nil {
+		flags = map[string]string{}
+	}
+	return &Loader{storage: storage, flags: flags}
+}
+
+// Effective is a resolved configuration along with where each value came from
+type Effective struct {
+	Config  *storage.Config
+	Sources map[string]Source
+	Path    string
+
+	// fileTimeoutIsLevel is set when the file's session timeout is the
+	// default of the security level saved with it
+	fileTimeoutIsLevel bool
+}
+
+func (l *Loader) Load() (*Effective, error) {
+	path := l.storage.ConfigPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l.resolve(storage.DefaultConfig(), nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	fileConfig, err := l.storage.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only keys present in the file count as set by it
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(data, &present); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	return l.resolve(fileConfig, present)
+}
+
+// LoadWith resolves the effective configuration as if fileConfig had been
+// saved, so changes can be checked before they are written.
+func (l *Loader) LoadWith(fileConfig *storage.Config) (*Effective, error) {
+	present := make(map[string]json.RawMessage, len(settings))
+	for _, s := range settings {
+		present[s.Key] = nil
+	}
+	return l.resolve(fileConfig, present)
+}
+
+func (l *Loader) resolve(fileConfig *storage.Config, present map[string]json.RawMessage) (*Effective, error) {
+	config := *fileConfig
+	effective := &Effective{
+		Config:  &config,
+		Sources: make(map[string]Source, len(settings)),
+		Path:    l.storage.ConfigPath(),
+	}
+
+	for _, s := range settings {
+		effective.Sources[s.Key] = SourceDefault
+		if _, ok := present[s.Key]; ok {
+			effective.Sources[s.Key] = SourceFile
+		}
+	}
+	if level, err := security.ParseSecurityLevel(fileConfig.SecurityLevel); err == nil {
+		levelTimeout := security.SessionConfigForLevel(level).DefaultTimeout
+		effective.fileTimeoutIsLevel = time.Duration(fileConfig.SessionTimeout)*time.Minute == levelTimeout
+	}
+
+	for _, s := range settings {
+		value := os.Getenv(s.Env)
+		if value == "" {
+			continue
+		}
+		if err := s.set(effective.Config, value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", s.Env, err)
+		}
+		effective.Sources[s.Key] = SourceEnv
+	}
+
+	for _, s := range settings {
+		value, ok := l.flags[s.Flag]
+		if !ok {
+			continue
+		}
+		if err := s.set(effective.Config, value); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w", s.Flag, err)
+		}
+		effective.Sources[s.Key] = SourceFlag
+	}
+
+	if err := effective.Config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if !utils.IsValidTheme(effective.Config.Theme) {
+		return nil, fmt.Errorf("invalid config: unknown theme: %s", effective.Config.Theme)
+	}
+
+	return effective, nil
+}
+
+// SessionTimeout returns the session timeout the user chose, or zero to
+// leave it to the security level. veterm writes every setting when it saves
+// the config file, so a timeout in the file that is still the default of the
+// level saved with it counts as left to the level.
+func (e *Effective) SessionTimeout() time.Duration {
+	switch e.Sources[sessionTimeoutKey] {
+	case SourceFlag, SourceEnv:
+	case SourceFile:
+		if e.fileTimeoutIsLevel {
+			return 0
+		}
+	default:
+		return 0
+	}
+	return time.Duration(e.Config.SessionTimeout) * time.Minute
+}
+
+// Overridden reports whether a setting is fixed by the environment or a flag,
+// so that editing the config file will not change it.
+func (e *Effective) Overridden(key string) bool {
+	source := e.Sources[key]
+	return source == SourceEnv || source == SourceFlag
+}
+
+func (e *Effective) BlockchainConfig() *BlockchainConfig {
+	return &BlockchainConfig{
+		Network:    e.Config.Network,
+		NodeURL:    e.Config.NodeURL,
+		Timeout:    time.Duration(e.Config.Timeout),
+		RetryCount: e.Config.RetryCount,
+		RetryDelay: time.Duration(e.Config.RetryDelay),
+		CacheTTL:   time.Duration(e.Config.CacheTTL),
+	}
+}
+
+// Print writes the effective settings and their sources as a table
+func (e *Effective) Print(w io.Writer) error {
+	fmt.Fprintf(w, "Config file: %s\n\n", e.Path)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		value := s.get(e.Config)
+		if value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Key, value, e.SourceLabel(s.Key))
+	}
+	return tw.Flush()
+}
+
+type jsonSetting struct {
+	Value  string `json:"value"`
+	Source Source `json:"source"`
+	Env    string `json:"env"`
+	Flag   string `json:"flag"`
+}
+
+// PrintJSON writes the effective settings and their sources as JSON
+func (e *Effective) PrintJSON(w io.Writer) error {
+	out := struct {
+		Path     string                 `json:"path"`
+		Settings map[string]jsonSetting `json:"settings"`
+	}{
+		Path:     e.Path,
+		Settings: make(map[string]jsonSetting, len(settings)),
+	}
+	for _, s := range settings {
+		out.Settings[s.Key] = jsonSetting{
+			Value:  s.get(e.Config),
+			Source: e.Sources[s.Key],
+			Env:    s.Env,
+			Flag:   "--" + s.Flag,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// SourceLabel describes where a setting came from, naming the variable or
+// flag for overrides
+func (e *Effective) SourceLabel(key string) string {
+	s, ok := findSetting(key)
+	if !ok {
+		return ""
+	}
+
+	switch e.Sources[key] {
+	case SourceEnv:
+		return fmt.Sprintf("env (%s)", s.Env)
+	case SourceFlag:
+		return fmt.Sprintf("flag (--%s)", s.Flag)
+	default:
+		return string(e.Sources[key])
+	}
+}
+
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// RegisterFlags adds a flag for every setting to fs. The returned function
+// collects the flags that were explicitly set, for passing to NewLoader.
+func RegisterFlags(fs *flag.FlagSet) func() map[string]string {
+	for _, s := range settings {
+		fs.String(s.Flag, "", fmt.Sprintf("%s (env %s)", s.Usage, s.Env))
+	}
+
+	return func() map[string]string {
+		values := make(map[string]string)
+		fs.Visit(func(f *flag.Flag) {
+			for _, s := range settings {
+				if s.Flag == f.Name {
+					values[f.Name] = f.Value.String()
+					return
+				}
+			}
+		})
+		return values
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+func newTestStorage(t *testing.T) *storage.Storage {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(storage.HomeEnv, "")
+	t.Setenv(storage.ProfileEnv, "")
+
+	for _, s := range settings {
+		t.Setenv(s.Env, "")
+	}
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return store
+}
+
+func TestLoaderDefaults(t *testing.T) {
+	store := newTestStorage(t)
+
+	effective, err := NewLoader(store, nil).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if effective.Config.Network != "mainnet" {
+		t.Errorf("Expected default network 'mainnet', got '%s'", effective.Config.Network)
+	}
+
+	for key, source := range effective.Sources {
+		if source != SourceDefault {
+			t.Errorf("Expected %s to come from defaults, got %s", key, source)
+		}
+	}
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	store := newTestStorage(t)
+
+	fileConfig := storage.DefaultConfig()
+	fileConfig.Network = "testnet"
+	fileConfig.CacheTTL = storage.Duration(time.Minute)
+	fileConfig.RetryCount = 5
+	fileConfig.SecurityLevel = "low"
+	if err := store.SaveConfig(fileConfig); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	t.Setenv("VETERM_RETRY_COUNT", "7")
+	t.Setenv("VETERM_SECURITY_LEVEL", "medium")
+
+	effective, err := NewLoader(store, map[string]string{"security-level": "max"}).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source Source
+	}{
+		{"network", "testnet", SourceFile},
+		{"cache_ttl", "1m0s", SourceFile},
+		{"retry_count", "7", SourceEnv},
+		{"security_level", "max", SourceFlag},
+	}
+
+	for _, tt := range tests {
+		s, _ := findSetting(tt.key)
+		if got := s.get(effective.Config); got != tt.value {
+			t.Errorf("Expected %s = %s, got %s", tt.key, tt.value, got)
+		}
+		if effective.Sources[tt.key] != tt.source {
+			t.Errorf("Expected %s from %s, got %s", tt.key, tt.source, effective.Sources[tt.key])
+		}
+	}
+
+	if !effective.Overridden("security_level") {
+		t.Error("security_level should be reported as overridden")
+	}
+	if effective.Overridden("network") {
+		t.Error("network should not be reported as overridden")
+	}
+
+	blockchainConfig := effective.BlockchainConfig()
+	if blockchainConfig.CacheTTL != time.Minute || blockchainConfig.RetryCount != 7 {
+		t.Errorf("Blockchain config does not match effective values: %+v", blockchainConfig)
+	}
+}
+
+func TestLoaderPartialFile(t *testing.T) {
+	store := newTestStorage(t)
+
+	if err := os.WriteFile(store.ConfigPath(), []byte(`{"theme": "catppuccin-latte"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	effective, err := NewLoader(store, nil).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if effective.Sources["theme"] != SourceFile {
+		t.Errorf("Expected theme from file, got %s", effective.Sources["theme"])
+	}
+	if effective.Sources["timeout"] != SourceDefault {
+		t.Errorf("Expected timeout from defaults, got %s", effective.Sources["timeout"])
+	}
+	if effective.Config.Timeout != storage.Duration(30*time.Second) {
+		t.Errorf("Expected default timeout 30s, got %v", effective.Config.Timeout)
+	}
+}
+
+func TestSessionTimeoutFollowsSecurityLevel(t *testing.T) {
+	store := newTestStorage(t)
+
+	// Nothing set: the level decides
+	effective, err := NewLoader(store, map[string]string{"security-level": "max"}).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if timeout := effective.SessionTimeout(); timeout != 0 {
+		t.Errorf("Expected the level's timeout with no timeout set, got %v", timeout)
+	}
+
+	// A saved file holds every setting; a timeout still at its level's
+	// default is not a choice
+	if err := store.SaveConfig(storage.DefaultConfig()); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	effective, err = NewLoader(store, map[string]string{"security-level": "max"}).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if timeout := effective.SessionTimeout(); timeout != 0 {
+		t.Errorf("Expected the level's timeout for a default file timeout, got %v", timeout)
+	}
+
+	fileConfig := storage.DefaultConfig()
+	fileConfig.SessionTimeout = 20
+	if err := store.SaveConfig(fileConfig); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	effective, err = NewLoader(store, map[string]string{"security-level": "max"}).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if timeout := effective.SessionTimeout(); timeout != 20*time.Minute {
+		t.Errorf("Expected the timeout chosen in the file, got %v", timeout)
+	}
+
+	t.Setenv("VETERM_SESSION_TIMEOUT", "5")
+	effective, err = NewLoader(store, nil).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if timeout := effective.SessionTimeout(); timeout != 5*time.Minute {
+		t.Errorf("Expected the timeout from the environment, got %v", timeout)
+	}
+
+	effective, err = NewLoader(store, map[string]string{"session-timeout": "3"}).Load()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if timeout := effective.SessionTimeout(); timeout != 3*time.Minute {
+		t.Errorf("Expected the timeout from the flag, got %v", timeout)
+	}
+}
+
+func TestLoaderRejectsInvalidValues(t *testing.T) {
+	store := newTestStorage(t)
+
+	t.Setenv("VETERM_TIMEOUT", "soon")
+	if _, err := NewLoader(store, nil).Load(); err == nil {
+		t.Error("Expected error for invalid env duration")
+	}
+
+	t.Setenv("VETERM_TIMEOUT", "")
+	if _, err := NewLoader(store, map[string]string{"theme": "neon"}).Load(); err == nil {
+		t.Error("Expected error for unknown theme")
+	}
+
+	newer := []byte(`{"version": 99}`)
+	if err := os.WriteFile(filepath.Join(store.DataDir(), "config.json"), newer, 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if _, err := NewLoader(store, nil).Load(); err == nil {
+		t.Error("Expected error for newer config version")
+	}
+}
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	collect := RegisterFlags(fs)
+
+	if err := fs.Parse([]string{"--network", "testnet", "--cache-ttl", "45s"}); err != nil {
+		t.Fatalf("Failed to parse flags: %v", err)
+	}
+
+	values := collect()
+	if len(values) != 2 || values["network"] != "testnet" || values["cache-ttl"] != "45s" {
+		t.Errorf("Unexpected flag values: %v", values)
+	}
+}
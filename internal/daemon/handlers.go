@@ -0,0 +1,716 @@
+package daemon
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const viewName = "daemon"
+
+type handlerFunc func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handlerFunc{
+	"wallet.list":      (*Server).walletList,
+	"wallet.unlock":    (*Server).walletUnlock,
+	"wallet.lock":      (*Server).walletLock,
+	"wallet.balance":   (*Server).walletBalance,
+	"wallet.history":   (*Server).walletHistory,
+	"contacts.lookup":  (*Server).contactsLookup,
+	"tx.build":         (*Server).txBuild,
+	"tx.sign":          (*Server).txSign,
+	"tx.status":        (*Server).txStatus,
+	"tx.pending":       (*Server).txPending,
+	"schedule.list":    (*Server).scheduleList,
+	"schedule.runs":    (*Server).scheduleRuns,
+	"schedule.approve": (*Server).scheduleApprove,
+	"schedule.reject":  (*Server).scheduleReject,
+}
+
+// Result types
+
+type WalletInfo struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Address   string `json:"address"`
+	Source    string `json:"source,omitempty"`
+	CreatedAt string `json:"created_at"`
+	Unlocked  bool   `json:"unlocked"`
+	// NeedsReimport is set for wallets saved without a signing key
+	NeedsReimport bool `json:"needs_reimport,omitempty"`
+}
+
+type SessionInfo struct {
+	WalletID     string    `json:"wallet_id"`
+	SessionToken string    `json:"session_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type BalanceInfo struct {
+	Address     string    `json:"address"`
+	VET         string    `json:"vet"`
+	VTHO        string    `json:"vtho"`
+	VETWei      string    `json:"vet_wei"`
+	VTHOWei     string    `json:"vtho_wei"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+type TransactionInfo struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Amount   string `json:"amount"`
+	Asset    string `json:"asset"`
+	GasLimit string `json:"gas_limit"`
+	GasPrice string `json:"gas_price"`
+	TxID     string `json:"tx_id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	RawTx    string `json:"raw_tx,omitempty"`
+}
+
+// Param types
+
+type walletParams struct {
+	WalletID string `json:"wallet_id"`
+}
+
+type transferParams struct {
+	WalletID     string `json:"wallet_id"`
+	SessionToken string `json:"session_token,omitempty"`
+	To           string `json:"to"`
+	Amount       string `json:"amount"`
+	Asset        string `json:"asset"`
+	Broadcast    *bool  `json:"broadcast,omitempty"`
+	// Code is the TOTP or recovery code wallets with a second factor need for
+	// each payment they sign
+	Code string `json:"code,omitempty"`
+}
+
+func (s *Server) walletList(params json.RawMessage) (interface{}, error) {
+	wallets, err := s.storage.ListWallets()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]WalletInfo, 0, len(wallets))
+	for _, w := range wallets {
+		_, unlocked := s.sessionManager.GetSession(w.ID)
+		result = append(result, WalletInfo{
+			ID:            w.ID,
+			Name:          w.Name,
+			Address:       w.Address,
+			Source:        string(w.Source),
+			CreatedAt:     w.CreatedAt,
+			Unlocked:      unlocked,
+			NeedsReimport: w.NeedsReimport(),
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Server) walletUnlock(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		WalletID string `json:"wallet_id"`
+		Password string `json:"password"`
+		// Code is the TOTP or recovery code for wallets with a second factor
+		Code string `json:"code,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findWallet(p.WalletID); err != nil {
+		return nil, err
+	}
+
+	if s.securityManager.IsAccountLocked(p.WalletID) {
+		remaining := s.securityManager.GetRemainingLockoutTime(p.WalletID)
+		return nil, NewRPCError(CodeAccountLocked, "wallet is locked for %s after too many failed attempts", utils.FormatDuration(remaining))
+	}
+
+	wallet, err := s.storage.LoadWallet(p.WalletID, p.Password)
+	if errors.Is(err, models.ErrWatchOnly) || errors.Is(err, storage.ErrKeystoreMissing) || errors.Is(err, storage.ErrAddressMismatch) || errors.Is(err, storage.ErrTwoFactorRemoved) {
+		return nil, NewRPCError(CodeKeystoreError, "%v", err)
+	}
+	if err != nil {
+		s.securityManager.RecordFailedAttempt(p.WalletID)
+		return nil, NewRPCError(CodeUnauthorized, "invalid password")
+	}
+	if err := s.verifySecondFactor(wallet, p.Code); err != nil {
+		return nil, err
+	}
+	s.securityManager.RecordSuccessfulAttempt(p.WalletID)
+
+	session, err := s.sessionManager.CreateSession(wallet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	s.sessionManager.RecordActivity(wallet.ID, "unlock", viewName)
+
+	return SessionInfo{
+		WalletID:     wallet.ID,
+		SessionToken: session.SessionToken,
+		ExpiresAt:    session.ExpiresAt,
+	}, nil
+}
+
+// verifySecondFactor checks the code a wallet with a second factor needs to
+// unlock and again for every payment it signs, so an unlocked session alone
+// cannot move funds
+func (s *Server) verifySecondFactor(wallet *models.Wallet, code string) error {
+	err := s.securityManager.VerifySecondFactor(s.storage, wallet, code)
+	switch {
+	case errors.Is(err, security.ErrLockedOut):
+		return NewRPCError(CodeAccountLocked, "%v", err)
+	case errors.Is(err, security.ErrSecondFactorRequired), errors.Is(err, security.ErrInvalidSecondFactor):
+		return NewRPCError(CodeUnauthorized, "%v", err)
+	}
+	return err
+}
+
+func (s *Server) walletLock(params json.RawMessage) (interface{}, error) {
+	var p walletParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionManager.CloseSession(p.WalletID); err != nil {
+		return nil, NewRPCError(CodeNotFound, "no active session for wallet %s", p.WalletID)
+	}
+
+	return map[string]bool{"locked": true}, nil
+}
+
+func (s *Server) walletBalance(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		WalletID string `json:"wallet_id,omitempty"`
+		Address  string `json:"address,omitempty"`
+		Refresh  bool   `json:"refresh,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	address := p.Address
+	if p.WalletID != "" {
+		wallet, err := s.findWallet(p.WalletID)
+		if err != nil {
+			return nil, err
+		}
+		address = wallet.Address
+	}
+	if err := utils.ValidateVeChainAddress(address); err != nil {
+		return nil, NewRPCError(CodeInvalidParams, "invalid address: %v", err)
+	}
+
+	client, err := s.requireClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var balance *blockchain.Balance
+	if p.Refresh {
+		balance, err = client.RefreshBalance(address)
+	} else {
+		balance, err = client.GetBalance(address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return BalanceInfo{
+		Address:     address,
+		VET:         utils.FormatAmount(balance.VET, 18),
+		VTHO:        utils.FormatAmount(balance.VTHO, 18),
+		VETWei:      balance.VET.String(),
+		VTHOWei:     balance.VTHO.String(),
+		LastUpdated: balance.LastUpdated,
+	}, nil
+}
+
+func (s *Server) walletHistory(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		WalletID string `json:"wallet_id"`
+		Limit    int    `json:"limit,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.findWallet(p.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The daemon pays from the wallet's own address, so other accounts
+	// derived from its seed are left out
+	transactions, err := s.storage.LoadAccountHistory(p.WalletID, wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// Most recent first
+	result := make([]models.Transaction, 0, len(transactions))
+	for i := len(transactions) - 1; i >= 0; i-- {
+		result = append(result, transactions[i])
+	}
+	if p.Limit > 0 && len(result) > p.Limit {
+		result = result[:p.Limit]
+	}
+
+	return result, nil
+}
+
+func (s *Server) contactsLookup(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Query   string `json:"query,omitempty"`
+		Address string `json:"address,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	contacts, err := s.storage.LoadContacts()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Address != "" {
+		contact := contacts.FindByAddress(p.Address)
+		if contact == nil {
+			return []models.Contact{}, nil
+		}
+		return []models.Contact{*contact}, nil
+	}
+
+	query := strings.ToLower(strings.TrimSpace(p.Query))
+	result := make([]models.Contact, 0)
+	for _, contact := range contacts.Contacts {
+		if query == "" ||
+			strings.Contains(strings.ToLower(contact.Name), query) ||
+			strings.Contains(strings.ToLower(contact.Address), query) ||
+			strings.Contains(strings.ToLower(contact.Category), query) {
+			result = append(result, contact)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Server) txBuild(params json.RawMessage) (interface{}, error) {
+	var p transferParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.findWallet(p.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, amount, asset, err := parseTransfer(p)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.requireClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := client.BuildTransaction(wallet.Address, to, amount, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	return transactionInfo(tx), nil
+}
+
+func (s *Server) txSign(params json.RawMessage) (interface{}, error) {
+	var p transferParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	session, err := s.requireSession(p.WalletID, p.SessionToken)
+	if err != nil {
+		return nil, err
+	}
+	wallet := session.UnlockedWallet
+
+	if wallet.PrivateKey == nil {
+		return nil, NewRPCError(CodePolicyViolation, "wallet %s has no signing key available", wallet.ID)
+	}
+
+	to, amount, asset, err := parseTransfer(p)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.requireClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := client.BuildTransaction(wallet.Address, to, amount, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.policy != nil {
+		if err := s.policy.CheckTransaction(wallet, tx); err != nil {
+			return nil, NewRPCError(CodePolicyViolation, "policy check failed: %v", err)
+		}
+	}
+	// The code is checked last so a payment the policy refuses does not use
+	// it up
+	if err := s.verifySecondFactor(wallet, p.Code); err != nil {
+		return nil, err
+	}
+
+	signedTx, err := client.SignTransaction(tx, wallet.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	s.sessionManager.RecordActivity(wallet.ID, "sign_transaction", viewName)
+
+	info := transactionInfo(tx)
+	info.TxID = signedTx.ID().String()
+
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signed transaction: %w", err)
+	}
+	info.RawTx = "0x" + hex.EncodeToString(raw)
+
+	// A transaction handed back unbroadcast can still be sent by the caller,
+	// so it is recorded as pending and counts toward the spending limits
+	// like any other payment
+	if p.Broadcast != nil && !*p.Broadcast {
+		if err := s.recordSent(wallet, tx, info.TxID); err != nil {
+			return nil, fmt.Errorf("transaction %s signed but not recorded: %w", info.TxID, err)
+		}
+		info.Status = "signed"
+		return info, nil
+	}
+
+	txID, err := client.BroadcastTransaction(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	info.TxID = txID
+	info.Status = string(blockchain.StatusPending)
+
+	if err := s.recordSent(wallet, tx, txID); err != nil {
+		return nil, fmt.Errorf("transaction %s broadcast but not recorded: %w", txID, err)
+	}
+
+	return info, nil
+}
+
+// recordSent saves a signed payment to the wallet's history as pending
+func (s *Server) recordSent(wallet *models.Wallet, tx *blockchain.Transaction, txID string) error {
+	record := models.NewTransaction(wallet.Address, tx.To, tx.Amount, string(tx.Asset))
+	record.Hash = txID
+	record.Direction = models.TransactionDirectionSent
+	record.Gas = tx.GasLimit.Uint64()
+	record.GasPrice = tx.GasPrice
+	if contacts, err := s.storage.LoadContacts(); err == nil {
+		if contact := contacts.FindByAddress(tx.To); contact != nil {
+			record.ContactName = contact.Name
+		}
+	}
+	return s.storage.SaveTransaction(wallet.ID, record)
+}
+
+func (s *Server) txStatus(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		TxID     string `json:"tx_id"`
+		WalletID string `json:"wallet_id,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+	if p.TxID == "" {
+		return nil, NewRPCError(CodeInvalidParams, "tx_id is required")
+	}
+
+	client, err := s.requireClient()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.GetTransactionStatus(p.TxID)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.WalletID != "" {
+		s.updateRecordedStatus(p.WalletID, p.TxID, *status)
+	}
+
+	return map[string]string{"tx_id": p.TxID, "status": string(*status)}, nil
+}
+
+func (s *Server) txPending(params json.RawMessage) (interface{}, error) {
+	var p walletParams
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findWallet(p.WalletID); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.storage.LoadTransactionHistory(p.WalletID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]models.Transaction, 0)
+	for _, tx := range transactions {
+		if tx.Status != models.TransactionStatusPending {
+			continue
+		}
+
+		// Refresh from the network where possible so callers see settled state
+		if s.client != nil && tx.Hash != "" {
+			if status, err := s.client.GetTransactionStatus(tx.Hash); err == nil && *status != blockchain.StatusPending {
+				s.updateRecordedStatus(p.WalletID, tx.Hash, *status)
+				continue
+			}
+		}
+		pending = append(pending, tx)
+	}
+
+	return pending, nil
+}
+
+func (s *Server) scheduleList(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		WalletID string `json:"wallet_id,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.storage.LoadSchedules()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.PaymentSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if p.WalletID == "" || schedule.WalletID == p.WalletID {
+			result = append(result, schedule)
+		}
+	}
+	return result, nil
+}
+
+func (s *Server) scheduleRuns(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		WalletID string `json:"wallet_id,omitempty"`
+		Status   string `json:"status,omitempty"`
+		Limit    int    `json:"limit,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	runs, err := s.storage.LoadScheduledRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	// Most recently due first
+	result := make([]models.ScheduledRun, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if (p.WalletID == "" || run.WalletID == p.WalletID) && (p.Status == "" || string(run.Status) == p.Status) {
+			result = append(result, run)
+		}
+	}
+	if p.Limit > 0 && len(result) > p.Limit {
+		result = result[:p.Limit]
+	}
+	return result, nil
+}
+
+func (s *Server) scheduleApprove(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		WalletID     string `json:"wallet_id"`
+		SessionToken string `json:"session_token"`
+		RunID        string `json:"run_id"`
+		Code         string `json:"code,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	scheduler, err := s.requireScheduler()
+	if err != nil {
+		return nil, err
+	}
+	session, err := s.requireSession(p.WalletID, p.SessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.verifySecondFactor(session.UnlockedWallet, p.Code); err != nil {
+		return nil, err
+	}
+
+	run, err := scheduler.Approve(p.RunID, session.UnlockedWallet)
+	if err != nil {
+		return nil, NewRPCError(CodePolicyViolation, "%v", err)
+	}
+	s.sessionManager.RecordActivity(p.WalletID, "approve_scheduled_payment", viewName)
+
+	return run, nil
+}
+
+func (s *Server) scheduleReject(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		RunID  string `json:"run_id"`
+		Reason string `json:"reason,omitempty"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	scheduler, err := s.requireScheduler()
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := scheduler.Reject(p.RunID, p.Reason)
+	if err != nil {
+		return nil, NewRPCError(CodeNotFound, "%v", err)
+	}
+	return run, nil
+}
+
+// Helpers
+
+func (s *Server) findWallet(walletID string) (*storage.EncryptedWallet, error) {
+	if walletID == "" {
+		return nil, NewRPCError(CodeInvalidParams, "wallet_id is required")
+	}
+
+	wallets, err := s.storage.ListWallets()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range wallets {
+		if wallets[i].ID == walletID {
+			return &wallets[i], nil
+		}
+	}
+
+	return nil, NewRPCError(CodeNotFound, "wallet not found: %s", walletID)
+}
+
+// requireSession validates the session token against the session manager and
+// extends the session so that idle timeouts are measured from the last call.
+func (s *Server) requireSession(walletID, token string) (*security.WalletSession, error) {
+	if walletID == "" {
+		return nil, NewRPCError(CodeInvalidParams, "wallet_id is required")
+	}
+
+	if token == "" || !s.sessionManager.ValidateSession(walletID, token) {
+		return nil, NewRPCError(CodeSessionRequired, "wallet %s is locked or the session has expired; call wallet.unlock", walletID)
+	}
+
+	session, ok := s.sessionManager.GetSession(walletID)
+	if !ok {
+		return nil, NewRPCError(CodeSessionRequired, "wallet %s is locked or the session has expired; call wallet.unlock", walletID)
+	}
+
+	if err := s.sessionManager.ExtendSession(walletID); err != nil {
+		return nil, NewRPCError(CodeSessionRequired, "failed to extend session: %v", err)
+	}
+
+	return session, nil
+}
+
+func (s *Server) requireScheduler() (*scheduler.Scheduler, error) {
+	if s.scheduler == nil {
+		return nil, NewRPCError(CodeInternalError, "scheduled payments are not enabled")
+	}
+	return s.scheduler, nil
+}
+
+func (s *Server) requireClient() (*blockchain.Client, error) {
+	if s.client == nil {
+		return nil, NewRPCError(CodeNetworkError, "blockchain client not available")
+	}
+	return s.client, nil
+}
+
+func (s *Server) updateRecordedStatus(walletID, txHash string, status blockchain.TransactionStatus) {
+	transactions, err := s.storage.LoadTransactionHistory(walletID)
+	if err != nil {
+		return
+	}
+
+	for _, tx := range transactions {
+		if strings.EqualFold(tx.Hash, txHash) {
+			tx.Status = models.TransactionStatus(status)
+			s.storage.SaveTransaction(walletID, &tx)
+			return
+		}
+	}
+}
+
+func parseTransfer(p transferParams) (string, *big.Int, blockchain.AssetType, error) {
+	to := strings.TrimSpace(p.To)
+	if err := utils.ValidateVeChainAddress(to); err != nil {
+		return "", nil, "", NewRPCError(CodeInvalidParams, "invalid recipient: %v", err)
+	}
+
+	amount, err := utils.ValidateAmount(p.Amount, 18)
+	if err != nil {
+		return "", nil, "", NewRPCError(CodeInvalidParams, "invalid amount: %v", err)
+	}
+
+	asset := blockchain.AssetType(strings.ToUpper(strings.TrimSpace(p.Asset)))
+	if asset == "" {
+		asset = blockchain.VET
+	}
+	if asset != blockchain.VET && asset != blockchain.VTHO {
+		return "", nil, "", NewRPCError(CodeInvalidParams, "unsupported asset: %s", p.Asset)
+	}
+
+	return to, amount, asset, nil
+}
+
+func transactionInfo(tx *blockchain.Transaction) TransactionInfo {
+	info := TransactionInfo{
+		From:   tx.From,
+		To:     tx.To,
+		Amount: utils.FormatAmount(tx.Amount, 18),
+		Asset:  string(tx.Asset),
+		TxID:   tx.TxID,
+		Status: string(tx.Status),
+	}
+	if tx.GasLimit != nil {
+		info.GasLimit = tx.GasLimit.String()
+	}
+	if tx.GasPrice != nil {
+		info.GasPrice = tx.GasPrice.String()
+	}
+	return info
+}
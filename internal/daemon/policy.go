@@ -0,0 +1,108 @@
+package daemon
+
+import (
+	"fmt"
+
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+// Policy decides whether a transaction may be signed on behalf of a wallet
+type Policy interface {
+	CheckTransaction(wallet *models.Wallet, tx *blockchain.Transaction) error
+}
+
+// Policies runs each policy in turn and stops at the first refusal
+type Policies []Policy
+
+func (ps Policies) CheckTransaction(wallet *models.Wallet, tx *blockchain.Transaction) error {
+	for _, policy := range ps {
+		if err := policy.CheckTransaction(wallet, tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withoutSpendingPolicy returns policy with any SpendingPolicy left out, or
+// nil when nothing else remains
+func withoutSpendingPolicy(policy Policy) Policy {
+	switch policy := policy.(type) {
+	case *SpendingPolicy:
+		return nil
+	case Policies:
+		var rest Policies
+		for _, p := range policy {
+			if p := withoutSpendingPolicy(p); p != nil {
+				rest = append(rest, p)
+			}
+		}
+		if len(rest) == 0 {
+			return nil
+		}
+		return rest
+	}
+	return policy
+}
+
+// SpendingPolicy enforces the wallet's spending policy. Payments above its
+// confirmation threshold are refused, since there is nobody to confirm them.
+type SpendingPolicy struct {
+	guard *security.SpendingGuard
+}
+
+func NewSpendingPolicy(guard *security.SpendingGuard) *SpendingPolicy {
+	return &SpendingPolicy{guard: guard}
+}
+
+func (p *SpendingPolicy) CheckTransaction(wallet *models.Wallet, tx *blockchain.Transaction) error {
+	return p.guard.Check(wallet.ID, wallet.Address, tx.To, tx.Amount, string(tx.Asset), false)
+}
+
+// BasicPolicy enforces the same checks the send flow performs before review:
+// a valid recipient, a positive amount and sufficient funds.
+type BasicPolicy struct {
+	client *blockchain.Client
+}
+
+func NewBasicPolicy(client *blockchain.Client) *BasicPolicy {
+	return &BasicPolicy{client: client}
+}
+
+func (p *BasicPolicy) CheckTransaction(wallet *models.Wallet, tx *blockchain.Transaction) error {
+	if err := utils.ValidateVeChainAddress(tx.To); err != nil {
+		return fmt.Errorf("invalid recipient: %w", err)
+	}
+
+	if tx.Amount == nil || tx.Amount.Sign() <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+
+	if p.client == nil {
+		return nil
+	}
+
+	balance, err := p.client.GetBalance(wallet.Address)
+	if err != nil {
+		return fmt.Errorf("failed to check balance: %w", err)
+	}
+
+	switch tx.Asset {
+	case blockchain.VET:
+		if tx.Amount.Cmp(balance.VET) > 0 {
+			return fmt.Errorf("insufficient VET balance (need %s, have %s)",
+				utils.FormatAmount(tx.Amount, 4), utils.FormatAmount(balance.VET, 4))
+		}
+	case blockchain.VTHO:
+		if tx.Amount.Cmp(balance.VTHO) > 0 {
+			return fmt.Errorf("insufficient VTHO balance (need %s, have %s)",
+				utils.FormatAmount(tx.Amount, 4), utils.FormatAmount(balance.VTHO, 4))
+		}
+	default:
+		return fmt.Errorf("unsupported asset type: %s", tx.Asset)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const jsonRPCVersion = "2.0"
+
+// Standard JSON-RPC 2.0 error codes plus daemon specific codes in the
+// implementation defined server error range.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeUnauthorized    = -32001
+	CodeSessionRequired = -32002
+	CodeAccountLocked   = -32003
+	CodePolicyViolation = -32004
+	CodeNetworkError    = -32005
+	CodeNotFound        = -32006
+	CodeKeystoreError   = -32007
+)
+
+// Request is a single JSON-RPC 2.0 request
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a single JSON-RPC 2.0 response
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is the error object carried in a failed response
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// NewRPCError creates a new RPC error with the given code
+func NewRPCError(code int, format string, args ...interface{}) *RPCError {
+	return &RPCError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func (r *Request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+func decodeParams(raw json.RawMessage, v interface{}) error {
+	if len(raw) == 0 {
+		return NewRPCError(CodeInvalidParams, "missing params")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return NewRPCError(CodeInvalidParams, "invalid params: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,431 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+const (
+	DefaultSocketName = "veterm.sock"
+	DefaultTokenName  = "daemon.token"
+
+	maxRequestSize = 1 << 20
+)
+
+// Config controls where the daemon listens and how it authenticates callers.
+// Exactly one of SocketPath or ListenAddr should be set; ListenAddr must be a
+// loopback address.
+type Config struct {
+	SocketPath string
+	ListenAddr string
+	TokenFile  string
+}
+
+// DefaultConfig returns a config that serves on a unix socket inside dataDir
+func DefaultConfig(dataDir string) Config {
+	return Config{
+		SocketPath: filepath.Join(dataDir, DefaultSocketName),
+		TokenFile:  filepath.Join(dataDir, DefaultTokenName),
+	}
+}
+
+// Server serves the wallet JSON-RPC API over newline delimited JSON
+type Server struct {
+	config          Config
+	token           string
+	storage         *storage.Storage
+	client          *blockchain.Client
+	sessionManager  *security.SessionManager
+	securityManager *security.SecurityManager
+	policy          Policy
+	guard           *security.SpendingGuard
+	scheduler       *scheduler.Scheduler
+
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// connState tracks per-connection authentication
+type connState struct {
+	authenticated bool
+}
+
+func NewServer(config Config, storage *storage.Storage, client *blockchain.Client, sessionManager *security.SessionManager, securityManager *security.SecurityManager) (*Server, error) {
+	if config.SocketPath == "" && config.ListenAddr == "" {
+		return nil, fmt.Errorf("either a socket path or a listen address is required")
+	}
+	if config.SocketPath != "" && config.ListenAddr != "" {
+		return nil, fmt.Errorf("socket path and listen address are mutually exclusive")
+	}
+	if config.TokenFile == "" {
+		return nil, fmt.Errorf("token file is required")
+	}
+
+	token, err := LoadOrCreateToken(config.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+
+	auditor, err := audit.NewPolicyAuditor(storage.AuditLogDir())
+	if err != nil {
+		return nil, err
+	}
+	guard := security.NewSpendingGuard(storage, auditor)
+
+	return &Server{
+		config:          config,
+		token:           token,
+		storage:         storage,
+		client:          client,
+		sessionManager:  sessionManager,
+		securityManager: securityManager,
+		policy:          Policies{NewBasicPolicy(client), NewSpendingPolicy(guard)},
+		guard:           guard,
+		conns:           make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// SetPolicy replaces the policy evaluated before signing
+func (s *Server) SetPolicy(policy Policy) {
+	s.policy = policy
+}
+
+// CheckTransaction runs the current policy, so signers outside the server
+// follow the same rules as tx.sign
+func (s *Server) CheckTransaction(wallet *models.Wallet, tx *blockchain.Transaction) error {
+	if s.policy == nil {
+		return nil
+	}
+	return s.policy.CheckTransaction(wallet, tx)
+}
+
+// CheckScheduledTransaction runs the current policy for a scheduled payment.
+// The scheduler checks the spending policy itself, passing on whether the
+// run was approved, so it is skipped here.
+func (s *Server) CheckScheduledTransaction(wallet *models.Wallet, tx *blockchain.Transaction) error {
+	policy := withoutSpendingPolicy(s.policy)
+	if policy == nil {
+		return nil
+	}
+	return policy.CheckTransaction(wallet, tx)
+}
+
+// SpendingGuard returns the guard behind the server's spending policy, for
+// the scheduler to share
+func (s *Server) SpendingGuard() *security.SpendingGuard {
+	return s.guard
+}
+
+// SetScheduler enables the schedule methods
+func (s *Server) SetScheduler(scheduler *scheduler.Scheduler) {
+	s.scheduler = scheduler
+}
+
+// Listen opens the configured socket or loopback port
+func (s *Server) Listen() error {
+	var (
+		listener net.Listener
+		err      error
+	)
+
+	if s.config.SocketPath != "" {
+		if err := removeStaleSocket(s.config.SocketPath); err != nil {
+			return err
+		}
+		listener, err = net.Listen("unix", s.config.SocketPath)
+		if err != nil {
+			return fmt.Errorf("failed to listen on socket: %w", err)
+		}
+		if err := os.Chmod(s.config.SocketPath, 0600); err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to secure socket: %w", err)
+		}
+	} else {
+		if err := validateLoopback(s.config.ListenAddr); err != nil {
+			return err
+		}
+		listener, err = net.Listen("tcp", s.config.ListenAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", s.config.ListenAddr, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Serve accepts connections until Close is called
+func (s *Server) Serve() error {
+	s.mu.Lock()
+	listener := s.listener
+	s.mu.Unlock()
+
+	if listener == nil {
+		return fmt.Errorf("server is not listening")
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("failed to accept connection: %w", err)
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// ListenAndServe opens the listener and serves until Close is called
+func (s *Server) ListenAndServe() error {
+	if err := s.Listen(); err != nil {
+		return err
+	}
+	return s.Serve()
+}
+
+// Close stops accepting connections, closes open ones and locks every wallet
+// that was unlocked through the daemon.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	if s.sessionManager != nil {
+		s.sessionManager.CloseAllSessions()
+	}
+	if s.config.SocketPath != "" {
+		os.Remove(s.config.SocketPath)
+	}
+
+	return err
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		conn.Close()
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+	}()
+
+	state := &connState{}
+	reader := bufio.NewReaderSize(conn, 4096)
+	encoder := json.NewEncoder(conn)
+
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			if errors.Is(err, errRequestTooLarge) {
+				encoder.Encode(errorResponse(nil, NewRPCError(CodeInvalidRequest, "request too large")))
+			}
+			return
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		response := s.handleMessage(state, line)
+		if response == nil {
+			continue
+		}
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handleMessage(state *connState, line []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, NewRPCError(CodeParseError, "parse error: %v", err))
+	}
+
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		return errorResponse(req.ID, NewRPCError(CodeInvalidRequest, "invalid request"))
+	}
+
+	result, err := s.dispatch(state, &req)
+	if req.isNotification() {
+		return nil
+	}
+	if err != nil {
+		return errorResponse(req.ID, toRPCError(err))
+	}
+
+	return &Response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(state *connState, req *Request) (interface{}, error) {
+	if req.Method == "auth" {
+		return s.handleAuth(state, req.Params)
+	}
+
+	if !state.authenticated {
+		return nil, NewRPCError(CodeUnauthorized, "unauthorized: call auth with the daemon token first")
+	}
+
+	handler, exists := handlers[req.Method]
+	if !exists {
+		return nil, NewRPCError(CodeMethodNotFound, "method not found: %s", req.Method)
+	}
+
+	return handler(s, req.Params)
+}
+
+func (s *Server) handleAuth(state *connState, params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Token string `json:"token"`
+	}
+	if err := decodeParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	if !tokensEqual(s.token, p.Token) {
+		state.authenticated = false
+		return nil, NewRPCError(CodeUnauthorized, "invalid token")
+	}
+
+	state.authenticated = true
+	return map[string]bool{"authenticated": true}, nil
+}
+
+func errorResponse(id json.RawMessage, rpcErr *RPCError) *Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &Response{JSONRPC: jsonRPCVersion, ID: id, Error: rpcErr}
+}
+
+func toRPCError(err error) *RPCError {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr
+	}
+
+	var chainErr *blockchain.BlockchainError
+	if errors.As(err, &chainErr) && chainErr.Type == blockchain.ErrNetworkConnection {
+		return &RPCError{Code: CodeNetworkError, Message: err.Error()}
+	}
+
+	return &RPCError{Code: CodeInternalError, Message: err.Error()}
+}
+
+var errRequestTooLarge = errors.New("request too large")
+
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				return line, nil
+			}
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxRequestSize {
+			return nil, errRequestTooLarge
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket path: %w", err)
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+
+	// Refuse to steal the socket from a daemon that is still running
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("another daemon is already listening on %s", path)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
+
+func validateLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid listen address: %w", err)
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("listen address must be a loopback address, got %s", host)
+	}
+	return nil
+}
@@ -0,0 +1,514 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+const (
+	testPassword = "correct-horse-battery"
+	testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+)
+
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int
+}
+
+func (c *testClient) call(method string, params interface{}) *Response {
+	c.t.Helper()
+	c.nextID++
+
+	id, _ := json.Marshal(c.nextID)
+	req := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      json.RawMessage(id),
+		"method":  method,
+	}
+	if params != nil {
+		req["params"] = params
+	}
+
+	if err := json.NewEncoder(c.conn).Encode(req); err != nil {
+		c.t.Fatalf("Failed to send request: %v", err)
+	}
+
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		c.t.Fatalf("Failed to read response: %v", err)
+	}
+
+	var resp Response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		c.t.Fatalf("Failed to decode response %q: %v", line, err)
+	}
+	return &resp
+}
+
+func setupServer(t *testing.T) (*Server, *testClient, *models.Wallet) {
+	t.Helper()
+
+	// Unix socket paths are length limited, so avoid the long t.TempDir path
+	dir, err := os.MkdirTemp("", "vtd")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	t.Setenv("HOME", dir)
+	t.Setenv(storage.HomeEnv, "")
+	t.Setenv(storage.ProfileEnv, "")
+
+	store, err := storage.NewStorage()
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Daemon Test", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	wallet.ID = "daemon-test-wallet"
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	sessionManager := security.NewSessionManager(store)
+	t.Cleanup(sessionManager.Shutdown)
+	securityManager := security.NewSecurityManager(sessionManager)
+
+	server, err := NewServer(DefaultConfig(store.DataDir()), store, nil, sessionManager, securityManager)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Listen(); err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go server.Serve()
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := net.Dial("unix", server.config.SocketPath)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return server, &testClient{t: t, conn: conn, reader: bufio.NewReader(conn)}, wallet
+}
+
+func TestServer_RequiresAuthentication(t *testing.T) {
+	server, client, _ := setupServer(t)
+
+	resp := client.call("wallet.list", nil)
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected unauthorized error, got %+v", resp)
+	}
+
+	resp = client.call("auth", map[string]string{"token": "wrong"})
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected invalid token error, got %+v", resp)
+	}
+
+	resp = client.call("auth", map[string]string{"token": server.token})
+	if resp.Error != nil {
+		t.Fatalf("Expected auth to succeed, got %+v", resp.Error)
+	}
+
+	resp = client.call("wallet.list", nil)
+	if resp.Error != nil {
+		t.Fatalf("Expected wallet.list to succeed, got %+v", resp.Error)
+	}
+}
+
+func TestServer_WalletListAndUnknownMethod(t *testing.T) {
+	server, client, wallet := setupServer(t)
+	client.call("auth", map[string]string{"token": server.token})
+
+	resp := client.call("wallet.list", nil)
+	data, _ := json.Marshal(resp.Result)
+
+	var wallets []WalletInfo
+	if err := json.Unmarshal(data, &wallets); err != nil {
+		t.Fatalf("Failed to decode wallets: %v", err)
+	}
+	if len(wallets) != 1 || wallets[0].ID != wallet.ID {
+		t.Fatalf("Expected wallet %s, got %+v", wallet.ID, wallets)
+	}
+	if wallets[0].Unlocked {
+		t.Error("Wallet should not be unlocked")
+	}
+
+	resp = client.call("wallet.delete", nil)
+	if resp.Error == nil || resp.Error.Code != CodeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", resp)
+	}
+}
+
+func TestServer_UnlockAndSessions(t *testing.T) {
+	server, client, wallet := setupServer(t)
+	client.call("auth", map[string]string{"token": server.token})
+
+	resp := client.call("wallet.unlock", map[string]string{"wallet_id": wallet.ID, "password": "nope"})
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected invalid password error, got %+v", resp)
+	}
+	if count := server.securityManager.GetFailedAttemptCount(wallet.ID); count != 1 {
+		t.Errorf("Expected 1 failed attempt, got %d", count)
+	}
+
+	resp = client.call("tx.sign", map[string]string{
+		"wallet_id": wallet.ID,
+		"to":        "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd",
+		"amount":    "1",
+	})
+	if resp.Error == nil || resp.Error.Code != CodeSessionRequired {
+		t.Fatalf("Expected session required error, got %+v", resp)
+	}
+
+	resp = client.call("wallet.unlock", map[string]string{"wallet_id": wallet.ID, "password": testPassword})
+	if resp.Error != nil {
+		t.Fatalf("Expected unlock to succeed, got %+v", resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Result)
+	var session SessionInfo
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatalf("Failed to decode session: %v", err)
+	}
+	if !server.sessionManager.ValidateSession(wallet.ID, session.SessionToken) {
+		t.Error("Session token should be valid in the session manager")
+	}
+
+	resp = client.call("wallet.lock", map[string]string{"wallet_id": wallet.ID})
+	if resp.Error != nil {
+		t.Fatalf("Expected lock to succeed, got %+v", resp.Error)
+	}
+	if server.sessionManager.ValidateSession(wallet.ID, session.SessionToken) {
+		t.Error("Session should be closed after lock")
+	}
+}
+
+func TestServer_UnlockRequiresSecondFactor(t *testing.T) {
+	server, client, wallet := setupServer(t)
+	client.call("auth", map[string]string{"token": server.token})
+
+	twoFactor, _, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := server.storage.SetTwoFactor(wallet.ID, testPassword, twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+
+	resp := client.call("wallet.unlock", map[string]string{"wallet_id": wallet.ID, "password": testPassword})
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected authentication code required error, got %+v", resp)
+	}
+
+	resp = client.call("wallet.unlock", map[string]string{"wallet_id": wallet.ID, "password": testPassword, "code": "12345a"})
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected invalid code error, got %+v", resp)
+	}
+	if count := server.securityManager.GetFailedAttemptCount(wallet.ID); count != 1 {
+		t.Errorf("Expected the wrong code to count as a failed attempt, got %d", count)
+	}
+
+	code, _ := twoFactor.Code(time.Now())
+	resp = client.call("wallet.unlock", map[string]string{"wallet_id": wallet.ID, "password": testPassword, "code": code})
+	if resp.Error != nil {
+		t.Fatalf("Expected unlock to succeed, got %+v", resp.Error)
+	}
+}
+
+// fakeNode serves the thor endpoints building and signing a transfer needs,
+// with every account holding 10 VET and 10 VTHO
+func fakeNode(t *testing.T) *blockchain.Client {
+	t.Helper()
+
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/blocks/"):
+			fmt.Fprintf(w, `{"number":1,"id":"0x%064x"}`, 0x27)
+		case strings.HasPrefix(r.URL.Path, "/accounts/"):
+			fmt.Fprint(w, `{"balance":"0x8ac7230489e80000","energy":"0x8ac7230489e80000","hasCode":false}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(node.Close)
+
+	client, err := blockchain.NewClient(blockchain.Config{Network: blockchain.TestNet, NodeURL: node.URL})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// checkedPayer pays without a network, running check first as ChainPayer
+// does
+type checkedPayer struct {
+	check scheduler.CheckFunc
+	paid  int
+}
+
+func (p *checkedPayer) Balance(address string) (*models.CachedBalance, error) {
+	return &models.CachedBalance{VET: big.NewInt(9e18), VTHO: big.NewInt(9e18)}, nil
+}
+
+func (p *checkedPayer) Pay(wallet *models.Wallet, to string, amount *big.Int, asset string) (*models.Transaction, error) {
+	tx := &blockchain.Transaction{From: wallet.Address, To: to, Amount: amount, Asset: blockchain.AssetType(asset)}
+	if err := p.check(wallet, tx); err != nil {
+		return nil, fmt.Errorf("policy check failed: %w", err)
+	}
+	p.paid++
+	record := models.NewTransaction(wallet.Address, to, amount, asset)
+	record.Hash = fmt.Sprintf("0x%064d", p.paid)
+	record.Direction = models.TransactionDirectionSent
+	return record, nil
+}
+
+// setupPendingRun gives the server a scheduler and a 2 VET scheduled run
+// waiting for approval under a policy that confirms payments above 1 VET
+func setupPendingRun(t *testing.T, server *Server, wallet *models.Wallet) (*checkedPayer, models.ScheduledRun) {
+	t.Helper()
+
+	policy := models.NewSpendingPolicy(wallet.ID)
+	policy.Enabled = true
+	policy.VET.ConfirmAbove = big.NewInt(1e18)
+	if err := server.SpendingGuard().UpdatePolicy(policy); err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+
+	auditor, err := audit.NewPaymentAuditor(server.storage.AuditLogDir())
+	if err != nil {
+		t.Fatalf("Failed to create auditor: %v", err)
+	}
+	payer := &checkedPayer{check: server.CheckScheduledTransaction}
+	payments := scheduler.New(server.storage, payer, auditor)
+	payments.SetGuard(server.SpendingGuard())
+	server.SetScheduler(payments)
+
+	template := models.NewTransactionTemplate("Retainer", "", "0xd3ae78222beadb038203be21ed5ce7c9b1bff602", "", "VET", "", big.NewInt(2e18), nil)
+	if err := server.storage.SaveTemplates(wallet.ID, []models.TransactionTemplate{*template}); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	schedule, err := models.NewPaymentSchedule("Retainer", wallet.ID, template.ID, "0 * * * *", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	if err := payments.AddSchedule(schedule); err != nil {
+		t.Fatalf("AddSchedule failed: %v", err)
+	}
+	runs, err := payments.RunDue()
+	if err != nil || len(runs) != 1 || runs[0].Status != models.RunPending {
+		t.Fatalf("Expected one pending run, got %+v (%v)", runs, err)
+	}
+	return payer, runs[0]
+}
+
+func unlockSession(t *testing.T, client *testClient, params map[string]string) SessionInfo {
+	t.Helper()
+
+	resp := client.call("wallet.unlock", params)
+	if resp.Error != nil {
+		t.Fatalf("Expected unlock to succeed, got %+v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	var session SessionInfo
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatalf("Failed to decode session: %v", err)
+	}
+	return session
+}
+
+func TestServer_ApproveScheduledRunAboveConfirmation(t *testing.T) {
+	server, client, wallet := setupServer(t)
+	client.call("auth", map[string]string{"token": server.token})
+	payer, run := setupPendingRun(t, server, wallet)
+	session := unlockSession(t, client, map[string]string{"wallet_id": wallet.ID, "password": testPassword})
+
+	// Approving is the confirmation the policy asks for above 1 VET
+	resp := client.call("schedule.approve", map[string]string{
+		"wallet_id":     wallet.ID,
+		"session_token": session.SessionToken,
+		"run_id":        run.ID,
+	})
+	if resp.Error != nil {
+		t.Fatalf("Expected approval to pay the run, got %+v", resp.Error)
+	}
+	if payer.paid != 1 {
+		t.Errorf("Expected one payment, got %d", payer.paid)
+	}
+
+	// tx.sign has nobody to confirm, so the same payment is still refused
+	if err := server.CheckTransaction(wallet, &blockchain.Transaction{To: run.To, Amount: big.NewInt(2e18), Asset: blockchain.VET}); err == nil {
+		t.Error("Expected an unconfirmed payment above the threshold to be refused")
+	}
+}
+
+func TestServer_SigningRequiresSecondFactor(t *testing.T) {
+	server, client, wallet := setupServer(t)
+	client.call("auth", map[string]string{"token": server.token})
+	payer, run := setupPendingRun(t, server, wallet)
+
+	twoFactor, _, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := server.storage.SetTwoFactor(wallet.ID, testPassword, twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+	code, _ := twoFactor.Code(time.Now())
+	session := unlockSession(t, client, map[string]string{"wallet_id": wallet.ID, "password": testPassword, "code": code})
+	server.client = fakeNode(t)
+	fresh, _ := twoFactor.Code(time.Now().Add(models.TOTPPeriod))
+
+	// An unlocked session is not enough to sign
+	resp := client.call("tx.sign", map[string]string{
+		"wallet_id":     wallet.ID,
+		"session_token": session.SessionToken,
+		"to":            run.To,
+		"amount":        "1",
+		"asset":         "VET",
+	})
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected tx.sign without a code to be refused, got %+v", resp)
+	}
+	// The policy is checked before the code, so a refused payment does not
+	// use it up
+	resp = client.call("tx.sign", map[string]string{
+		"wallet_id":     wallet.ID,
+		"session_token": session.SessionToken,
+		"to":            run.To,
+		"amount":        "2",
+		"asset":         "VET",
+		"code":          fresh,
+	})
+	if resp.Error == nil || resp.Error.Code != CodePolicyViolation {
+		t.Fatalf("Expected tx.sign above the confirmation threshold to be refused, got %+v", resp)
+	}
+
+	approve := map[string]string{
+		"wallet_id":     wallet.ID,
+		"session_token": session.SessionToken,
+		"run_id":        run.ID,
+	}
+	resp = client.call("schedule.approve", approve)
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected approval without a code to be refused, got %+v", resp)
+	}
+	// The unlock code was spent, so it cannot approve the payment either
+	approve["code"] = code
+	resp = client.call("schedule.approve", approve)
+	if resp.Error == nil || resp.Error.Code != CodeUnauthorized {
+		t.Fatalf("Expected approval with a used code to be refused, got %+v", resp)
+	}
+	if payer.paid != 0 {
+		t.Fatalf("Expected nothing to be paid, got %d payments", payer.paid)
+	}
+
+	approve["code"] = fresh
+	resp = client.call("schedule.approve", approve)
+	if resp.Error != nil {
+		t.Fatalf("Expected approval with a fresh code to pay the run, got %+v", resp.Error)
+	}
+	if payer.paid != 1 {
+		t.Errorf("Expected one payment, got %d", payer.paid)
+	}
+}
+
+func TestServer_UnbroadcastSignatureCountsTowardLimits(t *testing.T) {
+	server, client, wallet := setupServer(t)
+	client.call("auth", map[string]string{"token": server.token})
+	server.client = fakeNode(t)
+
+	policy := models.NewSpendingPolicy(wallet.ID)
+	policy.Enabled = true
+	policy.VET.Daily = big.NewInt(3e18)
+	if err := server.SpendingGuard().UpdatePolicy(policy); err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+	session := unlockSession(t, client, map[string]string{"wallet_id": wallet.ID, "password": testPassword})
+
+	sign := map[string]interface{}{
+		"wallet_id":     wallet.ID,
+		"session_token": session.SessionToken,
+		"to":            "0xd3ae78222beadb038203be21ed5ce7c9b1bff602",
+		"amount":        "2",
+		"asset":         "VET",
+		"broadcast":     false,
+	}
+	resp := client.call("tx.sign", sign)
+	if resp.Error != nil {
+		t.Fatalf("Expected signing to succeed, got %+v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Result)
+	var info TransactionInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("Failed to decode transaction: %v", err)
+	}
+	if info.RawTx == "" || info.Status != "signed" {
+		t.Fatalf("Expected a signed raw transaction, got %+v", info)
+	}
+
+	history, err := server.storage.LoadTransactionHistory(wallet.ID)
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(history) != 1 || history[0].Hash != info.TxID || history[0].Status != models.TransactionStatusPending {
+		t.Fatalf("Expected the signed transaction to be recorded as pending, got %+v", history)
+	}
+
+	// The caller could broadcast the first one, so a second would pass the cap
+	resp = client.call("tx.sign", sign)
+	if resp.Error == nil || resp.Error.Code != CodePolicyViolation {
+		t.Fatalf("Expected the daily limit to refuse a second signature, got %+v", resp)
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.token")
+
+	token, err := LoadOrCreateToken(path)
+	if err != nil {
+		t.Fatalf("Failed to create token: %v", err)
+	}
+	if len(token) != tokenBytes*2 {
+		t.Errorf("Expected %d character token, got %d", tokenBytes*2, len(token))
+	}
+
+	again, err := LoadOrCreateToken(path)
+	if err != nil {
+		t.Fatalf("Failed to reload token: %v", err)
+	}
+	if again != token {
+		t.Error("Reloaded token should match the generated token")
+	}
+
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatalf("Failed to chmod token file: %v", err)
+	}
+	if _, err := LoadOrCreateToken(path); err == nil {
+		t.Error("Expected error for world readable token file")
+	}
+}
@@ -0,0 +1,63 @@
+package daemon
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const tokenBytes = 32
+
+// LoadOrCreateToken reads the API token from path, generating a new one if the
+// file does not exist. The token file must not be readable by other users.
+func LoadOrCreateToken(path string) (string, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return createToken(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to stat token file: %w", err)
+	}
+
+	if info.Mode().Perm()&0077 != 0 {
+		return "", fmt.Errorf("token file %s has insecure permissions %o, expected 0600", path, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+
+	return token, nil
+}
+
+func createToken(path string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+
+	bytes := make([]byte, tokenBytes)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := hex.EncodeToString(bytes)
+
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write token file: %w", err)
+	}
+
+	return token, nil
+}
+
+func tokensEqual(expected, provided string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(provided)) == 1
+}
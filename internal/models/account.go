@@ -0,0 +1,216 @@
+package models
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"strings"
+
+	"github.com/darrenvechain/thorgo/crypto/hdwallet"
+)
+
+const (
+	// AccountPathPrefix is the VeChain BIP44 path up to the address index
+	AccountPathPrefix = "m/44'/818'/0'/0/"
+
+	// DefaultGapLimit is how many consecutive unused addresses end discovery
+	DefaultGapLimit = 20
+
+	// maxDiscoveredAccounts bounds discovery against a node that reports
+	// every address as used
+	maxDiscoveredAccounts = 1000
+)
+
+// Account is one address held by a wallet. Seed wallets derive each account
+// from the mnemonic at Path; other wallets have a single account with no path.
+type Account struct {
+	Path    string `json:"path,omitempty"`
+	Label   string `json:"label"`
+	Address string `json:"address"`
+}
+
+// AccountPath returns the standard derivation path for address index i
+func AccountPath(index int) string {
+	return fmt.Sprintf("%s%d", AccountPathPrefix, index)
+}
+
+// DeriveAccount derives the key at path from mnemonic and its optional BIP39
+// passphrase. A different passphrase gives an unrelated set of accounts.
+func DeriveAccount(mnemonic, passphrase, path string) (*ecdsa.PrivateKey, string, error) {
+	derivationPath, err := hdwallet.ParseDerivationPath(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid derivation path %q: %w", path, err)
+	}
+
+	seed, err := mnemonicSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	hdWallet, err := hdwallet.FromSeedAt(seed, derivationPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to derive account: %w", err)
+	}
+
+	privateKey, err := hdWallet.PrivateKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to derive private key: %w", err)
+	}
+
+	return privateKey, hdWallet.Address().Hex(), nil
+}
+
+// DiscoverAccounts derives accounts at increasing indices and keeps every one
+// up to the last that used reports as used. Scanning stops once gap
+// consecutive addresses are unused. The first account is always kept.
+func DiscoverAccounts(mnemonic, passphrase string, gap int, used func(address string) (bool, error)) ([]Account, error) {
+	if gap <= 0 {
+		gap = DefaultGapLimit
+	}
+
+	var (
+		derived  []Account
+		lastUsed = 0
+	)
+
+	for index := 0; index < maxDiscoveredAccounts && index-lastUsed <= gap; index++ {
+		path := AccountPath(index)
+		_, address, err := DeriveAccount(mnemonic, passphrase, path)
+		if err != nil {
+			return nil, err
+		}
+		derived = append(derived, Account{Path: path, Label: defaultAccountLabel(index), Address: address})
+
+		if index == 0 {
+			continue
+		}
+		isUsed, err := used(address)
+		if err != nil {
+			return derived[:lastUsed+1], fmt.Errorf("failed to check account %d: %w", index, err)
+		}
+		if isUsed {
+			lastUsed = index
+		}
+	}
+
+	return derived[:lastUsed+1], nil
+}
+
+func defaultAccountLabel(index int) string {
+	return fmt.Sprintf("Account %d", index+1)
+}
+
+// AccountList returns the wallet's accounts. Wallets that never derived more
+// than one have a single implicit account for their own address.
+func (w *Wallet) AccountList() []Account {
+	if len(w.Accounts) > 0 {
+		return w.Accounts
+	}
+
+	account := Account{Label: defaultAccountLabel(0), Address: w.Address}
+	if w.Source == KeySourceMnemonic {
+		account.Path = AccountPath(0)
+	}
+	return []Account{account}
+}
+
+// ActiveAccountLabel names the account the wallet currently acts as
+func (w *Wallet) ActiveAccountLabel() string {
+	accounts := w.AccountList()
+	if w.ActiveAccount < 0 || w.ActiveAccount >= len(accounts) {
+		return ""
+	}
+	return accounts[w.ActiveAccount].Label
+}
+
+// UseAccount returns a copy of the wallet acting as account i: its Address
+// and, when the mnemonic is loaded, PrivateKey belong to that account. The
+// copy's cached balance is cleared. The derived address must match the one
+// recorded for the account.
+func (w *Wallet) UseAccount(i int) (*Wallet, error) {
+	accounts := w.AccountList()
+	if i < 0 || i >= len(accounts) {
+		return nil, fmt.Errorf("account %d does not exist", i)
+	}
+	account := accounts[i]
+
+	copied := *w
+	copied.Accounts = accounts
+	copied.ActiveAccount = i
+	copied.Address = account.Address
+	copied.CachedBalance = nil
+
+	if account.Path != "" && w.Mnemonic != "" {
+		privateKey, address, err := DeriveAccount(w.Mnemonic, w.Passphrase, account.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(address, account.Address) {
+			return nil, fmt.Errorf("account %s derives %s, expected %s", account.Path, address, account.Address)
+		}
+		copied.PrivateKey = privateKey
+	} else if i != 0 {
+		copied.PrivateKey = nil
+	}
+
+	return &copied, nil
+}
+
+// AddAccount derives a new account at path and appends it with label. An
+// empty path uses the next unused standard index.
+func (w *Wallet) AddAccount(path, label string) (Account, error) {
+	if !w.Source.CanDeriveAccounts() {
+		return Account{}, fmt.Errorf("%s wallets cannot derive accounts", w.Source.Label())
+	}
+	if w.Mnemonic == "" {
+		return Account{}, fmt.Errorf("wallet must be unlocked to derive accounts")
+	}
+
+	accounts := w.AccountList()
+	if path == "" {
+		path = nextAccountPath(accounts)
+	}
+	for _, existing := range accounts {
+		if existing.Path == path {
+			return Account{}, fmt.Errorf("account %s already exists", path)
+		}
+	}
+
+	_, address, err := DeriveAccount(w.Mnemonic, w.Passphrase, path)
+	if err != nil {
+		return Account{}, err
+	}
+	if label == "" {
+		label = defaultAccountLabel(len(accounts))
+	}
+
+	account := Account{Path: path, Label: label, Address: address}
+	w.Accounts = append(append([]Account(nil), accounts...), account)
+	return account, nil
+}
+
+// RenameAccount changes the label of account i
+func (w *Wallet) RenameAccount(i int, label string) error {
+	label = strings.TrimSpace(label)
+	if label == "" {
+		return fmt.Errorf("account label cannot be empty")
+	}
+	accounts := append([]Account(nil), w.AccountList()...)
+	if i < 0 || i >= len(accounts) {
+		return fmt.Errorf("account %d does not exist", i)
+	}
+	accounts[i].Label = label
+	w.Accounts = accounts
+	return nil
+}
+
+func nextAccountPath(accounts []Account) string {
+	taken := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		taken[account.Path] = true
+	}
+	index := 0
+	for taken[AccountPath(index)] {
+		index++
+	}
+	return AccountPath(index)
+}
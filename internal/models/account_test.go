@@ -0,0 +1,130 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const accountTestMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDiscoverAccounts(t *testing.T) {
+	addresses := make([]string, 8)
+	for i := range addresses {
+		_, address, err := DeriveAccount(accountTestMnemonic, "", AccountPath(i))
+		if err != nil {
+			t.Fatalf("Failed to derive account %d: %v", i, err)
+		}
+		addresses[i] = address
+	}
+
+	used := map[string]bool{addresses[1]: true, addresses[4]: true}
+	var checked []string
+	accounts, err := DiscoverAccounts(accountTestMnemonic, "", 3, func(address string) (bool, error) {
+		checked = append(checked, address)
+		return used[address], nil
+	})
+	if err != nil {
+		t.Fatalf("Discovery failed: %v", err)
+	}
+
+	// Index 4 is found because only two addresses after index 1 are unused;
+	// scanning stops after the three unused addresses following it
+	if len(accounts) != 5 {
+		t.Fatalf("Expected 5 accounts, got %d", len(accounts))
+	}
+	if accounts[4].Address != addresses[4] || accounts[4].Path != AccountPath(4) {
+		t.Errorf("Unexpected last account: %+v", accounts[4])
+	}
+	if len(checked) != 7 {
+		t.Errorf("Expected indices 1-7 to be checked, got %d checks", len(checked))
+	}
+
+	// Account zero is kept even when nothing is used
+	accounts, err = DiscoverAccounts(accountTestMnemonic, "", 3, func(string) (bool, error) { return false, nil })
+	if err != nil || len(accounts) != 1 || accounts[0].Address != addresses[0] {
+		t.Errorf("Expected only the first account, got %+v (%v)", accounts, err)
+	}
+
+	// A failed check returns what was found so far
+	accounts, err = DiscoverAccounts(accountTestMnemonic, "", 5, func(address string) (bool, error) {
+		if address == addresses[3] {
+			return false, errors.New("node unavailable")
+		}
+		return address == addresses[2], nil
+	})
+	if err == nil || len(accounts) != 3 {
+		t.Errorf("Expected 3 accounts and an error, got %d (%v)", len(accounts), err)
+	}
+}
+
+func TestUseAccount(t *testing.T) {
+	wallet, err := NewWallet("Seed", accountTestMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	if len(wallet.AccountList()) != 1 || wallet.AccountList()[0].Address != wallet.Address {
+		t.Fatalf("New wallet should have its own address as the only account")
+	}
+
+	second, err := wallet.AddAccount("", "Savings")
+	if err != nil {
+		t.Fatalf("Failed to add account: %v", err)
+	}
+	if second.Path != AccountPath(1) {
+		t.Errorf("Expected next index path, got %s", second.Path)
+	}
+	custom, err := wallet.AddAccount("m/44'/818'/1'/0/0", "")
+	if err != nil {
+		t.Fatalf("Failed to add custom path account: %v", err)
+	}
+	if _, err := wallet.AddAccount(custom.Path, ""); err == nil {
+		t.Error("Expected error for duplicate path")
+	}
+
+	active, err := wallet.UseAccount(1)
+	if err != nil {
+		t.Fatalf("Failed to switch account: %v", err)
+	}
+	if active.Address != second.Address || active.ID != wallet.ID || active.ActiveAccountLabel() != "Savings" {
+		t.Errorf("Unexpected active account: %s %s", active.Address, active.ActiveAccountLabel())
+	}
+	if got := crypto.PubkeyToAddress(active.PrivateKey.PublicKey).Hex(); got != second.Address {
+		t.Errorf("Active key derives %s, expected %s", got, second.Address)
+	}
+	if wallet.Address == active.Address {
+		t.Error("Switching accounts must not change the original wallet")
+	}
+
+	// A recorded address that the mnemonic does not derive is rejected
+	active.Accounts[2].Address = wallet.Address
+	if _, err := active.UseAccount(2); err == nil {
+		t.Error("Expected error for mismatched account address")
+	}
+
+	if _, err := wallet.UseAccount(5); err == nil {
+		t.Error("Expected error for unknown account")
+	}
+}
+
+func TestPrivateKeyWalletAccounts(t *testing.T) {
+	key, _ := ParsePrivateKey("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	wallet, _ := NewWalletFromPrivateKey("Service", key)
+
+	if _, err := wallet.AddAccount("", ""); err == nil {
+		t.Error("Private key wallets must not derive accounts")
+	}
+
+	if err := wallet.RenameAccount(0, "Hot"); err != nil {
+		t.Fatalf("Failed to rename account: %v", err)
+	}
+	active, err := wallet.UseAccount(0)
+	if err != nil {
+		t.Fatalf("Failed to use account: %v", err)
+	}
+	if active.PrivateKey != key || active.ActiveAccountLabel() != "Hot" {
+		t.Error("Single account should keep the wallet's key")
+	}
+}
@@ -0,0 +1,130 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Address poisoning sends dust from an address made to share its first and
+// last characters with a real counterparty, hoping it is later copied from
+// history. Addresses are compared as lower-case hex without the 0x prefix.
+const (
+	// LookAlikeEnds is how many characters must match at both ends
+	LookAlikeEnds = 4
+	// LookAlikeOneEnd is how many characters must match when only one end
+	// does
+	LookAlikeOneEnd = 6
+)
+
+// DustThreshold is the largest transfer, in wei, treated as dust
+var DustThreshold = big.NewInt(1e16)
+
+// KnownAddress is an address the wallet has reason to trust, such as a
+// contact or a past recipient. Label says where it came from.
+type KnownAddress struct {
+	Address string
+	Label   string
+}
+
+// RecipientWarning explains why a recipient may be a poisoned address
+type RecipientWarning struct {
+	Recipient string
+	// LookAlikes are known addresses the recipient resembles without being
+	// identical to
+	LookAlikes []KnownAddress
+	// DustOnly is set when every recorded transaction with the recipient is
+	// dust it sent to the wallet
+	DustOnly bool
+}
+
+// Reasons describes each problem on its own line
+func (w *RecipientWarning) Reasons() []string {
+	var reasons []string
+	for _, known := range w.LookAlikes {
+		reasons = append(reasons, fmt.Sprintf("Looks like %s %s but is a different address", known.Label, known.Address))
+	}
+	if w.DustOnly {
+		reasons = append(reasons, "This address has only ever sent this wallet dust, a common sign of address poisoning")
+	}
+	return reasons
+}
+
+// CheckRecipient compares recipient against known addresses and the wallet's
+// history, returning nil when nothing looks wrong. Counterparties from history
+// are known addresses too, except those that only ever sent dust, since
+// those are the suspected poisoners.
+func CheckRecipient(recipient string, known []KnownAddress, history []Transaction) *RecipientWarning {
+	warning := &RecipientWarning{Recipient: recipient}
+
+	candidates := append([]KnownAddress(nil), known...)
+	dustOnly := make(map[string]bool)
+	for _, tx := range history {
+		switch tx.Direction {
+		case TransactionDirectionSent:
+			candidates = append(candidates, KnownAddress{Address: tx.To, Label: "past recipient"})
+			dustOnly[normalizeAddress(tx.To)] = false
+		case TransactionDirectionReceived:
+			from := normalizeAddress(tx.From)
+			if isDust(tx.Amount) {
+				if _, seen := dustOnly[from]; !seen {
+					dustOnly[from] = true
+				}
+				continue
+			}
+			candidates = append(candidates, KnownAddress{Address: tx.From, Label: "past sender"})
+			dustOnly[from] = false
+		}
+	}
+	warning.DustOnly = dustOnly[normalizeAddress(recipient)]
+
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		address := normalizeAddress(candidate.Address)
+		if seen[address] || dustOnly[address] {
+			continue
+		}
+		seen[address] = true
+		if LooksAlike(recipient, candidate.Address) {
+			warning.LookAlikes = append(warning.LookAlikes, candidate)
+		}
+	}
+
+	if len(warning.LookAlikes) == 0 && !warning.DustOnly {
+		return nil
+	}
+	return warning
+}
+
+// LooksAlike reports whether two different addresses share enough leading
+// and trailing characters to be mistaken for each other in a shortened form
+// such as utils.FormatAddress produces
+func LooksAlike(a, b string) bool {
+	a, b = normalizeAddress(a), normalizeAddress(b)
+	if a == "" || b == "" || a == b {
+		return false
+	}
+
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	if prefix >= LookAlikeEnds && suffix >= LookAlikeEnds {
+		return true
+	}
+	return prefix >= LookAlikeOneEnd || suffix >= LookAlikeOneEnd
+}
+
+func normalizeAddress(address string) string {
+	address = strings.ToLower(strings.TrimSpace(address))
+	return strings.TrimPrefix(address, "0x")
+}
+
+func isDust(amount *big.Int) bool {
+	return amount == nil || amount.Cmp(DustThreshold) <= 0
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"math/big"
+	"testing"
+)
+
+const (
+	realAddress     = "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed"
+	poisonedAddress = "0x7567d83b0000000000000000000000003364ffed"
+)
+
+func TestLooksAlike(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"identical", realAddress, realAddress, false},
+		{"identical ignoring case", realAddress, "0x7567D83B7B8D80ADDCB281A71D54FC7B3364FFED", false},
+		{"matching both ends", realAddress, poisonedAddress, true},
+		{"three characters at one end", realAddress, "0x7567000000000000000000000000000000000fed", false},
+		{"four characters at both ends", realAddress, "0x756700000000000000000000000000000000ffed", true},
+		{"long prefix only", realAddress, "0x7567d8000000000000000000000000000000000a", true},
+		{"long suffix only", realAddress, "0x000000000000000000000000000000000364ffed", true},
+		{"unrelated", realAddress, "0xd3ae78222beadb038203be21ed5ce7c9b1bff602", false},
+	}
+
+	for _, test := range tests {
+		if got := LooksAlike(test.a, test.b); got != test.want {
+			t.Errorf("%s: expected LooksAlike(%s, %s) to be %v", test.name, test.a, test.b, test.want)
+		}
+	}
+}
+
+func TestCheckRecipient(t *testing.T) {
+	dust := big.NewInt(1)
+	known := []KnownAddress{{Address: realAddress, Label: "contact Alice"}}
+	history := []Transaction{
+		{From: realAddress, Amount: vet(5), Direction: TransactionDirectionReceived},
+		{From: poisonedAddress, Amount: dust, Direction: TransactionDirectionReceived},
+	}
+
+	warning := CheckRecipient(poisonedAddress, known, history)
+	if warning == nil {
+		t.Fatal("Expected a warning for a look-alike recipient")
+	}
+	if len(warning.LookAlikes) != 1 || warning.LookAlikes[0].Label != "contact Alice" {
+		t.Errorf("Expected the recipient to look like the contact once, got %+v", warning.LookAlikes)
+	}
+	if !warning.DustOnly {
+		t.Error("Expected a recipient that only sent dust to be flagged")
+	}
+	if len(warning.Reasons()) != 2 {
+		t.Errorf("Expected two reasons, got %v", warning.Reasons())
+	}
+
+	// The real address must not be flagged for resembling the poisoner
+	if warning := CheckRecipient(realAddress, known, history); warning != nil {
+		t.Errorf("Expected no warning for the known address, got %v", warning.Reasons())
+	}
+
+	// Paying an address back clears the dust flag
+	paid := append(history, Transaction{To: poisonedAddress, Amount: vet(1), Direction: TransactionDirectionSent})
+	if warning := CheckRecipient(poisonedAddress, nil, paid); warning != nil && warning.DustOnly {
+		t.Error("Expected an address the wallet has paid not to be flagged as dust-only")
+	}
+
+	if warning := CheckRecipient("0xd3ae78222beadb038203be21ed5ce7c9b1bff602", known, history); warning != nil {
+		t.Errorf("Expected no warning for an unrelated address, got %v", warning.Reasons())
+	}
+}
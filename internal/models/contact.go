@@ -34,6 +34,11 @@ type Contact struct {
 
 type ContactList struct {
 	Contacts []Contact `json:"contacts"`
+
+	// Locked is set on lists read from an encrypted contacts file before it
+	// was unlocked. Sensitive fields, and with whole-file encryption every
+	// contact, are missing, so the list must not be saved back.
+	Locked bool `json:"-"`
 }
 
 func NewContact(name, address, notes string) *Contact {
@@ -350,12 +355,12 @@ func (c *Contact) ToEncryptedContact() *types.EncryptedContact {
 		ID:            c.ID,
 		Name:          c.Name,
 		Address:       c.Address,
-		CreatedAt:     c.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:     c.UpdatedAt.Format(time.RFC3339),
+		CreatedAt:     c.CreatedAt.Format(time.RFC3339Nano),
+		UpdatedAt:     c.UpdatedAt.Format(time.RFC3339Nano),
 		IsFavorite:    c.IsFavorite,
 		Category:      c.Category,
 		Tags:          c.Tags,
-		LastUsed:      c.LastUsed.Format(time.RFC3339),
+		LastUsed:      c.LastUsed.Format(time.RFC3339Nano),
 		UseCount:      c.UseCount,
 		SensitiveData: c.encryptedData,
 	}
@@ -389,27 +394,25 @@ func (c *Contact) FromEncryptedContact(enc *types.EncryptedContact) error {
 	return nil
 }
 
-// EncryptSensitiveData encrypts sensitive fields of the contact
-func (c *Contact) EncryptSensitiveData(password string) error {
+// EncryptSensitiveData seals the notes and payment totals with seal and
+// clears them, keeping the sealed form for ToEncryptedContact
+func (c *Contact) EncryptSensitiveData(seal func([]byte) ([]byte, error)) error {
 	sensitiveData := types.SensitiveContactData{
 		Notes:     c.Notes,
 		TotalSent: c.TotalSent,
-		Metadata:  make(map[string]string),
+	}
+	if c.TotalReceived != "" {
+		sensitiveData.Metadata = map[string]string{"total_received": c.TotalReceived}
 	}
 
-	// Add any additional metadata
-	sensitiveData.Metadata["total_received"] = c.TotalReceived
-
-	// Marshal sensitive data
 	sensitiveJSON, err := json.Marshal(sensitiveData)
 	if err != nil {
 		return fmt.Errorf("failed to marshal sensitive data: %w", err)
 	}
 
-	// Encrypt the data
-	encryptedData, err := json.Marshal(sensitiveJSON)
+	encryptedData, err := seal(sensitiveJSON)
 	if err != nil {
-		return fmt.Errorf("failed to marshal encrypted data: %w", err)
+		return fmt.Errorf("failed to encrypt sensitive data: %w", err)
 	}
 
 	c.encryptedData = encryptedData
@@ -422,19 +425,18 @@ func (c *Contact) EncryptSensitiveData(password string) error {
 	return nil
 }
 
-// DecryptSensitiveData decrypts sensitive fields of the contact
-func (c *Contact) DecryptSensitiveData(password string) error {
+// DecryptSensitiveData restores the fields sealed by EncryptSensitiveData
+// using open
+func (c *Contact) DecryptSensitiveData(open func([]byte) ([]byte, error)) error {
 	if len(c.encryptedData) == 0 {
 		return nil // No encrypted data to decrypt
 	}
 
-	// Unmarshal encrypted data
-	var sensitiveJSON []byte
-	if err := json.Unmarshal(c.encryptedData, &sensitiveJSON); err != nil {
-		return fmt.Errorf("failed to unmarshal encrypted data: %w", err)
+	sensitiveJSON, err := open(c.encryptedData)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt sensitive data: %w", err)
 	}
 
-	// Unmarshal sensitive data
 	var sensitiveData types.SensitiveContactData
 	if err := json.Unmarshal(sensitiveJSON, &sensitiveData); err != nil {
 		return fmt.Errorf("failed to unmarshal sensitive data: %w", err)
@@ -446,21 +448,11 @@ func (c *Contact) DecryptSensitiveData(password string) error {
 	if totalReceived, ok := sensitiveData.Metadata["total_received"]; ok {
 		c.TotalReceived = totalReceived
 	}
+	c.encryptedData = nil
 
 	return nil
 }
 
-// ReEncryptSensitiveData re-encrypts sensitive data with a new password
-func (c *Contact) ReEncryptSensitiveData(oldPassword, newPassword string) error {
-	// First decrypt with old password
-	if err := c.DecryptSensitiveData(oldPassword); err != nil {
-		return fmt.Errorf("failed to decrypt with old password: %w", err)
-	}
-
-	// Then encrypt with new password
-	return c.EncryptSensitiveData(newPassword)
-}
-
 // HasEncryptedData returns true if the contact has encrypted sensitive data
 func (c *Contact) HasEncryptedData() bool {
 	return len(c.encryptedData) > 0
@@ -0,0 +1,300 @@
+package models
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MnemonicLanguage names a standard BIP39 wordlist
+type MnemonicLanguage string
+
+const (
+	LanguageEnglish            MnemonicLanguage = "english"
+	LanguageSpanish            MnemonicLanguage = "spanish"
+	LanguageFrench             MnemonicLanguage = "french"
+	LanguageItalian            MnemonicLanguage = "italian"
+	LanguageJapanese           MnemonicLanguage = "japanese"
+	LanguageKorean             MnemonicLanguage = "korean"
+	LanguageChineseSimplified  MnemonicLanguage = "chinese_simplified"
+	LanguageChineseTraditional MnemonicLanguage = "chinese_traditional"
+	LanguageCzech              MnemonicLanguage = "czech"
+)
+
+// Label returns the language name shown to users
+func (l MnemonicLanguage) Label() string {
+	switch l {
+	case LanguageEnglish:
+		return "English"
+	case LanguageSpanish:
+		return "Spanish"
+	case LanguageFrench:
+		return "French"
+	case LanguageItalian:
+		return "Italian"
+	case LanguageJapanese:
+		return "Japanese"
+	case LanguageKorean:
+		return "Korean"
+	case LanguageChineseSimplified:
+		return "Chinese (Simplified)"
+	case LanguageChineseTraditional:
+		return "Chinese (Traditional)"
+	case LanguageCzech:
+		return "Czech"
+	default:
+		return string(l)
+	}
+}
+
+type mnemonicWordlist struct {
+	language MnemonicLanguage
+	words    []string
+	// index maps each word in NFKD form to its position
+	index map[string]int
+	// folded holds each word lowercased and without accents, for matching
+	// what users type against it
+	folded []string
+}
+
+// mnemonicWordlists is in detection order: when a phrase fits several lists
+// equally well (the Chinese lists share most characters) the first wins.
+//
+// TODO: Portuguese is missing. go-bip39 does not ship it, so it has to be
+// vendored from bip-0039/portuguese.txt in the bitcoin/bips repository and
+// checked against that file byte for byte. A copy typed from anywhere else
+// would still pass the checksum round trip but produce phrases other
+// wallets reject.
+var mnemonicWordlists = []*mnemonicWordlist{
+	newMnemonicWordlist(LanguageEnglish, wordlists.English),
+	newMnemonicWordlist(LanguageSpanish, wordlists.Spanish),
+	newMnemonicWordlist(LanguageFrench, wordlists.French),
+	newMnemonicWordlist(LanguageItalian, wordlists.Italian),
+	newMnemonicWordlist(LanguageJapanese, wordlists.Japanese),
+	newMnemonicWordlist(LanguageKorean, wordlists.Korean),
+	newMnemonicWordlist(LanguageChineseSimplified, wordlists.ChineseSimplified),
+	newMnemonicWordlist(LanguageChineseTraditional, wordlists.ChineseTraditional),
+	newMnemonicWordlist(LanguageCzech, wordlists.Czech),
+}
+
+func newMnemonicWordlist(language MnemonicLanguage, words []string) *mnemonicWordlist {
+	list := &mnemonicWordlist{
+		language: language,
+		words:    words,
+		index:    make(map[string]int, len(words)),
+		folded:   make([]string, len(words)),
+	}
+	for i, word := range words {
+		list.index[norm.NFKD.String(word)] = i
+		list.folded[i] = foldMnemonicWord(word)
+	}
+	return list
+}
+
+func findMnemonicWordlist(language MnemonicLanguage) *mnemonicWordlist {
+	for _, list := range mnemonicWordlists {
+		if list.language == language {
+			return list
+		}
+	}
+	return nil
+}
+
+// MnemonicLanguages returns the supported wordlists in detection order
+func MnemonicLanguages() []MnemonicLanguage {
+	languages := make([]MnemonicLanguage, len(mnemonicWordlists))
+	for i, list := range mnemonicWordlists {
+		languages[i] = list.language
+	}
+	return languages
+}
+
+// normalizeMnemonicWord puts a typed word in the form the wordlists use
+func normalizeMnemonicWord(word string) string {
+	return norm.NFKD.String(strings.ToLower(strings.TrimSpace(word)))
+}
+
+// foldMnemonicWord lowercases word and drops accents, so "arbol" matches
+// "árbol" while the user is typing
+func foldMnemonicWord(word string) string {
+	var b strings.Builder
+	for _, r := range normalizeMnemonicWord(word) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// WordLanguages returns the languages whose wordlist contains word
+func WordLanguages(word string) []MnemonicLanguage {
+	word = normalizeMnemonicWord(word)
+	var languages []MnemonicLanguage
+	for _, list := range mnemonicWordlists {
+		if _, ok := list.index[word]; ok {
+			languages = append(languages, list.language)
+		}
+	}
+	return languages
+}
+
+// DetectMnemonicLanguage returns the language whose wordlist contains the
+// most of words; empty words are ignored. It reports false when no word is in
+// any list.
+func DetectMnemonicLanguage(words []string) (MnemonicLanguage, bool) {
+	var (
+		best      *mnemonicWordlist
+		bestCount int
+	)
+	for _, list := range mnemonicWordlists {
+		count := 0
+		for _, word := range words {
+			if _, ok := list.index[normalizeMnemonicWord(word)]; ok {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = list, count
+		}
+	}
+	if best == nil {
+		return LanguageEnglish, false
+	}
+	return best.language, true
+}
+
+// CheckMnemonicWord returns an error if word is not in language's wordlist,
+// naming the list it belongs to when it is valid in another language
+func CheckMnemonicWord(language MnemonicLanguage, word string) error {
+	list := findMnemonicWordlist(language)
+	if list == nil {
+		return fmt.Errorf("unsupported wordlist: %s", language)
+	}
+	if _, ok := list.index[normalizeMnemonicWord(word)]; ok {
+		return nil
+	}
+
+	if others := WordLanguages(word); len(others) > 0 {
+		return fmt.Errorf("%q is from the %s wordlist, but this phrase is %s", word, others[0].Label(), language.Label())
+	}
+	return fmt.Errorf("%q is not a BIP39 word", word)
+}
+
+// CompleteMnemonicWord returns the words in language's wordlist that start
+// with prefix, ignoring case and accents, in list order. An empty language
+// searches every list, for when nothing typed so far identifies one.
+func CompleteMnemonicWord(language MnemonicLanguage, prefix string) []string {
+	prefix = foldMnemonicWord(prefix)
+	if prefix == "" {
+		return nil
+	}
+
+	var (
+		matches []string
+		seen    = make(map[string]bool)
+	)
+	for _, list := range mnemonicWordlists {
+		if language != "" && list.language != language {
+			continue
+		}
+		for i, folded := range list.folded {
+			word := norm.NFC.String(list.words[i])
+			if strings.HasPrefix(folded, prefix) && !seen[word] {
+				seen[word] = true
+				matches = append(matches, word)
+			}
+		}
+	}
+	return matches
+}
+
+// ValidateMnemonic checks a recovery phrase in any supported language: the
+// word count, that every word comes from one wordlist, and the checksum. It
+// returns the phrase's language.
+func ValidateMnemonic(mnemonic string) (MnemonicLanguage, error) {
+	words := strings.Fields(mnemonic)
+	switch len(words) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return "", fmt.Errorf("recovery phrase must have 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+
+	language, ok := DetectMnemonicLanguage(words)
+	if !ok {
+		return "", fmt.Errorf("recovery phrase is not in any supported BIP39 wordlist")
+	}
+
+	// Several lists can hold every word, so any of them with a matching
+	// checksum is the language
+	fits := false
+	for _, list := range mnemonicWordlists {
+		indices, ok := list.indices(words)
+		if !ok {
+			continue
+		}
+		fits = true
+		if mnemonicChecksumValid(indices) {
+			return list.language, nil
+		}
+	}
+	if !fits {
+		for i, word := range words {
+			if err := CheckMnemonicWord(language, word); err != nil {
+				return "", fmt.Errorf("word %d: %w", i+1, err)
+			}
+		}
+	}
+	return "", fmt.Errorf("checksum verification failed")
+}
+
+// NormalizeMnemonic returns the phrase as BIP39 hashes it: NFKD normalized
+// with single spaces between words
+func NormalizeMnemonic(mnemonic string) string {
+	return norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+}
+
+// mnemonicSeed validates mnemonic and returns its BIP39 seed
+func mnemonicSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	return bip39.NewSeed(NormalizeMnemonic(mnemonic), norm.NFKD.String(passphrase)), nil
+}
+
+func (l *mnemonicWordlist) indices(words []string) ([]int, bool) {
+	indices := make([]int, len(words))
+	for i, word := range words {
+		index, ok := l.index[normalizeMnemonicWord(word)]
+		if !ok {
+			return nil, false
+		}
+		indices[i] = index
+	}
+	return indices, true
+}
+
+// mnemonicChecksumValid checks the checksum carried in the last bits of the
+// words' 11-bit indices against the SHA-256 of the entropy before it
+func mnemonicChecksumValid(indices []int) bool {
+	totalBits := len(indices) * 11
+	checksumBits := totalBits / 33
+	entropyBits := totalBits - checksumBits
+
+	value := new(big.Int)
+	for _, index := range indices {
+		value.Lsh(value, 11)
+		value.Or(value, big.NewInt(int64(index)))
+	}
+
+	checksum := new(big.Int).And(value, big.NewInt(1<<checksumBits-1))
+	entropy := new(big.Int).Rsh(value, uint(checksumBits)).FillBytes(make([]byte, entropyBits/8))
+	hash := sha256.Sum256(entropy)
+
+	return uint64(hash[0]>>(8-checksumBits)) == checksum.Uint64()
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tyler-smith/go-bip39"
+	"github.com/tyler-smith/go-bip39/wordlists"
+)
+
+// mnemonicIn builds the phrase for entropy from wordlist
+func mnemonicIn(t *testing.T, wordlist []string, entropy []byte) string {
+	t.Helper()
+	bip39.SetWordList(wordlist)
+	defer bip39.SetWordList(wordlists.English)
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		t.Fatalf("Failed to build mnemonic: %v", err)
+	}
+	return mnemonic
+}
+
+func TestValidateMnemonicLanguages(t *testing.T) {
+	entropy := bytes.Repeat([]byte{0x7f}, 16)
+	tests := []struct {
+		language MnemonicLanguage
+		wordlist []string
+	}{
+		{LanguageEnglish, wordlists.English},
+		{LanguageSpanish, wordlists.Spanish},
+		{LanguageFrench, wordlists.French},
+		{LanguageItalian, wordlists.Italian},
+		{LanguageJapanese, wordlists.Japanese},
+		{LanguageKorean, wordlists.Korean},
+		{LanguageChineseSimplified, wordlists.ChineseSimplified},
+		{LanguageCzech, wordlists.Czech},
+	}
+
+	for _, tt := range tests {
+		mnemonic := mnemonicIn(t, tt.wordlist, entropy)
+
+		language, err := ValidateMnemonic(mnemonic)
+		if err != nil {
+			t.Errorf("%s: expected valid phrase, got %v", tt.language, err)
+			continue
+		}
+		if language != tt.language {
+			t.Errorf("%s: detected %s", tt.language, language)
+		}
+
+		// Seeds do not depend on the list, only on the normalized phrase
+		seed, err := mnemonicSeed(mnemonic, "")
+		if err != nil {
+			t.Errorf("%s: failed to derive seed: %v", tt.language, err)
+		} else if !bytes.Equal(seed, bip39.NewSeed(NormalizeMnemonic(mnemonic), "")) {
+			t.Errorf("%s: unexpected seed", tt.language)
+		}
+		if _, err := NewWallet("Test", mnemonic); err != nil {
+			t.Errorf("%s: failed to create wallet: %v", tt.language, err)
+		}
+	}
+}
+
+func TestValidateMnemonicErrors(t *testing.T) {
+	english := mnemonicIn(t, wordlists.English, bytes.Repeat([]byte{0x7f}, 16))
+	words := strings.Fields(english)
+
+	// A valid French word in an English phrase names the list it came from
+	mixed := append([]string{"abeille"}, words[1:]...)
+	_, err := ValidateMnemonic(strings.Join(mixed, " "))
+	if err == nil || !strings.Contains(err.Error(), "French") {
+		t.Errorf("Expected a wrong wordlist error, got %v", err)
+	}
+
+	swapped := append([]string{words[1], words[0]}, words[2:]...)
+	if _, err := ValidateMnemonic(strings.Join(swapped, " ")); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected a checksum error, got %v", err)
+	}
+
+	if _, err := ValidateMnemonic(strings.Join(words[:11], " ")); err == nil {
+		t.Error("Expected an error for 11 words")
+	}
+}
+
+func TestCheckMnemonicWord(t *testing.T) {
+	if err := CheckMnemonicWord(LanguageSpanish, "ábaco"); err != nil {
+		t.Errorf("Expected Spanish word to be valid: %v", err)
+	}
+	if err := CheckMnemonicWord(LanguageEnglish, "abeille"); err == nil || !strings.Contains(err.Error(), "French") {
+		t.Errorf("Expected French word to be reported, got %v", err)
+	}
+	if err := CheckMnemonicWord(LanguageEnglish, "notaword"); err == nil {
+		t.Error("Expected error for unknown word")
+	}
+}
+
+func TestCompleteMnemonicWord(t *testing.T) {
+	if got := CompleteMnemonicWord(LanguageEnglish, "aban"); len(got) != 1 || got[0] != "abandon" {
+		t.Errorf("Expected [abandon], got %v", got)
+	}
+
+	// Accents are optional while typing
+	if got := CompleteMnemonicWord(LanguageSpanish, "arbo"); len(got) != 1 || got[0] != "árbol" {
+		t.Errorf("Expected [árbol], got %v", got)
+	}
+
+	if got := CompleteMnemonicWord(LanguageEnglish, "ab"); len(got) < 2 {
+		t.Errorf("Expected several completions, got %v", got)
+	}
+
+	// With no language every list is searched
+	if got := CompleteMnemonicWord("", "abeil"); len(got) != 1 || got[0] != "abeille" {
+		t.Errorf("Expected [abeille], got %v", got)
+	}
+}
+
+func TestDetectMnemonicLanguage(t *testing.T) {
+	if _, ok := DetectMnemonicLanguage([]string{"", "xyz"}); ok {
+		t.Error("Expected no language for unknown words")
+	}
+	if language, _ := DetectMnemonicLanguage([]string{"abeille", "abandon", "abdiquer"}); language != LanguageFrench {
+		t.Errorf("Expected French, got %s", language)
+	}
+}
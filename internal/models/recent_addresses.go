@@ -18,8 +18,8 @@ type RecentAddress struct {
 }
 
 type RecentAddressManager struct {
-	addresses  []RecentAddress `json:"addresses"`
-	maxEntries int             `json:"max_entries"`
+	addresses  []RecentAddress
+	maxEntries int
 }
 
 func NewRecentAddressManager(maxEntries int) *RecentAddressManager {
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence yields the times a schedule is due
+type Recurrence interface {
+	// Next returns the first due time strictly after t, or the zero time if
+	// there is none
+	Next(t time.Time) time.Time
+}
+
+// ParseRecurrence reads a recurrence rule anchored at start. Rules are
+// either an interval ("every 30d", "every 2w", "every 12h") counted from
+// start, a five-field cron expression ("0 9 1 * *": minute, hour, day of
+// month, month, day of week) or one of @hourly, @daily, @weekly, @monthly.
+// Cron times are in start's time zone.
+func ParseRecurrence(rule string, start time.Time) (Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	switch strings.ToLower(rule) {
+	case "@hourly":
+		rule = "0 * * * *"
+	case "@daily":
+		rule = "0 0 * * *"
+	case "@weekly":
+		rule = "0 0 * * 0"
+	case "@monthly":
+		rule = "0 0 1 * *"
+	}
+
+	if interval, ok := strings.CutPrefix(strings.ToLower(rule), "every "); ok {
+		every, err := parseInterval(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, err
+		}
+		return intervalRecurrence{start: start, every: every}, nil
+	}
+
+	cron, err := parseCron(rule)
+	if err != nil {
+		return nil, err
+	}
+	cron.location = start.Location()
+	return cron, nil
+}
+
+// parseInterval reads a Go duration or a whole number of days ("30d") or
+// weeks ("2w")
+func parseInterval(value string) (time.Duration, error) {
+	var every time.Duration
+	if count, ok := strings.CutSuffix(value, "d"); ok {
+		days, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", value)
+		}
+		every = time.Duration(days) * 24 * time.Hour
+	} else if count, ok := strings.CutSuffix(value, "w"); ok {
+		weeks, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", value)
+		}
+		every = time.Duration(weeks) * 7 * 24 * time.Hour
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", value)
+		}
+		every = parsed
+	}
+
+	if every < time.Minute {
+		return 0, fmt.Errorf("interval must be at least a minute, got %q", value)
+	}
+	return every, nil
+}
+
+type intervalRecurrence struct {
+	start time.Time
+	every time.Duration
+}
+
+func (r intervalRecurrence) Next(t time.Time) time.Time {
+	if t.Before(r.start) {
+		return r.start
+	}
+	periods := t.Sub(r.start)/r.every + 1
+	return r.start.Add(periods * r.every)
+}
+
+// cronRecurrence matches times whose fields are all in the allowed sets.
+// As in cron, when both day of month and day of week are restricted a day
+// matching either is due.
+type cronRecurrence struct {
+	minutes, hours, days, months, weekdays fieldSet
+	daysRestricted, weekdaysRestricted     bool
+	location                               *time.Location
+}
+
+type fieldSet map[int]bool
+
+// cronSearchLimit bounds the search for an expression that never matches,
+// such as the 31st of February
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+func (r cronRecurrence) Next(t time.Time) time.Time {
+	t = t.In(r.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if !r.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, r.location)
+			continue
+		}
+		if !r.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, r.location)
+			continue
+		}
+		if !r.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, r.location)
+			continue
+		}
+		if !r.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (r cronRecurrence) dayMatches(t time.Time) bool {
+	day, weekday := r.days[t.Day()], r.weekdays[int(t.Weekday())]
+	if r.daysRestricted && r.weekdaysRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+func parseCron(expr string) (cronRecurrence, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return cronRecurrence{}, fmt.Errorf("invalid recurrence %q: use \"every <interval>\" or a cron expression with 5 fields", expr)
+	}
+
+	var r cronRecurrence
+	var err error
+	if r.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return r, fmt.Errorf("invalid minute field: %w", err)
+	}
+	if r.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return r, fmt.Errorf("invalid hour field: %w", err)
+	}
+	if r.days, err = parseCronField(fields[2], 1, 31); err != nil {
+		return r, fmt.Errorf("invalid day of month field: %w", err)
+	}
+	if r.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return r, fmt.Errorf("invalid month field: %w", err)
+	}
+	if r.weekdays, err = parseCronField(fields[4], 0, 7); err != nil {
+		return r, fmt.Errorf("invalid day of week field: %w", err)
+	}
+	// Both 0 and 7 are Sunday
+	if r.weekdays[7] {
+		r.weekdays[0] = true
+	}
+	r.daysRestricted = fields[2] != "*"
+	r.weekdaysRestricted = fields[4] != "*"
+	return r, nil
+}
+
+// parseCronField reads a comma-separated list of values, ranges ("1-5") and
+// steps ("*/15", "1-31/2")
+func parseCronField(field string, min, max int) (fieldSet, error) {
+	set := make(fieldSet)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepPart)
+			if err != nil || parsed < 1 {
+				return nil, fmt.Errorf("invalid step in %q", part)
+			}
+			step = parsed
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			from, to, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(from); err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(to); err != nil {
+					return nil, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			set[value] = true
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	start := time.Date(2026, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		rule  string
+		after time.Time
+		want  time.Time
+	}{
+		{"every 2w", start, start.AddDate(0, 0, 14)},
+		{"every 3d", start.Add(time.Hour), start.AddDate(0, 0, 3)},
+		{"every 90m", start, start.Add(90 * time.Minute)},
+		{"@daily", start, time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", start, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 9 1 * *", start, time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC)},
+		{"0 9 31 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", start, time.Date(2026, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 8-10/2 * * 1,3", start, time.Date(2026, 1, 19, 8, 0, 0, 0, time.UTC)},
+		// Day of month and day of week match either, as in cron
+		{"0 0 20 * 5", start, time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		recurrence, err := ParseRecurrence(tt.rule, start)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q) failed: %v", tt.rule, err)
+			continue
+		}
+		if got := recurrence.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("%q after %s = %s, want %s", tt.rule, tt.after, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "every", "every 0d", "every 30s", "@yearly-ish", "0 9 * *", "60 * * * *", "0 9 32 * *", "0 9 * * mon", "*/0 * * * *"} {
+		if _, err := ParseRecurrence(bad, start); err == nil {
+			t.Errorf("Expected ParseRecurrence(%q) to fail", bad)
+		}
+	}
+}
+
+func TestCronRecurrenceNeverMatching(t *testing.T) {
+	recurrence, err := ParseRecurrence("0 0 30 2 *", time.Now())
+	if err != nil {
+		t.Fatalf("ParseRecurrence failed: %v", err)
+	}
+	if next := recurrence.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected no time for 30 February, got %s", next)
+	}
+}
@@ -0,0 +1,360 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ScheduleMode decides what happens when a scheduled payment falls due
+type ScheduleMode string
+
+const (
+	// ScheduleApprove prepares the payment and waits for it to be approved
+	ScheduleApprove ScheduleMode = "approve"
+	// ScheduleAuto signs the payment straight away while its wallet is
+	// unlocked and the payment fits the auto-pay budget, and otherwise falls
+	// back to waiting for approval
+	ScheduleAuto ScheduleMode = "auto"
+)
+
+// MissedRunPolicy decides what happens to payments that fell due while
+// nothing was running to make them
+type MissedRunPolicy string
+
+const (
+	// MissedPayLatest still makes the most recent missed payment and records
+	// any older ones as missed
+	MissedPayLatest MissedRunPolicy = "pay_latest"
+	// MissedSkip records every missed payment as missed without paying
+	MissedSkip MissedRunPolicy = "skip"
+)
+
+// MissedRunGrace is how late a payment may be made before it counts as
+// missed
+const MissedRunGrace = time.Hour
+
+// maxCatchUpRuns bounds how many missed occurrences one check works through,
+// so a short interval left unattended for months cannot stall it
+const maxCatchUpRuns = 100
+
+// PaymentSchedule pays a transaction template on a recurrence. Values fill
+// the template's placeholders, keyed by placeholder, and are resolved each
+// time a payment falls due, so "50% of VTHO" follows the balance.
+type PaymentSchedule struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	WalletID   string            `json:"wallet_id"`
+	TemplateID string            `json:"template_id"`
+	Values     map[string]string `json:"values,omitempty"`
+	Recurrence string            `json:"recurrence"`
+	StartAt    time.Time         `json:"start_at"`
+	EndAt      *time.Time        `json:"end_at,omitempty"`
+	// MaxRuns stops the schedule after that many due dates, paid or not; 0
+	// means no limit
+	MaxRuns  int             `json:"max_runs,omitempty"`
+	Mode     ScheduleMode    `json:"mode"`
+	OnMissed MissedRunPolicy `json:"on_missed"`
+	Paused   bool            `json:"paused,omitempty"`
+
+	RunCount  int        `json:"run_count"`
+	NextRunAt time.Time  `json:"next_run_at"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// NewPaymentSchedule creates a schedule for a wallet's template, due first
+// at the recurrence's first time on or after start
+func NewPaymentSchedule(name, walletID, templateID, recurrence string, start time.Time) (*PaymentSchedule, error) {
+	if strings.TrimSpace(walletID) == "" || strings.TrimSpace(templateID) == "" {
+		return nil, fmt.Errorf("a schedule needs a wallet and a template")
+	}
+
+	now := time.Now()
+	schedule := &PaymentSchedule{
+		ID:         fmt.Sprintf("schedule_%s", now.Format("20060102150405.000000000")),
+		Name:       strings.TrimSpace(name),
+		WalletID:   walletID,
+		TemplateID: templateID,
+		Recurrence: strings.TrimSpace(recurrence),
+		StartAt:    start,
+		Mode:       ScheduleApprove,
+		OnMissed:   MissedPayLatest,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := schedule.Reset(); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Reset works out the first due time again after the recurrence, start or
+// end changed. Due dates already counted stay counted.
+func (ps *PaymentSchedule) Reset() error {
+	recurrence, err := ParseRecurrence(ps.Recurrence, ps.StartAt)
+	if err != nil {
+		return err
+	}
+	after := ps.StartAt.Add(-time.Nanosecond)
+	if ps.LastRunAt != nil && ps.LastRunAt.After(after) {
+		after = *ps.LastRunAt
+	}
+	ps.NextRunAt = recurrence.Next(after)
+	ps.UpdatedAt = time.Now()
+	return ps.Validate()
+}
+
+// Resume restarts a paused schedule from its next due time after now.
+// Dates that passed while it was paused are not owed.
+func (ps *PaymentSchedule) Resume(now time.Time) error {
+	recurrence, err := ParseRecurrence(ps.Recurrence, ps.StartAt)
+	if err != nil {
+		return err
+	}
+	ps.Paused = false
+	ps.NextRunAt = recurrence.Next(now)
+	ps.UpdatedAt = now
+	return nil
+}
+
+// Validate checks the schedule's settings
+func (ps *PaymentSchedule) Validate() error {
+	if ps.Name == "" {
+		return fmt.Errorf("schedule name is required")
+	}
+	if _, err := ParseRecurrence(ps.Recurrence, ps.StartAt); err != nil {
+		return err
+	}
+	if ps.EndAt != nil && ps.EndAt.Before(ps.StartAt) {
+		return fmt.Errorf("end date is before the start date")
+	}
+	if ps.MaxRuns < 0 {
+		return fmt.Errorf("max runs cannot be negative")
+	}
+	switch ps.Mode {
+	case ScheduleApprove, ScheduleAuto:
+	default:
+		return fmt.Errorf("invalid mode %q (must be %q or %q)", ps.Mode, ScheduleApprove, ScheduleAuto)
+	}
+	switch ps.OnMissed {
+	case MissedPayLatest, MissedSkip:
+	default:
+		return fmt.Errorf("invalid missed-run policy %q (must be %q or %q)", ps.OnMissed, MissedPayLatest, MissedSkip)
+	}
+	return nil
+}
+
+// ParseScheduleTime reads a local date ("2026-11-01") or date and time
+// ("2026-11-01 09:00")
+func ParseScheduleTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or YYYY-MM-DD HH:MM", value)
+}
+
+// ParseScheduleEnd reads an end date like ParseScheduleTime. A date alone
+// covers the whole of that day.
+func ParseScheduleEnd(value string) (time.Time, error) {
+	end, err := ParseScheduleTime(value)
+	if err != nil {
+		return end, err
+	}
+	if !strings.Contains(value, ":") {
+		end = end.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return end, nil
+}
+
+// Finished reports whether the schedule has no due dates left
+func (ps *PaymentSchedule) Finished() bool {
+	if ps.NextRunAt.IsZero() {
+		return true
+	}
+	if ps.MaxRuns > 0 && ps.RunCount >= ps.MaxRuns {
+		return true
+	}
+	return ps.EndAt != nil && ps.NextRunAt.After(*ps.EndAt)
+}
+
+// DueRun is one due date of a schedule
+type DueRun struct {
+	DueAt time.Time
+	// Missed is set for due dates that should be recorded but not paid
+	Missed bool
+}
+
+// TakeDue returns the due dates up to now and moves the schedule past them.
+// Dates more than MissedRunGrace old are missed, except that MissedPayLatest
+// still pays the last of them.
+func (ps *PaymentSchedule) TakeDue(now time.Time) ([]DueRun, error) {
+	if ps.Paused {
+		return nil, nil
+	}
+	recurrence, err := ParseRecurrence(ps.Recurrence, ps.StartAt)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []DueRun
+	for !ps.Finished() && !ps.NextRunAt.After(now) && len(due) < maxCatchUpRuns {
+		at := ps.NextRunAt
+		due = append(due, DueRun{DueAt: at, Missed: now.Sub(at) > MissedRunGrace})
+
+		ps.RunCount++
+		ps.LastRunAt = &at
+		ps.NextRunAt = recurrence.Next(at)
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+	ps.UpdatedAt = now
+
+	caughtUp := ps.Finished() || ps.NextRunAt.After(now)
+	if last := &due[len(due)-1]; last.Missed && caughtUp && ps.OnMissed == MissedPayLatest {
+		last.Missed = false
+	}
+	return due, nil
+}
+
+// Describe summarises when the schedule pays
+func (ps *PaymentSchedule) Describe() string {
+	var parts []string
+	parts = append(parts, ps.Recurrence)
+	if ps.EndAt != nil {
+		parts = append(parts, "until "+ps.EndAt.Format("2006-01-02"))
+	}
+	if ps.MaxRuns > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d", ps.RunCount, ps.MaxRuns))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ScheduleValues turns values keyed by placeholder name into the values a
+// schedule stores, keyed by placeholder. Defaults fill values not given, and
+// contact values may name a contact instead of giving its address.
+func (tt *TransactionTemplate) ScheduleValues(byName map[string]string, contacts *ContactList) (map[string]string, error) {
+	variables, err := tt.Variables()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(variables))
+	used := make(map[string]bool, len(byName))
+	for _, variable := range variables {
+		value, ok := byName[variable.Name]
+		if ok {
+			used[variable.Name] = true
+		}
+		if !ok || strings.TrimSpace(value) == "" {
+			value = variable.Default
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil, fmt.Errorf("no value for {{%s}}", variable.Placeholder)
+		}
+
+		if variable.Kind == VariableAmount {
+			// Percentages are checked against the balance when paid
+			if _, _, err := ResolveAmount(value, tt.Asset, nil); err != nil && !errors.Is(err, ErrBalanceUnknown) {
+				return nil, fmt.Errorf("%s: %w", variable.Name, err)
+			}
+		}
+		if variable.Kind == VariableContact && !strings.HasPrefix(strings.ToLower(value), "0x") {
+			address := ""
+			if contacts != nil {
+				for _, contact := range contacts.Contacts {
+					if strings.EqualFold(contact.Name, value) {
+						if variable.Category != "" && !strings.EqualFold(contact.Category, variable.Category) {
+							return nil, fmt.Errorf("contact %s is not in category %s", contact.Name, variable.Category)
+						}
+						address = contact.Address
+						break
+					}
+				}
+			}
+			if address == "" {
+				return nil, fmt.Errorf("no contact named %q", value)
+			}
+			value = address
+		}
+		values[variable.Placeholder] = value
+	}
+
+	for name := range byName {
+		if !used[name] {
+			return nil, fmt.Errorf("template %s has no placeholder named %s", tt.Name, name)
+		}
+	}
+	return values, nil
+}
+
+// RunStatus is where a scheduled payment stands
+type RunStatus string
+
+const (
+	RunPending  RunStatus = "pending"
+	RunSent     RunStatus = "sent"
+	RunMissed   RunStatus = "missed"
+	RunRejected RunStatus = "rejected"
+	RunFailed   RunStatus = "failed"
+)
+
+// ScheduledRun records one due date of a schedule and what came of it
+type ScheduledRun struct {
+	ID           string    `json:"id"`
+	ScheduleID   string    `json:"schedule_id"`
+	ScheduleName string    `json:"schedule_name"`
+	WalletID     string    `json:"wallet_id"`
+	DueAt        time.Time `json:"due_at"`
+	Status       RunStatus `json:"status"`
+
+	To          string   `json:"to,omitempty"`
+	ContactName string   `json:"contact_name,omitempty"`
+	Amount      *big.Int `json:"amount,omitempty"`
+	Asset       string   `json:"asset,omitempty"`
+	Notes       string   `json:"notes,omitempty"`
+
+	// Auto is set for payments signed without approval, which count against
+	// the auto-pay budget
+	Auto   bool   `json:"auto,omitempty"`
+	TxHash string `json:"tx_hash,omitempty"`
+	// Reason explains why a payment is waiting, was not made or failed
+	Reason string `json:"reason,omitempty"`
+
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// NewScheduledRun records a schedule's due date. The ID is derived from the
+// schedule and date so two processes checking at once record it only once.
+func NewScheduledRun(schedule *PaymentSchedule, dueAt time.Time) *ScheduledRun {
+	return &ScheduledRun{
+		ID:           ScheduledRunID(schedule.ID, dueAt),
+		ScheduleID:   schedule.ID,
+		ScheduleName: schedule.Name,
+		WalletID:     schedule.WalletID,
+		DueAt:        dueAt,
+		Status:       RunPending,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// ScheduledRunID returns the ID of a schedule's run for a due date
+func ScheduledRunID(scheduleID string, dueAt time.Time) string {
+	return fmt.Sprintf("run_%s_%d", scheduleID, dueAt.Unix())
+}
+
+// Resolve closes the run with status
+func (r *ScheduledRun) Resolve(status RunStatus, reason string, at time.Time) {
+	r.Status = status
+	r.Reason = reason
+	r.ResolvedAt = &at
+}
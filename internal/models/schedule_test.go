@@ -0,0 +1,122 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleTakeDue(t *testing.T) {
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	schedule, err := NewPaymentSchedule("Retainer", "wallet-a", "template-a", "0 9 1 * *", start)
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	if !schedule.NextRunAt.Equal(start) {
+		t.Fatalf("Expected the first run at the start, got %s", schedule.NextRunAt)
+	}
+
+	if due, _ := schedule.TakeDue(start.Add(-time.Minute)); len(due) != 0 {
+		t.Fatalf("Expected nothing due before the start, got %+v", due)
+	}
+
+	due, err := schedule.TakeDue(start.Add(10 * time.Minute))
+	if err != nil {
+		t.Fatalf("TakeDue failed: %v", err)
+	}
+	if len(due) != 1 || due[0].Missed || !due[0].DueAt.Equal(start) {
+		t.Fatalf("Expected one run on time, got %+v", due)
+	}
+	if schedule.RunCount != 1 || !schedule.NextRunAt.Equal(start.AddDate(0, 1, 0)) {
+		t.Fatalf("Expected the schedule to move to February, got %d runs next %s", schedule.RunCount, schedule.NextRunAt)
+	}
+
+	// Three months offline: only the latest date is paid
+	now := time.Date(2026, 4, 10, 12, 0, 0, 0, time.UTC)
+	due, err = schedule.TakeDue(now)
+	if err != nil {
+		t.Fatalf("TakeDue failed: %v", err)
+	}
+	if len(due) != 3 || !due[0].Missed || !due[1].Missed || due[2].Missed {
+		t.Fatalf("Expected two missed runs and the latest paid, got %+v", due)
+	}
+	if again, _ := schedule.TakeDue(now); len(again) != 0 {
+		t.Fatalf("Expected due dates to be taken once, got %+v", again)
+	}
+}
+
+func TestScheduleMissedSkipAndLimits(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule, err := NewPaymentSchedule("Weekly", "wallet-a", "template-a", "every 1w", start)
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	schedule.OnMissed = MissedSkip
+	schedule.MaxRuns = 3
+
+	due, err := schedule.TakeDue(start.AddDate(0, 2, 0))
+	if err != nil {
+		t.Fatalf("TakeDue failed: %v", err)
+	}
+	if len(due) != 3 {
+		t.Fatalf("Expected max runs to stop at 3, got %d", len(due))
+	}
+	for _, run := range due {
+		if !run.Missed {
+			t.Errorf("Expected %s to be skipped", run.DueAt)
+		}
+	}
+	if !schedule.Finished() {
+		t.Error("Expected the schedule to be finished")
+	}
+
+	ended, err := NewPaymentSchedule("Ended", "wallet-a", "template-a", "@daily", start)
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	end := start.AddDate(0, 0, 1)
+	ended.EndAt = &end
+	if due, _ := ended.TakeDue(start.AddDate(0, 0, 5)); len(due) != 2 || due[1].Missed {
+		t.Fatalf("Expected two runs up to the end date with the last paid, got %+v", due)
+	}
+
+	paused, _ := NewPaymentSchedule("Paused", "wallet-a", "template-a", "@daily", start)
+	paused.Paused = true
+	if due, _ := paused.TakeDue(start.AddDate(0, 0, 5)); len(due) != 0 {
+		t.Fatalf("Expected nothing due while paused, got %+v", due)
+	}
+	if err := paused.Resume(start.AddDate(0, 0, 5)); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if !paused.NextRunAt.Equal(start.AddDate(0, 0, 6)) {
+		t.Errorf("Expected dates passed while paused to be dropped, next run %s", paused.NextRunAt)
+	}
+}
+
+func TestScheduleValues(t *testing.T) {
+	template := NewTransactionTemplate("Invoice", "", "{{contact:category=suppliers}}", "", "VET", "Invoice {{memo}}", nil, nil)
+	template.AmountPlaceholder = "{{amount:default=25}}"
+	contacts := &ContactList{Contacts: []Contact{
+		{Name: "Acme", Address: "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", Category: "suppliers"},
+		{Name: "Bob", Address: "0x0000000000000000000000000000456e65726779", Category: "friends"},
+	}}
+
+	values, err := template.ScheduleValues(map[string]string{"contact": "acme", "memo": "42"}, contacts)
+	if err != nil {
+		t.Fatalf("ScheduleValues failed: %v", err)
+	}
+	if values["contact:category=suppliers"] != contacts.Contacts[0].Address || values["amount:default=25"] != "25" || values["memo"] != "42" {
+		t.Fatalf("Unexpected values %v", values)
+	}
+
+	for _, bad := range []map[string]string{
+		{"contact": "Bob", "memo": "1"},
+		{"contact": "Nobody", "memo": "1"},
+		{"contact": "Acme"},
+		{"contact": "Acme", "memo": "1", "amount": "lots"},
+		{"contact": "Acme", "memo": "1", "colour": "red"},
+	} {
+		if _, err := template.ScheduleValues(bad, contacts); err == nil {
+			t.Errorf("Expected ScheduleValues(%v) to fail", bad)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/tyler-smith/go-bip39"
+
+	"rhystmorgan/veWallet/internal/slip39"
+)
+
+// SplitMnemonicShares splits the entropy of an English recovery phrase into
+// count SLIP-39 shares, any threshold of which restore the phrase.
+//
+// The shares carry the phrase's BIP39 entropy, not a SLIP-39 master secret,
+// so they restore this wallet only through MnemonicFromShares; another
+// SLIP-39 wallet would derive different accounts from them. A BIP39
+// passphrase is not part of the shares and is still needed after recovery.
+func SplitMnemonicShares(mnemonic string, threshold, count int) ([]string, error) {
+	if threshold < 2 {
+		return nil, fmt.Errorf("threshold must be at least 2, or any one share would hold the whole phrase")
+	}
+
+	language, err := ValidateMnemonic(mnemonic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+	// Shares restore entropy, and the same entropy in another language is a
+	// different phrase and so a different wallet
+	if language != LanguageEnglish {
+		return nil, fmt.Errorf("only English recovery phrases can be split into shares, this one is %s", language.Label())
+	}
+
+	entropy, err := bip39.EntropyFromMnemonic(NormalizeMnemonic(mnemonic))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mnemonic entropy: %w", err)
+	}
+
+	shares, err := slip39.Split(entropy, "", threshold, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split recovery phrase: %w", err)
+	}
+	return shares, nil
+}
+
+// MnemonicFromShares restores the English recovery phrase from shares made
+// by SplitMnemonicShares. The error wraps slip39.ErrNotEnoughShares while
+// more shares are needed.
+func MnemonicFromShares(shares []string) (string, error) {
+	entropy, err := slip39.Combine(shares, "")
+	if err != nil {
+		return "", err
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("failed to restore recovery phrase: %w", err)
+	}
+	return mnemonic, nil
+}
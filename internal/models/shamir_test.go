@@ -0,0 +1,42 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/tyler-smith/go-bip39/wordlists"
+
+	"rhystmorgan/veWallet/internal/slip39"
+)
+
+func TestMnemonicShares(t *testing.T) {
+	shares, err := SplitMnemonicShares(accountTestMnemonic, 2, 3)
+	if err != nil {
+		t.Fatalf("Failed to split mnemonic: %v", err)
+	}
+	if len(shares) != 3 {
+		t.Fatalf("Expected 3 shares, got %d", len(shares))
+	}
+
+	if _, err := MnemonicFromShares(shares[:1]); !errors.Is(err, slip39.ErrNotEnoughShares) {
+		t.Errorf("Expected ErrNotEnoughShares for one share, got %v", err)
+	}
+
+	restored, err := MnemonicFromShares([]string{shares[2], shares[0]})
+	if err != nil {
+		t.Fatalf("Failed to restore mnemonic: %v", err)
+	}
+	if restored != accountTestMnemonic {
+		t.Errorf("Expected %q, got %q", accountTestMnemonic, restored)
+	}
+
+	if _, err := SplitMnemonicShares(accountTestMnemonic, 1, 3); err == nil {
+		t.Error("Expected error for a threshold of 1")
+	}
+
+	spanish := mnemonicIn(t, wordlists.Spanish, bytes.Repeat([]byte{0x7f}, 16))
+	if _, err := SplitMnemonicShares(spanish, 2, 3); err == nil {
+		t.Error("Expected error for a non-English phrase")
+	}
+}
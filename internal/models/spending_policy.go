@@ -0,0 +1,249 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Spending windows are rolling rather than calendar days, so a cap cannot be
+// spent twice either side of midnight
+const (
+	DailyWindow  = 24 * time.Hour
+	WeeklyWindow = 7 * DailyWindow
+)
+
+// SpendingLimits caps what may be sent of one asset. A nil limit is not
+// enforced.
+type SpendingLimits struct {
+	PerTx  *big.Int `json:"per_tx,omitempty"`
+	Daily  *big.Int `json:"daily,omitempty"`
+	Weekly *big.Int `json:"weekly,omitempty"`
+	// ConfirmAbove asks for a second confirmation for larger payments
+	ConfirmAbove *big.Int `json:"confirm_above,omitempty"`
+}
+
+// SpendingPolicy holds the rules a wallet's payments are checked against
+// before they are signed
+type SpendingPolicy struct {
+	// ID is the wallet's ID; a wallet has at most one policy
+	ID      string `json:"id"`
+	Enabled bool   `json:"enabled"`
+
+	VET  SpendingLimits `json:"vet"`
+	VTHO SpendingLimits `json:"vtho"`
+
+	// ContactsOnly limits recipients to saved contacts, and to those in
+	// AllowedCategories when any are given
+	ContactsOnly      bool     `json:"contacts_only"`
+	AllowedCategories []string `json:"allowed_categories,omitempty"`
+	// ContactCooldownHours is how long a new contact must wait before it
+	// can be paid
+	ContactCooldownHours int `json:"contact_cooldown_hours,omitempty"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewSpendingPolicy creates a disabled policy with no limits for a wallet
+func NewSpendingPolicy(walletID string) *SpendingPolicy {
+	return &SpendingPolicy{ID: walletID, UpdatedAt: time.Now()}
+}
+
+// Limits returns the limits for asset, or nil for an unknown asset
+func (p *SpendingPolicy) Limits(asset string) *SpendingLimits {
+	switch strings.ToUpper(asset) {
+	case "VET":
+		return &p.VET
+	case "VTHO":
+		return &p.VTHO
+	}
+	return nil
+}
+
+// Validate checks that the limits are consistent
+func (p *SpendingPolicy) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("spending policy needs a wallet")
+	}
+	if p.ContactCooldownHours < 0 {
+		return fmt.Errorf("contact cool-down cannot be negative")
+	}
+	for _, asset := range []string{"VET", "VTHO"} {
+		limits := p.Limits(asset)
+		for name, limit := range map[string]*big.Int{
+			"per-transaction maximum": limits.PerTx,
+			"daily cap":               limits.Daily,
+			"weekly cap":              limits.Weekly,
+			"confirmation threshold":  limits.ConfirmAbove,
+		} {
+			if limit != nil && limit.Sign() < 0 {
+				return fmt.Errorf("%s %s cannot be negative", asset, name)
+			}
+		}
+		if limits.Daily != nil && limits.Weekly != nil && limits.Daily.Cmp(limits.Weekly) > 0 {
+			return fmt.Errorf("%s daily cap is above the weekly cap", asset)
+		}
+	}
+	return nil
+}
+
+// PaymentCheck describes a payment to evaluate against a policy
+type PaymentCheck struct {
+	To     string
+	Amount *big.Int
+	Asset  string
+	At     time.Time
+	// Contact is the saved contact with the recipient's address, if any
+	Contact *Contact
+	// SpentDay and SpentWeek are what the wallet sent of the asset in the
+	// DailyWindow and WeeklyWindow before At
+	SpentDay  *big.Int
+	SpentWeek *big.Int
+}
+
+// PolicyDecision is the outcome of checking a payment
+type PolicyDecision struct {
+	// Violations explain why the payment may not be made
+	Violations []string
+	// ConfirmReason is set when the payment may be made after a second
+	// confirmation
+	ConfirmReason string
+}
+
+// Allowed reports whether the payment breaks no rule
+func (d *PolicyDecision) Allowed() bool {
+	return len(d.Violations) == 0
+}
+
+// NeedsConfirmation reports whether the payment needs a second confirmation
+func (d *PolicyDecision) NeedsConfirmation() bool {
+	return d.ConfirmReason != ""
+}
+
+// Err returns the violations as an error, or nil when there are none
+func (d *PolicyDecision) Err() error {
+	if d.Allowed() {
+		return nil
+	}
+	return fmt.Errorf("%s", strings.Join(d.Violations, "; "))
+}
+
+// Evaluate checks a payment against the policy. A disabled policy allows
+// everything.
+func (p *SpendingPolicy) Evaluate(check PaymentCheck) *PolicyDecision {
+	decision := &PolicyDecision{}
+	if p == nil || !p.Enabled {
+		return decision
+	}
+	asset := strings.ToUpper(check.Asset)
+
+	if limits := p.Limits(asset); limits != nil && check.Amount != nil {
+		if limits.PerTx != nil && check.Amount.Cmp(limits.PerTx) > 0 {
+			decision.Violations = append(decision.Violations,
+				fmt.Sprintf("%s %s is above the per-transaction maximum of %s %s", formatTokens(check.Amount), asset, formatTokens(limits.PerTx), asset))
+		}
+		if v := capViolation("daily", limits.Daily, check.SpentDay, check.Amount, asset); v != "" {
+			decision.Violations = append(decision.Violations, v)
+		}
+		if v := capViolation("weekly", limits.Weekly, check.SpentWeek, check.Amount, asset); v != "" {
+			decision.Violations = append(decision.Violations, v)
+		}
+		if limits.ConfirmAbove != nil && check.Amount.Cmp(limits.ConfirmAbove) > 0 {
+			decision.ConfirmReason = fmt.Sprintf("%s %s is above the confirmation threshold of %s %s", formatTokens(check.Amount), asset, formatTokens(limits.ConfirmAbove), asset)
+		}
+	}
+
+	if p.ContactsOnly {
+		if check.Contact == nil {
+			decision.Violations = append(decision.Violations, "recipient is not a saved contact")
+		} else if len(p.AllowedCategories) > 0 && !containsFold(p.AllowedCategories, check.Contact.Category) {
+			decision.Violations = append(decision.Violations,
+				fmt.Sprintf("contact %s is not in an allowed category (%s)", check.Contact.Name, strings.Join(p.AllowedCategories, ", ")))
+		}
+	}
+
+	if cooldown := time.Duration(p.ContactCooldownHours) * time.Hour; cooldown > 0 && check.Contact != nil {
+		if ready := check.Contact.CreatedAt.Add(cooldown); check.At.Before(ready) {
+			decision.Violations = append(decision.Violations,
+				fmt.Sprintf("contact %s was added %s ago and cannot be paid until %s", check.Contact.Name,
+					check.At.Sub(check.Contact.CreatedAt).Round(time.Minute), ready.Local().Format("2006-01-02 15:04")))
+		}
+	}
+
+	return decision
+}
+
+// Describe summarises the policy's rules
+func (p *SpendingPolicy) Describe() string {
+	if p == nil || !p.Enabled {
+		return "off"
+	}
+
+	var parts []string
+	for _, asset := range []string{"VET", "VTHO"} {
+		limits := p.Limits(asset)
+		for _, limit := range []struct {
+			label  string
+			amount *big.Int
+		}{
+			{"max", limits.PerTx},
+			{"per day", limits.Daily},
+			{"per week", limits.Weekly},
+			{"confirm over", limits.ConfirmAbove},
+		} {
+			if limit.amount != nil {
+				parts = append(parts, fmt.Sprintf("%s %s %s", limit.label, formatTokens(limit.amount), asset))
+			}
+		}
+	}
+	if p.ContactsOnly {
+		if len(p.AllowedCategories) > 0 {
+			parts = append(parts, "contacts in "+strings.Join(p.AllowedCategories, ", "))
+		} else {
+			parts = append(parts, "contacts only")
+		}
+	}
+	if p.ContactCooldownHours > 0 {
+		parts = append(parts, fmt.Sprintf("new contacts wait %dh", p.ContactCooldownHours))
+	}
+	if len(parts) == 0 {
+		return "on, no rules"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func capViolation(period string, limit, spent, amount *big.Int, asset string) string {
+	if limit == nil {
+		return ""
+	}
+	total := new(big.Int).Set(amount)
+	if spent != nil {
+		total.Add(total, spent)
+	}
+	if total.Cmp(limit) <= 0 {
+		return ""
+	}
+	used := spent
+	if used == nil {
+		used = new(big.Int)
+	}
+	return fmt.Sprintf("would exceed the %s cap of %s %s (%s already sent)", period, formatTokens(limit), asset, formatTokens(used))
+}
+
+// formatTokens shows a wei amount in whole tokens to at most 4 decimals
+func formatTokens(wei *big.Int) string {
+	value := new(big.Rat).SetFrac(wei, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil))
+	text := value.FloatString(4)
+	text = strings.TrimRight(text, "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
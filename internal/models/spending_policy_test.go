@@ -0,0 +1,124 @@
+package models
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func vet(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func TestSpendingPolicyDisabledAllowsEverything(t *testing.T) {
+	policy := NewSpendingPolicy("wallet-a")
+	policy.VET.PerTx = vet(1)
+
+	decision := policy.Evaluate(PaymentCheck{To: "0xabc", Amount: vet(100), Asset: "VET", At: time.Now()})
+	if !decision.Allowed() || decision.NeedsConfirmation() {
+		t.Errorf("Expected a disabled policy to allow the payment, got %+v", decision)
+	}
+}
+
+func TestSpendingPolicyLimits(t *testing.T) {
+	policy := NewSpendingPolicy("wallet-a")
+	policy.Enabled = true
+	policy.VET = SpendingLimits{PerTx: vet(50), Daily: vet(100), Weekly: vet(300), ConfirmAbove: vet(20)}
+
+	tests := []struct {
+		name       string
+		amount     int64
+		spentDay   int64
+		spentWeek  int64
+		violations int
+		confirm    bool
+	}{
+		{"within limits", 10, 0, 0, 0, false},
+		{"above confirmation threshold", 30, 0, 0, 0, true},
+		{"above per-transaction maximum", 60, 0, 0, 1, true},
+		{"over daily cap", 40, 70, 70, 1, true},
+		{"over weekly cap", 10, 0, 295, 1, false},
+		{"exactly at daily cap", 20, 80, 80, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := policy.Evaluate(PaymentCheck{
+				To:        "0xabc",
+				Amount:    vet(tt.amount),
+				Asset:     "vet",
+				At:        time.Now(),
+				SpentDay:  vet(tt.spentDay),
+				SpentWeek: vet(tt.spentWeek),
+			})
+			if len(decision.Violations) != tt.violations {
+				t.Errorf("Expected %d violations, got %v", tt.violations, decision.Violations)
+			}
+			if decision.NeedsConfirmation() != tt.confirm {
+				t.Errorf("Expected confirmation %v, got %q", tt.confirm, decision.ConfirmReason)
+			}
+		})
+	}
+
+	// VTHO has no limits set
+	decision := policy.Evaluate(PaymentCheck{To: "0xabc", Amount: vet(1000), Asset: "VTHO", At: time.Now()})
+	if !decision.Allowed() {
+		t.Errorf("Expected VTHO to be unlimited, got %v", decision.Violations)
+	}
+}
+
+func TestSpendingPolicyRecipients(t *testing.T) {
+	now := time.Now()
+	policy := NewSpendingPolicy("wallet-a")
+	policy.Enabled = true
+	policy.ContactsOnly = true
+	policy.AllowedCategories = []string{"Payroll"}
+	policy.ContactCooldownHours = 24
+
+	if decision := policy.Evaluate(PaymentCheck{To: "0xabc", Amount: vet(1), Asset: "VET", At: now}); decision.Allowed() {
+		t.Error("Expected an unknown recipient to be refused")
+	}
+
+	contact := &Contact{Name: "Alice", Address: "0xabc", Category: "payroll", CreatedAt: now.Add(-48 * time.Hour)}
+	if decision := policy.Evaluate(PaymentCheck{To: "0xabc", Amount: vet(1), Asset: "VET", At: now, Contact: contact}); !decision.Allowed() {
+		t.Errorf("Expected an allowed contact to be paid, got %v", decision.Violations)
+	}
+
+	contact.Category = "Friends"
+	decision := policy.Evaluate(PaymentCheck{To: "0xabc", Amount: vet(1), Asset: "VET", At: now, Contact: contact})
+	if decision.Allowed() || !strings.Contains(decision.Violations[0], "category") {
+		t.Errorf("Expected a category violation, got %v", decision.Violations)
+	}
+
+	contact.Category = "Payroll"
+	contact.CreatedAt = now.Add(-time.Hour)
+	decision = policy.Evaluate(PaymentCheck{To: "0xabc", Amount: vet(1), Asset: "VET", At: now, Contact: contact})
+	if decision.Allowed() || !strings.Contains(decision.Violations[0], "cannot be paid until") {
+		t.Errorf("Expected a cool-down violation, got %v", decision.Violations)
+	}
+}
+
+func TestSpendingPolicyValidate(t *testing.T) {
+	policy := NewSpendingPolicy("wallet-a")
+	policy.VET.Daily = vet(100)
+	policy.VET.Weekly = vet(50)
+	if err := policy.Validate(); err == nil {
+		t.Error("Expected a daily cap above the weekly cap to be rejected")
+	}
+
+	policy.VET.Weekly = vet(500)
+	policy.VTHO.PerTx = big.NewInt(-1)
+	if err := policy.Validate(); err == nil {
+		t.Error("Expected a negative limit to be rejected")
+	}
+
+	policy.VTHO.PerTx = nil
+	if err := policy.Validate(); err != nil {
+		t.Errorf("Expected a valid policy, got %v", err)
+	}
+
+	if err := NewSpendingPolicy("").Validate(); err == nil {
+		t.Error("Expected a policy without a wallet to be rejected")
+	}
+}
@@ -0,0 +1,225 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Templates may leave their recipient, amount and notes to be filled in when
+// they are used. Placeholders are written {{name}} or {{name:key=value,...}}.
+// The names amount, contact and address ask for a value of that kind; any
+// other name is free text unless a type option says otherwise:
+//
+//	{{amount:default=50% of VTHO}}
+//	{{contact:category=payroll}}
+//	{{memo:default=Invoice}}
+//	{{fee:type=amount}}
+
+// VariableKind is the kind of value a placeholder asks for
+type VariableKind string
+
+const (
+	VariableAmount  VariableKind = "amount"
+	VariableContact VariableKind = "contact"
+	VariableAddress VariableKind = "address"
+	VariableText    VariableKind = "text"
+)
+
+// TemplateVariable is one placeholder found in a template
+type TemplateVariable struct {
+	// Placeholder is the text between the braces; it identifies the variable
+	Placeholder string
+	Name        string
+	Kind        VariableKind
+	Default     string
+	// Category limits contact variables to contacts in that category
+	Category string
+}
+
+var placeholderPattern = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// ParsePlaceholder reads the text between a placeholder's braces
+func ParsePlaceholder(placeholder string) (TemplateVariable, error) {
+	placeholder = strings.TrimSpace(placeholder)
+	name, options, _ := strings.Cut(placeholder, ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return TemplateVariable{}, fmt.Errorf("placeholder {{%s}} has no name", placeholder)
+	}
+
+	variable := TemplateVariable{Placeholder: placeholder, Name: name, Kind: VariableText}
+	switch kind := VariableKind(strings.ToLower(name)); kind {
+	case VariableAmount, VariableContact, VariableAddress:
+		variable.Kind = kind
+	}
+
+	if strings.TrimSpace(options) == "" {
+		return variable, nil
+	}
+	for _, option := range strings.Split(options, ",") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return TemplateVariable{}, fmt.Errorf("placeholder {{%s}}: option %q must be key=value", placeholder, strings.TrimSpace(option))
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "default":
+			variable.Default = value
+		case "category":
+			variable.Category = value
+		case "type":
+			switch kind := VariableKind(strings.ToLower(value)); kind {
+			case VariableAmount, VariableContact, VariableAddress, VariableText:
+				variable.Kind = kind
+			default:
+				return TemplateVariable{}, fmt.Errorf("placeholder {{%s}}: unknown type %q", placeholder, value)
+			}
+		default:
+			return TemplateVariable{}, fmt.Errorf("placeholder {{%s}}: unknown option %q", placeholder, strings.TrimSpace(key))
+		}
+	}
+
+	if variable.Category != "" && variable.Kind != VariableContact {
+		return TemplateVariable{}, fmt.Errorf("placeholder {{%s}}: only contacts take a category", placeholder)
+	}
+	return variable, nil
+}
+
+// ParsePlaceholders returns the variables in text in order of appearance
+func ParsePlaceholders(text string) ([]TemplateVariable, error) {
+	var variables []TemplateVariable
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		variable, err := ParsePlaceholder(match[1])
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, variable)
+	}
+	return variables, nil
+}
+
+// HasPlaceholders reports whether text contains a placeholder
+func HasPlaceholders(text string) bool {
+	return placeholderPattern.MatchString(text)
+}
+
+// Variables returns the template's placeholders, each once, in the order the
+// recipient, amount and notes use them
+func (tt *TransactionTemplate) Variables() ([]TemplateVariable, error) {
+	var variables []TemplateVariable
+	seen := make(map[string]bool)
+	for _, field := range []string{tt.ToAddress, tt.AmountPlaceholder, tt.Notes} {
+		found, err := ParsePlaceholders(field)
+		if err != nil {
+			return nil, err
+		}
+		for _, variable := range found {
+			if !seen[variable.Placeholder] {
+				seen[variable.Placeholder] = true
+				variables = append(variables, variable)
+			}
+		}
+	}
+	return variables, nil
+}
+
+// HasVariables reports whether anything must be filled in before the
+// template can be used
+func (tt *TransactionTemplate) HasVariables() bool {
+	return tt.AmountPlaceholder != "" || HasPlaceholders(tt.ToAddress) || HasPlaceholders(tt.Notes)
+}
+
+// Fill returns a copy of the template with each placeholder replaced by its
+// value, keyed by Placeholder. Amounts are resolved against balance, which
+// may be nil when no relative amount is used.
+func (tt *TransactionTemplate) Fill(values map[string]string, balance *CachedBalance) (*TransactionTemplate, error) {
+	var missing error
+	substitute := func(text string) string {
+		return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+			placeholder := strings.TrimSpace(match[2 : len(match)-2])
+			value, ok := values[placeholder]
+			if !ok && missing == nil {
+				missing = fmt.Errorf("no value for {{%s}}", placeholder)
+			}
+			return value
+		})
+	}
+
+	filled := *tt
+	filled.Tags = append([]string(nil), tt.Tags...)
+	filled.ToAddress = strings.TrimSpace(substitute(tt.ToAddress))
+	filled.Notes = strings.TrimSpace(substitute(tt.Notes))
+	if tt.AmountPlaceholder != "" {
+		amount, asset, err := ResolveAmount(substitute(tt.AmountPlaceholder), tt.Asset, balance)
+		if err != nil && missing == nil {
+			return nil, err
+		}
+		filled.Amount, filled.Asset = amount, asset
+		filled.AmountPlaceholder = ""
+	}
+	if missing != nil {
+		return nil, missing
+	}
+	return &filled, nil
+}
+
+// ErrBalanceUnknown is returned for an amount relative to a balance that has
+// not been fetched
+var ErrBalanceUnknown = errors.New("balance has not been loaded yet")
+
+var amountPattern = regexp.MustCompile(`(?i)^([0-9]*\.?[0-9]+)\s*(?:(%)\s*(?:of\s+)?)?(VET|VTHO)?$`)
+
+// ResolveAmount reads an amount in whole tokens, optionally followed by the
+// asset ("12.5 VTHO"), or a percentage of a balance ("50% of VTHO"). Without
+// an asset the amount is in asset. It returns the amount in wei and the
+// asset it is in.
+func ResolveAmount(input, asset string, balance *CachedBalance) (*big.Int, string, error) {
+	input = strings.TrimSpace(input)
+	match := amountPattern.FindStringSubmatch(input)
+	if match == nil {
+		return nil, "", fmt.Errorf("invalid amount %q: use a number such as 12.5, 12.5 VTHO or 50%% of VTHO", input)
+	}
+	if match[3] != "" {
+		asset = strings.ToUpper(match[3])
+	}
+
+	value, ok := new(big.Rat).SetString(match[1])
+	if !ok || value.Sign() <= 0 {
+		return nil, "", fmt.Errorf("amount must be greater than 0")
+	}
+
+	if match[2] == "" {
+		if fraction := strings.SplitN(match[1], ".", 2); len(fraction) == 2 && len(fraction[1]) > 18 {
+			return nil, "", fmt.Errorf("amount cannot have more than 18 decimal places")
+		}
+		wei := value.Mul(value, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)))
+		return new(big.Int).Quo(wei.Num(), wei.Denom()), asset, nil
+	}
+
+	if value.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, "", fmt.Errorf("percentage cannot be more than 100%%")
+	}
+	var available *big.Int
+	if balance != nil {
+		switch asset {
+		case "VET":
+			available = balance.VET
+		case "VTHO":
+			available = balance.VTHO
+		}
+	}
+	if available == nil {
+		return nil, "", fmt.Errorf("%s: %w", input, ErrBalanceUnknown)
+	}
+
+	share := new(big.Rat).Mul(new(big.Rat).SetInt(available), value)
+	share.Quo(share, big.NewRat(100, 1))
+	amount := new(big.Int).Quo(share.Num(), share.Denom())
+	if amount.Sign() == 0 {
+		return nil, "", fmt.Errorf("%s of the %s balance is nothing to send", input, asset)
+	}
+	return amount, asset, nil
+}
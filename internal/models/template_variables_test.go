@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParsePlaceholder(t *testing.T) {
+	tests := []struct {
+		placeholder string
+		want        TemplateVariable
+	}{
+		{"amount", TemplateVariable{Placeholder: "amount", Name: "amount", Kind: VariableAmount}},
+		{"contact:category=payroll", TemplateVariable{Placeholder: "contact:category=payroll", Name: "contact", Kind: VariableContact, Category: "payroll"}},
+		{" memo:default=Invoice 42 ", TemplateVariable{Placeholder: "memo:default=Invoice 42", Name: "memo", Kind: VariableText, Default: "Invoice 42"}},
+		{"fee:type=amount,default=50% of VTHO", TemplateVariable{Placeholder: "fee:type=amount,default=50% of VTHO", Name: "fee", Kind: VariableAmount, Default: "50% of VTHO"}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePlaceholder(tt.placeholder)
+		if err != nil {
+			t.Errorf("ParsePlaceholder(%q) failed: %v", tt.placeholder, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePlaceholder(%q) = %+v, want %+v", tt.placeholder, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", ":default=1", "amount:default", "amount:colour=red", "memo:category=payroll", "x:type=date"} {
+		if _, err := ParsePlaceholder(bad); err == nil {
+			t.Errorf("Expected ParsePlaceholder(%q) to fail", bad)
+		}
+	}
+}
+
+func TestResolveAmount(t *testing.T) {
+	balance := &CachedBalance{VET: big.NewInt(4e18), VTHO: big.NewInt(3e18)}
+
+	tests := []struct {
+		input     string
+		wantWei   string
+		wantAsset string
+	}{
+		{"1.5", "1500000000000000000", "VET"},
+		{"2 vtho", "2000000000000000000", "VTHO"},
+		{"50%", "2000000000000000000", "VET"},
+		{"50% of VTHO", "1500000000000000000", "VTHO"},
+		{"12.5%VTHO", "375000000000000000", "VTHO"},
+	}
+	for _, tt := range tests {
+		amount, asset, err := ResolveAmount(tt.input, "VET", balance)
+		if err != nil {
+			t.Errorf("ResolveAmount(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if amount.String() != tt.wantWei || asset != tt.wantAsset {
+			t.Errorf("ResolveAmount(%q) = %s %s, want %s %s", tt.input, amount, asset, tt.wantWei, tt.wantAsset)
+		}
+	}
+
+	for _, bad := range []string{"", "abc", "0", "101%", "5 of VTHO", "1 BTC"} {
+		if _, _, err := ResolveAmount(bad, "VET", balance); err == nil {
+			t.Errorf("Expected ResolveAmount(%q) to fail", bad)
+		}
+	}
+	if _, _, err := ResolveAmount("10%", "VET", nil); !errors.Is(err, ErrBalanceUnknown) {
+		t.Errorf("Expected ErrBalanceUnknown without a balance, got %v", err)
+	}
+}
+
+func TestFillTemplate(t *testing.T) {
+	template := NewTransactionTemplate("Invoice", "", "{{contact:category=suppliers}}", "", "VET", "Invoice {{memo}}", nil, nil)
+	template.AmountPlaceholder = "{{amount:default=50% of VTHO}}"
+
+	variables, err := template.Variables()
+	if err != nil {
+		t.Fatalf("Failed to read variables: %v", err)
+	}
+	if len(variables) != 3 || variables[0].Kind != VariableContact || variables[1].Kind != VariableAmount || variables[2].Name != "memo" {
+		t.Fatalf("Unexpected variables %+v", variables)
+	}
+
+	values := map[string]string{
+		"contact:category=suppliers": "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed",
+		"amount:default=50% of VTHO": "50% of VTHO",
+		"memo":                       "INV-7",
+	}
+	filled, err := template.Fill(values, &CachedBalance{VET: big.NewInt(0), VTHO: big.NewInt(8e18)})
+	if err != nil {
+		t.Fatalf("Failed to fill template: %v", err)
+	}
+	if filled.ToAddress != values["contact:category=suppliers"] || filled.Notes != "Invoice INV-7" {
+		t.Errorf("Unexpected filled template %+v", filled)
+	}
+	if filled.Amount.Cmp(big.NewInt(4e18)) != 0 || filled.Asset != "VTHO" || filled.HasVariables() {
+		t.Errorf("Expected 4 VTHO and no placeholders left, got %s %s", filled.Amount, filled.Asset)
+	}
+	if !template.HasVariables() || template.Amount != nil {
+		t.Error("Fill should not change the template itself")
+	}
+
+	delete(values, "memo")
+	if _, err := template.Fill(values, nil); err == nil {
+		t.Error("Expected a missing value to be reported")
+	}
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters. These are the RFC 6238 defaults, which every
+// authenticator app supports.
+const (
+	TOTPPeriod = 30 * time.Second
+	TOTPDigits = 6
+	// TOTPSkew is how many periods either side of now a code is accepted,
+	// to allow for clock drift
+	TOTPSkew = 1
+
+	totpSecretBytes   = 20
+	totpIssuer        = "veWallet"
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactor is a wallet's TOTP second factor. The secret is only ever held
+// encrypted in the wallet's keystore; recovery codes are kept as hashes so
+// they can be spent without the wallet password.
+type TwoFactor struct {
+	Secret        string    `json:"-"`
+	RecoveryCodes []string  `json:"recovery_codes"`
+	EnrolledAt    time.Time `json:"enrolled_at"`
+	// LastCounter is the time step of the last code accepted. RFC 6238
+	// section 5.2 forbids accepting a code twice, so codes for this step and
+	// earlier ones are refused.
+	LastCounter int64 `json:"last_counter,omitempty"`
+	// FailedAttempts counts wrong codes since the last accepted one, and
+	// LockedUntil is when the lockout they caused ends. They are stored with
+	// the wallet so a lockout outlasts the process that set it.
+	FailedAttempts int       `json:"failed_attempts,omitempty"`
+	LockedUntil    time.Time `json:"locked_until,omitempty"`
+}
+
+// NewTwoFactor generates a TOTP secret and a set of recovery codes. The
+// codes are returned in plain text to be shown once; only their hashes are
+// kept.
+func NewTwoFactor() (*TwoFactor, []string, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate TOTP secret: %w", err)
+	}
+
+	twoFactor := &TwoFactor{Secret: totpEncoding.EncodeToString(secret), EnrolledAt: time.Now()}
+	codes, err := twoFactor.ResetRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return twoFactor, codes, nil
+}
+
+// ResetRecoveryCodes replaces the recovery codes with a new set, returning
+// them in plain text
+func (t *TwoFactor) ResetRecoveryCodes() ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		encoded := totpEncoding.EncodeToString(raw)
+		codes[i] = encoded[:4] + "-" + encoded[4:8] + "-" + encoded[8:12] + "-" + encoded[12:]
+		hashes[i] = HashRecoveryCode(codes[i])
+	}
+	t.RecoveryCodes = hashes
+	return codes, nil
+}
+
+// URI is the otpauth URI authenticator apps enrol from, labelled with the
+// wallet name
+func (t *TwoFactor) URI(account string) string {
+	values := url.Values{}
+	values.Set("secret", t.Secret)
+	values.Set("issuer", totpIssuer)
+	values.Set("algorithm", "SHA1")
+	values.Set("digits", fmt.Sprint(TOTPDigits))
+	values.Set("period", fmt.Sprint(int(TOTPPeriod/time.Second)))
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + values.Encode()
+}
+
+// Verify reports whether code is the TOTP code for at, or for a period
+// within TOTPSkew of it, and is later than the last code accepted. The
+// period it matched is recorded in LastCounter.
+func (t *TwoFactor) Verify(code string, at time.Time) bool {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != TOTPDigits {
+		return false
+	}
+
+	counter := at.Unix() / int64(TOTPPeriod/time.Second)
+	matched := int64(-1)
+	for offset := int64(-TOTPSkew); offset <= TOTPSkew; offset++ {
+		expected, err := totpCode(t.Secret, counter+offset)
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			matched = counter + offset
+		}
+	}
+	if matched < 0 || matched <= t.LastCounter {
+		return false
+	}
+	t.LastCounter = matched
+	return true
+}
+
+// Code returns the TOTP code for at
+func (t *TwoFactor) Code(at time.Time) (string, error) {
+	return totpCode(t.Secret, at.Unix()/int64(TOTPPeriod/time.Second))
+}
+
+// UseRecoveryCode removes code from the unused recovery codes, reporting
+// whether it was one of them
+func (t *TwoFactor) UseRecoveryCode(code string) bool {
+	hash := HashRecoveryCode(code)
+	for i, stored := range t.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(stored), []byte(hash)) == 1 {
+			t.RecoveryCodes = append(t.RecoveryCodes[:i], t.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// HashRecoveryCode hashes a recovery code ignoring case, spaces and dashes.
+// The codes carry 80 random bits, so an unsalted hash is enough.
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpCode is the RFC 4226 HOTP value of the base32 secret for counter
+func totpCode(secret string, counter int64) (string, error) {
+	// An empty key would give codes anyone can compute
+	if secret == "" {
+		return "", fmt.Errorf("TOTP secret is not loaded")
+	}
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	modulus := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", TOTPDigits, value%modulus), nil
+}
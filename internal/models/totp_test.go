@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// SHA-1 vectors from RFC 6238 appendix B, truncated to six digits
+func TestTOTPVectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	twoFactor := &TwoFactor{Secret: secret}
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		code, err := twoFactor.Code(time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("Failed to compute code: %v", err)
+		}
+		if code != tt.code {
+			t.Errorf("Expected %s at %d, got %s", tt.code, tt.unix, code)
+		}
+	}
+}
+
+func TestTOTPVerifySkew(t *testing.T) {
+	twoFactor, _, err := NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to enrol: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	code, err := twoFactor.Code(now)
+	if err != nil {
+		t.Fatalf("Failed to compute code: %v", err)
+	}
+
+	for _, at := range []time.Time{now, now.Add(-TOTPPeriod), now.Add(TOTPPeriod)} {
+		twoFactor.LastCounter = 0
+		if !twoFactor.Verify(code, at) {
+			t.Errorf("Expected code to be accepted at %v", at.Sub(now))
+		}
+	}
+	for _, at := range []time.Time{now.Add(-3 * TOTPPeriod), now.Add(3 * TOTPPeriod)} {
+		if twoFactor.Verify(code, at) {
+			t.Errorf("Expected code to be refused at %v", at.Sub(now))
+		}
+	}
+	if twoFactor.Verify("12345", now) || twoFactor.Verify("", now) {
+		t.Error("Expected malformed codes to be refused")
+	}
+
+	// A secret cleared on lock gives no codes rather than empty-key ones
+	if _, err := (&TwoFactor{}).Code(now); err == nil {
+		t.Error("Expected an empty secret to give no codes")
+	}
+}
+
+func TestTOTPRefusesReuse(t *testing.T) {
+	twoFactor, _, err := NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to enrol: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	code, _ := twoFactor.Code(now)
+	if !twoFactor.Verify(code, now) {
+		t.Fatal("Expected the current code to be accepted")
+	}
+	if twoFactor.Verify(code, now.Add(TOTPPeriod)) {
+		t.Error("Expected a code to be refused once it has been used")
+	}
+
+	// Codes from before the last one used are refused too
+	earlier, _ := twoFactor.Code(now.Add(-TOTPPeriod))
+	if twoFactor.Verify(earlier, now) {
+		t.Error("Expected an earlier code to be refused")
+	}
+	next, _ := twoFactor.Code(now.Add(TOTPPeriod))
+	if !twoFactor.Verify(next, now.Add(TOTPPeriod)) {
+		t.Error("Expected the next code to be accepted")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	twoFactor, codes, err := NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to enrol: %v", err)
+	}
+	if len(codes) != len(twoFactor.RecoveryCodes) {
+		t.Fatalf("Expected a hash per recovery code, got %d codes and %d hashes", len(codes), len(twoFactor.RecoveryCodes))
+	}
+	for _, hash := range twoFactor.RecoveryCodes {
+		for _, code := range codes {
+			if hash == code {
+				t.Fatal("Expected recovery codes to be stored hashed")
+			}
+		}
+	}
+
+	typed := strings.ToLower(strings.ReplaceAll(codes[3], "-", " "))
+	if !twoFactor.UseRecoveryCode(typed) {
+		t.Error("Expected a recovery code to be accepted regardless of case and separators")
+	}
+	if twoFactor.UseRecoveryCode(codes[3]) {
+		t.Error("Expected a recovery code to work only once")
+	}
+	if len(twoFactor.RecoveryCodes) != len(codes)-1 {
+		t.Errorf("Expected %d unused codes, got %d", len(codes)-1, len(twoFactor.RecoveryCodes))
+	}
+}
+
+func TestTwoFactorURI(t *testing.T) {
+	twoFactor := &TwoFactor{Secret: "JBSWY3DPEHPK3PXP"}
+	uri := twoFactor.URI("My Wallet")
+	if !strings.HasPrefix(uri, "otpauth://totp/veWallet:My%20Wallet?") {
+		t.Errorf("Unexpected label in %s", uri)
+	}
+	for _, part := range []string{"secret=JBSWY3DPEHPK3PXP", "issuer=veWallet", "digits=6", "period=30"} {
+		if !strings.Contains(uri, part) {
+			t.Errorf("Expected %s in %s", part, uri)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"math/big"
 	"time"
 )
@@ -120,6 +121,23 @@ func NewTransaction(from, to string, amount *big.Int, asset string) *Transaction
 	}
 }
 
+// NewReceivedTransaction records a confirmed VET transfer the wallet received
+// in clause of transaction txID. Each clause is its own record.
+func NewReceivedTransaction(txID string, clause int, from, to string, amount *big.Int, blockNumber uint64, at time.Time) *Transaction {
+	return &Transaction{
+		ID:          fmt.Sprintf("%s-%d", txID, clause),
+		Hash:        txID,
+		From:        from,
+		To:          to,
+		Amount:      amount,
+		Asset:       "VET",
+		Status:      TransactionStatusConfirmed,
+		Timestamp:   at,
+		BlockNumber: blockNumber,
+		Direction:   TransactionDirectionReceived,
+	}
+}
+
 func generateTransactionID() string {
 	return "tx_" + time.Now().Format("20060102150405") + "_" + generateRandomString(8)
 }
@@ -8,23 +8,26 @@ import (
 )
 
 type TransactionTemplate struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	ToAddress   string    `json:"to_address"`
-	ContactName string    `json:"contact_name,omitempty"`
-	Amount      *big.Int  `json:"amount,omitempty"`
-	Asset       string    `json:"asset"`
-	Notes       string    `json:"notes,omitempty"`
-	Tags        []string  `json:"tags,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	UseCount    int       `json:"use_count"`
-	IsFavorite  bool      `json:"is_favorite"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	ToAddress   string   `json:"to_address"`
+	ContactName string   `json:"contact_name,omitempty"`
+	Amount      *big.Int `json:"amount,omitempty"`
+	// AmountPlaceholder, such as {{amount}}, asks for the amount when the
+	// template is used instead of taking Amount
+	AmountPlaceholder string    `json:"amount_placeholder,omitempty"`
+	Asset             string    `json:"asset"`
+	Notes             string    `json:"notes,omitempty"`
+	Tags              []string  `json:"tags,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	UseCount          int       `json:"use_count"`
+	IsFavorite        bool      `json:"is_favorite"`
 }
 
 type TransactionTemplateManager struct {
-	templates []TransactionTemplate `json:"templates"`
+	templates []TransactionTemplate
 }
 
 func NewTransactionTemplateManager() *TransactionTemplateManager {
@@ -321,7 +324,7 @@ func (ttm *TransactionTemplateManager) Import(templates []TransactionTemplate) {
 
 // Helper functions
 func generateTemplateID() string {
-	return "template_" + time.Now().Format("20060102150405")
+	return "template_" + time.Now().Format("20060102150405.000000000")
 }
 
 func containsTag(tags []string, query string) bool {
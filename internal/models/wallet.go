@@ -4,17 +4,56 @@ import (
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
-	"github.com/darrenvechain/thorgo/crypto/hdwallet"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// KeySource records where a wallet's key came from, which decides the
+// features it supports
+type KeySource string
+
+const (
+	KeySourceMnemonic   KeySource = "mnemonic"
+	KeySourcePrivateKey KeySource = "private_key"
+	KeySourceKeystore   KeySource = "keystore"
+	KeySourceWatchOnly  KeySource = "watch_only"
+)
+
+// CanDeriveAccounts reports whether further accounts can be derived, which
+// needs a recovery phrase
+func (s KeySource) CanDeriveAccounts() bool {
+	return s == KeySourceMnemonic
+}
+
+// Label is the short tag shown next to a wallet
+func (s KeySource) Label() string {
+	switch s {
+	case KeySourceMnemonic:
+		return "mnemonic"
+	case KeySourcePrivateKey:
+		return "private key"
+	case KeySourceKeystore:
+		return "keystore"
+	case KeySourceWatchOnly:
+		return "watch-only"
+	default:
+		return string(s)
+	}
+}
+
 type Wallet struct {
 	ID            string            `json:"id"`
 	Name          string            `json:"name"`
 	Address       string            `json:"address"`
+	Source        KeySource         `json:"source,omitempty"`
+	Accounts      []Account         `json:"-"`
+	ActiveAccount int               `json:"-"`
 	Mnemonic      string            `json:"-"`
+	Passphrase    string            `json:"-"`
 	PrivateKey    *ecdsa.PrivateKey `json:"-"`
+	TwoFactor     *TwoFactor        `json:"-"`
 	CreatedAt     time.Time         `json:"created_at"`
 	IsEncrypted   bool              `json:"is_encrypted"`
 	CachedBalance *CachedBalance    `json:"-"`
@@ -39,33 +78,61 @@ type WalletInfo struct {
 }
 
 func NewWallet(name, mnemonic string) (*Wallet, error) {
-	derivationPath, err := hdwallet.ParseDerivationPath("m/44'/818'/0'/0/0")
-	if err != nil {
-		return nil, err
-	}
+	return NewWalletWithPassphrase(name, mnemonic, "")
+}
 
-	hdWallet, err := hdwallet.FromMnemonicAt(mnemonic, derivationPath)
+// NewWalletWithPassphrase creates a wallet from a mnemonic protected by a
+// BIP39 passphrase (the "25th word"). The same mnemonic with another
+// passphrase is a different wallet.
+func NewWalletWithPassphrase(name, mnemonic, passphrase string) (*Wallet, error) {
+	privateKey, address, err := DeriveAccount(mnemonic, passphrase, AccountPath(0))
 	if err != nil {
 		return nil, err
 	}
 
-	privateKey, err := hdWallet.PrivateKey()
-	if err != nil {
-		return nil, err
-	}
+	return &Wallet{
+		ID:         generateID(),
+		Name:       name,
+		Address:    address,
+		Source:     KeySourceMnemonic,
+		Mnemonic:   mnemonic,
+		Passphrase: passphrase,
+		PrivateKey: privateKey,
+		CreatedAt:  time.Now(),
+	}, nil
+}
 
-	address := hdWallet.Address()
+// NewWalletFromPrivateKey creates a wallet from a raw private key. It has no
+// recovery phrase; callers importing from a keystore file retag its Source.
+func NewWalletFromPrivateKey(name string, privateKey *ecdsa.PrivateKey) (*Wallet, error) {
+	if privateKey == nil {
+		return nil, fmt.Errorf("private key is required")
+	}
 
 	return &Wallet{
 		ID:         generateID(),
 		Name:       name,
-		Address:    address.Hex(),
-		Mnemonic:   mnemonic,
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+		Source:     KeySourcePrivateKey,
 		PrivateKey: privateKey,
 		CreatedAt:  time.Now(),
 	}, nil
 }
 
+// ParsePrivateKey reads a 32-byte private key written as 64 hex characters,
+// with or without a 0x prefix
+func ParsePrivateKey(hexKey string) (*ecdsa.PrivateKey, error) {
+	hexKey = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(hexKey), "0x"), "0X")
+	if len(hexKey) != 64 {
+		return nil, fmt.Errorf("private key must be 64 hex characters, got %d", len(hexKey))
+	}
+	privateKey, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return privateKey, nil
+}
+
 func generateID() string {
 	return time.Now().Format("20060102150405")
 }
@@ -80,6 +147,10 @@ func (w *Wallet) SetBalance(vetBalance, vthoBalance *big.Int) {
 }
 
 func (w *Wallet) GetDisplayBalance() (string, string) {
+	return w.GetDisplayBalanceWithPrecision(4)
+}
+
+func (w *Wallet) GetDisplayBalanceWithPrecision(decimals int) (string, string) {
 	if w.CachedBalance == nil {
 		return "0", "0"
 	}
@@ -93,7 +164,7 @@ func (w *Wallet) GetDisplayBalance() (string, string) {
 	vetBalance := new(big.Float).Quo(vetWei, divisor)
 	vthoBalance := new(big.Float).Quo(vthoWei, divisor)
 
-	return fmt.Sprintf("%.4f", vetBalance), fmt.Sprintf("%.4f", vthoBalance)
+	return fmt.Sprintf("%.*f", decimals, vetBalance), fmt.Sprintf("%.*f", decimals, vthoBalance)
 }
 
 func (w *Wallet) NeedsBalanceRefresh() bool {
@@ -230,6 +230,34 @@ func TestNewWallet(t *testing.T) {
 	}
 }
 
+func TestParsePrivateKey(t *testing.T) {
+	const hexKey = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+
+	for _, input := range []string{hexKey, "0x" + hexKey, "  0x" + hexKey + "\n"} {
+		if _, err := ParsePrivateKey(input); err != nil {
+			t.Errorf("Expected %q to parse, got %v", input, err)
+		}
+	}
+
+	for _, input := range []string{"", hexKey[:62], hexKey + "00", "zz" + hexKey[2:]} {
+		if _, err := ParsePrivateKey(input); err == nil {
+			t.Errorf("Expected error for %q", input)
+		}
+	}
+
+	key, _ := ParsePrivateKey(hexKey)
+	wallet, err := NewWalletFromPrivateKey("Service", key)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if wallet.Source != KeySourcePrivateKey || wallet.Mnemonic != "" {
+		t.Errorf("Expected private key wallet without mnemonic, got source %q", wallet.Source)
+	}
+	if wallet.Address != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Errorf("Unexpected address %s", wallet.Address)
+	}
+}
+
 func TestGenerateID(t *testing.T) {
 	id1 := generateID()
 	time.Sleep(1 * time.Second) // Ensure different timestamp (seconds precision)
@@ -252,3 +280,35 @@ func TestGenerateID(t *testing.T) {
 		t.Errorf("Expected ID length 14, got %d", len(id1))
 	}
 }
+
+func TestNewWalletWithPassphrase(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"
+
+	plain, err := NewWallet("Plain", mnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create 24-word wallet: %v", err)
+	}
+	protected, err := NewWalletWithPassphrase("Protected", mnemonic, "TREZOR")
+	if err != nil {
+		t.Fatalf("Failed to create passphrase wallet: %v", err)
+	}
+	other, _ := NewWalletWithPassphrase("Other", mnemonic, "trezor")
+
+	if plain.Address == protected.Address || protected.Address == other.Address {
+		t.Error("Each passphrase must produce a distinct wallet")
+	}
+	if protected.Passphrase != "TREZOR" {
+		t.Error("Wallet should keep its passphrase")
+	}
+
+	// Further accounts are derived from the same passphrase-protected seed
+	account, err := protected.AddAccount("", "")
+	if err != nil {
+		t.Fatalf("Failed to add account: %v", err)
+	}
+	_, expected, _ := DeriveAccount(mnemonic, "TREZOR", AccountPath(1))
+	if account.Address != expected {
+		t.Errorf("Expected account %s, got %s", expected, account.Address)
+	}
+}
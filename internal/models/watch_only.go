@@ -0,0 +1,117 @@
+package models
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrWatchOnly is returned by anything that would sign for a wallet whose
+// key is not held on this machine
+var ErrWatchOnly = errors.New("watch-only wallet: no signing key is held on this machine, so it cannot sign")
+
+// NewWatchOnlyWallet creates a wallet that tracks address without any key
+func NewWatchOnlyWallet(name, address string) (*Wallet, error) {
+	address, err := parseWatchAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Wallet{
+		ID:        generateID(),
+		Name:      name,
+		Address:   address,
+		Source:    KeySourceWatchOnly,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsWatchOnly reports whether the wallet only tracks an address
+func (w *Wallet) IsWatchOnly() bool {
+	return w.Source == KeySourceWatchOnly
+}
+
+// ParseWatchList reads watch-only wallets from a list with one address per
+// line, optionally followed by a comma or whitespace and a name. Blank lines
+// and lines starting with # are skipped. Unnamed entries are called
+// "<name> N"; a list of one unnamed address is just called name.
+func ParseWatchList(r io.Reader, name string) ([]*Wallet, error) {
+	type entry struct {
+		address string
+		label   string
+	}
+
+	var entries []entry
+	seen := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(text, ",", 2)
+		if len(fields) == 1 {
+			fields = strings.Fields(text)
+			fields = append(fields[:1], strings.Join(fields[1:], " "))
+		}
+
+		address, err := parseWatchAddress(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		key := strings.ToLower(address)
+		if previous, ok := seen[key]; ok {
+			return nil, fmt.Errorf("line %d: %s is already listed on line %d", line, address, previous)
+		}
+		seen[key] = line
+
+		entries = append(entries, entry{address: address, label: strings.TrimSpace(fields[1])})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read address list: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("address list is empty")
+	}
+
+	// IDs are time based, so wallets created together need a suffix
+	baseID := generateID()
+	wallets := make([]*Wallet, len(entries))
+	for i, e := range entries {
+		label := e.label
+		if label == "" {
+			label = name
+			if len(entries) > 1 {
+				label = fmt.Sprintf("%s %d", name, i+1)
+			}
+		}
+
+		wallet, _ := NewWatchOnlyWallet(label, e.address)
+		wallet.ID = baseID
+		if i > 0 {
+			wallet.ID = fmt.Sprintf("%s-%d", baseID, i+1)
+		}
+		wallets[i] = wallet
+	}
+
+	return wallets, nil
+}
+
+func parseWatchAddress(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if !strings.HasPrefix(address, "0x") || !common.IsHexAddress(address) {
+		return "", fmt.Errorf("invalid address: %q (expected 0x followed by 40 hex characters)", address)
+	}
+	parsed := common.HexToAddress(address)
+	if parsed == (common.Address{}) {
+		return "", fmt.Errorf("cannot watch the zero address")
+	}
+	return parsed.Hex(), nil
+}
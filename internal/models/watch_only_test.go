@@ -0,0 +1,53 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWatchList(t *testing.T) {
+	list := `# treasury and partners
+0x7567d83b7b8d80addcb281a71d54fc7b3364ffed, Treasury
+0xd3ae78222beadb038203be21ed5ce7c9b1bff602 Partner Ops
+
+0x5034aa590125b64023a0262112b98d72e3c8e40e
+`
+	wallets, err := ParseWatchList(strings.NewReader(list), "Watched")
+	if err != nil {
+		t.Fatalf("Failed to parse list: %v", err)
+	}
+	if len(wallets) != 3 {
+		t.Fatalf("Expected 3 wallets, got %d", len(wallets))
+	}
+
+	names := []string{"Treasury", "Partner Ops", "Watched 3"}
+	ids := make(map[string]bool)
+	for i, wallet := range wallets {
+		if wallet.Name != names[i] {
+			t.Errorf("Wallet %d: expected name %q, got %q", i, names[i], wallet.Name)
+		}
+		if !wallet.IsWatchOnly() || wallet.PrivateKey != nil {
+			t.Errorf("Wallet %d should be watch-only", i)
+		}
+		ids[wallet.ID] = true
+	}
+	if len(ids) != 3 {
+		t.Error("Wallets from one list must have distinct IDs")
+	}
+	if wallets[0].Address != "0x7567D83b7b8d80ADdCb281A71d54Fc7B3364ffed" {
+		t.Errorf("Address should be checksummed, got %s", wallets[0].Address)
+	}
+
+	invalid := []string{
+		"",
+		"0x1234",
+		"7567d83b7b8d80addcb281a71d54fc7b3364ffed",
+		"0x0000000000000000000000000000000000000000",
+		"0x7567d83b7b8d80addcb281a71d54fc7b3364ffed\n0x7567D83B7B8D80ADDCB281A71D54FC7B3364FFED",
+	}
+	for _, list := range invalid {
+		if _, err := ParseWatchList(strings.NewReader(list), "Watched"); err == nil {
+			t.Errorf("Expected error for list %q", list)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+// Package qrcode encodes short text as a QR code and renders it for a
+// terminal. It supports byte mode at error correction level M in versions 1
+// to 10, which holds up to 213 bytes: enough for an otpauth URI or an
+// address.
+// https://www.iso.org/standard/62021.html
+package qrcode
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxVersion is the largest symbol the encoder produces
+const MaxVersion = 10
+
+// ErrTooLong is returned for text that does not fit in MaxVersion
+var ErrTooLong = errors.New("text is too long for a QR code")
+
+// Level M tables, indexed by version - 1
+var (
+	totalCodewords = [MaxVersion]int{26, 44, 70, 100, 134, 172, 196, 242, 292, 346}
+	ecPerBlock     = [MaxVersion]int{10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	blockCount     = [MaxVersion]int{1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+	alignment      = [MaxVersion][]int{
+		nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34},
+		{6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+	}
+)
+
+const (
+	// formatLevelM is level M's error correction indicator in the format
+	// information
+	formatLevelM = 0
+	formatMask   = 0x5412
+	formatPoly   = 0x537
+	versionPoly  = 0x1F25
+	fieldPoly    = 0x11D
+)
+
+// Code is an encoded symbol. Modules are indexed [row][column] and true is
+// dark.
+type Code struct {
+	Version int
+	Mask    int
+	Modules [][]bool
+
+	function [][]bool
+}
+
+// Size is the width of the symbol in modules, without the quiet zone
+func (c *Code) Size() int {
+	return len(c.Modules)
+}
+
+// Encode encodes text in byte mode in the smallest version that holds it,
+// choosing the mask with the lowest penalty
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	version := 0
+	for v := 1; v <= MaxVersion; v++ {
+		if 4+countBits(v)+len(data)*8 <= dataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	codewords := addErrorCorrection(version, encodeData(version, data))
+
+	var best *Code
+	bestPenalty := 0
+	for mask := 0; mask < 8; mask++ {
+		code := newCode(version)
+		code.placeCodewords(codewords)
+		code.applyMask(mask)
+		code.drawFormat(mask)
+		if penalty := code.penalty(); best == nil || penalty < bestPenalty {
+			best, bestPenalty = code, penalty
+		}
+	}
+	best.function = nil
+	return best, nil
+}
+
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func dataCodewords(version int) int {
+	return totalCodewords[version-1] - ecPerBlock[version-1]*blockCount[version-1]
+}
+
+// encodeData builds the data codewords: mode, length, the bytes, a
+// terminator and padding
+func encodeData(version int, data []byte) []byte {
+	capacity := dataCodewords(version) * 8
+	var bits bitBuffer
+	bits.append(0x4, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// addErrorCorrection splits the data into blocks, computes each block's
+// error correction and interleaves the result
+func addErrorCorrection(version int, data []byte) []byte {
+	blocks := blockCount[version-1]
+	ecLen := ecPerBlock[version-1]
+	total := totalCodewords[version-1]
+	shortBlocks := blocks - total%blocks
+	shortData := total/blocks - ecLen
+
+	divisor := rsDivisor(ecLen)
+	dataBlocks := make([][]byte, blocks)
+	ecBlocks := make([][]byte, blocks)
+	offset := 0
+	for i := 0; i < blocks; i++ {
+		size := shortData
+		if i >= shortBlocks {
+			size++
+		}
+		dataBlocks[i] = data[offset : offset+size]
+		ecBlocks[i] = rsRemainder(dataBlocks[i], divisor)
+		offset += size
+	}
+
+	result := make([]byte, 0, total)
+	for i := 0; i <= shortData; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < ecLen; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// newCode draws the function patterns of an empty symbol, reserving the
+// format areas
+func newCode(version int) *Code {
+	size := 17 + 4*version
+	c := &Code{Version: version, Modules: make([][]bool, size), function: make([][]bool, size)}
+	for i := range c.Modules {
+		c.Modules[i] = make([]bool, size)
+		c.function[i] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ {
+		c.set(6, i, i%2 == 0)
+		c.set(i, 6, i%2 == 0)
+	}
+
+	c.drawFinder(3, 3)
+	c.drawFinder(3, size-4)
+	c.drawFinder(size-4, 3)
+
+	positions := alignment[version-1]
+	last := len(positions) - 1
+	for i, row := range positions {
+		for j, col := range positions {
+			// Skip the three corners taken by finder patterns
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			c.drawAlignment(row, col)
+		}
+	}
+
+	c.drawFormat(0)
+	c.drawVersion()
+	return c
+}
+
+func (c *Code) set(row, col int, dark bool) {
+	c.Modules[row][col] = dark
+	c.function[row][col] = true
+}
+
+// drawFinder draws a finder pattern and its separator centred on row, col
+func (c *Code) drawFinder(row, col int) {
+	size := c.Size()
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			r, cl := row+dy, col+dx
+			if r < 0 || r >= size || cl < 0 || cl >= size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			c.set(r, cl, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(row, col int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.set(row+dy, col+dx, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormat writes both copies of the format information for mask, and the
+// dark module
+func (c *Code) drawFormat(mask int) {
+	bits := formatBits(mask)
+	size := c.Size()
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.set(i, 8, bit(i))
+	}
+	c.set(7, 8, bit(6))
+	c.set(8, 8, bit(7))
+	c.set(8, 7, bit(8))
+	for i := 9; i < 15; i++ {
+		c.set(8, 14-i, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.set(8, size-1-i, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.set(size-15+i, 8, bit(i))
+	}
+	c.set(size-8, 8, true)
+}
+
+// drawVersion writes both copies of the version information, which only
+// versions 7 and up carry
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionBits(c.Version)
+	size := c.Size()
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 != 0
+		a, b := size-11+i%3, i/3
+		c.set(b, a, dark)
+		c.set(a, b, dark)
+	}
+}
+
+// formatBits is the 15-bit format information for level M and mask
+func formatBits(mask int) int {
+	data := formatLevelM<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*formatPoly
+	}
+	return (data<<10 | rem) ^ formatMask
+}
+
+// versionBits is the 18-bit version information
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*versionPoly
+	}
+	return version<<12 | rem
+}
+
+// placeCodewords fills the non-function modules in the zigzag order, two
+// columns at a time from the bottom right
+func (c *Code) placeCodewords(codewords []byte) {
+	size := c.Size()
+	i := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				col := right - j
+				row := vert
+				if (right+1)&2 == 0 {
+					row = size - 1 - vert
+				}
+				if c.function[row][col] || i >= len(codewords)*8 {
+					continue
+				}
+				c.Modules[row][col] = codewords[i>>3]>>(7-i&7)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	c.Mask = mask
+	for row := range c.Modules {
+		for col := range c.Modules[row] {
+			if !c.function[row][col] && maskBit(mask, row, col) {
+				c.Modules[row][col] = !c.Modules[row][col]
+			}
+		}
+	}
+}
+
+func maskBit(mask, row, col int) bool {
+	switch mask {
+	case 0:
+		return (row+col)%2 == 0
+	case 1:
+		return row%2 == 0
+	case 2:
+		return col%3 == 0
+	case 3:
+		return (row+col)%3 == 0
+	case 4:
+		return (row/2+col/3)%2 == 0
+	case 5:
+		return row*col%2+row*col%3 == 0
+	case 6:
+		return (row*col%2+row*col%3)%2 == 0
+	default:
+		return ((row+col)%2+row*col%3)%2 == 0
+	}
+}
+
+// penalty scores a masked symbol by the four rules of the standard; lower
+// is easier to scan
+func (c *Code) penalty() int {
+	size := c.Size()
+	score := 0
+	at := func(row, col int, transpose bool) bool {
+		if transpose {
+			return c.Modules[col][row]
+		}
+		return c.Modules[row][col]
+	}
+
+	for _, transpose := range []bool{false, true} {
+		for row := 0; row < size; row++ {
+			run := 1
+			for col := 1; col <= size; col++ {
+				if col < size && at(row, col, transpose) == at(row, col-1, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+
+			for col := 0; col+11 <= size; col++ {
+				var line [11]bool
+				for k := range line {
+					line[k] = at(row, col+k, transpose)
+				}
+				if line == finderLikeBefore || line == finderLikeAfter {
+					score += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			if c.Modules[row][col] {
+				dark++
+			}
+			if row+1 < size && col+1 < size {
+				v := c.Modules[row][col]
+				if c.Modules[row][col+1] == v && c.Modules[row+1][col] == v && c.Modules[row+1][col+1] == v {
+					score += 3
+				}
+			}
+		}
+	}
+	percent := dark * 100 / (size * size)
+	score += abs(percent-50) / 5 * 10
+
+	return score
+}
+
+var (
+	finderLikeBefore = [11]bool{true, false, true, true, true, false, true, false, false, false, false}
+	finderLikeAfter  = [11]bool{false, false, false, false, true, false, true, true, true, false, true}
+)
+
+// Terminal renders the symbol with a quiet zone using half-block characters,
+// two rows of modules per line. Light modules are drawn as blocks, so it
+// reads correctly as light text on a dark background.
+func (c *Code) Terminal() string {
+	const quiet = 2
+	size := c.Size()
+	light := func(row, col int) bool {
+		row, col = row-quiet, col-quiet
+		if row < 0 || row >= size || col < 0 || col >= size {
+			return true
+		}
+		return !c.Modules[row][col]
+	}
+
+	var b strings.Builder
+	full := size + 2*quiet
+	for row := 0; row < full; row += 2 {
+		for col := 0; col < full; col++ {
+			top := light(row, col)
+			bottom := row+1 < full && light(row+1, col)
+			switch {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteRune(' ')
+			}
+		}
+		if row+2 < full {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 != 0)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i>>3] |= 1 << (7 - i&7)
+		}
+	}
+	return result
+}
+
+// rsDivisor returns the coefficients of the Reed-Solomon generator
+// polynomial of degree, highest first and without the leading 1
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := 0; j < degree; j++ {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < degree {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the error correction codewords for data
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo the QR field polynomial
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*fieldPoly
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,248 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// The "HELLO WORLD" 1-M example from the Thonky QR code tutorial
+// https://www.thonky.com/qr-code-tutorial/error-correction-coding
+func TestErrorCorrectionVector(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("Expected error correction %v, got %v", want, got)
+	}
+}
+
+// Format and version information from the tables in the standard
+func TestFormatAndVersionBits(t *testing.T) {
+	formats := map[int]int{
+		0: 0b101010000010010,
+		1: 0b101000100100101,
+		5: 0b100000011001110,
+		7: 0b100101010100000,
+	}
+	for mask, want := range formats {
+		if got := formatBits(mask); got != want {
+			t.Errorf("Expected format bits %015b for mask %d, got %015b", want, mask, got)
+		}
+	}
+
+	versions := map[int]int{
+		7:  0b000111110010010100,
+		8:  0b001000010110111100,
+		10: 0b001010010011010011,
+	}
+	for version, want := range versions {
+		if got := versionBits(version); got != want {
+			t.Errorf("Expected version bits %018b for version %d, got %018b", want, version, got)
+		}
+	}
+}
+
+func TestEncodePicksSmallestVersion(t *testing.T) {
+	tests := []struct {
+		length  int
+		version int
+	}{
+		{1, 1},
+		{14, 1},
+		{15, 2},
+		{106, 6},
+		{107, 7},
+		{180, 9},
+		{213, 10},
+	}
+	for _, tt := range tests {
+		code, err := Encode(strings.Repeat("a", tt.length))
+		if err != nil {
+			t.Fatalf("Failed to encode %d bytes: %v", tt.length, err)
+		}
+		if code.Version != tt.version {
+			t.Errorf("Expected version %d for %d bytes, got %d", tt.version, tt.length, code.Version)
+		}
+		if code.Size() != 17+4*tt.version {
+			t.Errorf("Expected size %d, got %d", 17+4*tt.version, code.Size())
+		}
+	}
+
+	if _, err := Encode(strings.Repeat("a", 214)); err != ErrTooLong {
+		t.Errorf("Expected ErrTooLong, got %v", err)
+	}
+}
+
+// TestDataModuleCount checks the function patterns leave exactly the
+// codewords and remainder bits the standard gives each version
+func TestDataModuleCount(t *testing.T) {
+	remainder := [MaxVersion]int{0, 7, 7, 7, 7, 7, 0, 0, 0, 0}
+	for version := 1; version <= MaxVersion; version++ {
+		layout := newCode(version)
+		free := 0
+		for _, row := range layout.function {
+			for _, function := range row {
+				if !function {
+					free++
+				}
+			}
+		}
+		if want := totalCodewords[version-1]*8 + remainder[version-1]; free != want {
+			t.Errorf("Expected %d data modules in version %d, got %d", want, version, free)
+		}
+	}
+}
+
+// TestEncodeRoundTrip reads the symbol back the way a scanner would: format
+// information, unmasking, de-interleaving and an error correction check
+func TestEncodeRoundTrip(t *testing.T) {
+	texts := []string{
+		"0x7567d83b7b8d80addcb281a71d54fc7b3364ffed",
+		"otpauth://totp/veWallet:Savings?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=veWallet&algorithm=SHA1&digits=6&period=30",
+		strings.Repeat("veWallet ", 23),
+	}
+
+	for _, text := range texts {
+		code, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Failed to encode: %v", err)
+		}
+		if got := decode(t, code); got != text {
+			t.Errorf("Expected %q to round-trip, got %q", text, got)
+		}
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	code, err := Encode("veWallet")
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	lines := strings.Split(code.Terminal(), "\n")
+	width := code.Size() + 4
+	if len(lines) != (width+1)/2 {
+		t.Errorf("Expected %d lines, got %d", (width+1)/2, len(lines))
+	}
+	for _, line := range lines {
+		if n := len([]rune(line)); n != width {
+			t.Fatalf("Expected lines %d wide, got %d", width, n)
+		}
+	}
+	if !strings.HasPrefix(lines[0], strings.Repeat("█", width)) {
+		t.Error("Expected the quiet zone to be drawn light")
+	}
+}
+
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+	size := code.Size()
+
+	// Read the copy beside the top-left finder, then check the other copy
+	// agrees
+	var format, other int
+	firstCopy := [15][2]int{{0, 8}, {1, 8}, {2, 8}, {3, 8}, {4, 8}, {5, 8}, {7, 8}, {8, 8}, {8, 7}, {8, 5}, {8, 4}, {8, 3}, {8, 2}, {8, 1}, {8, 0}}
+	for i, pos := range firstCopy {
+		if code.Modules[pos[0]][pos[1]] {
+			format |= 1 << i
+		}
+	}
+	for i := 0; i < 15; i++ {
+		row, col := 8, size-1-i
+		if i >= 8 {
+			row, col = size-15+i, 8
+		}
+		if code.Modules[row][col] {
+			other |= 1 << i
+		}
+	}
+	if format != other {
+		t.Fatalf("Format information copies differ: %015b and %015b", format, other)
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask != code.Mask {
+		t.Fatalf("Expected format information for mask %d, read mask %d", code.Mask, mask)
+	}
+
+	// Function modules are the same for every symbol of a version
+	layout := newCode(code.Version)
+	var bits bitBuffer
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				col, row := right-j, vert
+				if (right+1)&2 == 0 {
+					row = size - 1 - vert
+				}
+				if layout.function[row][col] {
+					continue
+				}
+				dark := code.Modules[row][col] != maskBit(mask, row, col)
+				bits = append(bits, dark)
+			}
+		}
+	}
+	codewords := bits[:totalCodewords[code.Version-1]*8].bytes()
+
+	blocks := blockCount[code.Version-1]
+	ecLen := ecPerBlock[code.Version-1]
+	total := totalCodewords[code.Version-1]
+	shortBlocks := blocks - total%blocks
+	shortData := total/blocks - ecLen
+
+	dataBlocks := make([][]byte, blocks)
+	pos := 0
+	for i := 0; i <= shortData; i++ {
+		for b := range dataBlocks {
+			if i < shortData || b >= shortBlocks {
+				dataBlocks[b] = append(dataBlocks[b], codewords[pos])
+				pos++
+			}
+		}
+	}
+	divisor := rsDivisor(ecLen)
+	var data []byte
+	for b, block := range dataBlocks {
+		ec := make([]byte, ecLen)
+		for i := range ec {
+			ec[i] = codewords[pos+i*blocks+b]
+		}
+		if !bytes.Equal(rsRemainder(block, divisor), ec) {
+			t.Fatalf("Error correction of block %d does not match its data", b)
+		}
+		data = append(data, block...)
+	}
+
+	if data[0]>>4 != 0x4 {
+		t.Fatalf("Expected byte mode, got mode %d", data[0]>>4)
+	}
+	var read bitBuffer
+	for _, b := range data {
+		read.append(int(b), 8)
+	}
+	value := func(from, length int) int {
+		v := 0
+		for _, bit := range read[from : from+length] {
+			v <<= 1
+			if bit {
+				v |= 1
+			}
+		}
+		return v
+	}
+	count := value(4, countBits(code.Version))
+	text := make([]byte, count)
+	for i := range text {
+		text[i] = byte(value(4+countBits(code.Version)+i*8, 8))
+	}
+	return string(text)
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/big"
+
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/models"
+)
+
+// Payer reads balances and makes payments for the scheduler
+type Payer interface {
+	Balance(address string) (*models.CachedBalance, error)
+	// Pay signs and broadcasts a payment from wallet and returns its
+	// history record
+	Pay(wallet *models.Wallet, to string, amount *big.Int, asset string) (*models.Transaction, error)
+}
+
+// CheckFunc decides whether a transaction may be signed for wallet
+type CheckFunc func(wallet *models.Wallet, tx *blockchain.Transaction) error
+
+// ChainPayer pays through a blockchain client
+type ChainPayer struct {
+	client *blockchain.Client
+	check  CheckFunc
+}
+
+// NewChainPayer creates a payer that runs check, if set, on every
+// transaction before signing it
+func NewChainPayer(client *blockchain.Client, check CheckFunc) *ChainPayer {
+	return &ChainPayer{client: client, check: check}
+}
+
+func (p *ChainPayer) Balance(address string) (*models.CachedBalance, error) {
+	balance, err := p.client.GetBalance(address)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CachedBalance{VET: balance.VET, VTHO: balance.VTHO, LastUpdated: balance.LastUpdated}, nil
+}
+
+func (p *ChainPayer) Pay(wallet *models.Wallet, to string, amount *big.Int, asset string) (*models.Transaction, error) {
+	tx, err := p.client.BuildTransaction(wallet.Address, to, amount, blockchain.AssetType(asset))
+	if err != nil {
+		return nil, err
+	}
+
+	if p.check != nil {
+		if err := p.check(wallet, tx); err != nil {
+			return nil, fmt.Errorf("policy check failed: %w", err)
+		}
+	}
+
+	signedTx, err := p.client.SignTransaction(tx, wallet.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	txID, err := p.client.BroadcastTransaction(signedTx)
+	if err != nil {
+		return nil, err
+	}
+	p.client.InvalidateCache(wallet.Address)
+
+	record := models.NewTransaction(wallet.Address, to, amount, asset)
+	record.Hash = txID
+	record.Direction = models.TransactionDirectionSent
+	record.Gas = tx.GasLimit.Uint64()
+	record.GasPrice = tx.GasPrice
+	return record, nil
+}
@@ -0,0 +1,517 @@
+package scheduler
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+// BudgetWindow is the rolling period the auto-pay budget covers
+const BudgetWindow = 30 * 24 * time.Hour
+
+// ScheduledTag marks history entries made by a schedule
+const ScheduledTag = "scheduled"
+
+// Budget caps what each wallet's automatic payments may spend per asset in
+// any BudgetWindow. A nil or zero limit leaves every payment in that asset
+// waiting for approval.
+type Budget struct {
+	VET  *big.Int
+	VTHO *big.Int
+}
+
+func (b Budget) limit(asset string) *big.Int {
+	switch asset {
+	case "VET":
+		return b.VET
+	case "VTHO":
+		return b.VTHO
+	}
+	return nil
+}
+
+// KeyFunc returns the unlocked copy of a wallet, or nil while it is locked
+type KeyFunc func(walletID string) *models.Wallet
+
+// Scheduler turns due payment schedules into runs. Runs either wait for
+// approval or, for automatic schedules whose wallet is unlocked, are paid
+// within the budget. Every run is kept in the schedules file and written to
+// the payment audit log; payments made are also added to the wallet's
+// history.
+type Scheduler struct {
+	storage *storage.Storage
+	payer   Payer
+	auditor *audit.PaymentAuditor
+	keys    KeyFunc
+	budget  Budget
+	guard   *security.SpendingGuard
+	now     func() time.Time
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a scheduler. payer may be nil when offline, in which case
+// every payment waits for approval; auditor may be nil to skip the audit
+// log.
+func New(storage *storage.Storage, payer Payer, auditor *audit.PaymentAuditor) *Scheduler {
+	return &Scheduler{
+		storage: storage,
+		payer:   payer,
+		auditor: auditor,
+		now:     time.Now,
+	}
+}
+
+// SetKeys sets how automatic payments find an unlocked wallet
+func (s *Scheduler) SetKeys(keys KeyFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+// SetBudget sets the automatic payment budget
+func (s *Scheduler) SetBudget(budget Budget) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.budget = budget
+}
+
+// SetGuard checks every payment against the wallet's spending policy before
+// it is made. Approving a payment counts as its second confirmation;
+// automatic payments that need one wait for approval instead.
+func (s *Scheduler) SetGuard(guard *security.SpendingGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.guard = guard
+}
+
+// AddSchedule saves a new schedule
+func (s *Scheduler) AddSchedule(schedule *models.PaymentSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	if err := s.storage.SaveSchedule(schedule); err != nil {
+		return err
+	}
+	s.auditSchedule(audit.PaymentActionScheduled, schedule)
+	return nil
+}
+
+// UpdateSchedule saves changes to a schedule, such as pausing it
+func (s *Scheduler) UpdateSchedule(schedule *models.PaymentSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	schedule.UpdatedAt = s.now()
+	if err := s.storage.SaveSchedule(schedule); err != nil {
+		return err
+	}
+	s.auditSchedule(audit.PaymentActionUpdated, schedule)
+	return nil
+}
+
+// RemoveSchedule deletes a schedule. Runs already waiting for approval stay
+// until they are approved or rejected.
+func (s *Scheduler) RemoveSchedule(schedule *models.PaymentSchedule) error {
+	if err := s.storage.DeleteSchedule(schedule.ID); err != nil {
+		return err
+	}
+	s.auditSchedule(audit.PaymentActionRemoved, schedule)
+	return nil
+}
+
+// Pending returns the runs waiting for approval, for one wallet or, with an
+// empty ID, for all of them
+func (s *Scheduler) Pending(walletID string) ([]models.ScheduledRun, error) {
+	runs, err := s.storage.LoadScheduledRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []models.ScheduledRun
+	for _, run := range runs {
+		if run.Status == models.RunPending && (walletID == "" || run.WalletID == walletID) {
+			pending = append(pending, run)
+		}
+	}
+	return pending, nil
+}
+
+// RunDue records a run for every schedule date that has come due and
+// returns the new runs. A schedule that cannot be read is reported but does
+// not hold up the others.
+func (s *Scheduler) RunDue() ([]models.ScheduledRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The schedules are moved on before anything is paid, so neither a crash
+	// part way through nor another process checking at the same time, such
+	// as the TUI beside the daemon, can pay the same date twice
+	now := s.now()
+	taken, dueErr := s.storage.TakeDueSchedules(now)
+
+	var created []models.ScheduledRun
+	for i := range taken {
+		for _, d := range taken[i].Due {
+			run, err := s.prepare(&taken[i].Schedule, d, now)
+			if err != nil {
+				return created, err
+			}
+			created = append(created, *run)
+		}
+	}
+
+	return created, dueErr
+}
+
+// prepare records one due date: as missed, as failed if the template can no
+// longer be filled, paid if it may be paid automatically, and otherwise
+// waiting for approval
+func (s *Scheduler) prepare(schedule *models.PaymentSchedule, due models.DueRun, now time.Time) (*models.ScheduledRun, error) {
+	run := models.NewScheduledRun(schedule, due.DueAt)
+	run.CreatedAt = now
+
+	if due.Missed {
+		run.Resolve(models.RunMissed, fmt.Sprintf("not paid within %s of its due time", models.MissedRunGrace), now)
+		return run, s.record(run, audit.PaymentActionMissed)
+	}
+
+	payment, err := s.fill(schedule)
+	if err != nil {
+		run.Resolve(models.RunFailed, err.Error(), now)
+		return run, s.record(run, audit.PaymentActionFailed)
+	}
+	run.To = payment.ToAddress
+	run.ContactName = payment.ContactName
+	run.Amount = payment.Amount
+	run.Asset = payment.Asset
+	run.Notes = payment.Notes
+
+	if schedule.Mode != models.ScheduleAuto {
+		return run, s.record(run, audit.PaymentActionDue)
+	}
+
+	wallet, reason := s.autoPayWallet(run, now)
+	if wallet != nil {
+		if err := s.checkPolicy(run, wallet, false); err != nil {
+			wallet, reason = nil, err.Error()
+		}
+	}
+	if wallet == nil {
+		run.Reason = reason
+		return run, s.record(run, audit.PaymentActionDue)
+	}
+
+	if err := s.record(run, audit.PaymentActionDue); err != nil {
+		return run, err
+	}
+	if err := s.pay(run, wallet, true); err != nil {
+		run.Reason = "automatic payment failed: " + err.Error()
+		return run, s.record(run, audit.PaymentActionFailed)
+	}
+	return run, nil
+}
+
+// fill resolves the schedule's template and values into a payment
+func (s *Scheduler) fill(schedule *models.PaymentSchedule) (*models.TransactionTemplate, error) {
+	templates, err := s.storage.LoadTemplates(schedule.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	var template *models.TransactionTemplate
+	for i := range templates {
+		if templates[i].ID == schedule.TemplateID {
+			template = &templates[i]
+			break
+		}
+	}
+	if template == nil {
+		return nil, fmt.Errorf("template %s no longer exists", schedule.TemplateID)
+	}
+
+	var balance *models.CachedBalance
+	if template.AmountPlaceholder != "" && s.payer != nil {
+		if address, err := s.walletAddress(schedule.WalletID); err == nil {
+			balance, _ = s.payer.Balance(address)
+		}
+	}
+
+	payment, err := template.Fill(schedule.Values, balance)
+	if err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateVeChainAddress(payment.ToAddress); err != nil {
+		return nil, fmt.Errorf("invalid recipient: %w", err)
+	}
+	if payment.Amount == nil || payment.Amount.Sign() <= 0 {
+		return nil, fmt.Errorf("template %s has no amount", template.Name)
+	}
+	if contacts, err := s.storage.LoadContacts(); err == nil {
+		if contact := contacts.FindByAddress(payment.ToAddress); contact != nil {
+			payment.ContactName = contact.Name
+		}
+	}
+	return payment, nil
+}
+
+func (s *Scheduler) walletAddress(walletID string) (string, error) {
+	wallets, err := s.storage.ListWallets()
+	if err != nil {
+		return "", err
+	}
+	for _, wallet := range wallets {
+		if wallet.ID == walletID {
+			return wallet.Address, nil
+		}
+	}
+	return "", fmt.Errorf("wallet not found: %s", walletID)
+}
+
+// autoPayWallet returns the unlocked wallet to pay run from, or why it must
+// wait for approval instead
+func (s *Scheduler) autoPayWallet(run *models.ScheduledRun, now time.Time) (*models.Wallet, string) {
+	if s.payer == nil {
+		return nil, "not connected to the network"
+	}
+
+	var wallet *models.Wallet
+	if s.keys != nil {
+		wallet = s.keys(run.WalletID)
+	}
+	if wallet == nil || wallet.PrivateKey == nil {
+		return nil, "wallet was locked when the payment fell due"
+	}
+	if wallet.TwoFactor != nil {
+		return nil, "wallet needs an authentication code for every payment"
+	}
+
+	limit := s.budget.limit(run.Asset)
+	if limit == nil || limit.Sign() <= 0 {
+		return nil, fmt.Sprintf("no auto-pay budget is set for %s", run.Asset)
+	}
+	spent, err := s.spent(run.WalletID, run.Asset, now)
+	if err != nil {
+		return nil, err.Error()
+	}
+	if new(big.Int).Add(spent, run.Amount).Cmp(limit) > 0 {
+		return nil, fmt.Sprintf("would exceed the auto-pay budget of %s %s (%s spent in the last %d days)",
+			utils.FormatAmount(limit, 4), run.Asset, utils.FormatAmount(spent, 4), int(BudgetWindow.Hours()/24))
+	}
+	return wallet, ""
+}
+
+// spent totals the wallet's automatic payments in asset within the budget
+// window
+func (s *Scheduler) spent(walletID, asset string, now time.Time) (*big.Int, error) {
+	runs, err := s.storage.LoadScheduledRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	total := new(big.Int)
+	since := now.Add(-BudgetWindow)
+	for _, run := range runs {
+		if run.Auto && run.Status == models.RunSent && run.WalletID == walletID && run.Asset == asset &&
+			run.ResolvedAt != nil && run.ResolvedAt.After(since) && run.Amount != nil {
+			total.Add(total, run.Amount)
+		}
+	}
+	return total, nil
+}
+
+// Approve pays a run that is waiting for approval from wallet, which must
+// be its unlocked wallet. Callers check the wallet's second factor first. A
+// failed payment stays waiting so it can be tried again.
+func (s *Scheduler) Approve(runID string, wallet *models.Wallet) (*models.ScheduledRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, err := s.findPending(runID)
+	if err != nil {
+		return nil, err
+	}
+	if wallet == nil || wallet.ID != run.WalletID {
+		return nil, fmt.Errorf("run %s must be approved from wallet %s", runID, run.WalletID)
+	}
+	if wallet.PrivateKey == nil {
+		return nil, fmt.Errorf("wallet %s has no signing key available", wallet.ID)
+	}
+	if s.payer == nil {
+		return nil, fmt.Errorf("not connected to the network")
+	}
+
+	if err := s.checkPolicy(run, wallet, true); err != nil {
+		run.Reason = err.Error()
+		if recordErr := s.record(run, audit.PaymentActionFailed); recordErr != nil {
+			return nil, recordErr
+		}
+		return nil, err
+	}
+	if err := s.pay(run, wallet, false); err != nil {
+		run.Reason = "payment failed: " + err.Error()
+		if recordErr := s.record(run, audit.PaymentActionFailed); recordErr != nil {
+			return nil, recordErr
+		}
+		return nil, err
+	}
+	return run, nil
+}
+
+// Reject closes a run that is waiting for approval without paying it
+func (s *Scheduler) Reject(runID, reason string) (*models.ScheduledRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, err := s.findPending(runID)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "rejected"
+	}
+	run.Resolve(models.RunRejected, reason, s.now())
+	if err := s.record(run, audit.PaymentActionRejected); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (s *Scheduler) findPending(runID string) (*models.ScheduledRun, error) {
+	runs, err := s.storage.LoadScheduledRuns()
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ID != runID {
+			continue
+		}
+		if runs[i].Status != models.RunPending {
+			return nil, fmt.Errorf("run %s is already %s", runID, runs[i].Status)
+		}
+		return &runs[i], nil
+	}
+	return nil, fmt.Errorf("scheduled run not found: %s", runID)
+}
+
+// checkPolicy runs the spending guard, if set, on run paid from wallet
+func (s *Scheduler) checkPolicy(run *models.ScheduledRun, wallet *models.Wallet, approved bool) error {
+	if s.guard == nil {
+		return nil
+	}
+	return s.guard.Check(run.WalletID, wallet.Address, run.To, run.Amount, run.Asset, approved)
+}
+
+// pay sends run from wallet and records it in the wallet's history
+func (s *Scheduler) pay(run *models.ScheduledRun, wallet *models.Wallet, auto bool) error {
+	record, err := s.payer.Pay(wallet, run.To, run.Amount, run.Asset)
+	if err != nil {
+		return err
+	}
+
+	run.TxHash = record.Hash
+	run.Auto = auto
+	run.Resolve(models.RunSent, "", s.now())
+	if err := s.record(run, audit.PaymentActionSent); err != nil {
+		return fmt.Errorf("payment %s sent but not recorded: %w", record.Hash, err)
+	}
+
+	record.ContactName = run.ContactName
+	record.Notes = run.Notes
+	record.Tags = append(record.Tags, ScheduledTag)
+	if err := s.storage.SaveTransaction(run.WalletID, record); err != nil {
+		return fmt.Errorf("payment %s sent but not added to history: %w", record.Hash, err)
+	}
+	return nil
+}
+
+// record saves run and writes it to the audit log. The run is the record
+// that matters, so a failure to audit does not fail the run.
+func (s *Scheduler) record(run *models.ScheduledRun, action audit.PaymentAction) error {
+	if err := s.storage.SaveScheduledRun(run); err != nil {
+		return err
+	}
+	if s.auditor == nil {
+		return nil
+	}
+
+	details := map[string]interface{}{
+		"schedule": run.ScheduleName,
+		"due_at":   run.DueAt,
+		"status":   run.Status,
+	}
+	if run.Amount != nil {
+		details["to"] = run.To
+		details["amount"] = run.Amount.String()
+		details["asset"] = run.Asset
+	}
+	if run.TxHash != "" {
+		details["tx_hash"] = run.TxHash
+		details["auto"] = run.Auto
+	}
+	if run.Reason != "" {
+		details["reason"] = run.Reason
+	}
+	_ = s.auditor.LogPayment(action, run.ScheduleID, run.ID, run.WalletID, details)
+	return nil
+}
+
+func (s *Scheduler) auditSchedule(action audit.PaymentAction, schedule *models.PaymentSchedule) {
+	if s.auditor == nil {
+		return
+	}
+	_ = s.auditor.LogPayment(action, schedule.ID, "", schedule.WalletID, map[string]interface{}{
+		"name":       schedule.Name,
+		"template":   schedule.TemplateID,
+		"recurrence": schedule.Recurrence,
+		"mode":       schedule.Mode,
+		"paused":     schedule.Paused,
+	})
+}
+
+// Start checks for due payments every interval until Stop is called.
+// onError, if set, receives the errors of each check.
+func (s *Scheduler) Start(interval time.Duration, onError func(error)) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	check := func() {
+		if _, err := s.RunDue(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+
+	go func() {
+		defer close(s.done)
+		check()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop ends the checks started by Start and waits for one in progress
+func (s *Scheduler) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+}
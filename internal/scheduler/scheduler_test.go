@@ -0,0 +1,267 @@
+package scheduler
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+const payee = "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed"
+
+type fakePayer struct {
+	paid []string
+}
+
+func (p *fakePayer) Balance(address string) (*models.CachedBalance, error) {
+	return &models.CachedBalance{VET: big.NewInt(9e18), VTHO: big.NewInt(9e18)}, nil
+}
+
+func (p *fakePayer) Pay(wallet *models.Wallet, to string, amount *big.Int, asset string) (*models.Transaction, error) {
+	p.paid = append(p.paid, amount.String())
+	record := models.NewTransaction(wallet.Address, to, amount, asset)
+	record.Hash = fmt.Sprintf("0x%064d", len(p.paid))
+	record.Direction = models.TransactionDirectionSent
+	return record, nil
+}
+
+// newTestScheduler returns a scheduler whose clock is set to now, with a
+// monthly 2 VET schedule on wallet-a due from start
+func newTestScheduler(t *testing.T, mode models.ScheduleMode, start, now time.Time) (*Scheduler, *fakePayer, *storage.Storage, string) {
+	t.Helper()
+	store, err := storage.NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	template := models.NewTransactionTemplate("Retainer", "", payee, "", "VET", "Monthly retainer", big.NewInt(2e18), nil)
+	if err := store.SaveTemplates("wallet-a", []models.TransactionTemplate{*template}); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+
+	auditor, err := audit.NewPaymentAuditor(store.AuditLogDir())
+	if err != nil {
+		t.Fatalf("Failed to create auditor: %v", err)
+	}
+	payer := &fakePayer{}
+	s := New(store, payer, auditor)
+	s.now = func() time.Time { return now }
+
+	schedule, err := models.NewPaymentSchedule("Retainer", "wallet-a", template.ID, "0 9 1 * *", start)
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	schedule.Mode = mode
+	if err := s.AddSchedule(schedule); err != nil {
+		t.Fatalf("AddSchedule failed: %v", err)
+	}
+	return s, payer, store, schedule.ID
+}
+
+func unlocked(walletID string) *models.Wallet {
+	return &models.Wallet{ID: walletID, Address: "0x0000000000000000000000000000456e65726779", PrivateKey: &ecdsa.PrivateKey{}}
+}
+
+func TestRunDueWaitsForApproval(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s, payer, store, _ := newTestScheduler(t, models.ScheduleApprove, start, start.Add(time.Minute))
+
+	runs, err := s.RunDue()
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != models.RunPending || runs[0].To != payee || runs[0].Amount.Cmp(big.NewInt(2e18)) != 0 {
+		t.Fatalf("Expected one pending 2 VET run, got %+v", runs)
+	}
+	if again, _ := s.RunDue(); len(again) != 0 {
+		t.Fatalf("Expected the due date to be recorded once, got %+v", again)
+	}
+
+	if _, err := s.Approve(runs[0].ID, unlocked("wallet-b")); err == nil {
+		t.Fatal("Expected approval from another wallet to fail")
+	}
+	run, err := s.Approve(runs[0].ID, unlocked("wallet-a"))
+	if err != nil {
+		t.Fatalf("Approve failed: %v", err)
+	}
+	if run.Status != models.RunSent || run.Auto || len(payer.paid) != 1 {
+		t.Fatalf("Expected the run to be paid once on approval, got %+v", run)
+	}
+	if _, err := s.Approve(runs[0].ID, unlocked("wallet-a")); err == nil {
+		t.Fatal("Expected a sent run not to be approved again")
+	}
+
+	history, err := store.LoadTransactionHistory("wallet-a")
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(history) != 1 || history[0].Hash != run.TxHash || len(history[0].Tags) != 1 || history[0].Tags[0] != ScheduledTag {
+		t.Fatalf("Expected the payment in history tagged %q, got %+v", ScheduledTag, history)
+	}
+
+	entries, _ := os.ReadDir(store.AuditLogDir())
+	var logged string
+	for _, entry := range entries {
+		data, _ := os.ReadFile(filepath.Join(store.AuditLogDir(), entry.Name()))
+		logged += string(data)
+	}
+	for _, action := range []string{"scheduled", "due", "sent"} {
+		if !strings.Contains(logged, `"action":"`+action+`"`) {
+			t.Errorf("Expected a %q entry in the payment audit log", action)
+		}
+	}
+}
+
+func TestRunDuePaysAutomaticallyWithinBudget(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s, payer, store, scheduleID := newTestScheduler(t, models.ScheduleAuto, start, start.Add(time.Minute))
+
+	// Locked wallet: the payment waits for approval
+	runs, err := s.RunDue()
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != models.RunPending || !strings.Contains(runs[0].Reason, "locked") {
+		t.Fatalf("Expected a pending run while locked, got %+v", runs)
+	}
+	if _, err := s.Reject(runs[0].ID, ""); err != nil {
+		t.Fatalf("Reject failed: %v", err)
+	}
+
+	// Unlocked with room for one payment in the budget window
+	s.SetKeys(unlocked)
+	s.SetBudget(Budget{VET: big.NewInt(3e18)})
+	s.now = func() time.Time { return start.AddDate(0, 1, 0).Add(time.Minute) }
+	runs, err = s.RunDue()
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != models.RunSent || !runs[0].Auto || len(payer.paid) != 1 {
+		t.Fatalf("Expected an automatic payment, got %+v", runs)
+	}
+
+	// A second payment within 30 days would go over the budget
+	schedules, _ := store.LoadSchedules()
+	schedules[0].Recurrence = "every 1w"
+	schedules[0].StartAt = start.AddDate(0, 1, 0)
+	if err := schedules[0].Reset(); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if err := s.UpdateSchedule(&schedules[0]); err != nil {
+		t.Fatalf("UpdateSchedule failed: %v", err)
+	}
+	s.now = func() time.Time { return start.AddDate(0, 1, 7).Add(time.Minute) }
+	runs, err = s.RunDue()
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != models.RunPending || !strings.Contains(runs[0].Reason, "budget") {
+		t.Fatalf("Expected the over-budget payment to wait for approval, got %+v", runs)
+	}
+
+	pending, err := s.Pending("wallet-a")
+	if err != nil || len(pending) != 1 || pending[0].ScheduleID != scheduleID {
+		t.Fatalf("Expected one pending run, got %+v (%v)", pending, err)
+	}
+}
+
+func TestRunDueWaitsForSecondFactor(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s, payer, _, _ := newTestScheduler(t, models.ScheduleAuto, start, start.Add(time.Minute))
+	s.SetBudget(Budget{VET: big.NewInt(3e18)})
+	s.SetKeys(func(walletID string) *models.Wallet {
+		wallet := unlocked(walletID)
+		wallet.TwoFactor = &models.TwoFactor{Secret: "JBSWY3DPEHPK3PXP"}
+		return wallet
+	})
+
+	runs, err := s.RunDue()
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Status != models.RunPending || !strings.Contains(runs[0].Reason, "authentication code") || len(payer.paid) != 0 {
+		t.Fatalf("Expected the payment to wait for approval, got %+v", runs)
+	}
+}
+
+func TestRunDuePaysOnceAcrossProcesses(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	first, firstPayer, store, _ := newTestScheduler(t, models.ScheduleAuto, start, start.Add(time.Minute))
+
+	// A second scheduler on the same profile, as when the TUI and the daemon
+	// both check for due payments
+	otherStore, err := storage.NewStorageAt(store.DataDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	otherPayer := &fakePayer{}
+	second := New(otherStore, otherPayer, nil)
+	second.now = first.now
+
+	for _, s := range []*Scheduler{first, second} {
+		s.SetKeys(unlocked)
+		s.SetBudget(Budget{VET: big.NewInt(9e18)})
+	}
+
+	var wg sync.WaitGroup
+	for _, s := range []*Scheduler{first, second} {
+		wg.Add(1)
+		go func(s *Scheduler) {
+			defer wg.Done()
+			if _, err := s.RunDue(); err != nil {
+				t.Errorf("RunDue failed: %v", err)
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	if paid := len(firstPayer.paid) + len(otherPayer.paid); paid != 1 {
+		t.Errorf("Expected the due date to be paid once, got %d payments", paid)
+	}
+	runs, err := store.LoadScheduledRuns()
+	if err != nil || len(runs) != 1 {
+		t.Errorf("Expected one run, got %+v (%v)", runs, err)
+	}
+}
+
+func TestAutoPayBudgetIsPerWallet(t *testing.T) {
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	s, payer, store, _ := newTestScheduler(t, models.ScheduleAuto, start, start.Add(time.Minute))
+
+	template := models.NewTransactionTemplate("Rent", "", payee, "", "VET", "", big.NewInt(2e18), nil)
+	if err := store.SaveTemplates("wallet-b", []models.TransactionTemplate{*template}); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	schedule, err := models.NewPaymentSchedule("Rent", "wallet-b", template.ID, "0 9 1 * *", start)
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	schedule.Mode = models.ScheduleAuto
+	if err := s.AddSchedule(schedule); err != nil {
+		t.Fatalf("AddSchedule failed: %v", err)
+	}
+
+	// 4 VET in total, but each wallet stays within its own 3 VET
+	s.SetKeys(unlocked)
+	s.SetBudget(Budget{VET: big.NewInt(3e18)})
+	runs, err := s.RunDue()
+	if err != nil {
+		t.Fatalf("RunDue failed: %v", err)
+	}
+	if len(runs) != 2 || len(payer.paid) != 2 {
+		t.Fatalf("Expected both wallets to pay automatically, got %+v", runs)
+	}
+	for _, run := range runs {
+		if run.Status != models.RunSent {
+			t.Errorf("Expected the %s run to be paid, got %s (%s)", run.WalletID, run.Status, run.Reason)
+		}
+	}
+}
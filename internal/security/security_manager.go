@@ -271,43 +271,90 @@ const (
 	SecurityMax    SecurityLevel = "max"
 )
 
-func (sm *SecurityManager) ApplySecurityLevel(level SecurityLevel) {
+// SecurityLevels lists the supported levels from least to most restrictive
+var SecurityLevels = []SecurityLevel{SecurityLow, SecurityMedium, SecurityHigh, SecurityMax}
+
+// ParseSecurityLevel converts a configured level name into a SecurityLevel
+func ParseSecurityLevel(level string) (SecurityLevel, error) {
+	for _, l := range SecurityLevels {
+		if string(l) == level {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("invalid security level: %s", level)
+}
+
+// SessionConfigForLevel returns the session settings applied by a security level
+func SessionConfigForLevel(level SecurityLevel) *SessionConfig {
 	switch level {
 	case SecurityLow:
-		sm.sessionManager.UpdateConfig(&SessionConfig{
+		return &SessionConfig{
 			DefaultTimeout:  30 * time.Minute,
 			MaxSessions:     10,
 			CleanupInterval: 60 * time.Second,
 			InactivityLimit: 30 * time.Minute,
 			RequirePassword: false,
-		})
-		sm.UpdateAttemptTrackerConfig(5, 1*time.Minute)
+		}
 	case SecurityMedium:
-		sm.sessionManager.UpdateConfig(&SessionConfig{
+		return &SessionConfig{
 			DefaultTimeout:  15 * time.Minute,
 			MaxSessions:     5,
 			CleanupInterval: 30 * time.Second,
 			InactivityLimit: 15 * time.Minute,
 			RequirePassword: true,
-		})
-		sm.UpdateAttemptTrackerConfig(3, 5*time.Minute)
-	case SecurityHigh:
-		sm.sessionManager.UpdateConfig(&SessionConfig{
+		}
+	case SecurityMax:
+		return &SessionConfig{
+			DefaultTimeout:  2 * time.Minute,
+			MaxSessions:     1,
+			CleanupInterval: 10 * time.Second,
+			InactivityLimit: 2 * time.Minute,
+			RequirePassword: true,
+		}
+	default:
+		return &SessionConfig{
 			DefaultTimeout:  5 * time.Minute,
 			MaxSessions:     3,
 			CleanupInterval: 15 * time.Second,
 			InactivityLimit: 5 * time.Minute,
 			RequirePassword: true,
-		})
+		}
+	}
+}
+
+func (sm *SecurityManager) ApplySecurityLevel(level SecurityLevel) {
+	switch level {
+	case SecurityLow:
+		sm.sessionManager.UpdateConfig(SessionConfigForLevel(level))
+		sm.UpdateAttemptTrackerConfig(5, 1*time.Minute)
+	case SecurityMedium:
+		sm.sessionManager.UpdateConfig(SessionConfigForLevel(level))
+		sm.UpdateAttemptTrackerConfig(3, 5*time.Minute)
+	case SecurityHigh:
+		sm.sessionManager.UpdateConfig(SessionConfigForLevel(level))
 		sm.UpdateAttemptTrackerConfig(3, 15*time.Minute)
 	case SecurityMax:
-		sm.sessionManager.UpdateConfig(&SessionConfig{
-			DefaultTimeout:  2 * time.Minute,
-			MaxSessions:     1,
-			CleanupInterval: 10 * time.Second,
-			InactivityLimit: 2 * time.Minute,
-			RequirePassword: true,
-		})
+		sm.sessionManager.UpdateConfig(SessionConfigForLevel(level))
 		sm.UpdateAttemptTrackerConfig(1, 1*time.Hour)
 	}
 }
+
+// ApplySessionPolicy applies a security level and then any explicit session
+// overrides. A zero timeout or maxSessions keeps the level's default.
+func (sm *SecurityManager) ApplySessionPolicy(level SecurityLevel, timeout time.Duration, maxSessions int) {
+	sm.ApplySecurityLevel(level)
+
+	if timeout <= 0 && maxSessions <= 0 {
+		return
+	}
+
+	config := sm.sessionManager.GetConfig()
+	if timeout > 0 {
+		config.DefaultTimeout = timeout
+		config.InactivityLimit = timeout
+	}
+	if maxSessions > 0 {
+		config.MaxSessions = maxSessions
+	}
+	sm.sessionManager.UpdateConfig(&config)
+}
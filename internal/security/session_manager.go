@@ -167,6 +167,7 @@ func (sm *SessionManager) CloseSession(walletID string) error {
 	session.IsActive = false
 	sm.clearSensitiveData(session)
 	delete(sm.sessions, walletID)
+	sm.lockContactsWhenIdle()
 
 	return nil
 }
@@ -180,6 +181,7 @@ func (sm *SessionManager) CloseAllSessions() {
 		sm.clearSensitiveData(session)
 		delete(sm.sessions, walletID)
 	}
+	sm.lockContactsWhenIdle()
 }
 
 func (sm *SessionManager) GetActiveSessions() []string {
@@ -245,6 +247,14 @@ func (sm *SessionManager) UpdateConfig(config *SessionConfig) {
 	sm.config = config
 }
 
+// GetConfig returns a copy of the current session configuration
+func (sm *SessionManager) GetConfig() SessionConfig {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+
+	return *sm.config
+}
+
 func (sm *SessionManager) Shutdown() {
 	close(sm.stopCleanup)
 	if sm.cleanupTicker != nil {
@@ -269,8 +279,15 @@ func (sm *SessionManager) clearSensitiveData(session *WalletSession) {
 		}
 		session.UnlockedWallet.PrivateKey = nil
 
-		// Clear mnemonic if present
+		// Clear mnemonic and passphrase if present
 		session.UnlockedWallet.Mnemonic = ""
+		session.UnlockedWallet.Passphrase = ""
+	}
+
+	// Clear the TOTP secret but keep the second factor, so a stale copy of
+	// the wallet still asks for a code and refuses every one
+	if session.UnlockedWallet != nil && session.UnlockedWallet.TwoFactor != nil {
+		session.UnlockedWallet.TwoFactor.Secret = ""
 	}
 
 	// Clear session token
@@ -297,13 +314,27 @@ func (sm *SessionManager) cleanupExpiredSessions() {
 	defer sm.mu.Unlock()
 
 	now := time.Now()
+	expired := false
 	for walletID, session := range sm.sessions {
 		if now.After(session.ExpiresAt) {
 			session.IsActive = false
 			sm.clearSensitiveData(session)
 			delete(sm.sessions, walletID)
+			expired = true
 		}
 	}
+	if expired {
+		sm.lockContactsWhenIdle()
+	}
+}
+
+// lockContactsWhenIdle forgets the contacts key once no wallet is unlocked,
+// so decrypted contacts last no longer than the sessions that unlocked them.
+// Callers hold sm.mu.
+func (sm *SessionManager) lockContactsWhenIdle() {
+	if len(sm.sessions) == 0 && sm.storage != nil {
+		sm.storage.LockContacts()
+	}
 }
 
 func (sm *SessionManager) startActivityMonitor() {
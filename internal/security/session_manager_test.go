@@ -589,3 +589,106 @@ func BenchmarkSessionManager_ValidateSession(b *testing.B) {
 		sm.ValidateSession(wallet.ID, session.SessionToken)
 	}
 }
+
+func TestSessionManager_LocksContactsWhenIdle(t *testing.T) {
+	store, err := storage.NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, "correct-horse-battery"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	sm := NewSessionManager(store)
+	defer sm.Shutdown()
+
+	unlock := func(id string) {
+		t.Helper()
+		unlocked, err := store.LoadWallet(wallet.ID, "correct-horse-battery")
+		if err != nil {
+			t.Fatalf("Failed to load wallet: %v", err)
+		}
+		unlocked.ID = id
+		if _, err := sm.CreateSession(unlocked); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+	}
+
+	unlock("first")
+	unlock("second")
+	if !store.ContactsUnlocked() {
+		t.Fatal("Expected unlocking a wallet to unlock the contacts")
+	}
+	if err := sm.CloseSession("first"); err != nil {
+		t.Fatalf("Failed to close session: %v", err)
+	}
+	if !store.ContactsUnlocked() {
+		t.Error("Expected the contacts to stay unlocked while a wallet is unlocked")
+	}
+	if err := sm.CloseSession("second"); err != nil {
+		t.Fatalf("Failed to close session: %v", err)
+	}
+	if store.ContactsUnlocked() {
+		t.Error("Expected the contacts to lock with the last session")
+	}
+
+	// Expiry locks them too
+	unlock("first")
+	sm.mu.Lock()
+	sm.sessions["first"].ExpiresAt = time.Now().Add(-time.Second)
+	sm.mu.Unlock()
+	sm.cleanupExpiredSessions()
+	if store.ContactsUnlocked() {
+		t.Error("Expected the contacts to lock when the last session expires")
+	}
+}
+
+func TestSessionManager_ClearsTwoFactorSecret(t *testing.T) {
+	store, err := storage.NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	sm := NewSessionManager(store)
+	defer sm.Shutdown()
+
+	unlock := func(id string) *models.TwoFactor {
+		t.Helper()
+		twoFactor, _, err := models.NewTwoFactor()
+		if err != nil {
+			t.Fatalf("Failed to create second factor: %v", err)
+		}
+		wallet := &models.Wallet{ID: id, Name: id, Address: "0x1234567890abcdef1234567890abcdef12345690", TwoFactor: twoFactor}
+		if _, err := sm.CreateSession(wallet); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+		return twoFactor
+	}
+
+	locked := unlock("locked")
+	code, err := locked.Code(time.Now())
+	if err != nil {
+		t.Fatalf("Failed to compute code: %v", err)
+	}
+	if err := sm.CloseSession("locked"); err != nil {
+		t.Fatalf("Failed to close session: %v", err)
+	}
+	if locked.Secret != "" {
+		t.Error("Expected locking to clear the TOTP secret")
+	}
+	if locked.Verify(code, time.Now()) {
+		t.Error("Expected a cleared second factor to refuse its code")
+	}
+
+	expired := unlock("expired")
+	sm.mu.Lock()
+	sm.sessions["expired"].ExpiresAt = time.Now().Add(-time.Second)
+	sm.mu.Unlock()
+	sm.cleanupExpiredSessions()
+	if expired.Secret != "" {
+		t.Error("Expected expiry to clear the TOTP secret")
+	}
+}
@@ -35,12 +35,7 @@ type SessionData struct {
 }
 
 func NewSessionStore(storage *storage.Storage, encryptionKey []byte) *SessionStore {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		panic(fmt.Sprintf("failed to get home directory: %v", err))
-	}
-
-	sessionFile := filepath.Join(homeDir, ".veterm", "sessions.enc")
+	sessionFile := filepath.Join(storage.DataDir(), "sessions.enc")
 
 	return &SessionStore{
 		storage:       storage,
@@ -0,0 +1,189 @@
+package security
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+// SpendingGuard checks payments against each wallet's spending policy before
+// they are signed. Spending is counted across every account the wallet
+// derives, and every decision and policy change goes to the audit log.
+type SpendingGuard struct {
+	storage *storage.Storage
+	auditor *audit.PolicyAuditor
+	now     func() time.Time
+}
+
+// NewSpendingGuard creates a guard over the profile in storage. auditor may
+// be nil to skip the audit log.
+func NewSpendingGuard(storage *storage.Storage, auditor *audit.PolicyAuditor) *SpendingGuard {
+	return &SpendingGuard{
+		storage: storage,
+		auditor: auditor,
+		now:     time.Now,
+	}
+}
+
+// Policy returns a wallet's spending policy, or a disabled one when none has
+// been saved
+func (g *SpendingGuard) Policy(walletID string) (*models.SpendingPolicy, error) {
+	policy, err := g.storage.LoadSpendingPolicy(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = models.NewSpendingPolicy(walletID)
+	}
+	return policy, nil
+}
+
+// UpdatePolicy saves a wallet's spending policy
+func (g *SpendingGuard) UpdatePolicy(policy *models.SpendingPolicy) error {
+	policy.UpdatedAt = g.now()
+	if err := g.storage.SaveSpendingPolicy(policy); err != nil {
+		return err
+	}
+	g.log(audit.PolicyActionUpdated, policy.ID, map[string]interface{}{
+		"enabled": policy.Enabled,
+		"rules":   policy.Describe(),
+	})
+	return nil
+}
+
+// Evaluate checks a payment so its outcome can be shown before the user
+// commits to it. from is the paying account's address, kept in the audit
+// log. Violations and payments that need confirmation are logged.
+func (g *SpendingGuard) Evaluate(walletID, from, to string, amount *big.Int, asset string) (*models.PolicyDecision, error) {
+	decision, err := g.evaluate(walletID, from, to, amount, asset)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !decision.Allowed():
+		g.logDecision(audit.PolicyActionBlocked, walletID, from, to, amount, asset, decision, false)
+	case decision.NeedsConfirmation():
+		g.logDecision(audit.PolicyActionConfirmationRequired, walletID, from, to, amount, asset, decision, false)
+	}
+	return decision, nil
+}
+
+// Check decides a payment that is about to be signed. It fails when the
+// payment breaks the policy, or needs a second confirmation that was not
+// given.
+func (g *SpendingGuard) Check(walletID, from, to string, amount *big.Int, asset string, confirmed bool) error {
+	decision, err := g.evaluate(walletID, from, to, amount, asset)
+	if err != nil {
+		return err
+	}
+
+	if !decision.Allowed() {
+		g.logDecision(audit.PolicyActionBlocked, walletID, from, to, amount, asset, decision, false)
+		return fmt.Errorf("spending policy: %w", decision.Err())
+	}
+	if decision.NeedsConfirmation() && !confirmed {
+		g.logDecision(audit.PolicyActionBlocked, walletID, from, to, amount, asset, decision, false)
+		return fmt.Errorf("spending policy: %s and needs a second confirmation", decision.ConfirmReason)
+	}
+
+	g.logDecision(audit.PolicyActionAllowed, walletID, from, to, amount, asset, decision, confirmed)
+	return nil
+}
+
+func (g *SpendingGuard) evaluate(walletID, from, to string, amount *big.Int, asset string) (*models.PolicyDecision, error) {
+	policy, err := g.storage.LoadSpendingPolicy(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load spending policy: %w", err)
+	}
+	if policy == nil || !policy.Enabled {
+		return &models.PolicyDecision{}, nil
+	}
+
+	now := g.now()
+	check := models.PaymentCheck{To: to, Amount: amount, Asset: asset, At: now}
+
+	contactsLocked := false
+	if policy.ContactsOnly || policy.ContactCooldownHours > 0 {
+		contacts, err := g.storage.LoadContacts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load contacts: %w", err)
+		}
+		check.Contact = contacts.FindByAddress(to)
+		contactsLocked = check.Contact == nil && contacts.Locked
+	}
+
+	if limits := policy.Limits(asset); limits != nil && (limits.Daily != nil || limits.Weekly != nil) {
+		check.SpentDay, check.SpentWeek, err = g.spent(walletID, asset, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	decision := policy.Evaluate(check)
+	if contactsLocked && policy.ContactsOnly {
+		decision.Violations = append(decision.Violations, "contacts are locked, so the recipient cannot be checked against them")
+	}
+	return decision, nil
+}
+
+// spent totals what the wallet's accounts sent of asset in the daily and
+// weekly windows before now. The limits are the wallet's, so every account
+// counts toward them, and each payment is counted once for the account that
+// sent it. Failed and reverted transactions moved nothing and are left out.
+func (g *SpendingGuard) spent(walletID, asset string, now time.Time) (day, week *big.Int, err error) {
+	history, err := g.storage.LoadTransactionHistory(walletID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load transaction history: %w", err)
+	}
+
+	day, week = new(big.Int), new(big.Int)
+	for _, tx := range history {
+		if tx.Direction != models.TransactionDirectionSent || tx.Amount == nil || !strings.EqualFold(tx.Asset, asset) {
+			continue
+		}
+		if tx.Status == models.TransactionStatusFailed || tx.Status == models.TransactionStatusReverted {
+			continue
+		}
+		age := now.Sub(tx.Timestamp)
+		if age < models.WeeklyWindow {
+			week.Add(week, tx.Amount)
+		}
+		if age < models.DailyWindow {
+			day.Add(day, tx.Amount)
+		}
+	}
+	return day, week, nil
+}
+
+func (g *SpendingGuard) logDecision(action audit.PolicyAction, walletID, from, to string, amount *big.Int, asset string, decision *models.PolicyDecision, confirmed bool) {
+	details := map[string]interface{}{
+		"from":  from,
+		"to":    to,
+		"asset": asset,
+	}
+	if amount != nil {
+		details["amount"] = amount.String()
+	}
+	if len(decision.Violations) > 0 {
+		details["violations"] = decision.Violations
+	}
+	if decision.ConfirmReason != "" {
+		details["confirmation"] = decision.ConfirmReason
+		details["confirmed"] = confirmed
+	}
+	g.log(action, walletID, details)
+}
+
+// log writes to the audit log. A failure to audit does not block payments.
+func (g *SpendingGuard) log(action audit.PolicyAction, walletID string, details map[string]interface{}) {
+	if g.auditor == nil {
+		return
+	}
+	_ = g.auditor.LogPolicy(action, walletID, details)
+}
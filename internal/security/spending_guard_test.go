@@ -0,0 +1,94 @@
+package security
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+func tokens(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e18))
+}
+
+func newTestGuard(t *testing.T) (*SpendingGuard, *storage.Storage) {
+	t.Helper()
+	store, err := storage.NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	return NewSpendingGuard(store, nil), store
+}
+
+func TestSpendingGuardWithoutPolicy(t *testing.T) {
+	guard, _ := newTestGuard(t)
+
+	policy, err := guard.Policy("wallet-a")
+	if err != nil {
+		t.Fatalf("Failed to load policy: %v", err)
+	}
+	if policy.Enabled || policy.ID != "wallet-a" {
+		t.Errorf("Expected a disabled default policy, got %+v", policy)
+	}
+	if err := guard.Check("wallet-a", "0xaaa", "0xabc", tokens(1000), "VET", false); err != nil {
+		t.Errorf("Expected payments to be allowed without a policy, got %v", err)
+	}
+}
+
+func TestSpendingGuardCountsHistory(t *testing.T) {
+	guard, store := newTestGuard(t)
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	guard.now = func() time.Time { return now }
+
+	policy := models.NewSpendingPolicy("wallet-a")
+	policy.Enabled = true
+	policy.VET.Daily = tokens(120)
+	policy.VET.ConfirmAbove = tokens(30)
+	if err := guard.UpdatePolicy(policy); err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+
+	history := []*models.Transaction{
+		{ID: "recent", From: "0xaaa", Amount: tokens(60), Asset: "VET", Direction: models.TransactionDirectionSent, Timestamp: now.Add(-time.Hour)},
+		{ID: "old", From: "0xaaa", Amount: tokens(60), Asset: "VET", Direction: models.TransactionDirectionSent, Timestamp: now.Add(-30 * time.Hour)},
+		{ID: "failed", From: "0xaaa", Amount: tokens(60), Asset: "VET", Direction: models.TransactionDirectionSent, Status: models.TransactionStatusFailed, Timestamp: now},
+		{ID: "other-account", From: "0xbbb", To: "0xabc", Amount: tokens(10), Asset: "VET", Direction: models.TransactionDirectionSent, Timestamp: now},
+		// Moving funds between the wallet's accounts is spent once, by the
+		// sender
+		{ID: "between-accounts", From: "0xbbb", To: "0xaaa", Amount: tokens(10), Asset: "VET", Direction: models.TransactionDirectionSent, Timestamp: now},
+		{ID: "received", To: "0xaaa", Amount: tokens(60), Asset: "VET", Direction: models.TransactionDirectionReceived, Timestamp: now},
+	}
+	for _, tx := range history {
+		if err := store.SaveTransaction("wallet-a", tx); err != nil {
+			t.Fatalf("Failed to save transaction: %v", err)
+		}
+	}
+
+	// 80 VET was spent today across the wallet's accounts
+	if err := guard.Check("wallet-a", "0xaaa", "0xabc", tokens(20), "VET", false); err != nil {
+		t.Errorf("Expected 20 VET to fit in the daily cap, got %v", err)
+	}
+	if err := guard.Check("wallet-a", "0xaaa", "0xabc", tokens(50), "VET", true); err == nil {
+		t.Error("Expected 50 VET to exceed the daily cap")
+	}
+	// Accounts derived from the same seed share the wallet's cap
+	if err := guard.Check("wallet-a", "0xccc", "0xabc", tokens(50), "VET", true); err == nil {
+		t.Error("Expected another account's payment to count toward the wallet's cap")
+	}
+
+	decision, err := guard.Evaluate("wallet-a", "0xaaa", "0xabc", tokens(35), "VET")
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if !decision.Allowed() || !decision.NeedsConfirmation() {
+		t.Errorf("Expected 35 VET to need confirmation, got %+v", decision)
+	}
+	if err := guard.Check("wallet-a", "0xaaa", "0xabc", tokens(35), "VET", false); err == nil {
+		t.Error("Expected an unconfirmed payment above the threshold to be refused")
+	}
+	if err := guard.Check("wallet-a", "0xaaa", "0xabc", tokens(35), "VET", true); err != nil {
+		t.Errorf("Expected a confirmed payment to be allowed, got %v", err)
+	}
+}
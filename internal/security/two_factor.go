@@ -0,0 +1,114 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+var (
+	// ErrSecondFactorRequired is returned when a wallet with a second factor
+	// is used without a code
+	ErrSecondFactorRequired = errors.New("authentication code required")
+	// ErrInvalidSecondFactor is returned for a wrong TOTP or recovery code
+	ErrInvalidSecondFactor = errors.New("invalid authentication code")
+	// ErrLockedOut is returned while a wallet is locked after too many
+	// failed attempts
+	ErrLockedOut = errors.New("too many failed attempts")
+)
+
+// CheckSecondFactor verifies code for an unlocked wallet. A current TOTP
+// code is accepted once, or else an unused recovery code, which is then
+// spent. Wallets without a second factor need no code.
+func CheckSecondFactor(store *storage.Storage, wallet *models.Wallet, code string) error {
+	if wallet.TwoFactor == nil {
+		return nil
+	}
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return ErrSecondFactorRequired
+	}
+	if wallet.TwoFactor.Verify(code, time.Now()) {
+		fresh, err := store.UseTOTPCode(wallet.ID, wallet.TwoFactor.LastCounter)
+		if err != nil {
+			return fmt.Errorf("failed to record authentication code: %w", err)
+		}
+		if !fresh {
+			return fmt.Errorf("%w: code already used", ErrInvalidSecondFactor)
+		}
+		return nil
+	}
+
+	// Anything longer than a TOTP code can only be a recovery code
+	if len(code) > models.TOTPDigits {
+		used, err := store.UseRecoveryCode(wallet.ID, code)
+		if err != nil {
+			return fmt.Errorf("failed to check recovery code: %w", err)
+		}
+		if used {
+			return nil
+		}
+	}
+	return ErrInvalidSecondFactor
+}
+
+// VerifySecondFactor checks code as CheckSecondFactor does. Wrong codes
+// count toward the wallet's lockout along with wrong passwords, and no code
+// is checked while the wallet is locked out. Wrong codes are also counted
+// with the stored wallet, so starting a new process does not reset them.
+func (sm *SecurityManager) VerifySecondFactor(store *storage.Storage, wallet *models.Wallet, code string) error {
+	if wallet.TwoFactor == nil {
+		return nil
+	}
+	failures, lockedUntil, err := store.SecondFactorAttempts(wallet.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check failed attempts: %w", err)
+	}
+	if sm.IsAccountLocked(wallet.ID) || time.Now().Before(lockedUntil) {
+		return sm.lockedOut(wallet.ID, lockedUntil)
+	}
+
+	err = CheckSecondFactor(store, wallet, code)
+	switch {
+	case err == nil:
+		sm.RecordSuccessfulAttempt(wallet.ID)
+		if failures > 0 {
+			if err := store.ClearSecondFactorFailures(wallet.ID); err != nil {
+				return fmt.Errorf("failed to clear failed attempts: %w", err)
+			}
+		}
+	case errors.Is(err, ErrInvalidSecondFactor):
+		sm.RecordFailedAttempt(wallet.ID)
+		_, lockedUntil, recordErr := store.RecordSecondFactorFailure(wallet.ID, sm.secondFactorLockout)
+		if recordErr != nil {
+			return fmt.Errorf("failed to record failed attempt: %w", recordErr)
+		}
+		if sm.IsAccountLocked(wallet.ID) || time.Now().Before(lockedUntil) {
+			return sm.lockedOut(wallet.ID, lockedUntil)
+		}
+	}
+	return err
+}
+
+// secondFactorLockout is how long failures wrong codes in a row lock the
+// wallet for, or zero while they are under the attempt limit
+func (sm *SecurityManager) secondFactorLockout(failures int) time.Duration {
+	sm.attemptTracker.mu.RLock()
+	maxAttempts := sm.attemptTracker.maxAttempts
+	sm.attemptTracker.mu.RUnlock()
+
+	if failures < maxAttempts {
+		return 0
+	}
+	return sm.calculateLockoutDuration(failures)
+}
+
+func (sm *SecurityManager) lockedOut(walletID string, lockedUntil time.Time) error {
+	remaining := max(sm.GetRemainingLockoutTime(walletID), time.Until(lockedUntil)).Round(time.Second)
+	return fmt.Errorf("%w; try again in %s", ErrLockedOut, remaining)
+}
@@ -0,0 +1,140 @@
+package security
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestVerifySecondFactor(t *testing.T) {
+	store, err := storage.NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, "correct-horse-battery"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	twoFactor, codes, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := store.SetTwoFactor(wallet.ID, "correct-horse-battery", twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+	wallet.TwoFactor = twoFactor
+
+	sessionManager := NewSessionManager(store)
+	defer sessionManager.Shutdown()
+	sm := NewSecurityManager(sessionManager)
+
+	if err := sm.VerifySecondFactor(store, wallet, ""); !errors.Is(err, ErrSecondFactorRequired) {
+		t.Errorf("Expected a missing code to be refused, got %v", err)
+	}
+
+	code, _ := twoFactor.Code(time.Now())
+	if err := sm.VerifySecondFactor(store, wallet, code); err != nil {
+		t.Errorf("Expected the current code to be accepted, got %v", err)
+	}
+	// The used code is recorded with the wallet, so another unlocked copy
+	// refuses it too
+	other, err := store.LoadWallet(wallet.ID, "correct-horse-battery")
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	other.TwoFactor.LastCounter = 0
+	if err := CheckSecondFactor(store, other, code); !errors.Is(err, ErrInvalidSecondFactor) {
+		t.Errorf("Expected a used code to be refused, got %v", err)
+	}
+	if err := sm.VerifySecondFactor(store, wallet, codes[0]); err != nil {
+		t.Errorf("Expected a recovery code to be accepted, got %v", err)
+	}
+	if err := sm.VerifySecondFactor(store, wallet, codes[0]); !errors.Is(err, ErrInvalidSecondFactor) {
+		t.Errorf("Expected a spent recovery code to be refused, got %v", err)
+	}
+	if sm.GetFailedAttemptCount(wallet.ID) != 1 {
+		t.Errorf("Expected the failed code to be counted, got %d", sm.GetFailedAttemptCount(wallet.ID))
+	}
+
+	// The default tracker locks after three failures
+	sm.VerifySecondFactor(store, wallet, "000000")
+	if err := sm.VerifySecondFactor(store, wallet, "000001"); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("Expected the wallet to lock out, got %v", err)
+	}
+	code, _ = twoFactor.Code(time.Now())
+	if err := sm.VerifySecondFactor(store, wallet, code); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("Expected codes to be refused while locked out, got %v", err)
+	}
+
+	// Wallets without a second factor need no code
+	if err := sm.VerifySecondFactor(store, &models.Wallet{ID: "other"}, ""); err != nil {
+		t.Errorf("Expected no code to be needed, got %v", err)
+	}
+}
+
+func TestSecondFactorLockoutOutlivesProcess(t *testing.T) {
+	store, err := storage.NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, "correct-horse-battery"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	twoFactor, _, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := store.SetTwoFactor(wallet.ID, "correct-horse-battery", twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+	wallet.TwoFactor = twoFactor
+
+	// Each command gets a fresh security manager, as a new process would
+	verify := func(code string) error {
+		sessionManager := NewSessionManager(store)
+		defer sessionManager.Shutdown()
+		return NewSecurityManager(sessionManager).VerifySecondFactor(store, wallet, code)
+	}
+
+	if err := verify("000000"); !errors.Is(err, ErrInvalidSecondFactor) {
+		t.Fatalf("Expected a wrong code to be refused, got %v", err)
+	}
+	// An accepted code clears the count
+	code, _ := twoFactor.Code(time.Now())
+	if err := verify(code); err != nil {
+		t.Fatalf("Expected the current code to be accepted, got %v", err)
+	}
+	if failures, _, err := store.SecondFactorAttempts(wallet.ID); err != nil || failures != 0 {
+		t.Errorf("Expected the failed attempts to be cleared, got %d (%v)", failures, err)
+	}
+
+	verify("000000")
+	verify("000001")
+	if err := verify("000002"); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("Expected the third wrong code to lock the wallet, got %v", err)
+	}
+	code, _ = twoFactor.Code(time.Now().Add(models.TOTPPeriod))
+	if err := verify(code); !errors.Is(err, ErrLockedOut) {
+		t.Errorf("Expected a new process to stay locked out, got %v", err)
+	}
+
+	// Re-saving the wallet keeps the lockout
+	if err := store.SaveWallet(wallet, "correct-horse-battery"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	if _, lockedUntil, err := store.SecondFactorAttempts(wallet.ID); err != nil || !lockedUntil.After(time.Now()) {
+		t.Errorf("Expected the lockout to survive a save, got %v (%v)", lockedUntil, err)
+	}
+}
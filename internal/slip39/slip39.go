@@ -0,0 +1,518 @@
+// Package slip39 implements SLIP-39 Shamir secret sharing: a secret is
+// encrypted and split into mnemonic shares, a threshold of which restore it.
+// https://github.com/satoshilabs/slips/blob/master/slip-0039.md
+package slip39
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	radixBits     = 10
+	radix         = 1 << radixBits
+	headerWords   = 4
+	checksumWords = 3
+
+	// MinSecretLength is the shortest secret that can be shared, in bytes
+	MinSecretLength = 16
+	// MaxShareCount is the most shares a secret can be split into
+	MaxShareCount = 16
+	// DefaultIterationExponent sets the PBKDF2 work of the encryption to
+	// 10000 << exponent iterations
+	DefaultIterationExponent = 1
+
+	digestLength       = 4
+	digestIndex        = 254
+	secretIndex        = 255
+	baseIterationCount = 10000
+	roundCount         = 4
+
+	minMnemonicWords = headerWords + (MinSecretLength*8+radixBits-1)/radixBits + checksumWords
+)
+
+var (
+	// ErrInvalidShare is returned for a mnemonic that is not a well formed
+	// share, or shares that do not belong together
+	ErrInvalidShare = errors.New("invalid SLIP-39 share")
+	// ErrNotEnoughShares is returned while more shares are needed
+	ErrNotEnoughShares = errors.New("not enough shares")
+)
+
+var (
+	customizationOriginal   = []byte("shamir")
+	customizationExtendable = []byte("shamir_extendable")
+)
+
+// Share is one decoded mnemonic share
+type Share struct {
+	Identifier        int
+	Extendable        bool
+	IterationExponent int
+	GroupIndex        int
+	GroupThreshold    int
+	GroupCount        int
+	MemberIndex       int
+	MemberThreshold   int
+	Value             []byte
+}
+
+// Split encrypts secret with passphrase and splits it into count shares in
+// a single group, any threshold of which restore it
+func Split(secret []byte, passphrase string, threshold, count int) ([]string, error) {
+	if len(secret) < MinSecretLength || len(secret)%2 != 0 {
+		return nil, fmt.Errorf("secret must be an even number of bytes, at least %d", MinSecretLength)
+	}
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if count < 1 || count > MaxShareCount {
+		return nil, fmt.Errorf("share count must be between 1 and %d", MaxShareCount)
+	}
+	if threshold < 1 || threshold > count {
+		return nil, fmt.Errorf("threshold must be between 1 and the share count (%d)", count)
+	}
+	if threshold == 1 && count > 1 {
+		return nil, fmt.Errorf("a threshold of 1 would make every share a full copy; use a single share instead")
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate identifier: %w", err)
+	}
+	identifier := int(binary.BigEndian.Uint16(id[:]) >> 1)
+
+	encrypted, err := encrypt(secret, []byte(passphrase), DefaultIterationExponent, identifier, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// One group with a threshold of one holds the encrypted secret itself
+	members, err := splitSecret(threshold, count, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	mnemonics := make([]string, len(members))
+	for i, member := range members {
+		mnemonics[i] = Share{
+			Identifier:        identifier,
+			Extendable:        true,
+			IterationExponent: DefaultIterationExponent,
+			GroupIndex:        0,
+			GroupThreshold:    1,
+			GroupCount:        1,
+			MemberIndex:       member.x,
+			MemberThreshold:   threshold,
+			Value:             member.value,
+		}.Mnemonic()
+	}
+	return mnemonics, nil
+}
+
+// Combine restores the secret from mnemonic shares and the passphrase they
+// were created with. It returns an error wrapping ErrNotEnoughShares until
+// enough shares are given.
+func Combine(mnemonics []string, passphrase string) ([]byte, error) {
+	if len(mnemonics) == 0 {
+		return nil, fmt.Errorf("%w: no shares given", ErrNotEnoughShares)
+	}
+	if err := checkPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+
+	type group struct {
+		threshold int
+		members   []point
+	}
+
+	var (
+		first  Share
+		groups = make(map[int]*group)
+		order  []int
+	)
+	for i, mnemonic := range mnemonics {
+		share, err := ParseShare(mnemonic)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i+1, err)
+		}
+		if i == 0 {
+			first = share
+		} else if share.Identifier != first.Identifier || share.Extendable != first.Extendable ||
+			share.IterationExponent != first.IterationExponent || share.GroupThreshold != first.GroupThreshold ||
+			share.GroupCount != first.GroupCount || len(share.Value) != len(first.Value) {
+			return nil, fmt.Errorf("%w: share %d is from a different set of shares", ErrInvalidShare, i+1)
+		}
+
+		g, ok := groups[share.GroupIndex]
+		if !ok {
+			g = &group{threshold: share.MemberThreshold}
+			groups[share.GroupIndex] = g
+			order = append(order, share.GroupIndex)
+		}
+		if share.MemberThreshold != g.threshold {
+			return nil, fmt.Errorf("%w: share %d has a different member threshold from its group", ErrInvalidShare, i+1)
+		}
+
+		duplicate := false
+		for _, member := range g.members {
+			if member.x != share.MemberIndex {
+				continue
+			}
+			if !hmac.Equal(member.value, share.Value) {
+				return nil, fmt.Errorf("%w: share %d reuses member index %d", ErrInvalidShare, i+1, share.MemberIndex+1)
+			}
+			duplicate = true
+		}
+		if !duplicate {
+			g.members = append(g.members, point{x: share.MemberIndex, value: share.Value})
+		}
+	}
+
+	var recovered []point
+	for _, index := range order {
+		g := groups[index]
+		if len(g.members) < g.threshold {
+			continue
+		}
+		value, err := recoverSecret(g.threshold, g.members[:g.threshold])
+		if err != nil {
+			return nil, err
+		}
+		recovered = append(recovered, point{x: index, value: value})
+	}
+
+	if len(recovered) < first.GroupThreshold {
+		if first.GroupCount == 1 {
+			g := groups[0]
+			return nil, fmt.Errorf("%w: %d of %d shares entered", ErrNotEnoughShares, len(g.members), g.threshold)
+		}
+		return nil, fmt.Errorf("%w: %d of %d groups complete", ErrNotEnoughShares, len(recovered), first.GroupThreshold)
+	}
+
+	encrypted, err := recoverSecret(first.GroupThreshold, recovered[:first.GroupThreshold])
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(encrypted, []byte(passphrase), first.IterationExponent, first.Identifier, first.Extendable)
+}
+
+// ParseShare decodes a mnemonic share and verifies its checksum
+func ParseShare(mnemonic string) (Share, error) {
+	words := strings.Fields(strings.ToLower(mnemonic))
+	if len(words) < minMnemonicWords {
+		return Share{}, fmt.Errorf("%w: a share has at least %d words, got %d", ErrInvalidShare, minMnemonicWords, len(words))
+	}
+
+	paddingBits := radixBits * (len(words) - headerWords - checksumWords) % 16
+	if paddingBits > 8 {
+		return Share{}, fmt.Errorf("%w: %d words is not a valid share length", ErrInvalidShare, len(words))
+	}
+
+	values := make([]int, len(words))
+	for i, word := range words {
+		value, ok := wordIndex[word]
+		if !ok {
+			return Share{}, fmt.Errorf("%w: word %d (%q) is not in the SLIP-39 wordlist", ErrInvalidShare, i+1, word)
+		}
+		values[i] = value
+	}
+
+	idExp := values[0]<<radixBits | values[1]
+	share := Share{
+		Identifier:        idExp >> 5,
+		Extendable:        idExp>>4&1 == 1,
+		IterationExponent: idExp & 0xf,
+	}
+	if !verifyChecksum(values, share.Extendable) {
+		return Share{}, fmt.Errorf("%w: checksum verification failed", ErrInvalidShare)
+	}
+
+	params := values[2]<<radixBits | values[3]
+	share.GroupIndex = params >> 16
+	share.GroupThreshold = params>>12&0xf + 1
+	share.GroupCount = params>>8&0xf + 1
+	share.MemberIndex = params >> 4 & 0xf
+	share.MemberThreshold = params&0xf + 1
+	if share.GroupThreshold > share.GroupCount {
+		return Share{}, fmt.Errorf("%w: group threshold exceeds the group count", ErrInvalidShare)
+	}
+
+	value, err := wordsToBytes(values[headerWords:len(values)-checksumWords], paddingBits)
+	if err != nil {
+		return Share{}, err
+	}
+	share.Value = value
+
+	return share, nil
+}
+
+// Mnemonic encodes the share as words with a checksum
+func (s Share) Mnemonic() string {
+	extendable := 0
+	if s.Extendable {
+		extendable = 1
+	}
+	idExp := s.Identifier<<5 | extendable<<4 | s.IterationExponent
+	params := s.GroupIndex<<16 | (s.GroupThreshold-1)<<12 | (s.GroupCount-1)<<8 | s.MemberIndex<<4 | (s.MemberThreshold - 1)
+
+	values := []int{idExp >> radixBits, idExp % radix, params >> radixBits, params % radix}
+	values = append(values, bytesToWords(s.Value)...)
+	values = append(values, createChecksum(values, s.Extendable)...)
+
+	words := make([]string, len(values))
+	for i, value := range values {
+		words[i] = wordlist[value]
+	}
+	return strings.Join(words, " ")
+}
+
+func checkPassphrase(passphrase string) error {
+	for _, r := range passphrase {
+		if r < 32 || r > 126 {
+			return fmt.Errorf("passphrase must contain only printable ASCII characters")
+		}
+	}
+	return nil
+}
+
+// wordsToBytes unpacks 10-bit word values into bytes, dropping the leading
+// padding bits, which must be zero
+func wordsToBytes(values []int, paddingBits int) ([]byte, error) {
+	n := new(big.Int)
+	for _, value := range values {
+		n.Lsh(n, radixBits)
+		n.Or(n, big.NewInt(int64(value)))
+	}
+
+	valueBits := radixBits*len(values) - paddingBits
+	if n.BitLen() > valueBits {
+		return nil, fmt.Errorf("%w: invalid padding", ErrInvalidShare)
+	}
+	return n.FillBytes(make([]byte, valueBits/8)), nil
+}
+
+func bytesToWords(data []byte) []int {
+	count := (len(data)*8 + radixBits - 1) / radixBits
+	n := new(big.Int).SetBytes(data)
+	mask := big.NewInt(radix - 1)
+
+	values := make([]int, count)
+	for i := count - 1; i >= 0; i-- {
+		values[i] = int(new(big.Int).And(n, mask).Int64())
+		n.Rsh(n, radixBits)
+	}
+	return values
+}
+
+func customization(extendable bool) []byte {
+	if extendable {
+		return customizationExtendable
+	}
+	return customizationOriginal
+}
+
+// polymod is the RS1024 checksum over GF(1024)
+func polymod(values []int) int {
+	generator := [10]int{
+		0xe0e040, 0x1c1c080, 0x3838100, 0x7070200, 0xe0e0009,
+		0x1c0c2412, 0x38086c24, 0x3090fc48, 0x21b1f890, 0x3f3f120,
+	}
+
+	chk := 1
+	for _, v := range values {
+		b := chk >> 20
+		chk = (chk&0xfffff)<<10 ^ v
+		for i := 0; i < 10; i++ {
+			if (b>>i)&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func checksumInput(data []int, extendable bool) []int {
+	prefix := customization(extendable)
+	values := make([]int, 0, len(prefix)+len(data)+checksumWords)
+	for _, b := range prefix {
+		values = append(values, int(b))
+	}
+	return append(values, data...)
+}
+
+func createChecksum(data []int, extendable bool) []int {
+	values := append(checksumInput(data, extendable), make([]int, checksumWords)...)
+	chk := polymod(values) ^ 1
+
+	checksum := make([]int, checksumWords)
+	for i := range checksum {
+		checksum[i] = chk >> (radixBits * (checksumWords - 1 - i)) % radix
+	}
+	return checksum
+}
+
+func verifyChecksum(values []int, extendable bool) bool {
+	return polymod(checksumInput(values, extendable)) == 1
+}
+
+// Encryption is a four round Feistel network keyed with PBKDF2 of the
+// passphrase
+
+func salt(identifier int, extendable bool) []byte {
+	if extendable {
+		return nil
+	}
+	s := append([]byte{}, customizationOriginal...)
+	return append(s, byte(identifier>>8), byte(identifier))
+}
+
+func roundFunction(round int, passphrase []byte, exponent int, salt, r []byte) []byte {
+	password := append([]byte{byte(round)}, passphrase...)
+	roundSalt := append(append([]byte{}, salt...), r...)
+	return pbkdf2.Key(password, roundSalt, (baseIterationCount<<exponent)/roundCount, len(r), sha256.New)
+}
+
+func encrypt(secret, passphrase []byte, exponent, identifier int, extendable bool) ([]byte, error) {
+	return feistel(secret, passphrase, exponent, salt(identifier, extendable), []int{0, 1, 2, 3})
+}
+
+func decrypt(encrypted, passphrase []byte, exponent, identifier int, extendable bool) ([]byte, error) {
+	return feistel(encrypted, passphrase, exponent, salt(identifier, extendable), []int{3, 2, 1, 0})
+}
+
+func feistel(data, passphrase []byte, exponent int, salt []byte, rounds []int) ([]byte, error) {
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("%w: secret length must be even", ErrInvalidShare)
+	}
+	half := len(data) / 2
+	l := append([]byte{}, data[:half]...)
+	r := append([]byte{}, data[half:]...)
+
+	for _, round := range rounds {
+		f := roundFunction(round, passphrase, exponent, salt, r)
+		for i := range l {
+			l[i] ^= f[i]
+		}
+		l, r = r, l
+	}
+	return append(r, l...), nil
+}
+
+// Shamir sharing over GF(256) with the Rijndael polynomial
+
+type point struct {
+	x     int
+	value []byte
+}
+
+var expTable, logTable [256]int
+
+func init() {
+	poly := 1
+	for i := 0; i < 255; i++ {
+		expTable[i] = poly
+		logTable[poly] = i
+		// Multiply by the generator 3
+		poly = poly<<1 ^ poly
+		if poly&0x100 != 0 {
+			poly ^= 0x11b
+		}
+	}
+}
+
+// interpolate evaluates at x the polynomial through points
+func interpolate(points []point, x int) []byte {
+	for _, p := range points {
+		if p.x == x {
+			return append([]byte{}, p.value...)
+		}
+	}
+
+	logProduct := 0
+	for _, p := range points {
+		logProduct += logTable[p.x^x]
+	}
+
+	result := make([]byte, len(points[0].value))
+	for _, p := range points {
+		logBasis := logProduct - logTable[p.x^x]
+		for _, other := range points {
+			if other.x != p.x {
+				logBasis -= logTable[p.x^other.x]
+			}
+		}
+		logBasis = (logBasis%255 + 255) % 255
+
+		for i, v := range p.value {
+			if v != 0 {
+				result[i] ^= byte(expTable[(logTable[v]+logBasis)%255])
+			}
+		}
+	}
+	return result
+}
+
+func createDigest(random, secret []byte) []byte {
+	mac := hmac.New(sha256.New, random)
+	mac.Write(secret)
+	return mac.Sum(nil)[:digestLength]
+}
+
+// splitSecret shares secret among count points. Beyond threshold-2 random
+// points the polynomial is fixed by the secret at 255 and a digest of it at
+// 254, so a wrong combination of shares is detected on recovery.
+func splitSecret(threshold, count int, secret []byte) ([]point, error) {
+	if threshold == 1 {
+		points := make([]point, count)
+		for i := range points {
+			points[i] = point{x: i, value: append([]byte{}, secret...)}
+		}
+		return points, nil
+	}
+
+	randomCount := threshold - 2
+	points := make([]point, 0, count)
+	for i := 0; i < randomCount; i++ {
+		value := make([]byte, len(secret))
+		if _, err := rand.Read(value); err != nil {
+			return nil, fmt.Errorf("failed to generate share: %w", err)
+		}
+		points = append(points, point{x: i, value: value})
+	}
+
+	random := make([]byte, len(secret)-digestLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate share: %w", err)
+	}
+	base := append(append([]point{}, points...),
+		point{x: digestIndex, value: append(createDigest(random, secret), random...)},
+		point{x: secretIndex, value: secret},
+	)
+
+	for i := randomCount; i < count; i++ {
+		points = append(points, point{x: i, value: interpolate(base, i)})
+	}
+	return points, nil
+}
+
+func recoverSecret(threshold int, points []point) ([]byte, error) {
+	if threshold == 1 {
+		return points[0].value, nil
+	}
+
+	secret := interpolate(points, secretIndex)
+	digest := interpolate(points, digestIndex)
+	if !hmac.Equal(digest[:digestLength], createDigest(digest[digestLength:], secret)) {
+		return nil, fmt.Errorf("%w: the shares do not combine to a valid secret", ErrInvalidShare)
+	}
+	return secret, nil
+}
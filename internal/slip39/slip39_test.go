@@ -0,0 +1,128 @@
+package slip39
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// Vectors from the SLIP-39 specification, all with the passphrase "TREZOR"
+// https://github.com/trezor/python-shamir-mnemonic/blob/master/vectors.json
+func TestCombineVectors(t *testing.T) {
+	tests := []struct {
+		name      string
+		mnemonics []string
+		secret    string
+	}{
+		{
+			name:      "valid mnemonic without sharing (128 bits)",
+			mnemonics: []string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision keyboard"},
+			secret:    "bb54aac4b89dc868ba37d9cc21b2cece",
+		},
+		{
+			name:      "mnemonic with invalid checksum (128 bits)",
+			mnemonics: []string{"duckling enlarge academic academic agency result length solution fridge kidney coal piece deal husband erode duke ajar critical decision kidney"},
+		},
+		{
+			name: "basic sharing 2-of-3 (128 bits)",
+			mnemonics: []string{
+				"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed",
+				"shadow pistol academic acid actress prayer class unknown daughter sweater depict flip twice unkind craft early superior advocate guest smoking",
+			},
+			secret: "b43ceb7e57a0ea8766221624d01b0864",
+		},
+		{
+			name:      "basic sharing 2-of-3, one share (128 bits)",
+			mnemonics: []string{"shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed"},
+		},
+		{
+			name:      "valid mnemonic without sharing (256 bits)",
+			mnemonics: []string{"theory painting academic academic armed sweater year military elder discuss acne wildlife boring employer fused large satoshi bundle carbon diagnose anatomy hamster leaves tracks paces beyond phantom capital marvel lips brave detect luck"},
+			secret:    "989baf9dcaad5b10ca33dfd8cc75e42477025dce88ae83e75a230086a0e00e92",
+		},
+	}
+
+	for _, tt := range tests {
+		secret, err := Combine(tt.mnemonics, "TREZOR")
+		if tt.secret == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %x", tt.name, secret)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got := hex.EncodeToString(secret); got != tt.secret {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.secret, got)
+		}
+	}
+}
+
+func TestSplitAndCombine(t *testing.T) {
+	secret, _ := hex.DecodeString("0c94b3e5a8f9a6d1c2e3b4a5968778695a4b3c2d1e0f00112233445566778899")
+
+	shares, err := Split(secret, "", 3, 5)
+	if err != nil {
+		t.Fatalf("Failed to split secret: %v", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("Expected 5 shares, got %d", len(shares))
+	}
+
+	// Any three shares restore the secret
+	for _, picked := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}} {
+		var subset []string
+		for _, i := range picked {
+			subset = append(subset, shares[i])
+		}
+		restored, err := Combine(subset, "")
+		if err != nil {
+			t.Fatalf("Failed to combine shares %v: %v", picked, err)
+		}
+		if !bytes.Equal(restored, secret) {
+			t.Errorf("Shares %v restored %x", picked, restored)
+		}
+	}
+
+	if _, err := Combine(shares[:2], ""); !errors.Is(err, ErrNotEnoughShares) {
+		t.Errorf("Expected ErrNotEnoughShares for two shares, got %v", err)
+	}
+
+	// Shares from another split do not mix
+	other, _ := Split(secret, "", 3, 5)
+	if _, err := Combine([]string{shares[0], shares[1], other[2]}, ""); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for mixed sets, got %v", err)
+	}
+
+	if _, err := Split(secret, "", 1, 3); err == nil {
+		t.Error("Expected error for a threshold of 1 with several shares")
+	}
+	if _, err := Split(secret[:15], "", 2, 3); err == nil {
+		t.Error("Expected error for a short secret")
+	}
+}
+
+func TestParseShare(t *testing.T) {
+	mnemonic := "shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding armed"
+
+	share, err := ParseShare(mnemonic)
+	if err != nil {
+		t.Fatalf("Failed to parse share: %v", err)
+	}
+	if share.MemberThreshold != 2 || share.GroupThreshold != 1 || len(share.Value) != 16 {
+		t.Errorf("Unexpected share: %+v", share)
+	}
+	if got := share.Mnemonic(); got != mnemonic {
+		t.Errorf("Share did not round trip:\n%s\n%s", got, mnemonic)
+	}
+
+	if _, err := ParseShare("shadow pistol academic always"); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for a short mnemonic, got %v", err)
+	}
+	if _, err := ParseShare("shadow pistol academic always adequate wildlife fancy gross oasis cylinder mustang wrist rescue view short owner flip making coding abandon"); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for an unknown word, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const (
+	ageHeader      = "age-encryption.org/v1\n"
+	ageArmorHeader = "-----BEGIN AGE ENCRYPTED FILE-----"
+)
+
+// AgeOptions encrypts an export with age, either to X25519 recipients
+// (age1... public keys) so teammates can open it with their own keys, or
+// with a passphrase. age does not allow both in one file.
+type AgeOptions struct {
+	Recipients []string
+	Passphrase string
+}
+
+// Enabled reports whether any recipient or passphrase was given
+func (o AgeOptions) Enabled() bool {
+	return len(o.Recipients) > 0 || o.Passphrase != ""
+}
+
+func (o AgeOptions) recipients() ([]age.Recipient, error) {
+	if len(o.Recipients) > 0 && o.Passphrase != "" {
+		return nil, fmt.Errorf("encrypt to recipients or with a passphrase, not both")
+	}
+	if o.Passphrase != "" {
+		recipient, err := age.NewScryptRecipient(o.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	}
+
+	var recipients []age.Recipient
+	for _, key := range o.Recipients {
+		recipient, err := age.ParseX25519Recipient(strings.TrimSpace(key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", key, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no age recipients or passphrase given")
+	}
+	return recipients, nil
+}
+
+// EncryptAge encrypts data to the recipients or passphrase in opts
+func EncryptAge(data []byte, opts AgeOptions) ([]byte, error) {
+	recipients, err := opts.recipients()
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	writer, err := age.Encrypt(&out, recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encrypt with age: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// IsAgeEncrypted reports whether data is an age file, binary or armored
+func IsAgeEncrypted(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return bytes.HasPrefix(data, []byte(ageHeader)) || bytes.HasPrefix(trimmed, []byte(ageArmorHeader))
+}
+
+// AgeKeys are what an age file may be decrypted with: the X25519 identities
+// in an identity file (as written by age-keygen) and a passphrase
+type AgeKeys struct {
+	IdentityFile string
+	Passphrase   string
+}
+
+func (k AgeKeys) identities() ([]age.Identity, error) {
+	var identities []age.Identity
+	if k.IdentityFile != "" {
+		file, err := os.Open(expandPath(k.IdentityFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open age identity file: %w", err)
+		}
+		defer file.Close()
+
+		parsed, err := age.ParseIdentities(bufio.NewReader(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age identity file: %w", err)
+		}
+		identities = append(identities, parsed...)
+	}
+	if k.Passphrase != "" {
+		identity, err := age.NewScryptIdentity(k.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("file is age encrypted; give an identity file or passphrase")
+	}
+	return identities, nil
+}
+
+// DecryptAge decrypts an age file. When none of keys can open it the error
+// wraps ErrInvalidPassword.
+func DecryptAge(data []byte, keys AgeKeys) ([]byte, error) {
+	identities, err := keys.identities()
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if !bytes.HasPrefix(data, []byte(ageHeader)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimLeft(data, " \t\r\n")))
+	}
+
+	reader, err := age.Decrypt(src, identities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return nil, fmt.Errorf("%w: no matching age identity or passphrase", ErrInvalidPassword)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age file: %w", err)
+	}
+
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt age file: %w", err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+)
+
+func TestAgeRecipients(t *testing.T) {
+	alice, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+	bob, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+	mallory, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+
+	plaintext := []byte(`{"contacts":[]}`)
+	data, err := EncryptAge(plaintext, AgeOptions{Recipients: []string{alice.Recipient().String(), bob.Recipient().String()}})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if !IsAgeEncrypted(data) {
+		t.Fatal("Expected output to be detected as age")
+	}
+	if IsAgeEncrypted(plaintext) {
+		t.Fatal("Expected plaintext not to be detected as age")
+	}
+
+	dir := t.TempDir()
+	for _, identity := range []*age.X25519Identity{alice, bob} {
+		file := filepath.Join(dir, "key.txt")
+		if err := os.WriteFile(file, []byte(identity.String()+"\n"), 0600); err != nil {
+			t.Fatalf("Failed to write identity: %v", err)
+		}
+		got, err := DecryptAge(data, AgeKeys{IdentityFile: file})
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("Expected %q, got %q", plaintext, got)
+		}
+	}
+
+	file := filepath.Join(dir, "mallory.txt")
+	if err := os.WriteFile(file, []byte(mallory.String()+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write identity: %v", err)
+	}
+	if _, err := DecryptAge(data, AgeKeys{IdentityFile: file}); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Expected ErrInvalidPassword for a foreign identity, got %v", err)
+	}
+
+	if _, err := EncryptAge(plaintext, AgeOptions{Recipients: []string{"not-a-key"}}); err == nil {
+		t.Error("Expected an invalid recipient to be rejected")
+	}
+	if _, err := EncryptAge(plaintext, AgeOptions{Recipients: []string{alice.Recipient().String()}, Passphrase: "pw"}); err == nil {
+		t.Error("Expected recipients and a passphrase together to be rejected")
+	}
+}
+
+func TestAgeBackupPassphrase(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	config := DefaultConfig()
+	config.Network = "testnet"
+	if err := store.SaveConfig(config); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	path, err := store.CreateAgeBackup("", AgeOptions{Passphrase: backupPassword})
+	if err != nil {
+		t.Fatalf("Failed to create age backup: %v", err)
+	}
+	if filepath.Ext(path) != ".age" {
+		t.Errorf("Expected an .age file, got %s", path)
+	}
+
+	if _, err := OpenBackup(path, "wrong-password"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("Expected ErrInvalidPassword, got %v", err)
+	}
+	backup, err := OpenBackup(path, backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to open age backup: %v", err)
+	}
+	if backup.Profile != store.Profile() {
+		t.Errorf("Expected profile %s, got %s", store.Profile(), backup.Profile)
+	}
+	if len(backup.Files()) != 1 {
+		t.Errorf("Expected only the config file, got %v", backup.Files())
+	}
+}
@@ -0,0 +1,934 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const (
+	backupFormat = "veterm-backup"
+	auditDir     = "audit"
+)
+
+// BackupVersion is the backup file format written by this build
+const BackupVersion = 1
+
+// ErrBackupCorrupt is returned when a backup fails its integrity checks
+var ErrBackupCorrupt = errors.New("backup file is corrupt or incomplete")
+
+// ErrRestoreNeedsSecondFactor is returned when a restore would change the
+// spending policy or second factor of a wallet with one, and no code was
+// checked for it
+var ErrRestoreNeedsSecondFactor = errors.New("restore changes a wallet's spending policy or second factor; its authentication code is required")
+
+// BackupSection is a part of a profile's data that can be restored on its own
+type BackupSection string
+
+const (
+	SectionWallets   BackupSection = "wallets"
+	SectionContacts  BackupSection = "contacts"
+	SectionHistory   BackupSection = "history"
+	SectionTemplates BackupSection = "templates"
+	SectionSchedules BackupSection = "schedules"
+	SectionPolicies  BackupSection = "policies"
+	SectionConfig    BackupSection = "config"
+	SectionAudit     BackupSection = "audit"
+)
+
+// BackupSections lists every section in the order restores apply them
+func BackupSections() []BackupSection {
+	return []BackupSection{SectionWallets, SectionContacts, SectionHistory, SectionTemplates, SectionSchedules, SectionPolicies, SectionConfig, SectionAudit}
+}
+
+// ParseBackupSections reads a comma-separated list of section names. An
+// empty list selects every section.
+func ParseBackupSections(list string) ([]BackupSection, error) {
+	if strings.TrimSpace(list) == "" {
+		return BackupSections(), nil
+	}
+
+	var sections []BackupSection
+	for _, name := range strings.Split(list, ",") {
+		section := BackupSection(strings.ToLower(strings.TrimSpace(name)))
+		known := false
+		for _, candidate := range BackupSections() {
+			if section == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown backup section %q (choose from wallets, contacts, history, templates, schedules, policies, config, audit)", name)
+		}
+		sections = append(sections, section)
+	}
+	return sections, nil
+}
+
+// backupHeader is the unencrypted part of a backup file. Checksum covers the
+// envelope so corruption is told apart from a wrong password.
+type backupHeader struct {
+	Format   string         `json:"format"`
+	Version  int            `json:"version"`
+	Checksum string         `json:"checksum"`
+	Envelope *EncryptedData `json:"envelope"`
+}
+
+// backupPayload is the gzipped JSON sealed inside the envelope
+type backupPayload struct {
+	Profile   string        `json:"profile"`
+	CreatedAt time.Time     `json:"created_at"`
+	Files     []backupEntry `json:"files"`
+
+	// ProfileKey opens the backed-up contacts. It is only known, and only
+	// included, when a wallet was unlocked at backup time.
+	ProfileKey []byte `json:"profile_key,omitempty"`
+}
+
+type backupEntry struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256"`
+	Data   []byte `json:"data"`
+}
+
+// Backup is a decrypted and verified backup, ready to preview or restore.
+// Versioned files have already been migrated to this build's schema.
+type Backup struct {
+	Profile   string
+	CreatedAt time.Time
+	files     map[string][]byte
+
+	profileKey []byte
+}
+
+// Files returns the names of the files held in the backup
+func (b *Backup) Files() []string {
+	names := make([]string, 0, len(b.files))
+	for name := range b.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultBackupPath names a backup in the profile's exports directory
+func (s *Storage) DefaultBackupPath() string {
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05Z")
+	return filepath.Join(s.dataDir, exportsDir, fmt.Sprintf("veterm-backup--%s--%s.vtbak", timestamp, s.profile))
+}
+
+// CreateBackup writes every wallet, contact, transaction record, the config
+// file and audit logs to a single archive encrypted under password. A blank
+// path uses DefaultBackupPath; existing files are never overwritten. Wallets
+// stay sealed with their own passwords inside the archive.
+func (s *Storage) CreateBackup(path, password string) (string, error) {
+	if password == "" {
+		return "", fmt.Errorf("backup password is required")
+	}
+
+	compressed, err := s.backupArchive()
+	if err != nil {
+		return "", err
+	}
+	envelope, err := EncryptWithKDF(compressed, password, s.kdf)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt backup: %w", err)
+	}
+	data, err := json.MarshalIndent(backupHeader{
+		Format:   backupFormat,
+		Version:  BackupVersion,
+		Checksum: envelopeChecksum(envelope),
+		Envelope: envelope,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal backup: %w", err)
+	}
+
+	if strings.TrimSpace(path) == "" {
+		path = s.DefaultBackupPath()
+	}
+	return s.writeBackup(path, data)
+}
+
+// CreateAgeBackup writes the same archive as CreateBackup encrypted with
+// age instead, so it can be opened by the recipients' keys or the age CLI.
+// A blank path uses DefaultBackupPath with an .age suffix.
+func (s *Storage) CreateAgeBackup(path string, opts AgeOptions) (string, error) {
+	compressed, err := s.backupArchive()
+	if err != nil {
+		return "", err
+	}
+	data, err := EncryptAge(compressed, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(path) == "" {
+		path = s.DefaultBackupPath() + ".age"
+	}
+	return s.writeBackup(path, data)
+}
+
+func (s *Storage) writeBackup(path string, data []byte) (string, error) {
+	path = expandPath(path)
+	if err := writeExportFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write backup: %w", err)
+	}
+	return path, nil
+}
+
+// backupArchive collects the profile's files into the gzipped payload that
+// backups encrypt
+func (s *Storage) backupArchive() ([]byte, error) {
+	payload := backupPayload{Profile: s.profile, CreatedAt: time.Now().UTC(), ProfileKey: s.contactKey()}
+	for _, name := range []string{walletsFile, contactsFile, historyFile, templatesFile, schedulesFile, policiesFile, configFile} {
+		data, err := s.readVersionedFile(name)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			payload.Files = append(payload.Files, newBackupEntry(name, data))
+		}
+	}
+
+	auditRoot := s.AuditLogDir()
+	err := filepath.WalkDir(auditRoot, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(auditRoot, filePath)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		payload.Files = append(payload.Files, newBackupEntry(auditDir+"/"+filepath.ToSlash(rel), data))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit logs: %w", err)
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if err := json.NewEncoder(writer).Encode(payload); err != nil {
+		return nil, fmt.Errorf("failed to encode backup: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress backup: %w", err)
+	}
+	return compressed.Bytes(), nil
+}
+
+func newBackupEntry(name string, data []byte) backupEntry {
+	sum := sha256.Sum256(data)
+	return backupEntry{Name: name, SHA256: hex.EncodeToString(sum[:]), Data: data}
+}
+
+func envelopeChecksum(envelope *EncryptedData) string {
+	hash := sha256.New()
+	hash.Write(envelope.Salt)
+	hash.Write(envelope.Nonce)
+	hash.Write(envelope.Ciphertext)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// OpenBackup reads and decrypts a backup, verifying the archive checksum and
+// every file in it. A wrong password returns an error wrapping
+// ErrInvalidPassword; damage returns one wrapping ErrBackupCorrupt.
+func OpenBackup(path, password string) (*Backup, error) {
+	return OpenBackupWith(path, AgeKeys{Passphrase: password})
+}
+
+// OpenBackupWith opens a backup as OpenBackup does. Backups written with
+// CreateAgeBackup are decrypted with keys; others use keys.Passphrase as
+// the backup password.
+func OpenBackupWith(path string, keys AgeKeys) (*Backup, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	if IsAgeEncrypted(data) {
+		compressed, err := DecryptAge(data, keys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt backup: %w", err)
+		}
+		return readBackupArchive(compressed)
+	}
+
+	var header backupHeader
+	if err := json.Unmarshal(data, &header); err != nil || header.Format != backupFormat {
+		return nil, fmt.Errorf("%s is not a veterm backup", path)
+	}
+	if header.Version > BackupVersion {
+		return nil, fmt.Errorf("backup is version %d but this build supports up to %d: %w", header.Version, BackupVersion, ErrNewerVersion)
+	}
+	if header.Envelope == nil || envelopeChecksum(header.Envelope) != header.Checksum {
+		return nil, fmt.Errorf("%w: archive checksum does not match", ErrBackupCorrupt)
+	}
+
+	compressed, err := Decrypt(header.Envelope, keys.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt backup: %w", err)
+	}
+	return readBackupArchive(compressed)
+}
+
+// readBackupArchive unpacks a decrypted payload and checks every file in it
+func readBackupArchive(compressed []byte) (*Backup, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupt, err)
+	}
+	plaintext, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupt, err)
+	}
+
+	var payload backupPayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBackupCorrupt, err)
+	}
+
+	backup := &Backup{Profile: payload.Profile, CreatedAt: payload.CreatedAt, files: make(map[string][]byte), profileKey: payload.ProfileKey}
+	for _, entry := range payload.Files {
+		if sectionOf(entry.Name) == "" {
+			return nil, fmt.Errorf("%w: unexpected file %q", ErrBackupCorrupt, entry.Name)
+		}
+		if newBackupEntry(entry.Name, entry.Data).SHA256 != entry.SHA256 {
+			return nil, fmt.Errorf("%w: checksum mismatch for %s", ErrBackupCorrupt, entry.Name)
+		}
+		upgraded, err := upgradeData(entry.Name, entry.Data)
+		if err != nil {
+			return nil, err
+		}
+		backup.files[entry.Name] = upgraded
+	}
+	return backup, nil
+}
+
+// sectionOf maps a file in a backup to its section, or "" for names a
+// backup may not contain
+func sectionOf(name string) BackupSection {
+	switch name {
+	case walletsFile:
+		return SectionWallets
+	case contactsFile:
+		return SectionContacts
+	case historyFile:
+		return SectionHistory
+	case templatesFile:
+		return SectionTemplates
+	case schedulesFile:
+		return SectionSchedules
+	case policiesFile:
+		return SectionPolicies
+	case configFile:
+		return SectionConfig
+	}
+	rel, ok := strings.CutPrefix(name, auditDir+"/")
+	if ok && rel != "" && path.Clean(name) == name && !strings.HasPrefix(rel, "../") {
+		return SectionAudit
+	}
+	return ""
+}
+
+// RestoreAction is what a restore does with one item from a backup
+type RestoreAction string
+
+const (
+	RestoreAdd       RestoreAction = "add"
+	RestoreOverwrite RestoreAction = "overwrite"
+	RestoreSkip      RestoreAction = "skip"
+)
+
+// RestoreItem is one wallet, contact, history, template set, schedule,
+// scheduled run, spending policy, config file or audit log in a restore plan
+type RestoreItem struct {
+	Section BackupSection
+	Label   string
+	Action  RestoreAction
+	Reason  string
+}
+
+// RestorePlan previews a restore. Items the profile already holds unchanged
+// are skipped, as are records whose address is stored under another ID.
+type RestorePlan struct {
+	Items []RestoreItem
+
+	// SecondFactor lists the wallets with a second factor whose spending
+	// policy or second factor the plan changes. Restore needs each one's
+	// authentication code.
+	SecondFactor []string
+
+	// changed lists files rewritten by the plan, which are copied to the
+	// backups directory first
+	changed []string
+	writes  []func() error
+}
+
+// Count returns how many items the plan handles with action
+func (p *RestorePlan) Count(action RestoreAction) int {
+	count := 0
+	for _, item := range p.Items {
+		if item.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// PlanRestore works out what restoring the given sections of backup would
+// add, overwrite and skip, without changing anything
+func (s *Storage) PlanRestore(backup *Backup, sections []BackupSection) (*RestorePlan, error) {
+	plan := &RestorePlan{}
+	for _, section := range sections {
+		var err error
+		switch section {
+		case SectionWallets:
+			err = s.planWallets(backup, plan)
+		case SectionContacts:
+			err = s.planContacts(backup, plan)
+		case SectionHistory:
+			err = s.planHistory(backup, plan)
+		case SectionTemplates:
+			err = s.planTemplates(backup, plan)
+		case SectionSchedules:
+			err = s.planSchedules(backup, plan)
+		case SectionPolicies:
+			err = s.planPolicies(backup, plan)
+		case SectionConfig:
+			err = s.planConfig(backup, plan)
+		case SectionAudit:
+			err = s.planAudit(backup, plan)
+		default:
+			err = fmt.Errorf("unknown backup section %q", section)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan %s restore: %w", section, err)
+		}
+	}
+	return plan, nil
+}
+
+// Restore applies PlanRestore's plan and returns it. The current version of
+// every file it rewrites is kept in the backups directory first. verify is
+// called for each wallet in the plan's SecondFactor list and must check that
+// wallet's authentication code; nothing is restored unless all of them pass.
+func (s *Storage) Restore(backup *Backup, sections []BackupSection, verify func(walletID string) error) (*RestorePlan, error) {
+	plan, err := s.PlanRestore(backup, sections)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range plan.SecondFactor {
+		if verify == nil {
+			return nil, ErrRestoreNeedsSecondFactor
+		}
+		if err := verify(id); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range plan.changed {
+		data, err := os.ReadFile(filepath.Join(s.dataDir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if err := s.backupFile(name, schemas[name].Version, data); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, write := range plan.writes {
+		if err := write(); err != nil {
+			return nil, fmt.Errorf("restore stopped part way: %w", err)
+		}
+	}
+	return plan, nil
+}
+
+// needSecondFactor adds the wallets in ids to the plan's SecondFactor list
+func (p *RestorePlan) needSecondFactor(ids ...string) {
+	for _, id := range ids {
+		if !slices.Contains(p.SecondFactor, id) {
+			p.SecondFactor = append(p.SecondFactor, id)
+		}
+	}
+}
+
+// secondFactorChanges returns the IDs of local wallets with a second factor
+// whose backup record, keyed by id, differs from the local one
+func secondFactorChanges[T any](wallets *WalletStorage, backup, local []T, id func(T) string) []string {
+	protected := make(map[string]bool)
+	for _, wallet := range wallets.Wallets {
+		if wallet.HasTwoFactor() {
+			protected[wallet.ID] = true
+		}
+	}
+	current := make(map[string]T, len(local))
+	for _, record := range local {
+		current[id(record)] = record
+	}
+
+	var ids []string
+	for _, record := range backup {
+		if !protected[id(record)] {
+			continue
+		}
+		if existing, ok := current[id(record)]; ok && sameJSON(existing, record) {
+			continue
+		}
+		ids = append(ids, id(record))
+	}
+	return ids
+}
+
+func (p *RestorePlan) addWrite(name string, write func() error) {
+	p.changed = append(p.changed, name)
+	p.writes = append(p.writes, write)
+}
+
+// mergeRecords restores backup records over local ones with the same ID.
+// A record whose address is held by a different local record is skipped
+// rather than stored twice. It returns the plan items, the merged records
+// and whether anything changed.
+func mergeRecords[T any](section BackupSection, backup, local []T, identity func(T) (id, address, label string)) ([]RestoreItem, []T, bool) {
+	merged := append([]T(nil), local...)
+	byID := make(map[string]int, len(merged))
+	byAddress := make(map[string]string, len(merged))
+	for i, record := range merged {
+		id, address, label := identity(record)
+		byID[id] = i
+		if address != "" {
+			byAddress[strings.ToLower(address)] = label
+		}
+	}
+
+	var items []RestoreItem
+	changed := false
+	for _, record := range backup {
+		id, address, label := identity(record)
+		item := RestoreItem{Section: section, Label: label}
+		i, exists := byID[id]
+		switch {
+		case exists && sameJSON(merged[i], record):
+			item.Action = RestoreSkip
+			item.Reason = "unchanged"
+		case exists:
+			item.Action = RestoreOverwrite
+			merged[i] = record
+			changed = true
+		case address != "" && byAddress[strings.ToLower(address)] != "":
+			item.Action = RestoreSkip
+			item.Reason = "address already stored as " + byAddress[strings.ToLower(address)]
+		default:
+			item.Action = RestoreAdd
+			byID[id] = len(merged)
+			merged = append(merged, record)
+			changed = true
+		}
+		items = append(items, item)
+	}
+	return items, merged, changed
+}
+
+func sameJSON(a, b interface{}) bool {
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	return errLeft == nil && errRight == nil && bytes.Equal(left, right)
+}
+
+func (s *Storage) planWallets(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[walletsFile]
+	if !ok {
+		return nil
+	}
+	var restored WalletStorage
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to unmarshal backup wallets: %w", err)
+	}
+	local, err := s.loadWalletStorage()
+	if err != nil {
+		return err
+	}
+
+	items, merged, changed := mergeRecords(SectionWallets, restored.Wallets, local.Wallets, func(w EncryptedWallet) (string, string, string) {
+		return w.ID, w.Address, fmt.Sprintf("%s (%s)", w.Name, w.Address)
+	})
+	plan.Items = append(plan.Items, items...)
+	// Overwriting a wallet replaces its second factor, recovery codes and
+	// lockout along with it
+	plan.needSecondFactor(secondFactorChanges(local, restored.Wallets, local.Wallets, func(w EncryptedWallet) string {
+		return w.ID
+	})...)
+	if changed {
+		plan.addWrite(walletsFile, func() error {
+			local.Wallets = merged
+			return s.saveWalletStorage(local)
+		})
+	}
+	return nil
+}
+
+func (s *Storage) planContacts(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[contactsFile]
+	if !ok {
+		return nil
+	}
+	local, err := s.LoadContacts()
+	if err != nil {
+		return err
+	}
+	if local.Locked && backup.profileKey != nil {
+		// A backup of this profile carries the key to its contacts
+		if unlocked, err := decodeContactsWith(s.contactsData(), backup.profileKey); err == nil {
+			s.adoptProfileKey(backup.profileKey)
+			local = unlocked
+		}
+	}
+
+	restored, err := decodeContactsWith(data, backup.profileKey)
+	if err == nil && restored.Locked {
+		restored, err = s.decodeContacts(data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read backup contacts: %w", err)
+	}
+
+	switch {
+	case restored.Locked && !local.Locked && len(local.Contacts) == 0:
+		// Without the key the encrypted file can only be restored whole; a
+		// restored wallet holding the key unlocks it
+		plan.Items = append(plan.Items, RestoreItem{Section: SectionContacts, Label: "contacts", Action: RestoreAdd, Reason: "encrypted"})
+		plan.addWrite(contactsFile, func() error {
+			raw := json.RawMessage(data)
+			return s.writeDataFile(contactsFile, &raw)
+		})
+		return nil
+	case restored.Locked:
+		plan.Items = append(plan.Items, RestoreItem{Section: SectionContacts, Label: "contacts", Action: RestoreSkip, Reason: "encrypted with a key this profile does not hold"})
+		return nil
+	case local.Locked:
+		plan.Items = append(plan.Items, RestoreItem{Section: SectionContacts, Label: "contacts", Action: RestoreSkip, Reason: ErrContactsLocked.Error()})
+		return nil
+	}
+
+	items, merged, changed := mergeRecords(SectionContacts, restored.Contacts, local.Contacts, func(c models.Contact) (string, string, string) {
+		return c.ID, c.Address, fmt.Sprintf("%s (%s)", c.Name, c.Address)
+	})
+	plan.Items = append(plan.Items, items...)
+	if changed {
+		plan.addWrite(contactsFile, func() error {
+			local.Contacts = merged
+			return s.SaveContacts(local)
+		})
+	}
+	return nil
+}
+
+// walletHistory is one wallet's transactions, the unit history is restored in
+type walletHistory struct {
+	WalletID     string
+	Transactions []models.Transaction
+}
+
+func (s *Storage) planHistory(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[historyFile]
+	if !ok {
+		return nil
+	}
+	var restored HistoryStorage
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to unmarshal backup history: %w", err)
+	}
+	local, err := s.loadHistoryStorage()
+	if err != nil {
+		return err
+	}
+
+	toList := func(wallets map[string][]models.Transaction) []walletHistory {
+		list := make([]walletHistory, 0, len(wallets))
+		for id, transactions := range wallets {
+			list = append(list, walletHistory{WalletID: id, Transactions: transactions})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].WalletID < list[j].WalletID })
+		return list
+	}
+
+	items, merged, changed := mergeRecords(SectionHistory, toList(restored.Wallets), toList(local.Wallets), func(h walletHistory) (string, string, string) {
+		return h.WalletID, "", fmt.Sprintf("%d transactions for wallet %s", len(h.Transactions), h.WalletID)
+	})
+	plan.Items = append(plan.Items, items...)
+	if changed {
+		plan.addWrite(historyFile, func() error {
+			for _, history := range merged {
+				local.Wallets[history.WalletID] = history.Transactions
+			}
+			return s.saveHistoryStorage(local)
+		})
+	}
+	return nil
+}
+
+// walletTemplates is one wallet's templates and recent addresses, the unit
+// templates are restored in
+type walletTemplates struct {
+	WalletID string
+	*WalletTemplates
+}
+
+func (s *Storage) planTemplates(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[templatesFile]
+	if !ok {
+		return nil
+	}
+	var restored TemplateStorage
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to unmarshal backup templates: %w", err)
+	}
+	local, err := s.loadTemplateStorage()
+	if err != nil {
+		return err
+	}
+
+	toList := func(wallets map[string]*WalletTemplates) []walletTemplates {
+		list := make([]walletTemplates, 0, len(wallets))
+		for id, templates := range wallets {
+			if templates != nil {
+				list = append(list, walletTemplates{WalletID: id, WalletTemplates: templates})
+			}
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].WalletID < list[j].WalletID })
+		return list
+	}
+
+	items, merged, changed := mergeRecords(SectionTemplates, toList(restored.Wallets), toList(local.Wallets), func(t walletTemplates) (string, string, string) {
+		return t.WalletID, "", fmt.Sprintf("%d templates for wallet %s", len(t.Templates), t.WalletID)
+	})
+	plan.Items = append(plan.Items, items...)
+	if changed {
+		plan.addWrite(templatesFile, func() error {
+			for _, templates := range merged {
+				local.Wallets[templates.WalletID] = templates.WalletTemplates
+			}
+			return s.saveTemplateStorage(local)
+		})
+	}
+	return nil
+}
+
+func (s *Storage) planSchedules(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[schedulesFile]
+	if !ok {
+		return nil
+	}
+	var restored ScheduleStorage
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to unmarshal backup schedules: %w", err)
+	}
+	local, err := s.loadScheduleStorage()
+	if err != nil {
+		return err
+	}
+
+	items, schedules, schedulesChanged := mergeRecords(SectionSchedules, restored.Schedules, local.Schedules, func(ps models.PaymentSchedule) (string, string, string) {
+		return ps.ID, "", "schedule " + ps.Name
+	})
+	plan.Items = append(plan.Items, items...)
+	items, runs, runsChanged := mergeRecords(SectionSchedules, restored.Runs, local.Runs, func(r models.ScheduledRun) (string, string, string) {
+		return r.ID, "", fmt.Sprintf("%s run due %s", r.ScheduleName, r.DueAt.Format("2006-01-02 15:04"))
+	})
+	plan.Items = append(plan.Items, items...)
+	if schedulesChanged || runsChanged {
+		plan.addWrite(schedulesFile, func() error {
+			local.Schedules, local.Runs = schedules, runs
+			return s.saveScheduleStorage(local)
+		})
+	}
+	return nil
+}
+
+func (s *Storage) planPolicies(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[policiesFile]
+	if !ok {
+		return nil
+	}
+	var restored PolicyStorage
+	if err := json.Unmarshal(data, &restored); err != nil {
+		return fmt.Errorf("failed to unmarshal backup spending policies: %w", err)
+	}
+	local, err := s.loadPolicyStorage()
+	if err != nil {
+		return err
+	}
+
+	items, policies, changed := mergeRecords(SectionPolicies, restored.Policies, local.Policies, func(p models.SpendingPolicy) (string, string, string) {
+		return p.ID, "", "spending policy for wallet " + p.ID
+	})
+	plan.Items = append(plan.Items, items...)
+	wallets, err := s.loadWalletStorage()
+	if err != nil {
+		return err
+	}
+	plan.needSecondFactor(secondFactorChanges(wallets, restored.Policies, local.Policies, func(p models.SpendingPolicy) string {
+		return p.ID
+	})...)
+	if changed {
+		plan.addWrite(policiesFile, func() error {
+			local.Policies = policies
+			return s.savePolicyStorage(local)
+		})
+	}
+	return nil
+}
+
+func (s *Storage) planConfig(backup *Backup, plan *RestorePlan) error {
+	data, ok := backup.files[configFile]
+	if !ok {
+		return nil
+	}
+	restored := DefaultConfig()
+	if err := json.Unmarshal(data, restored); err != nil {
+		return fmt.Errorf("failed to unmarshal backup config: %w", err)
+	}
+
+	item := RestoreItem{Section: SectionConfig, Label: configFile, Action: RestoreAdd}
+	if _, err := os.Stat(s.ConfigPath()); err == nil {
+		local, err := s.LoadConfig()
+		if err != nil {
+			return err
+		}
+		item.Action = RestoreOverwrite
+		if sameJSON(local, restored) {
+			item.Action = RestoreSkip
+			item.Reason = "unchanged"
+		}
+	}
+	if item.Action != RestoreSkip {
+		if err := restored.Validate(); err != nil {
+			item.Action = RestoreSkip
+			item.Reason = "invalid: " + err.Error()
+		}
+	}
+
+	plan.Items = append(plan.Items, item)
+	if item.Action != RestoreSkip {
+		plan.addWrite(configFile, func() error { return s.SaveConfig(restored) })
+	}
+	return nil
+}
+
+// planAudit restores audit logs the profile lacks and adds the entries a
+// local log is missing to its end. Local entries are never replaced, so an
+// older backup cannot erase what was logged since it was made.
+func (s *Storage) planAudit(backup *Backup, plan *RestorePlan) error {
+	for _, name := range backup.Files() {
+		if sectionOf(name) != SectionAudit {
+			continue
+		}
+		filePath := filepath.Join(s.dataDir, filepath.FromSlash(name))
+
+		item := RestoreItem{Section: SectionAudit, Label: name, Action: RestoreAdd}
+		existing, err := os.ReadFile(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		missing := missingLines(existing, backup.files[name])
+		switch {
+		case len(missing) == 0:
+			item.Action = RestoreSkip
+			item.Reason = "unchanged"
+		case err == nil:
+			item.Action = RestoreOverwrite
+			item.Reason = fmt.Sprintf("%d missing entries appended", bytes.Count(missing, []byte("\n")))
+		}
+
+		plan.Items = append(plan.Items, item)
+		if item.Action != RestoreSkip {
+			plan.writes = append(plan.writes, func() error {
+				return appendAuditLines(filePath, missing)
+			})
+		}
+	}
+	return nil
+}
+
+// missingLines returns the lines of restored that local does not hold, in
+// their order in restored, each ending in a newline
+func missingLines(local, restored []byte) []byte {
+	have := make(map[string]bool)
+	for _, line := range bytes.Split(local, []byte("\n")) {
+		have[string(line)] = true
+	}
+
+	var missing []byte
+	for _, line := range bytes.Split(restored, []byte("\n")) {
+		if len(line) == 0 || have[string(line)] {
+			continue
+		}
+		have[string(line)] = true
+		missing = append(missing, line...)
+		missing = append(missing, '\n')
+	}
+	return missing
+}
+
+// appendAuditLines adds lines to the end of an audit log the way the
+// auditors write it, so entries logged while restoring are kept
+func appendAuditLines(filePath string, lines []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Start on a new line when the log does not end in one
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if size := info.Size(); size > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, size-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			lines = append([]byte("\n"), lines...)
+		}
+	}
+	if _, err := file.Write(lines); err != nil {
+		return err
+	}
+	return file.Sync()
+}
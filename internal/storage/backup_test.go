@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const backupPassword = "backup-horse-battery"
+
+func TestBackupRestore(t *testing.T) {
+	source, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	source.SetKDFParams(Argon2idParams(1, MinKDFMemory, 1))
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := source.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	contact := models.NewContact("Alice", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "")
+	if err := source.SaveContacts(&models.ContactList{Contacts: []models.Contact{*contact}}); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+	config := DefaultConfig()
+	config.Network = "testnet"
+	if err := source.SaveConfig(config); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if err := os.MkdirAll(source.AuditLogDir(), 0700); err != nil {
+		t.Fatalf("Failed to create audit directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(source.AuditLogDir(), "2026-01-01.log"), []byte("unlock\n"), 0600); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	path, err := source.CreateBackup("", backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if _, err := OpenBackup(path, "wrong-password"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("Expected ErrInvalidPassword, got %v", err)
+	}
+	backup, err := OpenBackup(path, backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	if len(backup.Files()) != 4 {
+		t.Errorf("Expected wallets, contacts, config and one audit log, got %v", backup.Files())
+	}
+
+	target, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	// Only contacts: nothing else may be touched
+	plan, err := target.Restore(backup, []BackupSection{SectionContacts}, nil)
+	if err != nil {
+		t.Fatalf("Failed to restore contacts: %v", err)
+	}
+	if len(plan.Items) != 1 || plan.Items[0].Action != RestoreAdd {
+		t.Fatalf("Expected one contact added, got %+v", plan.Items)
+	}
+	if wallets, _ := target.ListWallets(); len(wallets) != 0 {
+		t.Error("A contacts-only restore must not add wallets")
+	}
+
+	plan, err = target.PlanRestore(backup, BackupSections())
+	if err != nil {
+		t.Fatalf("Failed to plan restore: %v", err)
+	}
+	if plan.Count(RestoreAdd) != 3 || plan.Count(RestoreSkip) != 1 {
+		t.Errorf("Expected wallet, config and audit log added and contact skipped, got %+v", plan.Items)
+	}
+	if _, err := target.Restore(backup, BackupSections(), nil); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+
+	loaded, err := target.LoadWallet(wallet.ID, testPassword)
+	if err != nil || loaded.Mnemonic != testMnemonic {
+		t.Fatalf("Restored wallet should unlock with its own password: %v", err)
+	}
+	if restored, _ := target.LoadConfig(); restored.Network != "testnet" {
+		t.Errorf("Expected restored config, got network %s", restored.Network)
+	}
+	if data, _ := os.ReadFile(filepath.Join(target.AuditLogDir(), "2026-01-01.log")); string(data) != "unlock\n" {
+		t.Errorf("Audit log not restored: %q", data)
+	}
+
+	// A renamed contact is overwritten and the old file kept
+	contacts, _ := target.LoadContacts()
+	contacts.Contacts[0].Name = "Renamed"
+	target.SaveContacts(contacts)
+	plan, err = target.Restore(backup, []BackupSection{SectionContacts}, nil)
+	if err != nil || plan.Count(RestoreOverwrite) != 1 {
+		t.Fatalf("Expected contact overwritten, got %+v, %v", plan, err)
+	}
+	if contacts, _ := target.LoadContacts(); contacts.Contacts[0].Name != "Alice" {
+		t.Errorf("Expected contact restored, got %s", contacts.Contacts[0].Name)
+	}
+	if copies, _ := filepath.Glob(filepath.Join(target.DataDir(), backupsDir, contactsFile+".*.bak")); len(copies) != 1 {
+		t.Errorf("Expected the replaced contacts file to be kept, found %v", copies)
+	}
+}
+
+func TestRestoreKeepsNewerAuditEntries(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	logPath := filepath.Join(store.AuditLogDir(), "2026-01-01.log")
+	if err := os.MkdirAll(store.AuditLogDir(), 0700); err != nil {
+		t.Fatalf("Failed to create audit directory: %v", err)
+	}
+	if err := os.WriteFile(logPath, []byte("unlock\n"), 0600); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	path, err := store.CreateBackup("", backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	backup, err := OpenBackup(path, backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+
+	// Entries logged after the backup, and a log that lost its first entry
+	if err := os.WriteFile(logPath, []byte("send\nlock\n"), 0600); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+	plan, err := store.Restore(backup, []BackupSection{SectionAudit}, nil)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if data, _ := os.ReadFile(logPath); string(data) != "send\nlock\nunlock\n" {
+		t.Errorf("Expected the missing entry appended to the newer ones, got %q", data)
+	}
+
+	plan, err = store.PlanRestore(backup, []BackupSection{SectionAudit})
+	if err != nil || plan.Count(RestoreSkip) != 1 {
+		t.Errorf("Expected a log holding every backed up entry to be skipped, got %+v, %v", plan, err)
+	}
+}
+
+func TestRestoreRequiresSecondFactor(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	store.SetKDFParams(Argon2idParams(1, MinKDFMemory, 1))
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	if err := store.SaveSpendingPolicy(&models.SpendingPolicy{ID: wallet.ID, Enabled: false}); err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+	path, err := store.CreateBackup("", backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	backup, err := OpenBackup(path, backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+
+	// Since the backup, the policy was turned on and a second factor added
+	if err := store.SaveSpendingPolicy(&models.SpendingPolicy{ID: wallet.ID, Enabled: true}); err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+	twoFactor, _, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := store.SetTwoFactor(wallet.ID, testPassword, twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+
+	for _, section := range []BackupSection{SectionPolicies, SectionWallets} {
+		plan, err := store.PlanRestore(backup, []BackupSection{section})
+		if err != nil {
+			t.Fatalf("Failed to plan %s restore: %v", section, err)
+		}
+		if len(plan.SecondFactor) != 1 || plan.SecondFactor[0] != wallet.ID {
+			t.Errorf("Expected a %s restore to need the wallet's code, got %v", section, plan.SecondFactor)
+		}
+		if _, err := store.Restore(backup, []BackupSection{section}, nil); !errors.Is(err, ErrRestoreNeedsSecondFactor) {
+			t.Errorf("Expected a %s restore without a code to be refused, got %v", section, err)
+		}
+		refused := errors.New("wrong code")
+		if _, err := store.Restore(backup, []BackupSection{section}, func(string) error { return refused }); !errors.Is(err, refused) {
+			t.Errorf("Expected a %s restore with a wrong code to be refused, got %v", section, err)
+		}
+	}
+	if policy, _ := store.LoadSpendingPolicy(wallet.ID); policy == nil || !policy.Enabled {
+		t.Fatal("A refused restore must not change the policy")
+	}
+	if wallets, _ := store.ListWallets(); !wallets[0].HasTwoFactor() {
+		t.Fatal("A refused restore must not remove the second factor")
+	}
+
+	verified := []string{}
+	if _, err := store.Restore(backup, []BackupSection{SectionPolicies}, func(id string) error {
+		verified = append(verified, id)
+		return nil
+	}); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if len(verified) != 1 {
+		t.Errorf("Expected one code check, got %v", verified)
+	}
+	if policy, _ := store.LoadSpendingPolicy(wallet.ID); policy == nil || policy.Enabled {
+		t.Error("Expected the policy restored once the code was checked")
+	}
+}
+
+func TestOpenBackupDetectsCorruption(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	store.SetKDFParams(Argon2idParams(1, MinKDFMemory, 1))
+	if err := store.SaveConfig(DefaultConfig()); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+
+	path, err := store.CreateBackup(filepath.Join(t.TempDir(), "backup.vtbak"), backupPassword)
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if _, err := store.CreateBackup(path, backupPassword); err == nil {
+		t.Error("Expected an existing backup never to be overwritten")
+	}
+
+	data, _ := os.ReadFile(path)
+	var header backupHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		t.Fatalf("Failed to parse backup: %v", err)
+	}
+	header.Envelope.Ciphertext[0] ^= 1
+	damaged, _ := json.Marshal(header)
+	os.WriteFile(path, damaged, 0600)
+
+	if _, err := OpenBackup(path, backupPassword); !errors.Is(err, ErrBackupCorrupt) {
+		t.Errorf("Expected ErrBackupCorrupt, got %v", err)
+	}
+}
+
+func TestParseBackupSections(t *testing.T) {
+	sections, err := ParseBackupSections(" Contacts,wallets ")
+	if err != nil || len(sections) != 2 || sections[0] != SectionContacts || sections[1] != SectionWallets {
+		t.Errorf("Unexpected sections %v, %v", sections, err)
+	}
+	if all, _ := ParseBackupSections(""); len(all) != len(BackupSections()) {
+		t.Error("An empty list should select every section")
+	}
+	if _, err := ParseBackupSections("keys"); err == nil {
+		t.Error("Expected error for an unknown section")
+	}
+}
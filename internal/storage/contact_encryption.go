@@ -1,125 +1,397 @@
 package storage
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 
+	"rhystmorgan/veWallet/internal/models"
 	"rhystmorgan/veWallet/internal/types"
 )
 
-// EncryptContact encrypts sensitive fields of a contact
-func EncryptContact(contact map[string]interface{}, password string) (*types.EncryptedContact, error) {
-	// Extract sensitive data
-	sensitiveData := types.SensitiveContactData{
-		Notes: contact["notes"].(string),
+// Contacts are encrypted with a random profile key rather than a password,
+// so every wallet in the profile can open them. The key is stored inside each
+// wallet's encrypted data; unlocking any wallet that holds it unlocks the
+// contacts, and wallets unlocked afterwards are given a copy.
+
+// ContactEncryption is how the contacts file is encrypted
+type ContactEncryption string
+
+const (
+	// ContactEncryptionFile seals the whole contact list
+	ContactEncryptionFile ContactEncryption = "file"
+	// ContactEncryptionFields seals notes and payment totals, leaving names
+	// and addresses readable before unlock so they can still be searched
+	ContactEncryptionFields ContactEncryption = "fields"
+)
+
+const profileKeyLength = 32
+
+// ErrContactsLocked is returned when saving contacts that were read, or are
+// stored, encrypted while no wallet holding the profile key is unlocked
+var ErrContactsLocked = errors.New("contacts are locked; unlock a wallet first")
+
+// ParseContactEncryption checks a contact_encryption setting
+func ParseContactEncryption(value string) (ContactEncryption, error) {
+	switch mode := ContactEncryption(value); mode {
+	case ContactEncryptionFile, ContactEncryptionFields:
+		return mode, nil
 	}
+	return "", fmt.Errorf("invalid contact encryption: %s (must be 'file' or 'fields')", value)
+}
 
-	if metadata, ok := contact["metadata"].(map[string]string); ok {
-		sensitiveData.Metadata = metadata
+// SetContactEncryption sets how contacts are encrypted from the next save
+func (s *Storage) SetContactEncryption(mode ContactEncryption) error {
+	if _, err := ParseContactEncryption(string(mode)); err != nil {
+		return err
 	}
-	if totalSent, ok := contact["total_sent"].(string); ok {
-		sensitiveData.TotalSent = totalSent
+	s.mu.Lock()
+	s.contactEncryption = mode
+	s.mu.Unlock()
+	return nil
+}
+
+// ContactsUnlocked reports whether the profile key is available
+func (s *Storage) ContactsUnlocked() bool {
+	return s.contactKey() != nil
+}
+
+// LockContacts forgets the profile key; contacts read afterwards are locked
+// until a wallet is unlocked again
+func (s *Storage) LockContacts() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.profileKey {
+		s.profileKey[i] = 0
 	}
+	s.profileKey = nil
+}
 
-	// Encrypt sensitive data
-	sensitiveJSON, err := json.Marshal(sensitiveData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal sensitive data: %w", err)
+func (s *Storage) contactKey() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.profileKey
+}
+
+func (s *Storage) contactMode() ContactEncryption {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.contactEncryption == "" {
+		return ContactEncryptionFile
+	}
+	return s.contactEncryption
+}
+
+// unlockContacts adopts the profile key found in an unlocked wallet and
+// encrypts a contacts file that is still stored in plaintext or in another
+// mode. The first key seen is kept.
+func (s *Storage) unlockContacts(key []byte) {
+	s.adoptProfileKey(key)
+
+	// The wallet is unlocked either way; a failed conversion is retried on
+	// the next unlock
+	s.sealStoredContacts()
+}
+
+func (s *Storage) adoptProfileKey(key []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profileKey == nil {
+		s.profileKey = append([]byte(nil), key...)
+	}
+}
+
+// ensureProfileKey returns the profile key, creating one when no encrypted
+// contacts file exists yet. It returns nil when the contacts are encrypted
+// with a key that has not been unlocked, so wallets are never given a key
+// that cannot open them.
+func (s *Storage) ensureProfileKey() ([]byte, error) {
+	if key := s.contactKey(); key != nil {
+		return key, nil
+	}
+
+	mode, err := s.storedContactEncryption()
+	if err != nil || mode != "" {
+		return nil, err
 	}
 
-	encryptedData, err := Encrypt(sensitiveJSON, password)
+	key := make([]byte, profileKeyLength)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	if s.profileKey == nil {
+		s.profileKey = key
+	}
+	key = s.profileKey
+	s.mu.Unlock()
+	return key, nil
+}
+
+// sealStoredContacts rewrites the contacts file with the current mode when it
+// is in plaintext or another mode
+func (s *Storage) sealStoredContacts() error {
+	stored, err := s.storedContactEncryption()
+	if err != nil || stored == s.contactMode() {
+		return err
+	}
+	contacts, err := s.LoadContacts()
 	if err != nil {
-		return nil, fmt.Errorf("failed to encrypt sensitive data: %w", err)
-	}
-
-	// Create encrypted contact
-	encContact := &types.EncryptedContact{
-		ID:            contact["id"].(string),
-		Name:          contact["name"].(string),
-		Address:       contact["address"].(string),
-		CreatedAt:     contact["created_at"].(string),
-		UpdatedAt:     contact["updated_at"].(string),
-		IsFavorite:    contact["is_favorite"].(bool),
-		Category:      contact["category"].(string),
-		Tags:          contact["tags"].([]string),
-		LastUsed:      contact["last_used"].(string),
-		UseCount:      contact["use_count"].(int),
-		SensitiveData: encryptedData.Ciphertext,
-	}
-
-	return encContact, nil
-}
-
-// DecryptContact decrypts sensitive fields of a contact
-func DecryptContact(encContact *types.EncryptedContact, password string) (map[string]interface{}, error) {
-	// Start with non-sensitive data
-	contact := map[string]interface{}{
-		"id":          encContact.ID,
-		"name":        encContact.Name,
-		"address":     encContact.Address,
-		"created_at":  encContact.CreatedAt,
-		"updated_at":  encContact.UpdatedAt,
-		"is_favorite": encContact.IsFavorite,
-		"category":    encContact.Category,
-		"tags":        encContact.Tags,
-		"last_used":   encContact.LastUsed,
-		"use_count":   encContact.UseCount,
-	}
-
-	// Decrypt sensitive data if present
-	if len(encContact.SensitiveData) > 0 {
-		encData := &EncryptedData{
-			Ciphertext: encContact.SensitiveData,
+		return err
+	}
+	if contacts.Locked {
+		return nil
+	}
+	if stored == "" && len(contacts.Contacts) == 0 {
+		// Nothing to protect yet; the first save encrypts it
+		return nil
+	}
+	return s.SaveContacts(contacts)
+}
+
+// contactsData returns the contacts file as stored, or nil
+func (s *Storage) contactsData() []byte {
+	data, _ := s.readDataFile(contactsFile)
+	return data
+}
+
+// storedContactEncryption returns the encryption of the contacts file on
+// disk, "" for plaintext or no file
+func (s *Storage) storedContactEncryption() (ContactEncryption, error) {
+	data, err := s.readDataFile(contactsFile)
+	if err != nil || data == nil {
+		return "", err
+	}
+	return contactEncryptionOf(data)
+}
+
+func contactEncryptionOf(data []byte) (ContactEncryption, error) {
+	var header struct {
+		Encryption ContactEncryption `json:"encryption"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return "", fmt.Errorf("failed to unmarshal contacts: %w", err)
+	}
+	return header.Encryption, nil
+}
+
+// encryptedContactsDocument is the on-disk form of an encrypted contact list.
+// Whole-file encryption fills Sealed with a sealed contactsDocument; field
+// encryption fills Contacts.
+type encryptedContactsDocument struct {
+	Version    int                      `json:"version"`
+	Encryption ContactEncryption        `json:"encryption"`
+	Contacts   []types.EncryptedContact `json:"contacts,omitempty"`
+	Sealed     []byte                   `json:"sealed,omitempty"`
+}
+
+// encodeContacts returns the document SaveContacts writes: encrypted with the
+// profile key when there is one, or plaintext for a profile whose contacts
+// have never been encrypted
+func (s *Storage) encodeContacts(contacts *models.ContactList) (interface{}, error) {
+	if contacts.Locked {
+		return nil, ErrContactsLocked
+	}
+
+	key := s.contactKey()
+	if key == nil {
+		stored, err := s.storedContactEncryption()
+		if err != nil {
+			return nil, err
 		}
+		if stored != "" {
+			return nil, ErrContactsLocked
+		}
+		return &contactsDocument{Version: ContactsVersion, ContactList: contacts}, nil
+	}
 
-		decryptedJSON, err := Decrypt(encData, password)
+	doc := &encryptedContactsDocument{Version: ContactsVersion, Encryption: s.contactMode()}
+	seal := func(data []byte) ([]byte, error) { return sealWithKey(key, data) }
+
+	if doc.Encryption == ContactEncryptionFile {
+		plaintext, err := json.Marshal(contactsDocument{Version: ContactsVersion, ContactList: contacts})
 		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt sensitive data: %w", err)
+			return nil, fmt.Errorf("failed to marshal contacts: %w", err)
+		}
+		if doc.Sealed, err = seal(plaintext); err != nil {
+			return nil, err
 		}
+		return doc, nil
+	}
 
-		var sensitiveData types.SensitiveContactData
-		if err := json.Unmarshal(decryptedJSON, &sensitiveData); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal sensitive data: %w", err)
+	doc.Contacts = make([]types.EncryptedContact, 0, len(contacts.Contacts))
+	for _, contact := range contacts.Contacts {
+		if err := contact.EncryptSensitiveData(seal); err != nil {
+			return nil, err
 		}
+		doc.Contacts = append(doc.Contacts, *contact.ToEncryptedContact())
+	}
+	return doc, nil
+}
 
-		// Add decrypted sensitive data
-		contact["notes"] = sensitiveData.Notes
-		if sensitiveData.Metadata != nil {
-			contact["metadata"] = sensitiveData.Metadata
+// mergeContacts merges contacts another process saved since this one last
+// read them. Sealed data gets a fresh nonce on every save, so encrypted
+// files are opened with the profile key, merged as plaintext and sealed
+// again in the current mode.
+func (s *Storage) mergeContacts(base, ours, theirs []byte) ([]byte, error) {
+	encrypted := false
+	for _, data := range [][]byte{base, ours, theirs} {
+		if data == nil {
+			continue
 		}
-		if sensitiveData.TotalSent != "" {
-			contact["total_sent"] = sensitiveData.TotalSent
+		encryption, err := contactEncryptionOf(data)
+		if err != nil {
+			continue
 		}
+		encrypted = encrypted || encryption != ""
+	}
+	if !encrypted {
+		return mergeDataFile(contactsFile, base, ours, theirs, &contactsDocument{})
+	}
+
+	key := s.contactKey()
+	if key == nil {
+		return nil, ErrContactsLocked
+	}
+
+	if _, err := contactEncryptionOf(theirs); err != nil {
+		// The file on disk is damaged; ours is all there is to keep
+		return ours, nil
 	}
 
-	return contact, nil
+	plain := make([][]byte, 3)
+	for i, data := range [][]byte{base, ours, theirs} {
+		if data == nil {
+			continue
+		}
+		contacts, err := decodeContactsWith(data, key)
+		if err != nil {
+			if i == 0 {
+				// An unreadable base only costs the merge its history
+				continue
+			}
+			return nil, err
+		}
+		if plain[i], err = json.Marshal(contactsDocument{Version: ContactsVersion, ContactList: contacts}); err != nil {
+			return nil, fmt.Errorf("failed to marshal contacts: %w", err)
+		}
+	}
+
+	merged, err := mergeDataFile(contactsFile, plain[0], plain[1], plain[2], &contactsDocument{})
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := decodeContactsWith(merged, nil)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.encodeContacts(contacts)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
 }
 
-// ReEncryptContact re-encrypts a contact's sensitive data with a new password
-func ReEncryptContact(encContact *types.EncryptedContact, oldPassword, newPassword string) (*types.EncryptedContact, error) {
-	// First decrypt with old password
-	contact, err := DecryptContact(encContact, oldPassword)
+// decodeContacts reads a contacts file in any mode. Encrypted contacts read
+// without the profile key come back Locked: names and addresses only for
+// field encryption, nothing for whole-file encryption.
+func (s *Storage) decodeContacts(data []byte) (*models.ContactList, error) {
+	return decodeContactsWith(data, s.contactKey())
+}
+
+func decodeContactsWith(data, key []byte) (*models.ContactList, error) {
+	encryption, err := contactEncryptionOf(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt with old password: %w", err)
+		return nil, err
+	}
+	if encryption == "" {
+		contacts := contactsDocument{ContactList: &models.ContactList{}}
+		if err := json.Unmarshal(data, &contacts); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal contacts: %w", err)
+		}
+		return contacts.ContactList, nil
+	}
+
+	var doc encryptedContactsDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal contacts: %w", err)
+	}
+
+	open := func(data []byte) ([]byte, error) { return openWithKey(key, data) }
+
+	switch doc.Encryption {
+	case ContactEncryptionFile:
+		if key == nil {
+			return &models.ContactList{Contacts: []models.Contact{}, Locked: true}, nil
+		}
+		plaintext, err := open(doc.Sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt contacts: %w", err)
+		}
+		return decodeContactsWith(plaintext, key)
+
+	case ContactEncryptionFields:
+		list := &models.ContactList{Contacts: make([]models.Contact, 0, len(doc.Contacts)), Locked: key == nil}
+		for i := range doc.Contacts {
+			var contact models.Contact
+			if err := contact.FromEncryptedContact(&doc.Contacts[i]); err != nil {
+				return nil, fmt.Errorf("failed to read contact %s: %w", doc.Contacts[i].ID, err)
+			}
+			if key != nil {
+				if err := contact.DecryptSensitiveData(open); err != nil {
+					return nil, fmt.Errorf("failed to read contact %s: %w", contact.ID, err)
+				}
+			} else {
+				contact.ClearSensitiveData()
+			}
+			list.Contacts = append(list.Contacts, contact)
+		}
+		return list, nil
 	}
 
-	// Then encrypt with new password
-	return EncryptContact(contact, newPassword)
+	return nil, fmt.Errorf("unknown contact encryption %q", doc.Encryption)
 }
 
-// UpdateEncryptedContact updates an encrypted contact's sensitive data
-func UpdateEncryptedContact(encContact *types.EncryptedContact, updates map[string]interface{}, password string) (*types.EncryptedContact, error) {
-	// First decrypt current data
-	contact, err := DecryptContact(encContact, password)
+// sealWithKey encrypts data with AES-256-GCM under a raw key, returning the
+// nonce followed by the ciphertext
+func sealWithKey(key, data []byte) ([]byte, error) {
+	aesGCM, err := newKeyGCM(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt current data: %w", err)
+		return nil, err
+	}
+	nonce := make([]byte, nonceLength)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
 	}
+	return aesGCM.Seal(nonce, nonce, data, nil), nil
+}
 
-	// Apply updates
-	for key, value := range updates {
-		contact[key] = value
+func openWithKey(key, sealed []byte) ([]byte, error) {
+	aesGCM, err := newKeyGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < nonceLength {
+		return nil, ErrInvalidPassword
 	}
+	plaintext, err := aesGCM.Open(nil, sealed[:nonceLength], sealed[nonceLength:], nil)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+	return plaintext, nil
+}
 
-	// Re-encrypt with updates
-	return EncryptContact(contact, password)
+func newKeyGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != profileKeyLength {
+		return nil, fmt.Errorf("profile key must be %d bytes", profileKeyLength)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
 }
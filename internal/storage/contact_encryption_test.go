@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func openFastStorage(t *testing.T, dir string) *Storage {
+	t.Helper()
+	store, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	store.SetKDFParams(Argon2idParams(1, MinKDFMemory, 1))
+	return store
+}
+
+func TestContactsEncryptedOnUnlock(t *testing.T) {
+	dir := t.TempDir()
+	store := openFastStorage(t, dir)
+
+	// Contacts saved before any wallet exists stay plaintext
+	alice := models.NewContact("Alice", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "rent")
+	if err := store.SaveContacts(&models.ContactList{Contacts: []models.Contact{*alice}}); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	raw, err := os.ReadFile(filepath.Join(dir, contactsFile))
+	if err != nil {
+		t.Fatalf("Failed to read contacts: %v", err)
+	}
+	if bytes.Contains(raw, []byte("Alice")) || bytes.Contains(raw, []byte("rent")) {
+		t.Fatalf("Contacts should be encrypted once a wallet holds the key:\n%s", raw)
+	}
+
+	// Another process sees a locked, empty list until it unlocks a wallet
+	other := openFastStorage(t, dir)
+	locked, err := other.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load locked contacts: %v", err)
+	}
+	if !locked.Locked || len(locked.Contacts) != 0 {
+		t.Fatalf("Expected an empty locked list, got %+v", locked)
+	}
+	if err := other.SaveContacts(&models.ContactList{}); !errors.Is(err, ErrContactsLocked) {
+		t.Fatalf("Expected ErrContactsLocked saving over encrypted contacts, got %v", err)
+	}
+
+	if _, err := other.LoadWallet(wallet.ID, testPassword); err != nil {
+		t.Fatalf("Failed to unlock wallet: %v", err)
+	}
+	unlocked, err := other.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load contacts: %v", err)
+	}
+	if unlocked.Locked || len(unlocked.Contacts) != 1 || unlocked.Contacts[0].Notes != "rent" {
+		t.Fatalf("Expected Alice with notes after unlock, got %+v", unlocked)
+	}
+
+	// A password change keeps the key
+	if err := other.ChangeWalletPassword(wallet.ID, testPassword, "another-password-123"); err != nil {
+		t.Fatalf("Failed to change password: %v", err)
+	}
+	third := openFastStorage(t, dir)
+	if _, err := third.LoadWallet(wallet.ID, "another-password-123"); err != nil {
+		t.Fatalf("Failed to unlock wallet: %v", err)
+	}
+	if contacts, _ := third.LoadContacts(); contacts.Locked || len(contacts.Contacts) != 1 {
+		t.Errorf("Contacts should unlock with the new password, got %+v", contacts)
+	}
+}
+
+func TestFieldEncryptionKeepsNamesSearchable(t *testing.T) {
+	dir := t.TempDir()
+	store := openFastStorage(t, dir)
+	store.SetContactEncryption(ContactEncryptionFields)
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	alice := models.NewContact("Alice", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "rent")
+	alice.TotalSent = "1000000000000000000"
+	if err := store.SaveContacts(&models.ContactList{Contacts: []models.Contact{*alice}}); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	raw, _ := os.ReadFile(filepath.Join(dir, contactsFile))
+	if !bytes.Contains(raw, []byte("Alice")) || bytes.Contains(raw, []byte("rent")) || bytes.Contains(raw, []byte(alice.TotalSent)) {
+		t.Fatalf("Expected the name in the clear and notes and totals sealed:\n%s", raw)
+	}
+
+	other := openFastStorage(t, dir)
+	locked, err := other.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load locked contacts: %v", err)
+	}
+	if !locked.Locked || len(locked.Contacts) != 1 || locked.FindByAddress(alice.Address) == nil || locked.Contacts[0].Notes != "" {
+		t.Fatalf("Expected Alice without notes before unlock, got %+v", locked.Contacts)
+	}
+
+	if _, err := other.LoadWallet(wallet.ID, testPassword); err != nil {
+		t.Fatalf("Failed to unlock wallet: %v", err)
+	}
+	unlocked, _ := other.LoadContacts()
+	if unlocked.Contacts[0].Notes != "rent" || unlocked.Contacts[0].TotalSent != alice.TotalSent {
+		t.Errorf("Expected sealed fields restored, got %+v", unlocked.Contacts[0])
+	}
+}
+
+func TestWalletsShareTheProfileKey(t *testing.T) {
+	dir := t.TempDir()
+	store := openFastStorage(t, dir)
+
+	first, _ := models.NewWallet("First", testMnemonic)
+	if err := store.SaveWallet(first, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	contact := models.NewContact("Alice", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "")
+	if err := store.SaveContacts(&models.ContactList{Contacts: []models.Contact{*contact}}); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	second, err := models.NewWalletFromPrivateKey("Second", key)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(second, "second-password-1"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	other := openFastStorage(t, dir)
+	if _, err := other.LoadWallet(second.ID, "second-password-1"); err != nil {
+		t.Fatalf("Failed to unlock wallet: %v", err)
+	}
+	if contacts, _ := other.LoadContacts(); contacts.Locked || len(contacts.Contacts) != 1 {
+		t.Errorf("Either wallet should unlock the contacts, got %+v", contacts)
+	}
+}
+
+func TestEncryptedContactsMergeChangesFromOtherProcesses(t *testing.T) {
+	for _, mode := range []ContactEncryption{ContactEncryptionFile, ContactEncryptionFields} {
+		t.Run(string(mode), func(t *testing.T) {
+			dir := t.TempDir()
+			store := openFastStorage(t, dir)
+			store.SetContactEncryption(mode)
+
+			wallet, err := models.NewWallet("Main", testMnemonic)
+			if err != nil {
+				t.Fatalf("Failed to create wallet: %v", err)
+			}
+			if err := store.SaveWallet(wallet, testPassword); err != nil {
+				t.Fatalf("Failed to save wallet: %v", err)
+			}
+			shared := models.NewContact("Shared", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "")
+			shared.ID = "contact_shared"
+			if err := store.SaveContacts(&models.ContactList{Contacts: []models.Contact{*shared}}); err != nil {
+				t.Fatalf("Failed to save contacts: %v", err)
+			}
+
+			first := openFastStorage(t, dir)
+			second := openFastStorage(t, dir)
+			for _, process := range []*Storage{first, second} {
+				process.SetContactEncryption(mode)
+				if _, err := process.LoadWallet(wallet.ID, testPassword); err != nil {
+					t.Fatalf("Failed to unlock wallet: %v", err)
+				}
+			}
+			firstList, err := first.LoadContacts()
+			if err != nil {
+				t.Fatalf("Failed to load contacts: %v", err)
+			}
+			secondList, err := second.LoadContacts()
+			if err != nil {
+				t.Fatalf("Failed to load contacts: %v", err)
+			}
+
+			// Each process edits its own copy and saves without reloading.
+			// The second reseals Shared unchanged, which must not undo the
+			// first's edit.
+			alice := models.NewContact("Alice", "0x0000000000000000000000000000456e65726779", "")
+			alice.ID = "contact_alice"
+			firstList.Contacts[0].Notes = "edited"
+			firstList.Contacts = append(firstList.Contacts, *alice)
+			if err := first.SaveContacts(firstList); err != nil {
+				t.Fatalf("Failed to save contacts: %v", err)
+			}
+			bob := models.NewContact("Bob", "0xd3ae78222beadb038203be21ed5ce7c9b1bff602", "")
+			bob.ID = "contact_bob"
+			secondList.Contacts = append(secondList.Contacts, *bob)
+			if err := second.SaveContacts(secondList); err != nil {
+				t.Fatalf("Failed to save contacts: %v", err)
+			}
+
+			raw, _ := os.ReadFile(filepath.Join(dir, contactsFile))
+			if bytes.Contains(raw, []byte("edited")) {
+				t.Fatalf("Expected merged notes to be sealed:\n%s", raw)
+			}
+
+			merged, err := first.LoadContacts()
+			if err != nil {
+				t.Fatalf("Failed to load contacts: %v", err)
+			}
+			notes := map[string]string{}
+			for _, contact := range merged.Contacts {
+				notes[contact.Name] = contact.Notes
+			}
+			if _, ok := notes["Alice"]; len(merged.Contacts) != 3 || !ok || notes["Shared"] != "edited" {
+				t.Fatalf("Expected Shared (edited), Alice and Bob, got %+v", merged.Contacts)
+			}
+		})
+	}
+}
+
+func TestMergeWithoutProfileKeyIsLocked(t *testing.T) {
+	dir := t.TempDir()
+	store := openFastStorage(t, dir)
+
+	// A process reads the contacts while they are still plaintext
+	other := openFastStorage(t, dir)
+	contacts, err := other.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load contacts: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	alice := models.NewContact("Alice", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "rent")
+	if err := store.SaveContacts(&models.ContactList{Contacts: []models.Contact{*alice}}); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	// The file it would merge with is now encrypted under a key it lacks
+	base, _ := other.lastSeen(contactsFile)
+	ours, _ := json.Marshal(contactsDocument{Version: ContactsVersion, ContactList: contacts})
+	if _, err := other.mergeContacts(base, ours, store.contactsData()); !errors.Is(err, ErrContactsLocked) {
+		t.Errorf("Expected ErrContactsLocked merging without the key, got %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const (
+	DataExportVersion = 1
+
+	exportKindTemplates = "templates"
+	exportKindHistory   = "history"
+)
+
+// DataExport is the file ExportTemplates and ExportHistory write: one
+// wallet's templates or transaction records, in plain JSON or encrypted
+// with age
+type DataExport struct {
+	Version      int                          `json:"version"`
+	Kind         string                       `json:"kind"`
+	ExportedAt   time.Time                    `json:"exported_at"`
+	Templates    []models.TransactionTemplate `json:"templates,omitempty"`
+	Transactions []models.Transaction         `json:"transactions,omitempty"`
+}
+
+// DefaultDataExportPath names a templates or history export in the profile's
+// exports directory
+func (s *Storage) DefaultDataExportPath(kind string) string {
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05Z")
+	return filepath.Join(s.dataDir, exportsDir, fmt.Sprintf("veterm-%s--%s--%s.json", kind, timestamp, s.profile))
+}
+
+// ExportTemplates writes a wallet's transaction templates to path, encrypted
+// with age when opts is enabled. A blank path uses DefaultDataExportPath;
+// existing files are never overwritten. It returns the path written and the
+// number of templates.
+func (s *Storage) ExportTemplates(walletID, path string, opts AgeOptions) (string, int, error) {
+	templates, err := s.LoadTemplates(walletID)
+	if err != nil {
+		return "", 0, err
+	}
+	export := &DataExport{Kind: exportKindTemplates, Templates: templates}
+	path, err = s.writeDataExport(path, export, opts)
+	return path, len(templates), err
+}
+
+// ExportHistory writes a wallet's recorded transactions to path, encrypted
+// with age when opts is enabled. A blank path uses DefaultDataExportPath;
+// existing files are never overwritten. It returns the path written and the
+// number of transactions.
+func (s *Storage) ExportHistory(walletID, path string, opts AgeOptions) (string, int, error) {
+	transactions, err := s.LoadTransactionHistory(walletID)
+	if err != nil {
+		return "", 0, err
+	}
+	export := &DataExport{Kind: exportKindHistory, Transactions: transactions}
+	path, err = s.writeDataExport(path, export, opts)
+	return path, len(transactions), err
+}
+
+// ImportTemplates adds the templates in an export to a wallet, decrypting
+// age files with keys. Templates whose ID the wallet already has are left as
+// they are. It returns how many were added.
+func (s *Storage) ImportTemplates(walletID, path string, keys AgeKeys) (int, error) {
+	export, err := readDataExport(path, keys, exportKindTemplates)
+	if err != nil {
+		return 0, err
+	}
+
+	templates, err := s.LoadTemplates(walletID)
+	if err != nil {
+		return 0, err
+	}
+	existing := make(map[string]bool, len(templates))
+	for _, template := range templates {
+		existing[template.ID] = true
+	}
+
+	added := 0
+	for _, template := range export.Templates {
+		if existing[template.ID] {
+			continue
+		}
+		existing[template.ID] = true
+		templates = append(templates, template)
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	if err := s.SaveTemplates(walletID, templates); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// ImportHistory adds the transactions in an export to a wallet's history,
+// decrypting age files with keys. Records already kept are skipped the way
+// RecordReceived skips them. It returns how many were added.
+func (s *Storage) ImportHistory(walletID, path string, keys AgeKeys) (int, error) {
+	export, err := readDataExport(path, keys, exportKindHistory)
+	if err != nil {
+		return 0, err
+	}
+	return s.addTransactions(walletID, export.Transactions)
+}
+
+func (s *Storage) writeDataExport(path string, export *DataExport, opts AgeOptions) (string, error) {
+	export.Version = DataExportVersion
+	export.ExportedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s export: %w", export.Kind, err)
+	}
+
+	if strings.TrimSpace(path) == "" {
+		path = s.DefaultDataExportPath(export.Kind)
+		if opts.Enabled() {
+			path += ".age"
+		}
+	}
+	if opts.Enabled() {
+		if data, err = EncryptAge(data, opts); err != nil {
+			return "", err
+		}
+	}
+
+	path = expandPath(path)
+	if err := writeExportFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write %s export: %w", export.Kind, err)
+	}
+	return path, nil
+}
+
+// readDataExport reads an export of the given kind, decrypting it first when
+// it is an age file
+func readDataExport(path string, keys AgeKeys, kind string) (*DataExport, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	if IsAgeEncrypted(data) {
+		if data, err = DecryptAge(data, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	var export DataExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal export: %w", err)
+	}
+	if export.Version > DataExportVersion {
+		return nil, fmt.Errorf("export version %d is newer than this build supports", export.Version)
+	}
+	if export.Kind != kind {
+		return nil, fmt.Errorf("file is a %s export, not %s", export.Kind, kind)
+	}
+	return &export, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func TestTemplatesExportPassphrase(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	rent := models.NewTransactionTemplate("Rent", "", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "Alice", "VET", "", big.NewInt(1e18), nil)
+	if err := store.SaveTemplates("wallet-a", []models.TransactionTemplate{*rent}); err != nil {
+		t.Fatalf("Failed to save templates: %v", err)
+	}
+
+	path, count, err := store.ExportTemplates("wallet-a", "", AgeOptions{Passphrase: "correct horse"})
+	if err != nil {
+		t.Fatalf("Failed to export templates: %v", err)
+	}
+	if count != 1 || !strings.HasSuffix(path, ".json.age") {
+		t.Fatalf("Expected one template in a .json.age file, got %d in %s", count, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	if !IsAgeEncrypted(data) || bytes.Contains(data, []byte("Rent")) {
+		t.Fatal("Expected the export to be age encrypted")
+	}
+	if _, _, err := store.ExportTemplates("wallet-a", path, AgeOptions{}); err == nil {
+		t.Error("Expected an existing export not to be overwritten")
+	}
+
+	if _, err := store.ImportTemplates("wallet-b", path, AgeKeys{Passphrase: "wrong"}); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Expected ErrInvalidPassword for the wrong passphrase, got %v", err)
+	}
+	added, err := store.ImportTemplates("wallet-b", path, AgeKeys{Passphrase: "correct horse"})
+	if err != nil || added != 1 {
+		t.Fatalf("Expected one template imported, got %d, %v", added, err)
+	}
+	templates, err := store.LoadTemplates("wallet-b")
+	if err != nil || len(templates) != 1 || templates[0].Amount.Cmp(big.NewInt(1e18)) != 0 {
+		t.Fatalf("Expected the Rent template in wallet-b, got %+v, %v", templates, err)
+	}
+
+	// Importing again adds nothing
+	if added, err := store.ImportTemplates("wallet-b", path, AgeKeys{Passphrase: "correct horse"}); err != nil || added != 0 {
+		t.Errorf("Expected no templates added twice, got %d, %v", added, err)
+	}
+	if _, err := store.ImportHistory("wallet-b", path, AgeKeys{Passphrase: "correct horse"}); err == nil {
+		t.Error("Expected a templates export to be refused as history")
+	}
+}
+
+func TestHistoryExportRecipients(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStorageAt(filepath.Join(dir, "profile"))
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate identity: %v", err)
+	}
+	identityFile := filepath.Join(dir, "key.txt")
+	if err := os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write identity: %v", err)
+	}
+
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	received := models.NewReceivedTransaction("0xabc", 0, "0x0000000000000000000000000000000000000001", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", big.NewInt(5), 10, at)
+	if err := store.SaveTransaction("wallet-a", received); err != nil {
+		t.Fatalf("Failed to save transaction: %v", err)
+	}
+
+	path := filepath.Join(dir, "history.age")
+	if _, count, err := store.ExportHistory("wallet-a", path, AgeOptions{Recipients: []string{identity.Recipient().String()}}); err != nil || count != 1 {
+		t.Fatalf("Expected one transaction exported, got %d, %v", count, err)
+	}
+	if _, err := store.ImportHistory("wallet-a", path, AgeKeys{}); err == nil {
+		t.Error("Expected an age export to need an identity")
+	}
+
+	// The record is already kept, so only the new wallet gains it
+	if added, err := store.ImportHistory("wallet-a", path, AgeKeys{IdentityFile: identityFile}); err != nil || added != 0 {
+		t.Errorf("Expected no duplicate records, got %d, %v", added, err)
+	}
+	if added, err := store.ImportHistory("wallet-b", path, AgeKeys{IdentityFile: identityFile}); err != nil || added != 1 {
+		t.Fatalf("Expected one record imported, got %d, %v", added, err)
+	}
+	history, err := store.LoadTransactionHistory("wallet-b")
+	if err != nil || len(history) != 1 || history[0].ID != received.ID || !history[0].Timestamp.Equal(at) {
+		t.Errorf("Expected the received transfer in wallet-b, got %+v, %v", history, err)
+	}
+}
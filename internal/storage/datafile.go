@@ -0,0 +1,396 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+)
+
+// Data files are shared by every veterm process using the profile. Writes go
+// through writeDataFile, which holds the profile lock, merges in changes
+// another process made since this one last read the file, keeps the old
+// contents as a last-known-good copy and replaces the file atomically.
+
+const (
+	lockFile = ".lock"
+	// lastGoodCopies is how many previous versions of each data file are kept
+	lastGoodCopies = 3
+	lockRetryDelay = 50 * time.Millisecond
+)
+
+// lockTimeout is how long a write waits for another process to finish
+var lockTimeout = 10 * time.Second
+
+// ErrLocked is returned when another process holds the data directory lock
+// for longer than lockTimeout
+var ErrLocked = errors.New("data directory is locked by another veterm process")
+
+// withLock runs fn holding the advisory lock on the data directory
+func (s *Storage) withLock(fn func() error) error {
+	file, err := os.OpenFile(filepath.Join(s.dataDir, lockFile), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer file.Close()
+
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to lock data directory: %w", err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			return ErrLocked
+		}
+		time.Sleep(lockRetryDelay)
+	}
+	defer unlockFile(file)
+
+	return fn()
+}
+
+// remember records data as the contents of name this process last read or
+// wrote; nil means the file did not exist
+func (s *Storage) remember(name string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen == nil {
+		s.seen = make(map[string][]byte)
+	}
+	s.seen[name] = data
+}
+
+func (s *Storage) lastSeen(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.seen[name]
+	return data, ok
+}
+
+// writeDataFile saves doc as name. If the file changed on disk since this
+// process last saw it, both sides' changes are merged rather than the other
+// process's being overwritten.
+func (s *Storage) writeDataFile(name string, doc interface{}) error {
+	return s.writeDataFileWith(name, doc, func(base, ours, theirs []byte) ([]byte, error) {
+		return mergeDataFile(name, base, ours, theirs, doc)
+	})
+}
+
+// writeDataFileWith is writeDataFile with the merge supplied, for files whose
+// stored form cannot be merged as it is. merge runs under the profile lock.
+func (s *Storage) writeDataFileWith(name string, doc interface{}, merge func(base, ours, theirs []byte) ([]byte, error)) error {
+	ours, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	filePath := filepath.Join(s.dataDir, name)
+
+	return s.withLock(func() error {
+		current, err := os.ReadFile(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		data := ours
+		if base, ok := s.lastSeen(name); ok && current != nil && !bytes.Equal(base, current) {
+			if data, err = merge(base, ours, current); err != nil {
+				return err
+			}
+		}
+
+		if current != nil {
+			if err := s.keepLastGood(name, current); err != nil {
+				return err
+			}
+		}
+		if err := writeFileAtomic(filePath, data, 0600); err != nil {
+			return err
+		}
+
+		// Later writes merge against what the caller saved, so changes the
+		// other process made still count as theirs until the file is reloaded
+		s.remember(name, ours)
+		return nil
+	})
+}
+
+// updateDataFile reads name into doc, runs update and writes doc back, all
+// under the profile lock, so a check update makes against the file cannot be
+// raced by another process. Nothing is written unless update reports a
+// change.
+func (s *Storage) updateDataFile(name string, doc interface{}, update func() (bool, error)) error {
+	filePath := filepath.Join(s.dataDir, name)
+
+	return s.withLock(func() error {
+		current, err := os.ReadFile(filePath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if current != nil {
+			data, err := upgradeData(name, current)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, doc); err != nil {
+				return fmt.Errorf("failed to unmarshal %s: %w", name, err)
+			}
+		}
+
+		changed, err := update()
+		if err != nil || !changed {
+			return err
+		}
+
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		if current != nil {
+			if err := s.keepLastGood(name, current); err != nil {
+				return err
+			}
+		}
+		// The change is not remembered as this process's own, so copies of
+		// the file loaded before it merge it in as another process's change
+		return writeFileAtomic(filePath, data, 0600)
+	})
+}
+
+// lastGoodPath is the nth most recent previous version of name
+func (s *Storage) lastGoodPath(name string, n int) string {
+	return filepath.Join(s.dataDir, backupsDir, fmt.Sprintf("%s.good.%d", name, n))
+}
+
+// keepLastGood rotates the last-known-good copies of name, making data the
+// newest. Contents that are not valid JSON are never kept.
+func (s *Storage) keepLastGood(name string, data []byte) error {
+	if !json.Valid(data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Join(s.dataDir, backupsDir), 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	for n := lastGoodCopies - 1; n >= 1; n-- {
+		err := os.Rename(s.lastGoodPath(name, n), s.lastGoodPath(name, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate copies of %s: %w", name, err)
+		}
+	}
+	if err := writeFileAtomic(s.lastGoodPath(name, 1), data, 0600); err != nil {
+		return fmt.Errorf("failed to keep a copy of %s: %w", name, err)
+	}
+	return nil
+}
+
+// recoverLastGood replaces a damaged data file with its newest readable
+// last-known-good copy. The damaged file is kept in the backups directory.
+func (s *Storage) recoverLastGood(name string) ([]byte, error) {
+	var recovered []byte
+	err := s.withLock(func() error {
+		filePath := filepath.Join(s.dataDir, name)
+		damaged, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		for n := 1; n <= lastGoodCopies; n++ {
+			data, err := os.ReadFile(s.lastGoodPath(name, n))
+			if err != nil {
+				continue
+			}
+			if _, _, err := decodeDocument(data); err != nil {
+				continue
+			}
+
+			damagedName := fmt.Sprintf("%s.damaged.%s", name, time.Now().Format("20060102-150405"))
+			if err := os.WriteFile(filepath.Join(s.dataDir, backupsDir, damagedName), damaged, 0600); err != nil {
+				return fmt.Errorf("failed to keep damaged %s: %w", name, err)
+			}
+			if err := writeFileAtomic(filePath, data, 0600); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", name, err)
+			}
+			recovered = data
+			return nil
+		}
+		return fmt.Errorf("no readable copy of %s", name)
+	})
+	return recovered, err
+}
+
+// mergeDataFile combines the changes this process made to base (ours) with
+// those another process wrote to disk (theirs), re-encoding the result
+// through doc's type so the file keeps its usual layout
+func mergeDataFile(name string, base, ours, theirs []byte, doc interface{}) ([]byte, error) {
+	theirs, err := upgradeData(name, theirs)
+	if errors.Is(err, ErrNewerVersion) {
+		return nil, fmt.Errorf("%s was changed by another process: %w", name, err)
+	}
+	if err != nil {
+		// The file on disk is damaged; ours is all there is to keep
+		return ours, nil
+	}
+
+	var baseValue, oursValue, theirsValue interface{} = map[string]interface{}{}, nil, nil
+	if base != nil {
+		if baseValue, err = decodeJSON(base); err != nil {
+			baseValue = map[string]interface{}{}
+		}
+	}
+	if oursValue, err = decodeJSON(ours); err != nil {
+		return nil, err
+	}
+	if theirsValue, err = decodeJSON(theirs); err != nil {
+		return ours, nil
+	}
+
+	merged, err := json.Marshal(merge3(baseValue, oursValue, theirsValue))
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+	}
+
+	typed := reflect.New(reflect.TypeOf(doc).Elem()).Interface()
+	if err := json.Unmarshal(merged, typed); err != nil {
+		return nil, fmt.Errorf("failed to merge %s: %w", name, err)
+	}
+	return json.MarshalIndent(typed, "", "  ")
+}
+
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// absent stands in for a key or record missing from one side of a merge
+type absentValue struct{}
+
+var absent interface{} = absentValue{}
+
+// merge3 combines the changes ours and theirs each made to base. Objects are
+// merged key by key and lists of records by ID. Records are never mixed
+// field by field: one changed on both sides, like any other conflicting
+// value, keeps ours.
+func merge3(base, ours, theirs interface{}) interface{} {
+	switch {
+	case reflect.DeepEqual(ours, base):
+		return theirs
+	case reflect.DeepEqual(theirs, base), reflect.DeepEqual(ours, theirs):
+		return ours
+	}
+
+	oursObject, oursOK := ours.(map[string]interface{})
+	theirsObject, theirsOK := theirs.(map[string]interface{})
+	if oursOK && theirsOK {
+		baseObject, _ := base.(map[string]interface{})
+		return mergeObjects(baseObject, oursObject, theirsObject)
+	}
+
+	oursList, oursOK := ours.([]interface{})
+	theirsList, theirsOK := theirs.([]interface{})
+	if oursOK && theirsOK && isRecordList(oursList) && isRecordList(theirsList) {
+		baseList, _ := base.([]interface{})
+		return mergeRecordLists(baseList, oursList, theirsList)
+	}
+
+	return ours
+}
+
+func mergeObjects(base, ours, theirs map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(ours))
+	keys := make(map[string]bool, len(ours)+len(theirs))
+	for key := range ours {
+		keys[key] = true
+	}
+	for key := range theirs {
+		keys[key] = true
+	}
+
+	for key := range keys {
+		if value := merge3(lookup(base, key), lookup(ours, key), lookup(theirs, key)); value != absent {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+func lookup(object map[string]interface{}, key string) interface{} {
+	if value, ok := object[key]; ok {
+		return value
+	}
+	return absent
+}
+
+// mergeRecordLists merges lists of objects keyed by their "id". The result
+// follows theirs' order with records only ours has appended.
+func mergeRecordLists(base, ours, theirs []interface{}) []interface{} {
+	baseByID := recordsByID(base)
+	oursByID := recordsByID(ours)
+	theirsByID := recordsByID(theirs)
+
+	merged := make([]interface{}, 0, len(theirs)+len(ours))
+	for _, record := range theirs {
+		id := recordID(record)
+		if value := pickRecord(lookup(baseByID, id), lookup(oursByID, id), record); value != absent {
+			merged = append(merged, value)
+		}
+	}
+	for _, record := range ours {
+		id := recordID(record)
+		if _, ok := theirsByID[id]; ok {
+			continue
+		}
+		if value := pickRecord(lookup(baseByID, id), record, absent); value != absent {
+			merged = append(merged, value)
+		}
+	}
+	return merged
+}
+
+// pickRecord keeps whichever side changed a record, or ours when both did
+func pickRecord(base, ours, theirs interface{}) interface{} {
+	if reflect.DeepEqual(ours, base) {
+		return theirs
+	}
+	return ours
+}
+
+func recordsByID(records []interface{}) map[string]interface{} {
+	byID := make(map[string]interface{}, len(records))
+	for _, record := range records {
+		byID[recordID(record)] = record
+	}
+	return byID
+}
+
+func recordID(record interface{}) string {
+	object, _ := record.(map[string]interface{})
+	id, _ := object["id"].(string)
+	return id
+}
+
+// isRecordList reports whether every item is an object with a unique ID
+func isRecordList(items []interface{}) bool {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		id := recordID(item)
+		if id == "" || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
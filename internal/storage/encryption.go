@@ -4,33 +4,52 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"errors"
+	"fmt"
 	"io"
-
-	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
 	keyLength   = 32
 	nonceLength = 12
 	saltLength  = 32
-	iterations  = 100000
 )
 
+// EnvelopeVersion is the encryption envelope format written by this build.
+// Version 1 envelopes have no version or KDF field and used PBKDF2.
+const EnvelopeVersion = 2
+
+// ErrInvalidPassword is returned when data cannot be decrypted with the
+// given password
+var ErrInvalidPassword = errors.New("invalid password or corrupted data")
+
+// EncryptedData is an AES-256-GCM envelope whose key is derived from a
+// password by the recorded KDF
 type EncryptedData struct {
-	Salt       []byte `json:"salt"`
-	Nonce      []byte `json:"nonce"`
-	Ciphertext []byte `json:"ciphertext"`
+	Version    int        `json:"version,omitempty"`
+	KDF        *KDFParams `json:"kdf,omitempty"`
+	Salt       []byte     `json:"salt"`
+	Nonce      []byte     `json:"nonce"`
+	Ciphertext []byte     `json:"ciphertext"`
 }
 
+// Encrypt seals data under password with the default KDF parameters
 func Encrypt(data []byte, password string) (*EncryptedData, error) {
+	return EncryptWithKDF(data, password, DefaultKDFParams())
+}
+
+// EncryptWithKDF seals data under password, deriving the key with params and
+// a fresh salt
+func EncryptWithKDF(data []byte, password string, params KDFParams) (*EncryptedData, error) {
 	salt := make([]byte, saltLength)
 	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
 		return nil, err
 	}
 
-	key := pbkdf2.Key([]byte(password), salt, iterations, keyLength, sha256.New)
+	key, err := params.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -50,6 +69,8 @@ func Encrypt(data []byte, password string) (*EncryptedData, error) {
 	ciphertext := aesGCM.Seal(nil, nonce, data, nil)
 
 	return &EncryptedData{
+		Version:    EnvelopeVersion,
+		KDF:        &params,
 		Salt:       salt,
 		Nonce:      nonce,
 		Ciphertext: ciphertext,
@@ -60,8 +81,14 @@ func Decrypt(encData *EncryptedData, password string) ([]byte, error) {
 	if encData == nil {
 		return nil, errors.New("encrypted data is nil")
 	}
+	if encData.Version > EnvelopeVersion {
+		return nil, fmt.Errorf("encryption envelope version %d is newer than this build supports", encData.Version)
+	}
 
-	key := pbkdf2.Key([]byte(password), encData.Salt, iterations, keyLength, sha256.New)
+	key, err := encData.kdfParams().deriveKey(password, encData.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption envelope: %w", err)
+	}
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
@@ -75,12 +102,27 @@ func Decrypt(encData *EncryptedData, password string) ([]byte, error) {
 
 	plaintext, err := aesGCM.Open(nil, encData.Nonce, encData.Ciphertext, nil)
 	if err != nil {
-		return nil, errors.New("invalid password or corrupted data")
+		return nil, ErrInvalidPassword
 	}
 
 	return plaintext, nil
 }
 
+// kdfParams returns the envelope's KDF, falling back to the fixed PBKDF2
+// cost of version 1 envelopes
+func (e *EncryptedData) kdfParams() KDFParams {
+	if e.KDF == nil {
+		return legacyKDFParams()
+	}
+	return *e.KDF
+}
+
+// UsesKDF reports whether the envelope is current and was sealed with
+// exactly params; anything else is re-encrypted on the next unlock
+func (e *EncryptedData) UsesKDF(params KDFParams) bool {
+	return e != nil && e.Version == EnvelopeVersion && e.KDF != nil && *e.KDF == params
+}
+
 func ValidatePassword(encData *EncryptedData, password string) bool {
 	_, err := Decrypt(encData, password)
 	return err == nil
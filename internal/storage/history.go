@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const historyFile = "history.json"
+
+type HistoryStorage struct {
+	Version int                             `json:"version"`
+	Wallets map[string][]models.Transaction `json:"wallets"`
+}
+
+// SaveTransaction records a transaction in the wallet's history, replacing any
+// existing entry with the same ID or hash.
+func (s *Storage) SaveTransaction(walletID string, tx *models.Transaction) error {
+	history, err := s.loadHistoryStorage()
+	if err != nil {
+		return err
+	}
+
+	transactions := history.Wallets[walletID]
+	for i, existing := range transactions {
+		if existing.ID == tx.ID || (tx.Hash != "" && strings.EqualFold(existing.Hash, tx.Hash)) {
+			transactions[i] = *tx
+			history.Wallets[walletID] = transactions
+			return s.saveHistoryStorage(history)
+		}
+	}
+
+	history.Wallets[walletID] = append(transactions, *tx)
+	return s.saveHistoryStorage(history)
+}
+
+// RecordReceived adds transfers the wallet received to its history, skipping
+// any already recorded under the same ID, or under the same hash and
+// recipient, such as a transfer between the wallet's own accounts. History
+// is kept in time order. It returns how many were added.
+func (s *Storage) RecordReceived(walletID string, received []models.Transaction) (int, error) {
+	return s.addTransactions(walletID, received)
+}
+
+// addTransactions adds records to the wallet's history, skipping any already
+// kept under the same ID or the same hash and recipient, and keeps the
+// history in time order
+func (s *Storage) addTransactions(walletID string, records []models.Transaction) (int, error) {
+	history, err := s.loadHistoryStorage()
+	if err != nil {
+		return 0, err
+	}
+
+	transactions := history.Wallets[walletID]
+	added := 0
+	for _, tx := range records {
+		recorded := false
+		for _, existing := range transactions {
+			if existing.ID == tx.ID || (tx.Hash != "" && strings.EqualFold(existing.Hash, tx.Hash) && strings.EqualFold(existing.To, tx.To)) {
+				recorded = true
+				break
+			}
+		}
+		if !recorded {
+			transactions = append(transactions, tx)
+			added++
+		}
+	}
+	if added == 0 {
+		return 0, nil
+	}
+
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Timestamp.Before(transactions[j].Timestamp)
+	})
+	history.Wallets[walletID] = transactions
+	if err := s.saveHistoryStorage(history); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// LoadTransactionHistory returns the recorded transactions for a wallet
+func (s *Storage) LoadTransactionHistory(walletID string) ([]models.Transaction, error) {
+	history, err := s.loadHistoryStorage()
+	if err != nil {
+		return nil, err
+	}
+	return history.Wallets[walletID], nil
+}
+
+// LoadAccountHistory returns the wallet's recorded transactions sent from or
+// to address. Accounts derived from one seed share a wallet ID, so this is
+// each account's own history.
+func (s *Storage) LoadAccountHistory(walletID, address string) ([]models.Transaction, error) {
+	transactions, err := s.LoadTransactionHistory(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var account []models.Transaction
+	for _, tx := range transactions {
+		if strings.EqualFold(tx.From, address) || strings.EqualFold(tx.To, address) {
+			account = append(account, tx)
+		}
+	}
+	return account, nil
+}
+
+// AddressHasHistory reports whether any recorded transaction was sent from or
+// to address
+func (s *Storage) AddressHasHistory(address string) (bool, error) {
+	history, err := s.loadHistoryStorage()
+	if err != nil {
+		return false, err
+	}
+
+	for _, transactions := range history.Wallets {
+		for _, tx := range transactions {
+			if strings.EqualFold(tx.From, address) || strings.EqualFold(tx.To, address) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (s *Storage) loadHistoryStorage() (*HistoryStorage, error) {
+	data, err := s.readVersionedFile(historyFile)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return &HistoryStorage{Version: HistoryVersion, Wallets: make(map[string][]models.Transaction)}, nil
+	}
+
+	var history HistoryStorage
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal history: %w", err)
+	}
+	if history.Wallets == nil {
+		history.Wallets = make(map[string][]models.Transaction)
+	}
+
+	return &history, nil
+}
+
+func (s *Storage) saveHistoryStorage(history *HistoryStorage) error {
+	history.Version = HistoryVersion
+	if err := s.writeDataFile(historyFile, history); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"runtime"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Key derivation functions recorded in an encryption envelope
+const (
+	KDFArgon2id = "argon2id"
+	KDFPBKDF2   = "pbkdf2-sha256"
+)
+
+// Argon2id cost defaults and limits. Memory is in KiB; the defaults follow
+// the second recommended option of RFC 9106.
+const (
+	DefaultKDFTime    = 3
+	DefaultKDFMemory  = 64 * 1024
+	DefaultKDFThreads = 4
+
+	MinKDFMemory  = 8 * 1024
+	MaxKDFMemory  = 4 * 1024 * 1024
+	MaxKDFTime    = 100
+	MaxKDFThreads = 64
+)
+
+// UnlockKeyDerivations is how many keys unlocking a wallet derives from its
+// password: the wallet data and its keystore are sealed separately, each
+// with its own salt
+const UnlockKeyDerivations = 2
+
+// legacyIterations is the PBKDF2 cost of data written before envelopes
+// recorded their KDF
+const legacyIterations = 100000
+
+// KDFParams names the key derivation function used for an envelope and its
+// cost. Only the fields of the named function are set.
+type KDFParams struct {
+	Name string `json:"name"`
+
+	// Argon2id
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory_kib,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+
+	// PBKDF2
+	Iterations int `json:"iterations,omitempty"`
+}
+
+// DefaultKDFParams returns the Argon2id parameters used when none have been
+// configured or calibrated
+func DefaultKDFParams() KDFParams {
+	return Argon2idParams(DefaultKDFTime, DefaultKDFMemory, DefaultKDFThreads)
+}
+
+// Argon2idParams returns Argon2id parameters with the given passes, memory
+// in KiB and parallelism
+func Argon2idParams(time, memory, threads int) KDFParams {
+	return KDFParams{Name: KDFArgon2id, Time: uint32(time), Memory: uint32(memory), Threads: uint8(threads)}
+}
+
+// legacyKDFParams describes envelopes written before the KDF was recorded
+func legacyKDFParams() KDFParams {
+	return KDFParams{Name: KDFPBKDF2, Iterations: legacyIterations}
+}
+
+// Validate checks the parameters are ones this build will derive keys with.
+// Limits also stop a tampered file from demanding unbounded memory.
+func (p KDFParams) Validate() error {
+	switch p.Name {
+	case KDFArgon2id:
+		if p.Time < 1 || p.Time > MaxKDFTime {
+			return fmt.Errorf("argon2id time must be between 1 and %d, got %d", MaxKDFTime, p.Time)
+		}
+		if p.Memory < MinKDFMemory || p.Memory > MaxKDFMemory {
+			return fmt.Errorf("argon2id memory must be between %d and %d KiB, got %d", MinKDFMemory, MaxKDFMemory, p.Memory)
+		}
+		if p.Threads < 1 || p.Threads > MaxKDFThreads {
+			return fmt.Errorf("argon2id threads must be between 1 and %d, got %d", MaxKDFThreads, p.Threads)
+		}
+	case KDFPBKDF2:
+		if p.Iterations < 1000 || p.Iterations > 10000000 {
+			return fmt.Errorf("pbkdf2 iterations must be between 1000 and 10000000, got %d", p.Iterations)
+		}
+	default:
+		return fmt.Errorf("unsupported key derivation function: %q", p.Name)
+	}
+	return nil
+}
+
+// String describes the parameters for display
+func (p KDFParams) String() string {
+	switch p.Name {
+	case KDFArgon2id:
+		return fmt.Sprintf("argon2id (time %d, memory %d MiB, threads %d)", p.Time, p.Memory/1024, p.Threads)
+	case KDFPBKDF2:
+		return fmt.Sprintf("pbkdf2-sha256 (%d iterations)", p.Iterations)
+	}
+	return p.Name
+}
+
+func (p KDFParams) deriveKey(password string, salt []byte) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	switch p.Name {
+	case KDFArgon2id:
+		return argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, keyLength), nil
+	default:
+		return pbkdf2.Key([]byte(password), salt, p.Iterations, keyLength, sha256.New), nil
+	}
+}
+
+// CalibrateKDF picks Argon2id parameters that take about target to unlock a
+// wallet on this machine, so each of the UnlockKeyDerivations keys gets its
+// share. Memory starts at the default and is only reduced when a single pass
+// already exceeds that share; passes are then added to fill it.
+func CalibrateKDF(target time.Duration) KDFParams {
+	target /= UnlockKeyDerivations
+	threads := min(runtime.NumCPU(), DefaultKDFThreads)
+	params := Argon2idParams(1, DefaultKDFMemory, threads)
+	salt := make([]byte, saltLength)
+
+	measure := func() time.Duration {
+		start := time.Now()
+		params.deriveKey("calibration", salt)
+		return time.Since(start)
+	}
+
+	elapsed := measure()
+	for elapsed > target && params.Memory/2 >= MinKDFMemory {
+		params.Memory /= 2
+		elapsed = measure()
+	}
+
+	if elapsed > 0 && elapsed < target {
+		passes := int(target / elapsed)
+		params.Time = uint32(max(1, min(passes, MaxKDFTime)))
+	}
+	return params
+}
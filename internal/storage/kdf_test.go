@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+// legacyEnvelope seals data the way builds before envelope versions did
+func legacyEnvelope(t *testing.T, data []byte, password string) *EncryptedData {
+	t.Helper()
+	encrypted, err := EncryptWithKDF(data, password, legacyKDFParams())
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	encrypted.Version = 0
+	encrypted.KDF = nil
+	return encrypted
+}
+
+func TestEncryptionEnvelope(t *testing.T) {
+	fast := Argon2idParams(1, MinKDFMemory, 1)
+	encrypted, err := EncryptWithKDF([]byte("secret"), testPassword, fast)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if encrypted.Version != EnvelopeVersion || encrypted.KDF == nil || *encrypted.KDF != fast {
+		t.Fatalf("Envelope should record version and KDF, got version %d kdf %v", encrypted.Version, encrypted.KDF)
+	}
+	if !encrypted.UsesKDF(fast) || encrypted.UsesKDF(DefaultKDFParams()) {
+		t.Error("UsesKDF should match only the sealing parameters")
+	}
+
+	plaintext, err := Decrypt(encrypted, testPassword)
+	if err != nil || string(plaintext) != "secret" {
+		t.Fatalf("Failed to decrypt: %q, %v", plaintext, err)
+	}
+	if _, err := Decrypt(encrypted, "wrong-password"); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Expected ErrInvalidPassword, got %v", err)
+	}
+
+	legacy := legacyEnvelope(t, []byte("old secret"), testPassword)
+	if plaintext, err := Decrypt(legacy, testPassword); err != nil || string(plaintext) != "old secret" {
+		t.Errorf("Failed to decrypt legacy envelope: %q, %v", plaintext, err)
+	}
+
+	// A tampered envelope must not be able to demand unbounded memory
+	encrypted.KDF.Memory = MaxKDFMemory * 2
+	if _, err := Decrypt(encrypted, testPassword); err == nil || errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Expected out-of-range parameters to be rejected, got %v", err)
+	}
+
+	encrypted.Version = EnvelopeVersion + 1
+	if _, err := Decrypt(encrypted, testPassword); err == nil {
+		t.Error("Expected error for a newer envelope version")
+	}
+}
+
+func TestLoadWalletUpgradesEnvelopes(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	// Rewrite the record as an older build would have left it
+	storage, err := store.loadWalletStorage()
+	if err != nil {
+		t.Fatalf("Failed to load wallet storage: %v", err)
+	}
+	for _, data := range []**EncryptedData{&storage.Wallets[0].Data, &storage.Wallets[0].Keystore.Data} {
+		plaintext, err := Decrypt(*data, testPassword)
+		if err != nil {
+			t.Fatalf("Failed to decrypt: %v", err)
+		}
+		*data = legacyEnvelope(t, plaintext, testPassword)
+	}
+	if err := store.saveWalletStorage(storage); err != nil {
+		t.Fatalf("Failed to save wallet storage: %v", err)
+	}
+
+	fast := Argon2idParams(1, MinKDFMemory, 1)
+	if err := store.SetKDFParams(fast); err != nil {
+		t.Fatalf("Failed to set KDF parameters: %v", err)
+	}
+	if _, err := store.LoadWallet(wallet.ID, "wrong-password"); err == nil {
+		t.Fatal("Expected error for wrong password")
+	}
+	wallets, _ := store.ListWallets()
+	if wallets[0].Data.KDF != nil {
+		t.Fatal("A failed unlock must not upgrade the wallet")
+	}
+
+	loaded, err := store.LoadWallet(wallet.ID, testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load legacy wallet: %v", err)
+	}
+	if loaded.Mnemonic != testMnemonic {
+		t.Error("Legacy wallet lost its mnemonic")
+	}
+
+	wallets, _ = store.ListWallets()
+	if !wallets[0].Data.UsesKDF(fast) || !wallets[0].Keystore.Data.UsesKDF(fast) {
+		t.Fatal("Expected the wallet to be re-encrypted with the configured KDF")
+	}
+	if _, err := store.LoadWallet(wallet.ID, testPassword); err != nil {
+		t.Fatalf("Failed to load upgraded wallet: %v", err)
+	}
+}
+
+func TestKDFParamsValidate(t *testing.T) {
+	valid := []KDFParams{DefaultKDFParams(), legacyKDFParams(), Argon2idParams(1, MinKDFMemory, 1)}
+	for _, params := range valid {
+		if err := params.Validate(); err != nil {
+			t.Errorf("Expected %s to be valid, got %v", params, err)
+		}
+	}
+
+	invalid := []KDFParams{
+		{Name: "md5"},
+		Argon2idParams(0, DefaultKDFMemory, 1),
+		Argon2idParams(1, MinKDFMemory-1, 1),
+		Argon2idParams(1, DefaultKDFMemory, 0),
+		{Name: KDFPBKDF2, Iterations: 10},
+	}
+	for _, params := range invalid {
+		if err := params.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", params)
+		}
+	}
+}
+
+func TestCalibrateKDF(t *testing.T) {
+	params := CalibrateKDF(time.Millisecond)
+	if err := params.Validate(); err != nil {
+		t.Fatalf("Calibrated parameters are invalid: %v", err)
+	}
+	if params.Time != 1 {
+		t.Errorf("Expected a single pass for a 1ms target, got %d", params.Time)
+	}
+}
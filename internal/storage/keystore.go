@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const (
+	KeystoreMnemonic   = "mnemonic"
+	KeystorePrivateKey = "private_key"
+
+	// DefaultDerivationPath is the first VeChain account
+	DefaultDerivationPath = "m/44'/818'/0'/0/0"
+)
+
+var (
+	// ErrKeystoreMissing marks wallets saved before the signing secret was
+	// persisted. They must be re-imported from their recovery phrase.
+	ErrKeystoreMissing = errors.New("wallet has no stored signing key; re-import it from its recovery phrase")
+
+	// ErrAddressMismatch means the decrypted secret does not derive the
+	// wallet's recorded address, so the record must not be used for signing
+	ErrAddressMismatch = errors.New("keystore does not match wallet address")
+
+	// ErrTwoFactorRemoved means the sealed secret holds a TOTP secret but the
+	// wallet record lists no second factor. The record is not authenticated,
+	// so this is refused rather than unlocking without a code.
+	ErrTwoFactorRemoved = errors.New("keystore holds a second factor that is missing from the wallet record")
+)
+
+// Keystore holds a wallet's encrypted signing secret. Address is the address
+// the secret must derive to and is checked on every unlock. TwoFactor is set
+// when the wallet has a TOTP second factor; its secret is sealed in Data with
+// the signing secret, and only the recovery code hashes are in the clear.
+type Keystore struct {
+	Type      string            `json:"type"`
+	Path      string            `json:"path,omitempty"`
+	Address   string            `json:"address"`
+	Data      *EncryptedData    `json:"data"`
+	TwoFactor *models.TwoFactor `json:"two_factor,omitempty"`
+}
+
+type keystoreSecret struct {
+	Mnemonic string `json:"mnemonic,omitempty"`
+	// Passphrase is the optional BIP39 passphrase protecting the seed
+	Passphrase string `json:"passphrase,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+	TOTPSecret string `json:"totp_secret,omitempty"`
+}
+
+// newKeystore encrypts the wallet's mnemonic and passphrase, or its private
+// key when there is no mnemonic, deriving the key with kdf
+func newKeystore(wallet *models.Wallet, password string, kdf KDFParams) (*Keystore, error) {
+	keystore := &Keystore{Address: wallet.Address}
+
+	var secret keystoreSecret
+	switch {
+	case wallet.Mnemonic != "":
+		keystore.Type = KeystoreMnemonic
+		keystore.Path = DefaultDerivationPath
+		secret.Mnemonic = wallet.Mnemonic
+		secret.Passphrase = wallet.Passphrase
+	case wallet.PrivateKey != nil:
+		keystore.Type = KeystorePrivateKey
+		secret.PrivateKey = hex.EncodeToString(crypto.FromECDSA(wallet.PrivateKey))
+	default:
+		return nil, ErrKeystoreMissing
+	}
+
+	// Never persist a secret that does not produce the wallet's address
+	if _, err := keystore.derive(secret); err != nil {
+		return nil, err
+	}
+
+	if wallet.TwoFactor != nil {
+		secret.TOTPSecret = wallet.TwoFactor.Secret
+		keystore.TwoFactor = copyTwoFactor(wallet.TwoFactor)
+	}
+
+	data, err := json.Marshal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal keystore secret: %w", err)
+	}
+
+	keystore.Data, err = EncryptWithKDF(data, password, kdf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt keystore: %w", err)
+	}
+
+	return keystore, nil
+}
+
+// unlock decrypts the secret, re-derives the address and loads the key
+// material into wallet
+func (k *Keystore) unlock(wallet *models.Wallet, password string) error {
+	data, err := Decrypt(k.Data, password)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+
+	var secret keystoreSecret
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return fmt.Errorf("failed to unmarshal keystore secret: %w", err)
+	}
+
+	privateKey, err := k.derive(secret)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(k.Address, wallet.Address) {
+		return fmt.Errorf("%w: keystore is for %s but wallet is %s", ErrAddressMismatch, k.Address, wallet.Address)
+	}
+
+	if k.TwoFactor == nil && secret.TOTPSecret != "" {
+		return ErrTwoFactorRemoved
+	}
+	wallet.TwoFactor = nil
+	if k.TwoFactor != nil {
+		if secret.TOTPSecret == "" {
+			return fmt.Errorf("keystore lists a second factor but holds no TOTP secret")
+		}
+		wallet.TwoFactor = copyTwoFactor(k.TwoFactor)
+		wallet.TwoFactor.Secret = secret.TOTPSecret
+	}
+
+	wallet.Mnemonic = secret.Mnemonic
+	wallet.Passphrase = secret.Passphrase
+	wallet.PrivateKey = privateKey
+	return nil
+}
+
+// copyTwoFactor copies a second factor so the keystore and an unlocked wallet
+// never share a recovery code list
+func copyTwoFactor(twoFactor *models.TwoFactor) *models.TwoFactor {
+	copied := *twoFactor
+	copied.RecoveryCodes = append([]string(nil), twoFactor.RecoveryCodes...)
+	return &copied
+}
+
+func (k *Keystore) derive(secret keystoreSecret) (*ecdsa.PrivateKey, error) {
+	var privateKey *ecdsa.PrivateKey
+
+	switch k.Type {
+	case KeystoreMnemonic:
+		var err error
+		privateKey, _, err = models.DeriveAccount(secret.Mnemonic, secret.Passphrase, k.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key from mnemonic: %w", err)
+		}
+	case KeystorePrivateKey:
+		var err error
+		privateKey, err = crypto.HexToECDSA(secret.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stored private key: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown keystore type: %s", k.Type)
+	}
+
+	derived := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	if !strings.EqualFold(derived, k.Address) {
+		return nil, fmt.Errorf("%w: expected %s, derived %s", ErrAddressMismatch, k.Address, derived)
+	}
+
+	return privateKey, nil
+}
+
+// source is the key source implied by the stored secret, used for records
+// written before wallets were tagged
+func (k *Keystore) source() models.KeySource {
+	if k.Type == KeystorePrivateKey {
+		return models.KeySourcePrivateKey
+	}
+	return models.KeySourceMnemonic
+}
+
+// HasKeystore reports whether the wallet can be unlocked for signing
+func (w EncryptedWallet) HasKeystore() bool {
+	return w.Keystore != nil
+}
+
+// HasTwoFactor reports whether signing and other sensitive actions need a
+// TOTP code as well as the password
+func (w EncryptedWallet) HasTwoFactor() bool {
+	return w.Keystore != nil && w.Keystore.TwoFactor != nil
+}
+
+// ReimportWallet attaches key material to an existing wallet that was saved
+// without it. The recovered address must match the stored one; the record
+// keeps its ID and creation time and is re-encrypted with password.
+func (s *Storage) ReimportWallet(id string, wallet *models.Wallet, password string) error {
+	storage, err := s.loadWalletStorage()
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range storage.Wallets {
+		if existing.ID != id {
+			continue
+		}
+		if existing.IsWatchOnly() {
+			return fmt.Errorf("wallet %s is watch-only and cannot hold a key", existing.Name)
+		}
+		if existing.HasKeystore() {
+			return fmt.Errorf("wallet %s already has a stored key", existing.Name)
+		}
+		if !strings.EqualFold(existing.Address, wallet.Address) {
+			return fmt.Errorf("%w: wallet is %s but recovery phrase gives %s", ErrAddressMismatch, existing.Address, wallet.Address)
+		}
+
+		wallet.ID = existing.ID
+		if wallet.Name == "" {
+			wallet.Name = existing.Name
+		}
+		if createdAt, err := time.Parse(time.RFC3339, existing.CreatedAt); err == nil {
+			wallet.CreatedAt = createdAt
+		}
+		wallet.Address = existing.Address
+		return s.SaveWallet(wallet, password)
+	}
+
+	return fmt.Errorf("wallet not found")
+}
@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const (
+	testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	testPassword = "correct-horse-battery"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(store.DataDir(), walletsFile))
+	if strings.Contains(string(data), "abandon") {
+		t.Fatal("Mnemonic must not be stored in plaintext")
+	}
+
+	loaded, err := store.LoadWallet(wallet.ID, testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	if loaded.PrivateKey == nil || loaded.Mnemonic != testMnemonic {
+		t.Fatal("Loaded wallet should carry its key material")
+	}
+	if got := crypto.PubkeyToAddress(loaded.PrivateKey.PublicKey).Hex(); got != wallet.Address {
+		t.Errorf("Loaded key derives %s, expected %s", got, wallet.Address)
+	}
+
+	if _, err := store.LoadWallet(wallet.ID, "wrong-password"); err == nil {
+		t.Error("Expected error for wrong password")
+	}
+
+	// Saving metadata alone keeps the stored key
+	loaded.Name = "Renamed"
+	loaded.Mnemonic = ""
+	loaded.PrivateKey = nil
+	if err := store.SaveWallet(loaded, testPassword); err != nil {
+		t.Fatalf("Failed to re-save wallet: %v", err)
+	}
+	if again, err := store.LoadWallet(wallet.ID, testPassword); err != nil || again.PrivateKey == nil {
+		t.Fatalf("Keystore should survive a metadata save: %v", err)
+	}
+}
+
+func TestKeystoreAddressMismatch(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	wrongKey, _ := crypto.GenerateKey()
+	tampered := *wallet
+	tampered.Mnemonic = ""
+	tampered.PrivateKey = wrongKey
+	if err := store.SaveWallet(&tampered, testPassword); !errors.Is(err, ErrAddressMismatch) {
+		t.Fatalf("Expected ErrAddressMismatch when saving, got %v", err)
+	}
+
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	// Point the record at a different address, as a corrupted file would
+	walletStorage, _ := store.loadWalletStorage()
+	walletStorage.Wallets[0].Keystore.Address = crypto.PubkeyToAddress(wrongKey.PublicKey).Hex()
+	if err := store.saveWalletStorage(walletStorage); err != nil {
+		t.Fatalf("Failed to save wallet storage: %v", err)
+	}
+
+	if _, err := store.LoadWallet(wallet.ID, testPassword); !errors.Is(err, ErrAddressMismatch) {
+		t.Fatalf("Expected ErrAddressMismatch on unlock, got %v", err)
+	}
+}
+
+func TestReimportSecretlessWallet(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Legacy", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	// Write a record the way older builds did, without a keystore
+	walletData := []byte(`{"id":"legacy","name":"Legacy","address":"` + wallet.Address + `"}`)
+	encrypted, err := Encrypt(walletData, "old-password")
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	legacy := &WalletStorage{Wallets: []EncryptedWallet{{
+		ID: "legacy", Name: "Legacy", Address: wallet.Address, CreatedAt: "2024-01-01T00:00:00Z", Data: encrypted,
+	}}}
+	if err := store.saveWalletStorage(legacy); err != nil {
+		t.Fatalf("Failed to save wallet storage: %v", err)
+	}
+
+	if _, err := store.LoadWallet("legacy", "old-password"); !errors.Is(err, ErrKeystoreMissing) {
+		t.Fatalf("Expected ErrKeystoreMissing, got %v", err)
+	}
+
+	other, _ := models.NewWallet("Other", "legal winner thank year wave sausage worth useful legal winner thank yellow")
+	if err := store.ReimportWallet("legacy", other, testPassword); !errors.Is(err, ErrAddressMismatch) {
+		t.Fatalf("Expected ErrAddressMismatch for a different phrase, got %v", err)
+	}
+
+	if err := store.ReimportWallet("legacy", wallet, testPassword); err != nil {
+		t.Fatalf("Failed to re-import wallet: %v", err)
+	}
+
+	loaded, err := store.LoadWallet("legacy", testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load re-imported wallet: %v", err)
+	}
+	if loaded.PrivateKey == nil || loaded.CreatedAt.Year() != 2024 {
+		t.Errorf("Re-imported wallet should keep its record and gain a key: %+v", loaded)
+	}
+}
+
+func TestWalletKeySource(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	key, err := models.ParsePrivateKey("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatalf("Failed to parse private key: %v", err)
+	}
+	wallet, err := models.NewWalletFromPrivateKey("Service", key)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	wallets, _ := store.ListWallets()
+	if len(wallets) != 1 || wallets[0].Source != models.KeySourcePrivateKey {
+		t.Fatalf("Expected a private key wallet, got %+v", wallets)
+	}
+	if wallets[0].Source.CanDeriveAccounts() {
+		t.Error("Private key wallets cannot derive accounts")
+	}
+
+	loaded, err := store.LoadWallet(wallet.ID, testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	if loaded.Source != models.KeySourcePrivateKey || loaded.Mnemonic != "" || !loaded.PrivateKey.Equal(key) {
+		t.Errorf("Unexpected loaded wallet: source %q", loaded.Source)
+	}
+
+	// Metadata-only saves keep the source
+	loaded.Source = ""
+	loaded.PrivateKey = nil
+	if err := store.SaveWallet(loaded, testPassword); err != nil {
+		t.Fatalf("Failed to re-save wallet: %v", err)
+	}
+	wallets, _ = store.ListWallets()
+	if wallets[0].Source != models.KeySourcePrivateKey {
+		t.Errorf("Source lost on re-save: %q", wallets[0].Source)
+	}
+}
+
+func TestKeystorePassphrase(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	plain, _ := models.NewWallet("Plain", testMnemonic)
+	protected, err := models.NewWalletWithPassphrase("Protected", testMnemonic, "extra words")
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	protected.ID += "-p"
+	for _, wallet := range []*models.Wallet{plain, protected} {
+		if err := store.SaveWallet(wallet, testPassword); err != nil {
+			t.Fatalf("Failed to save %s: %v", wallet.Name, err)
+		}
+	}
+
+	data, _ := os.ReadFile(filepath.Join(store.DataDir(), walletsFile))
+	if strings.Contains(string(data), "extra words") {
+		t.Fatal("Passphrase must not be stored in plaintext")
+	}
+
+	loaded, err := store.LoadWallet(protected.ID, testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	if loaded.Passphrase != "extra words" || loaded.Address == plain.Address {
+		t.Errorf("Passphrase wallet not restored: %s %q", loaded.Address, loaded.Passphrase)
+	}
+	if got := crypto.PubkeyToAddress(loaded.PrivateKey.PublicKey).Hex(); got != protected.Address {
+		t.Errorf("Loaded key derives %s, expected %s", got, protected.Address)
+	}
+}
+
+func TestChangeWalletPassword(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	before, _ := store.ListWallets()
+
+	const newPassword = "new-horse-battery-staple"
+	if err := store.ChangeWalletPassword(wallet.ID, "wrong-password", newPassword); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("Expected ErrInvalidPassword for wrong old password, got %v", err)
+	}
+	if err := store.ChangeWalletPassword(wallet.ID, testPassword, testPassword); err == nil {
+		t.Error("Expected error when the new password matches the old one")
+	}
+	if err := store.ChangeWalletPassword(wallet.ID, testPassword, newPassword); err != nil {
+		t.Fatalf("Failed to change password: %v", err)
+	}
+
+	if _, err := store.LoadWallet(wallet.ID, testPassword); !errors.Is(err, ErrInvalidPassword) {
+		t.Errorf("Expected old password to be rejected, got %v", err)
+	}
+	loaded, err := store.LoadWallet(wallet.ID, newPassword)
+	if err != nil {
+		t.Fatalf("Failed to load wallet with new password: %v", err)
+	}
+	if loaded.Mnemonic != testMnemonic || loaded.Address != wallet.Address {
+		t.Error("Wallet contents changed along with the password")
+	}
+
+	after, _ := store.ListWallets()
+	if after[0].CreatedAt != before[0].CreatedAt || after[0].Source != before[0].Source {
+		t.Error("Wallet metadata should survive a password change")
+	}
+	if string(after[0].Data.Salt) == string(before[0].Data.Salt) ||
+		string(after[0].Keystore.Data.Salt) == string(before[0].Keystore.Data.Salt) {
+		t.Error("Expected fresh salts after a password change")
+	}
+
+	entries, _ := os.ReadDir(store.DataDir())
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".tmp") {
+			t.Errorf("Temporary file %s left behind", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const exportsDir = "exports"
+
+// Scrypt cost used for exported keystore files. Tests lower these to keep
+// runs fast; exports always use the standard parameters.
+var (
+	keystoreV3ScryptN = keystore.StandardScryptN
+	keystoreV3ScryptP = keystore.StandardScryptP
+)
+
+// DecryptKeystoreV3 reads the private key from a Web3 Secret Storage
+// (keystore v3) file as written by geth, Sync2 and most other wallets
+func DecryptKeystoreV3(data []byte, password string) (*ecdsa.PrivateKey, error) {
+	key, err := keystore.DecryptKey(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file: %w", err)
+	}
+	return key.PrivateKey, nil
+}
+
+// EncryptKeystoreV3 encodes a private key as a Web3 Secret Storage
+// (keystore v3) file protected by password
+func EncryptKeystoreV3(privateKey *ecdsa.PrivateKey, password string) ([]byte, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate keystore id: %w", err)
+	}
+
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(privateKey.PublicKey),
+		PrivateKey: privateKey,
+	}
+
+	data, err := keystore.EncryptKey(key, password, keystoreV3ScryptN, keystoreV3ScryptP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt keystore file: %w", err)
+	}
+	return data, nil
+}
+
+// ReadKeystoreV3File loads and decrypts a keystore v3 file from disk
+func ReadKeystoreV3File(path, password string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+	return DecryptKeystoreV3(data, password)
+}
+
+// ExportWalletKeystore writes a wallet's signing key as a keystore v3 file.
+// The wallet password is checked by unlocking the wallet, so an export always
+// requires re-authentication. An empty path writes to the profile's exports
+// directory using geth's file naming. It returns the path written.
+func (s *Storage) ExportWalletKeystore(id, walletPassword, filePassword, path string) (string, error) {
+	wallet, err := s.LoadWallet(id, walletPassword)
+	if err != nil {
+		return "", err
+	}
+	return s.ExportKeystore(wallet, filePassword, path)
+}
+
+// ExportKeystore writes an unlocked wallet's signing key as a keystore v3
+// file, for callers that have already re-authenticated the user
+func (s *Storage) ExportKeystore(wallet *models.Wallet, filePassword, path string) (string, error) {
+	if wallet.PrivateKey == nil {
+		return "", ErrKeystoreMissing
+	}
+
+	data, err := EncryptKeystoreV3(wallet.PrivateKey, filePassword)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		path = s.DefaultKeystoreExportPath(wallet.Address)
+	}
+	path = expandPath(path)
+
+	if err := writeExportFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write keystore file: %w", err)
+	}
+
+	return path, nil
+}
+
+// writeExportFile writes data readable only by the owner, creating the
+// directory if needed. Existing files are never overwritten.
+func writeExportFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("refusing to overwrite existing file: %s", path)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// DefaultKeystoreExportPath names an export the way geth names its keystore
+// files: UTC--<timestamp>--<address>
+func (s *Storage) DefaultKeystoreExportPath(address string) string {
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	name := fmt.Sprintf("UTC--%s--%s.json", timestamp, strings.ToLower(strings.TrimPrefix(address, "0x")))
+	return filepath.Join(s.dataDir, exportsDir, name)
+}
+
+// expandPath resolves a leading ~ to the user's home directory
+func expandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func useLightScrypt(t *testing.T) {
+	n, p := keystoreV3ScryptN, keystoreV3ScryptP
+	keystoreV3ScryptN, keystoreV3ScryptP = keystore.LightScryptN, keystore.LightScryptP
+	t.Cleanup(func() { keystoreV3ScryptN, keystoreV3ScryptP = n, p })
+}
+
+func TestKeystoreV3RoundTrip(t *testing.T) {
+	useLightScrypt(t)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	data, err := EncryptKeystoreV3(key, "file-password")
+	if err != nil {
+		t.Fatalf("Failed to encrypt keystore: %v", err)
+	}
+
+	decrypted, err := DecryptKeystoreV3(data, "file-password")
+	if err != nil {
+		t.Fatalf("Failed to decrypt keystore: %v", err)
+	}
+	if !decrypted.Equal(key) {
+		t.Error("Decrypted key does not match original")
+	}
+
+	if _, err := DecryptKeystoreV3(data, "wrong"); err == nil {
+		t.Error("Expected error for wrong keystore password")
+	}
+}
+
+func TestExportWalletKeystore(t *testing.T) {
+	useLightScrypt(t)
+
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	if _, err := store.ExportWalletKeystore(wallet.ID, "wrong-password", "file-password", ""); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("Expected ErrInvalidPassword, got %v", err)
+	}
+
+	path, err := store.ExportWalletKeystore(wallet.ID, testPassword, "file-password", "")
+	if err != nil {
+		t.Fatalf("Failed to export keystore: %v", err)
+	}
+	if filepath.Dir(path) != filepath.Join(store.DataDir(), exportsDir) {
+		t.Errorf("Unexpected default export path: %s", path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Export not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected export mode 0600, got %v", info.Mode().Perm())
+	}
+
+	key, err := ReadKeystoreV3File(path, "file-password")
+	if err != nil {
+		t.Fatalf("Failed to read exported keystore: %v", err)
+	}
+	if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != wallet.Address {
+		t.Errorf("Exported key derives %s, expected %s", got, wallet.Address)
+	}
+
+	if _, err := store.ExportWalletKeystore(wallet.ID, testPassword, "file-password", path); err == nil {
+		t.Error("Export should not overwrite an existing file")
+	}
+
+	// Importing the exported file gives back a wallet with the same address
+	imported, err := models.NewWalletFromPrivateKey("Imported", key)
+	if err != nil {
+		t.Fatalf("Failed to create wallet from key: %v", err)
+	}
+	if imported.Address != wallet.Address || imported.Mnemonic != "" {
+		t.Errorf("Unexpected imported wallet: %s", imported.Address)
+	}
+}
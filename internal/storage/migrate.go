@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Schema versions written by this build. Bump the version and append a
+// migration whenever the on-disk shape of a file changes.
+const (
+	WalletsVersion   = 5
+	ContactsVersion  = 2
+	HistoryVersion   = 1
+	TemplatesVersion = 2
+	SchedulesVersion = 1
+	PoliciesVersion  = 1
+)
+
+const backupsDir = "backups"
+
+// ErrNewerVersion is returned for files written by a newer release, which
+// are never modified
+var ErrNewerVersion = errors.New("file was written by a newer version of veterm")
+
+// document is a decoded JSON file. Numbers are kept as json.Number so large
+// amounts survive a round trip unchanged.
+type document map[string]interface{}
+
+// Migration upgrades a document from version From to From+1
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(doc document) error
+}
+
+type schema struct {
+	Version    int
+	Migrations []Migration
+}
+
+var schemas = map[string]schema{
+	walletsFile: {
+		Version: WalletsVersion,
+		Migrations: []Migration{
+			{From: 0, Description: "add schema version", Apply: func(doc document) error { return nil }},
+			// Records gain an optional keystore. Bumped so older builds, which
+			// would drop it on save, refuse the file instead.
+			{From: 1, Description: "add wallet keystores", Apply: func(doc document) error { return nil }},
+			{From: 2, Description: "tag wallets with their key source", Apply: migrateWalletsV2},
+			// Records gain derived accounts, which older builds would drop
+			{From: 3, Description: "add derived accounts", Apply: func(doc document) error { return nil }},
+			// Encrypted data gains a versioned envelope naming its KDF, which
+			// older builds would misread as PBKDF2 and reject every password for
+			{From: 4, Description: "add versioned encryption envelopes", Apply: func(doc document) error { return nil }},
+		},
+	},
+	contactsFile: {
+		Version: ContactsVersion,
+		Migrations: []Migration{
+			{From: 0, Description: "fill updated_at for contacts created before it was tracked", Apply: migrateContactsV0},
+			// The file may be encrypted, which older builds would read as an
+			// empty list and overwrite. Plaintext files are encrypted when a
+			// wallet is next unlocked.
+			{From: 1, Description: "add contact encryption", Apply: func(doc document) error { return nil }},
+		},
+	},
+	configFile: {
+		Version: ConfigVersion,
+		Migrations: []Migration{
+			{From: 0, Description: "add schema version", Apply: func(doc document) error { return nil }},
+		},
+	},
+	historyFile: {
+		Version: HistoryVersion,
+		Migrations: []Migration{
+			{From: 0, Description: "move legacy memo to notes and mark unknown statuses pending", Apply: migrateHistoryV0},
+		},
+	},
+	templatesFile: {
+		Version: TemplatesVersion,
+		Migrations: []Migration{
+			// Templates gain amount placeholders, which older builds would
+			// drop, leaving the template with no amount
+			{From: 1, Description: "add template placeholders", Apply: func(doc document) error { return nil }},
+		},
+	},
+	schedulesFile: {
+		Version: SchedulesVersion,
+	},
+	policiesFile: {
+		Version: PoliciesVersion,
+	},
+}
+
+// migrateAll upgrades every known file in the data directory
+func (s *Storage) migrateAll() error {
+	for name := range schemas {
+		if _, err := s.readVersionedFile(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readVersionedFile reads a data file, upgrading it in place first if it was
+// written by an older version. A file that cannot be parsed is replaced by
+// its last-known-good copy. It returns nil data when the file is missing.
+func (s *Storage) readVersionedFile(name string) ([]byte, error) {
+	data, err := s.readDataFile(name)
+	if err == nil {
+		s.remember(name, data)
+	}
+	return data, err
+}
+
+func (s *Storage) readDataFile(name string) ([]byte, error) {
+	filePath := filepath.Join(s.dataDir, name)
+
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+
+	schema, ok := schemas[name]
+	if !ok {
+		return data, nil
+	}
+
+	doc, version, err := decodeDocument(data)
+	if err != nil {
+		recovered, recoverErr := s.recoverLastGood(name)
+		if recoverErr != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		data = recovered
+		if doc, version, err = decodeDocument(data); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+
+	if version > schema.Version {
+		return nil, fmt.Errorf("%s is version %d but this build supports up to %d: %w", name, version, schema.Version, ErrNewerVersion)
+	}
+	if version == schema.Version {
+		return data, nil
+	}
+
+	if err := s.backupFile(name, version, data); err != nil {
+		return nil, err
+	}
+
+	migrated, err := migrateDocument(doc, version, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", name, err)
+	}
+
+	err = s.withLock(func() error {
+		return writeFileAtomic(filePath, migrated, 0600)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write migrated %s: %w", name, err)
+	}
+
+	return migrated, nil
+}
+
+// upgradeData migrates the contents of a data file to this build's schema in
+// memory, as when reading a file from a backup
+func upgradeData(name string, data []byte) ([]byte, error) {
+	schema, ok := schemas[name]
+	if !ok {
+		return data, nil
+	}
+
+	doc, version, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if version > schema.Version {
+		return nil, fmt.Errorf("%s is version %d but this build supports up to %d: %w", name, version, schema.Version, ErrNewerVersion)
+	}
+	if version == schema.Version {
+		return data, nil
+	}
+
+	migrated, err := migrateDocument(doc, version, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", name, err)
+	}
+	return migrated, nil
+}
+
+func decodeDocument(data []byte) (document, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var doc document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, 0, err
+	}
+	if doc == nil {
+		return nil, 0, fmt.Errorf("expected a JSON object")
+	}
+
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		number, ok := raw.(json.Number)
+		if !ok {
+			return nil, 0, fmt.Errorf("version must be a number")
+		}
+		parsed, err := number.Int64()
+		if err != nil || parsed < 0 {
+			return nil, 0, fmt.Errorf("invalid version: %s", number)
+		}
+		version = int(parsed)
+	}
+
+	return doc, version, nil
+}
+
+// migrateDocument applies each migration from version up to the schema's
+// current version and returns the encoded result
+func migrateDocument(doc document, version int, schema schema) ([]byte, error) {
+	for v := version; v < schema.Version; v++ {
+		migration, ok := findMigration(schema.Migrations, v)
+		if !ok {
+			return nil, fmt.Errorf("no migration from version %d", v)
+		}
+		if err := migration.Apply(doc); err != nil {
+			return nil, fmt.Errorf("migration from version %d (%s) failed: %w", v, migration.Description, err)
+		}
+		doc["version"] = json.Number(fmt.Sprint(v + 1))
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func findMigration(migrations []Migration, from int) (Migration, bool) {
+	for _, migration := range migrations {
+		if migration.From == from {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// backupFile keeps a copy of a file as it was before migration
+func (s *Storage) backupFile(name string, version int, data []byte) error {
+	dir := filepath.Join(s.dataDir, backupsDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	backupName := fmt.Sprintf("%s.v%d.%s.bak", name, version, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(filepath.Join(dir, backupName), data, 0600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// objectList returns the objects in a decoded JSON array, skipping anything else
+func objectList(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	objects := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+func isMissing(object map[string]interface{}, key string) bool {
+	value, ok := object[key]
+	if !ok || value == nil {
+		return true
+	}
+	text, isString := value.(string)
+	return isString && (text == "" || text == "0001-01-01T00:00:00Z")
+}
+
+// migrateWalletsV2 infers the key source from the stored keystore. Wallets
+// without one could only have been created from a recovery phrase.
+func migrateWalletsV2(doc document) error {
+	for _, wallet := range objectList(doc["wallets"]) {
+		if !isMissing(wallet, "source") {
+			continue
+		}
+		wallet["source"] = "mnemonic"
+		if keystore, ok := wallet["keystore"].(map[string]interface{}); ok && keystore["type"] == KeystorePrivateKey {
+			wallet["source"] = "private_key"
+		}
+	}
+	return nil
+}
+
+func migrateContactsV0(doc document) error {
+	for _, contact := range objectList(doc["contacts"]) {
+		if isMissing(contact, "updated_at") && !isMissing(contact, "created_at") {
+			contact["updated_at"] = contact["created_at"]
+		}
+	}
+	return nil
+}
+
+func migrateHistoryV0(doc document) error {
+	wallets, _ := doc["wallets"].(map[string]interface{})
+	for _, transactions := range wallets {
+		for _, tx := range objectList(transactions) {
+			if isMissing(tx, "notes") && !isMissing(tx, "memo") {
+				tx["notes"] = tx["memo"]
+				delete(tx, "memo")
+			}
+			// Status was added later; let the status check settle old entries
+			if isMissing(tx, "status") {
+				tx["status"] = "pending"
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestMigrationsGolden(t *testing.T) {
+	for _, name := range []string{walletsFile, contactsFile, configFile, historyFile} {
+		t.Run(name, func(t *testing.T) {
+			base := strings.TrimSuffix(name, ".json")
+			input, err := os.ReadFile(filepath.Join("testdata", "migrations", base+".v0.json"))
+			if err != nil {
+				t.Fatalf("Failed to read input: %v", err)
+			}
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, name), input, 0600); err != nil {
+				t.Fatalf("Failed to write input: %v", err)
+			}
+
+			if _, err := NewStorageAt(dir); err != nil {
+				t.Fatalf("Failed to open storage: %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatalf("Failed to read migrated file: %v", err)
+			}
+
+			goldenPath := filepath.Join("testdata", "migrations", base+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+					t.Fatalf("Failed to update golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("Migrated %s does not match %s:\n%s", name, goldenPath, got)
+			}
+
+			backups, _ := filepath.Glob(filepath.Join(dir, backupsDir, name+".v0.*.bak"))
+			if len(backups) != 1 {
+				t.Fatalf("Expected one backup of %s, got %d", name, len(backups))
+			}
+			backup, _ := os.ReadFile(backups[0])
+			if string(backup) != string(input) {
+				t.Error("Backup should hold the original file contents")
+			}
+		})
+	}
+}
+
+func TestMigratedFilesLoad(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{contactsFile, historyFile} {
+		base := strings.TrimSuffix(name, ".json")
+		input, err := os.ReadFile(filepath.Join("testdata", "migrations", base+".v0.json"))
+		if err != nil {
+			t.Fatalf("Failed to read input: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), input, 0600); err != nil {
+			t.Fatalf("Failed to write input: %v", err)
+		}
+	}
+
+	store, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	contacts, err := store.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load contacts: %v", err)
+	}
+	if len(contacts.Contacts) != 2 || !contacts.Contacts[0].UpdatedAt.Equal(contacts.Contacts[0].CreatedAt) {
+		t.Errorf("Unexpected migrated contacts: %+v", contacts.Contacts)
+	}
+
+	history, err := store.LoadTransactionHistory("wallet_1700000000")
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(history) != 1 || history[0].Notes != "rent" || history[0].Status != "pending" {
+		t.Fatalf("Unexpected migrated history: %+v", history)
+	}
+	if history[0].Amount.String() != "1500000000000000000000000" {
+		t.Errorf("Amount lost precision during migration: %s", history[0].Amount)
+	}
+}
+
+func TestRefusesNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	newer := []byte(`{"version": 99, "wallets": []}`)
+	if err := os.WriteFile(filepath.Join(dir, walletsFile), newer, 0600); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := NewStorageAt(dir); !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("Expected ErrNewerVersion, got %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, walletsFile))
+	if string(data) != string(newer) {
+		t.Error("Newer file should be left untouched")
+	}
+}
+
+func TestSavedFilesAreVersioned(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	contacts := &models.ContactList{Contacts: []models.Contact{*models.NewContact("Alice", "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd", "")}}
+	if err := store.SaveContacts(contacts); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(store.DataDir(), contactsFile))
+	_, version, err := decodeDocument(data)
+	if err != nil || version != ContactsVersion {
+		t.Errorf("Expected contacts version %d, got %d (%v)", ContactsVersion, version, err)
+	}
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"fmt"
+)
+
+// ChangeWalletPassword re-encrypts a wallet's data and keystore under
+// newPassword after unlocking it with oldPassword. Both are sealed with fresh
+// salts and written in a single atomic replace of the wallets file, so a
+// crash leaves either the old password or the new one working, never a mix.
+//
+// An incorrect old password returns an error wrapping ErrInvalidPassword.
+// Contacts and persisted sessions are not encrypted with the wallet password
+// and so are left untouched.
+func (s *Storage) ChangeWalletPassword(id, oldPassword, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("new password is required")
+	}
+	if newPassword == oldPassword {
+		return fmt.Errorf("new password must differ from the current one")
+	}
+
+	wallet, err := s.LoadWallet(id, oldPassword)
+	if err != nil {
+		return err
+	}
+	if err := s.SaveWallet(wallet, newPassword); err != nil {
+		return fmt.Errorf("failed to re-encrypt wallet: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const policiesFile = "policies.json"
+
+// PolicyStorage holds each wallet's spending policy
+type PolicyStorage struct {
+	Version  int                     `json:"version"`
+	Policies []models.SpendingPolicy `json:"policies"`
+}
+
+// LoadSpendingPolicy returns a wallet's spending policy, or nil when it has
+// none
+func (s *Storage) LoadSpendingPolicy(walletID string) (*models.SpendingPolicy, error) {
+	policies, err := s.loadPolicyStorage()
+	if err != nil {
+		return nil, err
+	}
+	for i := range policies.Policies {
+		if policies.Policies[i].ID == walletID {
+			return &policies.Policies[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveSpendingPolicy adds a wallet's policy or replaces its existing one
+func (s *Storage) SaveSpendingPolicy(policy *models.SpendingPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	policies, err := s.loadPolicyStorage()
+	if err != nil {
+		return err
+	}
+
+	for i := range policies.Policies {
+		if policies.Policies[i].ID == policy.ID {
+			policies.Policies[i] = *policy
+			return s.savePolicyStorage(policies)
+		}
+	}
+	policies.Policies = append(policies.Policies, *policy)
+	return s.savePolicyStorage(policies)
+}
+
+func (s *Storage) loadPolicyStorage() (*PolicyStorage, error) {
+	data, err := s.readVersionedFile(policiesFile)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return &PolicyStorage{Version: PoliciesVersion}, nil
+	}
+
+	var policies PolicyStorage
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spending policies: %w", err)
+	}
+
+	return &policies, nil
+}
+
+func (s *Storage) savePolicyStorage(policies *PolicyStorage) error {
+	policies.Version = PoliciesVersion
+	if err := s.writeDataFile(policiesFile, policies); err != nil {
+		return fmt.Errorf("failed to write spending policies file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+const (
+	// DefaultProfile lives directly in the home directory so data written
+	// before profiles existed keeps working
+	DefaultProfile = "default"
+	profilesDir    = "profiles"
+
+	HomeEnv    = "VETERM_HOME"
+	ProfileEnv = "VETERM_PROFILE"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// HomeDir resolves the data directory root. An explicit override wins, then
+// VETERM_HOME, then ~/.veterm.
+func HomeDir(override string) (string, error) {
+	if override != "" {
+		return filepath.Abs(override)
+	}
+	if env := os.Getenv(HomeEnv); env != "" {
+		return filepath.Abs(env)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, appDir), nil
+}
+
+// ProfileName resolves the profile to use, preferring an explicit override
+// over VETERM_PROFILE. It returns "" when neither is set.
+func ProfileName(override string) string {
+	if override != "" {
+		return override
+	}
+	return os.Getenv(ProfileEnv)
+}
+
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use up to 32 lowercase letters, digits, '-' or '_')", name)
+	}
+	return nil
+}
+
+// ProfileDir returns the data directory for a profile under home
+func ProfileDir(home, name string) string {
+	if name == "" || name == DefaultProfile {
+		return home
+	}
+	return filepath.Join(home, profilesDir, name)
+}
+
+// ListProfiles returns the default profile followed by any named profiles
+func ListProfiles(home string) ([]string, error) {
+	profiles := []string{DefaultProfile}
+
+	entries, err := os.ReadDir(filepath.Join(home, profilesDir))
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+
+	var named []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != DefaultProfile && ValidateProfileName(entry.Name()) == nil {
+			named = append(named, entry.Name())
+		}
+	}
+	sort.Strings(named)
+
+	return append(profiles, named...), nil
+}
+
+// OpenProfile opens the storage for an existing profile
+func OpenProfile(home, name string) (*Storage, error) {
+	if name == "" {
+		name = DefaultProfile
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+
+	dir := ProfileDir(home, name)
+	if name != DefaultProfile {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return nil, fmt.Errorf("profile %q does not exist", name)
+		}
+	}
+
+	storage, err := NewStorageAt(dir)
+	if err != nil {
+		return nil, err
+	}
+	storage.profile = name
+	return storage, nil
+}
+
+// CreateProfile creates a new named profile and opens it
+func CreateProfile(home, name string) (*Storage, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return nil, err
+	}
+
+	dir := ProfileDir(home, name)
+	if name != DefaultProfile {
+		if _, err := os.Stat(dir); err == nil {
+			return nil, fmt.Errorf("profile %q already exists", name)
+		}
+	}
+
+	storage, err := NewStorageAt(dir)
+	if err != nil {
+		return nil, err
+	}
+	storage.profile = name
+	return storage, nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func TestHomeDirPrecedence(t *testing.T) {
+	envHome := t.TempDir()
+	t.Setenv(HomeEnv, envHome)
+
+	home, err := HomeDir("")
+	if err != nil {
+		t.Fatalf("Failed to resolve home: %v", err)
+	}
+	if home != envHome {
+		t.Errorf("Expected %s from %s, got %s", envHome, HomeEnv, home)
+	}
+
+	override := t.TempDir()
+	home, err = HomeDir(override)
+	if err != nil {
+		t.Fatalf("Failed to resolve home: %v", err)
+	}
+	if home != override {
+		t.Errorf("Expected override %s, got %s", override, home)
+	}
+}
+
+func TestProfilesAreIsolated(t *testing.T) {
+	home := t.TempDir()
+
+	defaultStore, err := OpenProfile(home, "")
+	if err != nil {
+		t.Fatalf("Failed to open default profile: %v", err)
+	}
+	if defaultStore.DataDir() != home || defaultStore.Profile() != DefaultProfile {
+		t.Errorf("Default profile should live in the home directory, got %s", defaultStore.DataDir())
+	}
+
+	workStore, err := CreateProfile(home, "work")
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	if workStore.DataDir() != filepath.Join(home, "profiles", "work") {
+		t.Errorf("Unexpected profile directory: %s", workStore.DataDir())
+	}
+
+	wallet, err := models.NewWallet("Work", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := workStore.SaveWallet(wallet, "password123"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	wallets, err := defaultStore.ListWallets()
+	if err != nil {
+		t.Fatalf("Failed to list wallets: %v", err)
+	}
+	if len(wallets) != 0 {
+		t.Errorf("Default profile should not see work wallets, got %d", len(wallets))
+	}
+
+	profiles, err := ListProfiles(home)
+	if err != nil {
+		t.Fatalf("Failed to list profiles: %v", err)
+	}
+	if !reflect.DeepEqual(profiles, []string{DefaultProfile, "work"}) {
+		t.Errorf("Unexpected profiles: %v", profiles)
+	}
+
+	if _, err := CreateProfile(home, "work"); err == nil {
+		t.Error("Expected error creating an existing profile")
+	}
+	if _, err := OpenProfile(home, "personal"); err == nil {
+		t.Error("Expected error opening a missing profile")
+	}
+	if _, err := CreateProfile(home, "../escape"); err == nil {
+		t.Error("Expected error for invalid profile name")
+	}
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const schedulesFile = "schedules.json"
+
+// ScheduleStorage holds payment schedules and the record of their runs
+type ScheduleStorage struct {
+	Version   int                      `json:"version"`
+	Schedules []models.PaymentSchedule `json:"schedules"`
+	Runs      []models.ScheduledRun    `json:"runs"`
+}
+
+// DueSchedule is a schedule moved past the dates that came due, with the
+// dates that still need a run
+type DueSchedule struct {
+	Schedule models.PaymentSchedule
+	Due      []models.DueRun
+}
+
+// TakeDueSchedules moves every schedule past the dates that have come due by
+// now and returns them. The schedules are read and saved under the profile
+// lock, so when several processes check at once each date goes to only one.
+// Dates that already have a run are left out, and a schedule whose dates
+// cannot be worked out is reported without holding up the others.
+func (s *Storage) TakeDueSchedules(now time.Time) ([]DueSchedule, error) {
+	var (
+		schedules ScheduleStorage
+		taken     []DueSchedule
+		errs      []error
+	)
+	err := s.updateDataFile(schedulesFile, &schedules, func() (bool, error) {
+		recorded := make(map[string]bool, len(schedules.Runs))
+		for _, run := range schedules.Runs {
+			recorded[run.ID] = true
+		}
+
+		for i := range schedules.Schedules {
+			schedule := &schedules.Schedules[i]
+			due, err := schedule.TakeDue(now)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("schedule %s: %w", schedule.Name, err))
+				continue
+			}
+			if len(due) == 0 {
+				continue
+			}
+
+			var unrecorded []models.DueRun
+			for _, d := range due {
+				if !recorded[models.ScheduledRunID(schedule.ID, d.DueAt)] {
+					unrecorded = append(unrecorded, d)
+				}
+			}
+			taken = append(taken, DueSchedule{Schedule: *schedule, Due: unrecorded})
+		}
+		schedules.Version = SchedulesVersion
+		return len(taken) > 0, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write schedules file: %w", err)
+	}
+	return taken, errors.Join(errs...)
+}
+
+// LoadSchedules returns every payment schedule, across all wallets
+func (s *Storage) LoadSchedules() ([]models.PaymentSchedule, error) {
+	schedules, err := s.loadScheduleStorage()
+	if err != nil {
+		return nil, err
+	}
+	return schedules.Schedules, nil
+}
+
+// SaveSchedule adds a schedule or replaces the one with the same ID
+func (s *Storage) SaveSchedule(schedule *models.PaymentSchedule) error {
+	schedules, err := s.loadScheduleStorage()
+	if err != nil {
+		return err
+	}
+
+	for i := range schedules.Schedules {
+		if schedules.Schedules[i].ID == schedule.ID {
+			schedules.Schedules[i] = *schedule
+			return s.saveScheduleStorage(schedules)
+		}
+	}
+	schedules.Schedules = append(schedules.Schedules, *schedule)
+	return s.saveScheduleStorage(schedules)
+}
+
+// DeleteSchedule removes a schedule. Its runs are kept as a record of what
+// was paid.
+func (s *Storage) DeleteSchedule(id string) error {
+	schedules, err := s.loadScheduleStorage()
+	if err != nil {
+		return err
+	}
+
+	for i := range schedules.Schedules {
+		if schedules.Schedules[i].ID == id {
+			schedules.Schedules = append(schedules.Schedules[:i], schedules.Schedules[i+1:]...)
+			return s.saveScheduleStorage(schedules)
+		}
+	}
+	return fmt.Errorf("schedule not found: %s", id)
+}
+
+// LoadScheduledRuns returns the recorded runs of every schedule, oldest due
+// first
+func (s *Storage) LoadScheduledRuns() ([]models.ScheduledRun, error) {
+	schedules, err := s.loadScheduleStorage()
+	if err != nil {
+		return nil, err
+	}
+	runs := schedules.Runs
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].DueAt.Before(runs[j].DueAt) })
+	return runs, nil
+}
+
+// SaveScheduledRun adds a run or replaces the one with the same ID
+func (s *Storage) SaveScheduledRun(run *models.ScheduledRun) error {
+	schedules, err := s.loadScheduleStorage()
+	if err != nil {
+		return err
+	}
+
+	for i := range schedules.Runs {
+		if schedules.Runs[i].ID == run.ID {
+			schedules.Runs[i] = *run
+			return s.saveScheduleStorage(schedules)
+		}
+	}
+	schedules.Runs = append(schedules.Runs, *run)
+	return s.saveScheduleStorage(schedules)
+}
+
+func (s *Storage) loadScheduleStorage() (*ScheduleStorage, error) {
+	data, err := s.readVersionedFile(schedulesFile)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return &ScheduleStorage{Version: SchedulesVersion}, nil
+	}
+
+	var schedules ScheduleStorage
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal schedules: %w", err)
+	}
+
+	return &schedules, nil
+}
+
+func (s *Storage) saveScheduleStorage(schedules *ScheduleStorage) error {
+	schedules.Version = SchedulesVersion
+	if err := s.writeDataFile(schedulesFile, schedules); err != nil {
+		return fmt.Errorf("failed to write schedules file: %w", err)
+	}
+
+	return nil
+}
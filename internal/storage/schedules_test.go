@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func TestSchedulesPersist(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	start := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	schedule, err := models.NewPaymentSchedule("Retainer", "wallet-a", "template-a", "@monthly", start)
+	if err != nil {
+		t.Fatalf("NewPaymentSchedule failed: %v", err)
+	}
+	schedule.Values = map[string]string{"amount": "100"}
+	if err := store.SaveSchedule(schedule); err != nil {
+		t.Fatalf("Failed to save schedule: %v", err)
+	}
+
+	later := models.NewScheduledRun(schedule, start.AddDate(0, 1, 0))
+	first := models.NewScheduledRun(schedule, start)
+	for _, run := range []*models.ScheduledRun{later, first} {
+		if err := store.SaveScheduledRun(run); err != nil {
+			t.Fatalf("Failed to save run: %v", err)
+		}
+	}
+	first.Resolve(models.RunSent, "", start)
+	if err := store.SaveScheduledRun(first); err != nil {
+		t.Fatalf("Failed to update run: %v", err)
+	}
+
+	reopened, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	schedules, err := reopened.LoadSchedules()
+	if err != nil {
+		t.Fatalf("Failed to load schedules: %v", err)
+	}
+	if len(schedules) != 1 || schedules[0].ID != schedule.ID || schedules[0].Values["amount"] != "100" {
+		t.Fatalf("Expected the saved schedule back, got %+v", schedules)
+	}
+	runs, err := reopened.LoadScheduledRuns()
+	if err != nil {
+		t.Fatalf("Failed to load runs: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != first.ID || runs[0].Status != models.RunSent {
+		t.Fatalf("Expected two runs oldest first with the update applied, got %+v", runs)
+	}
+
+	if err := reopened.DeleteSchedule(schedule.ID); err != nil {
+		t.Fatalf("Failed to delete schedule: %v", err)
+	}
+	if err := reopened.DeleteSchedule(schedule.ID); err == nil {
+		t.Fatal("Expected deleting a missing schedule to fail")
+	}
+	if runs, _ := reopened.LoadScheduledRuns(); len(runs) != 2 {
+		t.Fatalf("Expected runs to outlive their schedule, got %d", len(runs))
+	}
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ExportShamirShare writes recovery share index (from 1) of count to the
+// profile's exports directory as numbered words for printing. It returns the
+// path written; the file should be deleted once the share is on paper.
+func (s *Storage) ExportShamirShare(address, share string, index, threshold, count int) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Recovery share %d of %d for %s\n", index, count, address)
+	fmt.Fprintf(&b, "Any %d of the %d shares restore the wallet's recovery phrase.\n\n", threshold, count)
+	for i, word := range strings.Fields(share) {
+		fmt.Fprintf(&b, "%2d. %s\n", i+1, word)
+	}
+
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	name := fmt.Sprintf("share-%d-of-%d--%s--%s.txt", index, count, timestamp, strings.ToLower(strings.TrimPrefix(address, "0x")))
+	path := filepath.Join(s.dataDir, exportsDir, name)
+
+	if err := writeExportFile(path, []byte(b.String())); err != nil {
+		return "", fmt.Errorf("failed to write share file: %w", err)
+	}
+	return path, nil
+}
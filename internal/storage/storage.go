@@ -3,8 +3,13 @@ package storage
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"rhystmorgan/veWallet/internal/models"
 )
@@ -18,47 +23,346 @@ const (
 
 type Storage struct {
 	dataDir string
+	profile string
+	kdf     KDFParams
+
+	// seen holds each data file as this process last read or wrote it, the
+	// base for merging changes made by other processes
+	mu   sync.Mutex
+	seen map[string][]byte
+
+	// profileKey encrypts the contacts; see contact_encryption.go
+	profileKey        []byte
+	contactEncryption ContactEncryption
 }
 
 type WalletStorage struct {
+	Version int               `json:"version"`
 	Wallets []EncryptedWallet `json:"wallets"`
 }
 
+// contactsDocument is the on-disk form of the contact list
+type contactsDocument struct {
+	Version int `json:"version"`
+	*models.ContactList
+}
+
+// walletSecret is what a wallet record's encrypted data holds: the wallet
+// and the profile key that encrypts the contacts
+type walletSecret struct {
+	*models.Wallet
+	ProfileKey []byte `json:"profile_key,omitempty"`
+}
+
 type EncryptedWallet struct {
-	ID        string         `json:"id"`
-	Name      string         `json:"name"`
-	Address   string         `json:"address"`
-	CreatedAt string         `json:"created_at"`
-	Data      *EncryptedData `json:"data"`
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Address   string           `json:"address"`
+	Source    models.KeySource `json:"source,omitempty"`
+	Accounts  []models.Account `json:"accounts,omitempty"`
+	CreatedAt string           `json:"created_at"`
+	Data      *EncryptedData   `json:"data"`
+	Keystore  *Keystore        `json:"keystore,omitempty"`
 }
 
+// Config is the single versioned settings file. Values here are the lowest
+// precedence layer; environment variables and command-line flags override them.
 type Config struct {
+	Version       int    `json:"version"`
 	DefaultWallet string `json:"default_wallet,omitempty"`
-	Network       string `json:"network"`
 	Theme         string `json:"theme"`
+
+	// Blockchain
+	Network    string   `json:"network"`
+	NodeURL    string   `json:"node_url,omitempty"`
+	Timeout    Duration `json:"timeout"`
+	RetryCount int      `json:"retry_count"`
+	RetryDelay Duration `json:"retry_delay"`
+	CacheTTL   Duration `json:"cache_ttl"`
+
+	// Security and sessions
+	SecurityLevel  string `json:"security_level"`
+	SessionTimeout int    `json:"session_timeout_minutes"`
+	MaxSessions    int    `json:"max_sessions,omitempty"`
+
+	// Display
+	AutoRefresh      int `json:"auto_refresh_seconds"`
+	DisplayPrecision int `json:"display_precision"`
+
+	// Accounts
+	AccountGapLimit int `json:"account_gap_limit"`
+
+	// Argon2id cost for newly encrypted wallets; see CalibrateKDF
+	KDFTime    int `json:"kdf_time"`
+	KDFMemory  int `json:"kdf_memory_kib"`
+	KDFThreads int `json:"kdf_threads"`
+
+	// ContactEncryption is "file" to encrypt the whole contact book, or
+	// "fields" to keep names and addresses searchable before unlock
+	ContactEncryption string `json:"contact_encryption"`
+
+	// Automatic scheduled payments may spend up to these amounts, in whole
+	// tokens, in any 30 days. At "0" every scheduled payment in that asset
+	// waits for approval.
+	ScheduleBudgetVET  string `json:"schedule_budget_vet"`
+	ScheduleBudgetVTHO string `json:"schedule_budget_vtho"`
+}
+
+// ConfigVersion is the config file schema version written by this build
+const ConfigVersion = 1
+
+const (
+	MinSessionTimeout   = 1
+	MaxSessionTimeout   = 240
+	MaxSessions         = 10
+	MinAutoRefresh      = 10
+	MaxAutoRefresh      = 3600
+	MaxDisplayPrecision = 18
+	MaxAccountGapLimit  = 100
+)
+
+func DefaultConfig() *Config {
+	return &Config{
+		Version:            ConfigVersion,
+		Theme:              "catppuccin",
+		Network:            "mainnet",
+		Timeout:            Duration(30 * time.Second),
+		RetryCount:         3,
+		RetryDelay:         Duration(2 * time.Second),
+		CacheTTL:           Duration(30 * time.Second),
+		SecurityLevel:      "high",
+		SessionTimeout:     5,
+		AutoRefresh:        30,
+		DisplayPrecision:   4,
+		AccountGapLimit:    models.DefaultGapLimit,
+		KDFTime:            DefaultKDFTime,
+		KDFMemory:          DefaultKDFMemory,
+		KDFThreads:         DefaultKDFThreads,
+		ContactEncryption:  string(ContactEncryptionFile),
+		ScheduleBudgetVET:  "0",
+		ScheduleBudgetVTHO: "0",
+	}
 }
 
+// Validate checks the settings that can be verified without other packages.
+// Theme names are validated by the caller against the available themes.
+func (c *Config) Validate() error {
+	switch c.Network {
+	case "mainnet", "testnet":
+	default:
+		return fmt.Errorf("invalid network: %s (must be 'mainnet' or 'testnet')", c.Network)
+	}
+
+	if c.NodeURL != "" {
+		parsed, err := url.Parse(c.NodeURL)
+		if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("invalid node URL: %s (must be an http or https URL)", c.NodeURL)
+		}
+	}
+
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got: %v", c.Timeout)
+	}
+
+	if c.RetryCount < 0 {
+		return fmt.Errorf("retry count must be non-negative, got: %d", c.RetryCount)
+	}
+
+	if c.RetryDelay < 0 {
+		return fmt.Errorf("retry delay must be non-negative, got: %v", c.RetryDelay)
+	}
+
+	if c.CacheTTL <= 0 {
+		return fmt.Errorf("cache TTL must be positive, got: %v", c.CacheTTL)
+	}
+
+	switch c.SecurityLevel {
+	case "low", "medium", "high", "max":
+	default:
+		return fmt.Errorf("invalid security level: %s", c.SecurityLevel)
+	}
+
+	if c.SessionTimeout < MinSessionTimeout || c.SessionTimeout > MaxSessionTimeout {
+		return fmt.Errorf("session timeout must be between %d and %d minutes", MinSessionTimeout, MaxSessionTimeout)
+	}
+
+	if c.MaxSessions < 0 || c.MaxSessions > MaxSessions {
+		return fmt.Errorf("max sessions must be between 0 (security level default) and %d", MaxSessions)
+	}
+
+	if c.AutoRefresh != 0 && (c.AutoRefresh < MinAutoRefresh || c.AutoRefresh > MaxAutoRefresh) {
+		return fmt.Errorf("auto-refresh interval must be 0 (off) or between %d and %d seconds", MinAutoRefresh, MaxAutoRefresh)
+	}
+
+	if c.DisplayPrecision < 0 || c.DisplayPrecision > MaxDisplayPrecision {
+		return fmt.Errorf("display precision must be between 0 and %d", MaxDisplayPrecision)
+	}
+
+	if c.AccountGapLimit < 1 || c.AccountGapLimit > MaxAccountGapLimit {
+		return fmt.Errorf("account gap limit must be between 1 and %d", MaxAccountGapLimit)
+	}
+
+	if err := c.KDFParams().Validate(); err != nil {
+		return err
+	}
+
+	if _, err := ParseContactEncryption(c.ContactEncryption); err != nil {
+		return err
+	}
+
+	if _, _, err := c.ScheduleBudget(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// KDFParams returns the configured Argon2id parameters
+func (c *Config) KDFParams() KDFParams {
+	return Argon2idParams(c.KDFTime, c.KDFMemory, c.KDFThreads)
+}
+
+// ScheduleBudget returns the automatic payment budget in wei
+func (c *Config) ScheduleBudget() (vet, vtho *big.Int, err error) {
+	if vet, err = parseTokenAmount(c.ScheduleBudgetVET); err != nil {
+		return nil, nil, fmt.Errorf("invalid VET schedule budget: %w", err)
+	}
+	if vtho, err = parseTokenAmount(c.ScheduleBudgetVTHO); err != nil {
+		return nil, nil, fmt.Errorf("invalid VTHO schedule budget: %w", err)
+	}
+	return vet, vtho, nil
+}
+
+// parseTokenAmount reads a non-negative amount in whole tokens, such as
+// "250.5", into wei. An empty value is zero.
+func parseTokenAmount(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return new(big.Int), nil
+	}
+	amount, ok := new(big.Rat).SetString(value)
+	if !ok || amount.Sign() < 0 || strings.ContainsAny(value, "/eE") {
+		return nil, fmt.Errorf("%q is not an amount", value)
+	}
+	if _, fraction, ok := strings.Cut(value, "."); ok && len(fraction) > 18 {
+		return nil, fmt.Errorf("%q has more than 18 decimal places", value)
+	}
+	wei := amount.Mul(amount, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)))
+	return new(big.Int).Quo(wei.Num(), wei.Denom()), nil
+}
+
+// NewStorage opens the profile selected by VETERM_PROFILE (or the default
+// profile) under the home directory resolved by HomeDir
 func NewStorage() (*Storage, error) {
-	homeDir, err := os.UserHomeDir()
+	home, err := HomeDir("")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+		return nil, err
 	}
+	return OpenProfile(home, ProfileName(""))
+}
 
-	dataDir := filepath.Join(homeDir, appDir)
+// NewStorageAt uses dataDir directly, creating it if needed
+func NewStorageAt(dataDir string) (*Storage, error) {
 	if err := os.MkdirAll(dataDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	return &Storage{dataDir: dataDir}, nil
+	s := &Storage{dataDir: dataDir, profile: DefaultProfile, kdf: DefaultKDFParams()}
+	if err := s.migrateAll(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Storage) DataDir() string {
+	return s.dataDir
+}
+
+// Profile returns the name of the profile this storage belongs to
+func (s *Storage) Profile() string {
+	return s.profile
+}
+
+// SetKDFParams sets the key derivation used for data encrypted from now on.
+// Wallets sealed with other parameters are re-encrypted when next unlocked.
+func (s *Storage) SetKDFParams(params KDFParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	s.kdf = params
+	return nil
+}
+
+// AuditLogDir is where audit logs for this profile are written
+func (s *Storage) AuditLogDir() string {
+	return filepath.Join(s.dataDir, auditDir)
 }
 
+// SaveWallet encrypts the wallet with password. The mnemonic or private key
+// goes into the wallet's keystore; a wallet without key material may only
+// update an existing record, whose keystore is kept. A second factor is
+// carried over as stored, with its recovery codes; SetTwoFactor changes it.
 func (s *Storage) SaveWallet(wallet *models.Wallet, password string) error {
-	walletData, err := json.Marshal(wallet)
+	return s.saveWallet(wallet, password, false)
+}
+
+// saveWallet saves the wallet, taking its second factor from wallet when
+// replaceTwoFactor is set and from the stored record otherwise
+func (s *Storage) saveWallet(wallet *models.Wallet, password string, replaceTwoFactor bool) error {
+	if wallet.IsWatchOnly() {
+		return fmt.Errorf("watch-only wallets have no key to encrypt; use SaveWatchOnlyWallets")
+	}
+
+	storage, err := s.loadWalletStorage()
+	if err != nil {
+		return err
+	}
+
+	existingIndex := -1
+	for i, existing := range storage.Wallets {
+		if existing.ID == wallet.ID {
+			existingIndex = i
+			break
+		}
+	}
+
+	var keystore *Keystore
+	if wallet.Mnemonic != "" || wallet.PrivateKey != nil {
+		keystore, err = newKeystore(wallet, password, s.kdf)
+		if err != nil {
+			return err
+		}
+		if wallet.Source == "" {
+			wallet.Source = keystore.source()
+		}
+		if existingIndex >= 0 && !replaceTwoFactor {
+			if err := keepTwoFactor(keystore, storage.Wallets[existingIndex].Keystore); err != nil {
+				return err
+			}
+		}
+	} else if existingIndex >= 0 {
+		keystore = storage.Wallets[existingIndex].Keystore
+		if wallet.Source == "" {
+			wallet.Source = storage.Wallets[existingIndex].Source
+		}
+		if len(wallet.Accounts) == 0 {
+			wallet.Accounts = storage.Wallets[existingIndex].Accounts
+		}
+	} else {
+		return ErrKeystoreMissing
+	}
+
+	profileKey, err := s.ensureProfileKey()
+	if err != nil {
+		return err
+	}
+	walletData, err := json.Marshal(walletSecret{Wallet: wallet, ProfileKey: profileKey})
 	if err != nil {
 		return fmt.Errorf("failed to marshal wallet: %w", err)
 	}
 
-	encryptedData, err := Encrypt(walletData, password)
+	encryptedData, err := EncryptWithKDF(walletData, password, s.kdf)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt wallet: %w", err)
 	}
@@ -67,26 +371,35 @@ func (s *Storage) SaveWallet(wallet *models.Wallet, password string) error {
 		ID:        wallet.ID,
 		Name:      wallet.Name,
 		Address:   wallet.Address,
+		Source:    wallet.Source,
+		Accounts:  wallet.Accounts,
 		CreatedAt: wallet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		Data:      encryptedData,
+		Keystore:  keystore,
 	}
 
-	storage, err := s.loadWalletStorage()
-	if err != nil {
+	if existingIndex >= 0 {
+		storage.Wallets[existingIndex] = encWallet
+	} else {
+		storage.Wallets = append(storage.Wallets, encWallet)
+	}
+	if err := s.saveWalletStorage(storage); err != nil {
 		return err
 	}
 
-	for i, existing := range storage.Wallets {
-		if existing.ID == wallet.ID {
-			storage.Wallets[i] = encWallet
-			return s.saveWalletStorage(storage)
-		}
+	// Contacts still in plaintext are encrypted once a wallet holds the key;
+	// a failure is retried on the next unlock
+	if profileKey != nil {
+		s.sealStoredContacts()
 	}
-
-	storage.Wallets = append(storage.Wallets, encWallet)
-	return s.saveWalletStorage(storage)
+	return nil
 }
 
+// LoadWallet decrypts a wallet and its keystore, verifying that the stored
+// secret still derives the wallet's address. Records encrypted with other
+// KDF parameters are re-encrypted with the current ones. Wallets saved before keystores
+// existed return ErrKeystoreMissing, and watch-only wallets, which have
+// nothing to unlock, return models.ErrWatchOnly.
 func (s *Storage) LoadWallet(id, password string) (*models.Wallet, error) {
 	storage, err := s.loadWalletStorage()
 	if err != nil {
@@ -95,16 +408,55 @@ func (s *Storage) LoadWallet(id, password string) (*models.Wallet, error) {
 
 	for _, encWallet := range storage.Wallets {
 		if encWallet.ID == id {
+			if encWallet.IsWatchOnly() {
+				return nil, models.ErrWatchOnly
+			}
+
 			walletData, err := Decrypt(encWallet.Data, password)
 			if err != nil {
 				return nil, fmt.Errorf("failed to decrypt wallet: %w", err)
 			}
 
 			var wallet models.Wallet
-			if err := json.Unmarshal(walletData, &wallet); err != nil {
+			secret := walletSecret{Wallet: &wallet}
+			if err := json.Unmarshal(walletData, &secret); err != nil {
 				return nil, fmt.Errorf("failed to unmarshal wallet: %w", err)
 			}
 
+			if !strings.EqualFold(wallet.Address, encWallet.Address) {
+				return nil, fmt.Errorf("%w: record lists %s but encrypted data holds %s", ErrAddressMismatch, encWallet.Address, wallet.Address)
+			}
+
+			if wallet.Source == "" {
+				wallet.Source = encWallet.Source
+			}
+			wallet.Accounts = encWallet.Accounts
+
+			if encWallet.Keystore == nil {
+				return nil, ErrKeystoreMissing
+			}
+			if err := encWallet.Keystore.unlock(&wallet, password); err != nil {
+				return nil, err
+			}
+
+			needsKey := secret.ProfileKey == nil
+			if !needsKey {
+				s.unlockContacts(secret.ProfileKey)
+			} else if key, _ := s.ensureProfileKey(); key == nil {
+				// The contacts use a key this wallet has never been given
+				needsKey = false
+			}
+
+			// Move data sealed with an older envelope or different KDF
+			// parameters onto the current ones, and give the wallet a copy of
+			// the profile key, while the password is at hand. The unlock has
+			// succeeded either way, so a failed upgrade is left for the next
+			// one.
+			if needsKey || !encWallet.Data.UsesKDF(s.kdf) || !encWallet.Keystore.Data.UsesKDF(s.kdf) {
+				upgrade := wallet
+				s.SaveWallet(&upgrade, password)
+			}
+
 			return &wallet, nil
 		}
 	}
@@ -112,6 +464,43 @@ func (s *Storage) LoadWallet(id, password string) (*models.Wallet, error) {
 	return nil, fmt.Errorf("wallet not found")
 }
 
+// SaveAccounts replaces a wallet's account list. The first account must be
+// the wallet's own address; derived addresses are re-checked against the
+// mnemonic whenever an account is used.
+func (s *Storage) SaveAccounts(id string, accounts []models.Account) error {
+	storage, err := s.loadWalletStorage()
+	if err != nil {
+		return err
+	}
+
+	for i := range storage.Wallets {
+		wallet := &storage.Wallets[i]
+		if wallet.ID != id {
+			continue
+		}
+
+		if len(accounts) == 0 || !strings.EqualFold(accounts[0].Address, wallet.Address) {
+			return fmt.Errorf("first account must be the wallet address %s", wallet.Address)
+		}
+		seen := make(map[string]bool, len(accounts))
+		for _, account := range accounts {
+			if strings.TrimSpace(account.Label) == "" {
+				return fmt.Errorf("account %s has no label", account.Address)
+			}
+			address := strings.ToLower(account.Address)
+			if seen[address] {
+				return fmt.Errorf("duplicate account %s", account.Address)
+			}
+			seen[address] = true
+		}
+
+		wallet.Accounts = accounts
+		return s.saveWalletStorage(storage)
+	}
+
+	return fmt.Errorf("wallet not found")
+}
+
 func (s *Storage) ListWallets() ([]EncryptedWallet, error) {
 	storage, err := s.loadWalletStorage()
 	if err != nil {
@@ -137,13 +526,11 @@ func (s *Storage) DeleteWallet(id string) error {
 }
 
 func (s *Storage) SaveContacts(contacts *models.ContactList) error {
-	data, err := json.MarshalIndent(contacts, "", "  ")
+	doc, err := s.encodeContacts(contacts)
 	if err != nil {
-		return fmt.Errorf("failed to marshal contacts: %w", err)
+		return err
 	}
-
-	filePath := filepath.Join(s.dataDir, contactsFile)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+	if err := s.writeDataFileWith(contactsFile, doc, s.mergeContacts); err != nil {
 		return fmt.Errorf("failed to write contacts file: %w", err)
 	}
 
@@ -151,33 +538,24 @@ func (s *Storage) SaveContacts(contacts *models.ContactList) error {
 }
 
 func (s *Storage) LoadContacts() (*models.ContactList, error) {
-	filePath := filepath.Join(s.dataDir, contactsFile)
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return &models.ContactList{Contacts: []models.Contact{}}, nil
-	}
-
-	data, err := os.ReadFile(filePath)
+	data, err := s.readVersionedFile(contactsFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read contacts file: %w", err)
+		return nil, err
 	}
-
-	var contacts models.ContactList
-	if err := json.Unmarshal(data, &contacts); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal contacts: %w", err)
+	if data == nil {
+		return &models.ContactList{Contacts: []models.Contact{}}, nil
 	}
 
-	return &contacts, nil
+	return s.decodeContacts(data)
 }
 
-func (s *Storage) SaveConfig(config *Config) error {
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
+func (s *Storage) ConfigPath() string {
+	return filepath.Join(s.dataDir, configFile)
+}
 
-	filePath := filepath.Join(s.dataDir, configFile)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
+func (s *Storage) SaveConfig(config *Config) error {
+	config.Version = ConfigVersion
+	if err := s.writeDataFile(configFile, config); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
@@ -185,39 +563,36 @@ func (s *Storage) SaveConfig(config *Config) error {
 }
 
 func (s *Storage) LoadConfig() (*Config, error) {
-	filePath := filepath.Join(s.dataDir, configFile)
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		defaultConfig := &Config{
-			Network: "mainnet",
-			Theme:   "catppuccin",
-		}
-		return defaultConfig, nil
-	}
-
-	data, err := os.ReadFile(filePath)
+	data, err := s.readVersionedFile(configFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, err
+	}
+	if data == nil {
+		// Without a file the defaults are what a later save merges against
+		config := DefaultConfig()
+		config.Version = ConfigVersion
+		if defaults, err := json.MarshalIndent(config, "", "  "); err == nil {
+			s.remember(configFile, defaults)
+		}
+		return config, nil
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Start from the defaults so fields missing from older files keep sane values
+	config := DefaultConfig()
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 func (s *Storage) loadWalletStorage() (*WalletStorage, error) {
-	filePath := filepath.Join(s.dataDir, walletsFile)
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return &WalletStorage{Wallets: []EncryptedWallet{}}, nil
-	}
-
-	data, err := os.ReadFile(filePath)
+	data, err := s.readVersionedFile(walletsFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read wallets file: %w", err)
+		return nil, err
+	}
+	if data == nil {
+		return &WalletStorage{Version: WalletsVersion, Wallets: []EncryptedWallet{}}, nil
 	}
 
 	var storage WalletStorage
@@ -229,15 +604,49 @@ func (s *Storage) loadWalletStorage() (*WalletStorage, error) {
 }
 
 func (s *Storage) saveWalletStorage(storage *WalletStorage) error {
-	data, err := json.MarshalIndent(storage, "", "  ")
+	storage.Version = WalletsVersion
+	if err := s.writeDataFile(walletsFile, storage); err != nil {
+		return fmt.Errorf("failed to write wallets file: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic replaces path with data by writing a synced temporary file
+// beside it and renaming it into place, so readers and crashes only ever see
+// the old contents or the new ones
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
 	if err != nil {
-		return fmt.Errorf("failed to marshal wallet storage: %w", err)
+		return err
 	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
 
-	filePath := filepath.Join(s.dataDir, walletsFile)
-	if err := os.WriteFile(filePath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write wallets file: %w", err)
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
 	}
 
+	// Sync the directory so the rename itself survives a crash
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
 	return nil
 }
@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func TestSaveAccounts(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Seed", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	if _, err := wallet.AddAccount("", "Savings"); err != nil {
+		t.Fatalf("Failed to add account: %v", err)
+	}
+	if err := store.SaveAccounts(wallet.ID, wallet.Accounts); err != nil {
+		t.Fatalf("Failed to save accounts: %v", err)
+	}
+
+	loaded, err := store.LoadWallet(wallet.ID, testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	if len(loaded.Accounts) != 2 || loaded.Accounts[1].Label != "Savings" {
+		t.Fatalf("Accounts not restored: %+v", loaded.Accounts)
+	}
+	if _, err := loaded.UseAccount(1); err != nil {
+		t.Errorf("Loaded account should be usable: %v", err)
+	}
+
+	// The wallet's own address must stay first and addresses must be unique
+	reordered := []models.Account{wallet.Accounts[1], wallet.Accounts[0]}
+	if err := store.SaveAccounts(wallet.ID, reordered); err == nil {
+		t.Error("Expected error when the first account is not the wallet address")
+	}
+	duplicated := []models.Account{wallet.Accounts[0], wallet.Accounts[0]}
+	if err := store.SaveAccounts(wallet.ID, duplicated); err == nil {
+		t.Error("Expected error for duplicate accounts")
+	}
+}
+
+func TestAccountHistory(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Seed", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if _, err := wallet.AddAccount("", "Savings"); err != nil {
+		t.Fatalf("Failed to add account: %v", err)
+	}
+	savings, err := wallet.UseAccount(1)
+	if err != nil {
+		t.Fatalf("Failed to use account: %v", err)
+	}
+
+	// Both accounts share the wallet ID, so only the address tells them apart
+	sent := []*models.Transaction{
+		{ID: "from-primary", From: wallet.Address, To: "0xabc", Direction: models.TransactionDirectionSent},
+		{ID: "from-savings", From: savings.Address, To: "0xabc", Direction: models.TransactionDirectionSent},
+	}
+	for _, tx := range sent {
+		if err := store.SaveTransaction(savings.ID, tx); err != nil {
+			t.Fatalf("Failed to save transaction: %v", err)
+		}
+	}
+
+	primaryHistory, err := store.LoadAccountHistory(wallet.ID, wallet.Address)
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(primaryHistory) != 1 || primaryHistory[0].ID != "from-primary" {
+		t.Errorf("Expected only the primary account's transaction, got %+v", primaryHistory)
+	}
+
+	savingsHistory, err := store.LoadAccountHistory(savings.ID, savings.Address)
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(savingsHistory) != 1 || savingsHistory[0].ID != "from-savings" {
+		t.Errorf("Expected only the savings account's transaction, got %+v", savingsHistory)
+	}
+}
+
+func TestReceivedTransfersFlagDustSenders(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+
+	const (
+		realSender = "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed"
+		poisoner   = "0x7567d800000000000000000000000000b364ffed"
+	)
+	paidOut := &models.Transaction{ID: "paid", Hash: "0x01", From: wallet.Address, To: realSender, Amount: big.NewInt(1e18), Timestamp: time.Now().Add(-time.Hour), Direction: models.TransactionDirectionSent}
+	if err := store.SaveTransaction(wallet.ID, paidOut); err != nil {
+		t.Fatalf("Failed to save transaction: %v", err)
+	}
+
+	received := []models.Transaction{
+		*models.NewReceivedTransaction("0x02", 0, realSender, wallet.Address, big.NewInt(5e18), 10, time.Now().Add(-2*time.Hour)),
+		*models.NewReceivedTransaction("0x03", 0, poisoner, wallet.Address, big.NewInt(1), 20, time.Now().Add(-time.Minute)),
+	}
+	if added, err := store.RecordReceived(wallet.ID, received); err != nil || added != 2 {
+		t.Fatalf("Expected two transfers recorded, got %d, %v", added, err)
+	}
+	if added, err := store.RecordReceived(wallet.ID, received); err != nil || added != 0 {
+		t.Errorf("Expected recorded transfers to be skipped, got %d, %v", added, err)
+	}
+
+	history, err := store.LoadAccountHistory(wallet.ID, wallet.Address)
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(history) != 3 || history[0].Hash != "0x02" || history[2].Hash != "0x03" {
+		t.Fatalf("Expected history in time order, got %+v", history)
+	}
+
+	// The send view checks recipients against this history
+	warning := models.CheckRecipient(poisoner, nil, history)
+	if warning == nil || !warning.DustOnly {
+		t.Errorf("Expected a recipient that only sent dust to be flagged, got %+v", warning)
+	}
+	if warning := models.CheckRecipient(realSender, nil, history); warning != nil {
+		t.Errorf("Expected no warning for a real counterparty, got %v", warning.Reasons())
+	}
+}
+
+func TestWatchOnlyWallet(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWatchOnlyWallet("Treasury", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed")
+	if err != nil {
+		t.Fatalf("Failed to create watch-only wallet: %v", err)
+	}
+	if err := store.SaveWatchOnlyWallets([]*models.Wallet{wallet}); err != nil {
+		t.Fatalf("Failed to save watch-only wallet: %v", err)
+	}
+
+	wallets, _ := store.ListWallets()
+	if len(wallets) != 1 || !wallets[0].IsWatchOnly() || wallets[0].NeedsReimport() {
+		t.Fatalf("Unexpected stored record: %+v", wallets)
+	}
+
+	loaded, err := store.LoadWatchOnlyWallet(wallet.ID)
+	if err != nil {
+		t.Fatalf("Failed to open watch-only wallet: %v", err)
+	}
+	if loaded.Address != wallet.Address || loaded.PrivateKey != nil {
+		t.Errorf("Unexpected watch-only wallet: %+v", loaded)
+	}
+
+	// Nothing can be unlocked for signing
+	if _, err := store.LoadWallet(wallet.ID, testPassword); !errors.Is(err, models.ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly from LoadWallet, got %v", err)
+	}
+	if _, err := store.ExportWalletKeystore(wallet.ID, testPassword, testPassword, ""); !errors.Is(err, models.ErrWatchOnly) {
+		t.Errorf("Expected ErrWatchOnly from keystore export, got %v", err)
+	}
+
+	again, _ := models.NewWatchOnlyWallet("Again", wallet.Address)
+	if err := store.SaveWatchOnlyWallets([]*models.Wallet{again}); err == nil {
+		t.Error("Expected error for an address that is already stored")
+	}
+}
+
+func TestSavesMergeChangesFromOtherProcesses(t *testing.T) {
+	dir := t.TempDir()
+	first, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	second, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	shared := models.NewContact("Shared", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "")
+	shared.ID = "contact_shared"
+	if err := first.SaveContacts(&models.ContactList{Contacts: []models.Contact{*shared}}); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	firstList, err := first.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load contacts: %v", err)
+	}
+	secondList, err := second.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load contacts: %v", err)
+	}
+
+	// Each process edits its own copy and saves without reloading
+	alice := models.NewContact("Alice", "0x0000000000000000000000000000456e65726779", "")
+	alice.ID = "contact_alice"
+	firstList.Contacts = append(firstList.Contacts, *alice)
+	if err := first.SaveContacts(firstList); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+	bob := models.NewContact("Bob", "0xd3ae78222beadb038203be21ed5ce7c9b1bff602", "")
+	bob.ID = "contact_bob"
+	secondList.Contacts[0].Notes = "edited"
+	secondList.Contacts = append(secondList.Contacts, *bob)
+	if err := second.SaveContacts(secondList); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	merged, err := first.LoadContacts()
+	if err != nil {
+		t.Fatalf("Failed to load contacts: %v", err)
+	}
+	names := map[string]string{}
+	for _, contact := range merged.Contacts {
+		names[contact.Name] = contact.Notes
+	}
+	if len(merged.Contacts) != 3 || names["Shared"] != "edited" {
+		t.Fatalf("Expected Shared (edited), Alice and Bob, got %+v", merged.Contacts)
+	}
+
+	// Settings changed by different processes are both kept
+	firstConfig, _ := first.LoadConfig()
+	secondConfig, _ := second.LoadConfig()
+	firstConfig.Theme = "light"
+	secondConfig.Network = "testnet"
+	if err := first.SaveConfig(firstConfig); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	if err := second.SaveConfig(secondConfig); err != nil {
+		t.Fatalf("Failed to save config: %v", err)
+	}
+	config, err := first.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if config.Theme != "light" || config.Network != "testnet" {
+		t.Errorf("Expected both config changes, got theme %q network %q", config.Theme, config.Network)
+	}
+}
+
+func TestDamagedFileRecoversLastGoodCopy(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	contact := models.NewContact("Alice", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "")
+	list := &models.ContactList{Contacts: []models.Contact{*contact}}
+	if err := store.SaveContacts(list); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+	// The second save keeps the first as the last-known-good copy
+	if err := store.SaveContacts(list); err != nil {
+		t.Fatalf("Failed to save contacts: %v", err)
+	}
+
+	// Simulate a torn write
+	if err := os.WriteFile(filepath.Join(dir, contactsFile), []byte(`{"version": 1, "contac`), 0600); err != nil {
+		t.Fatalf("Failed to damage contacts: %v", err)
+	}
+
+	loaded, err := store.LoadContacts()
+	if err != nil {
+		t.Fatalf("Expected recovery from the last-known-good copy, got %v", err)
+	}
+	if len(loaded.Contacts) != 1 || loaded.Contacts[0].Name != "Alice" {
+		t.Errorf("Recovered wrong contacts: %+v", loaded.Contacts)
+	}
+	damaged, _ := filepath.Glob(filepath.Join(dir, backupsDir, contactsFile+".damaged.*"))
+	if len(damaged) != 1 {
+		t.Errorf("Expected the damaged file to be kept, found %v", damaged)
+	}
+}
+
+func TestLockTimesOut(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	previous := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	defer func() { lockTimeout = previous }()
+
+	err = store.withLock(func() error {
+		return store.SaveConfig(DefaultConfig())
+	})
+	if !errors.Is(err, ErrLocked) {
+		t.Errorf("Expected ErrLocked while another holder has the lock, got %v", err)
+	}
+}
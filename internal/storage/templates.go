@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+const templatesFile = "templates.json"
+
+// TemplateStorage holds each wallet's transaction templates and recently used
+// addresses, keyed by wallet ID
+type TemplateStorage struct {
+	Version int                         `json:"version"`
+	Wallets map[string]*WalletTemplates `json:"wallets"`
+}
+
+// WalletTemplates is one wallet's send-screen data
+type WalletTemplates struct {
+	Templates       []models.TransactionTemplate `json:"templates"`
+	RecentAddresses []models.RecentAddress       `json:"recent_addresses"`
+}
+
+// LoadTemplates returns the saved transaction templates for a wallet
+func (s *Storage) LoadTemplates(walletID string) ([]models.TransactionTemplate, error) {
+	templates, err := s.loadTemplateStorage()
+	if err != nil {
+		return nil, err
+	}
+	if wallet := templates.Wallets[walletID]; wallet != nil {
+		return wallet.Templates, nil
+	}
+	return nil, nil
+}
+
+// SaveTemplates replaces the saved transaction templates for a wallet
+func (s *Storage) SaveTemplates(walletID string, list []models.TransactionTemplate) error {
+	templates, err := s.loadTemplateStorage()
+	if err != nil {
+		return err
+	}
+	templates.wallet(walletID).Templates = list
+	return s.saveTemplateStorage(templates)
+}
+
+// LoadRecentAddresses returns the addresses a wallet recently sent to
+func (s *Storage) LoadRecentAddresses(walletID string) ([]models.RecentAddress, error) {
+	templates, err := s.loadTemplateStorage()
+	if err != nil {
+		return nil, err
+	}
+	if wallet := templates.Wallets[walletID]; wallet != nil {
+		return wallet.RecentAddresses, nil
+	}
+	return nil, nil
+}
+
+// SaveRecentAddresses replaces the addresses a wallet recently sent to
+func (s *Storage) SaveRecentAddresses(walletID string, addresses []models.RecentAddress) error {
+	templates, err := s.loadTemplateStorage()
+	if err != nil {
+		return err
+	}
+	templates.wallet(walletID).RecentAddresses = addresses
+	return s.saveTemplateStorage(templates)
+}
+
+func (ts *TemplateStorage) wallet(walletID string) *WalletTemplates {
+	wallet := ts.Wallets[walletID]
+	if wallet == nil {
+		wallet = &WalletTemplates{}
+		ts.Wallets[walletID] = wallet
+	}
+	return wallet
+}
+
+func (s *Storage) loadTemplateStorage() (*TemplateStorage, error) {
+	data, err := s.readVersionedFile(templatesFile)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return &TemplateStorage{Version: TemplatesVersion, Wallets: make(map[string]*WalletTemplates)}, nil
+	}
+
+	var templates TemplateStorage
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal templates: %w", err)
+	}
+	if templates.Wallets == nil {
+		templates.Wallets = make(map[string]*WalletTemplates)
+	}
+
+	return &templates, nil
+}
+
+func (s *Storage) saveTemplateStorage(templates *TemplateStorage) error {
+	templates.Version = TemplatesVersion
+	if err := s.writeDataFile(templatesFile, templates); err != nil {
+		return fmt.Errorf("failed to write templates file: %w", err)
+	}
+
+	return nil
+}
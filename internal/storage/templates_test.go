@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"math/big"
+	"testing"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func TestTemplatesPersistPerWallet(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	templates := models.NewTransactionTemplateManager()
+	rent := models.NewTransactionTemplate("Rent", "", "0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "Alice", "VET", "", big.NewInt(1e18), []string{"bills"})
+	rent.ToggleFavorite()
+	templates.AddTemplate(rent)
+	if err := store.SaveTemplates("wallet-a", templates.Export()); err != nil {
+		t.Fatalf("Failed to save templates: %v", err)
+	}
+
+	recent := models.NewRecentAddressManager(50)
+	recent.AddAddress("0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "Alice", "VET", big.NewInt(1e18))
+	recent.AddAddress("0x7567d83b7b8d80addcb281a71d54fc7b3364ffed", "Alice", "VET", big.NewInt(2e18))
+	if err := store.SaveRecentAddresses("wallet-a", recent.Export()); err != nil {
+		t.Fatalf("Failed to save recent addresses: %v", err)
+	}
+
+	reopened, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	loaded, err := reopened.LoadTemplates("wallet-a")
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+	restored := models.NewTransactionTemplateManager()
+	restored.Import(loaded)
+	got := restored.FindByID(rent.ID)
+	if got == nil || !got.IsFavorite || got.Amount.Cmp(big.NewInt(1e18)) != 0 || len(got.Tags) != 1 {
+		t.Fatalf("Expected the favorite Rent template back, got %+v", loaded)
+	}
+
+	addresses, err := reopened.LoadRecentAddresses("wallet-a")
+	if err != nil {
+		t.Fatalf("Failed to load recent addresses: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0].UseCount != 2 || addresses[0].LastAmount.Cmp(big.NewInt(2e18)) != 0 {
+		t.Fatalf("Expected one address used twice, got %+v", addresses)
+	}
+
+	// Saving one list leaves the other alone
+	if err := reopened.SaveTemplates("wallet-a", nil); err != nil {
+		t.Fatalf("Failed to save templates: %v", err)
+	}
+	if addresses, _ := reopened.LoadRecentAddresses("wallet-a"); len(addresses) != 1 {
+		t.Errorf("Expected recent addresses to survive a template save, got %+v", addresses)
+	}
+
+	if other, _ := reopened.LoadTemplates("wallet-b"); len(other) != 0 {
+		t.Errorf("Expected no templates for another wallet, got %+v", other)
+	}
+}
@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+// ErrTwoFactorMissing is returned when a second factor is needed but the
+// wallet has none
+var ErrTwoFactorMissing = errors.New("wallet has no second factor")
+
+// SetTwoFactor enrols the wallet in a TOTP second factor, replacing any
+// existing one, or removes it when twoFactor is nil. The wallet is unlocked
+// with password so the secret can be sealed in its keystore.
+func (s *Storage) SetTwoFactor(id, password string, twoFactor *models.TwoFactor) error {
+	wallet, err := s.LoadWallet(id, password)
+	if err != nil {
+		return err
+	}
+
+	wallet.TwoFactor = twoFactor
+	if err := s.saveWallet(wallet, password, true); err != nil {
+		return fmt.Errorf("failed to save second factor: %w", err)
+	}
+	return nil
+}
+
+// UseRecoveryCode spends one of the wallet's recovery codes, reporting
+// whether code was an unused one. Recovery code hashes are stored outside the
+// encrypted secret, so no password is needed.
+func (s *Storage) UseRecoveryCode(id, code string) (bool, error) {
+	return s.updateTwoFactor(id, func(twoFactor *models.TwoFactor) bool {
+		return twoFactor.UseRecoveryCode(code)
+	})
+}
+
+// UseTOTPCode records counter as the wallet's last accepted TOTP time step,
+// reporting false when a code for that step or a later one was already
+// accepted, by this process or another
+func (s *Storage) UseTOTPCode(id string, counter int64) (bool, error) {
+	return s.updateTwoFactor(id, func(twoFactor *models.TwoFactor) bool {
+		if counter <= twoFactor.LastCounter {
+			return false
+		}
+		twoFactor.LastCounter = counter
+		return true
+	})
+}
+
+// SecondFactorAttempts returns how many wrong codes were entered for the
+// wallet since the last accepted one and when the lockout they caused ends
+func (s *Storage) SecondFactorAttempts(id string) (int, time.Time, error) {
+	wallets, err := s.ListWallets()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	for _, wallet := range wallets {
+		if wallet.ID != id {
+			continue
+		}
+		if !wallet.HasTwoFactor() {
+			return 0, time.Time{}, ErrTwoFactorMissing
+		}
+		return wallet.Keystore.TwoFactor.FailedAttempts, wallet.Keystore.TwoFactor.LockedUntil, nil
+	}
+	return 0, time.Time{}, fmt.Errorf("wallet not found")
+}
+
+// RecordSecondFactorFailure counts a wrong code for the wallet, locking it
+// until lockout(failures) from now when that is not zero. It returns the new
+// count and the end of the lockout.
+func (s *Storage) RecordSecondFactorFailure(id string, lockout func(failures int) time.Duration) (int, time.Time, error) {
+	var (
+		failures    int
+		lockedUntil time.Time
+	)
+	_, err := s.updateTwoFactor(id, func(twoFactor *models.TwoFactor) bool {
+		twoFactor.FailedAttempts++
+		if duration := lockout(twoFactor.FailedAttempts); duration > 0 {
+			twoFactor.LockedUntil = time.Now().Add(duration)
+		}
+		failures, lockedUntil = twoFactor.FailedAttempts, twoFactor.LockedUntil
+		return true
+	})
+	return failures, lockedUntil, err
+}
+
+// ClearSecondFactorFailures resets the wallet's wrong code count and lockout
+// after a code is accepted
+func (s *Storage) ClearSecondFactorFailures(id string) error {
+	_, err := s.updateTwoFactor(id, func(twoFactor *models.TwoFactor) bool {
+		if twoFactor.FailedAttempts == 0 && twoFactor.LockedUntil.IsZero() {
+			return false
+		}
+		twoFactor.FailedAttempts = 0
+		twoFactor.LockedUntil = time.Time{}
+		return true
+	})
+	return err
+}
+
+// updateTwoFactor applies update to the wallet's stored second factor and
+// saves it when update reports a change. The stored factor is read, checked
+// and written under the profile lock, so two processes cannot both accept
+// the same code. It holds no secret, so no password is needed.
+func (s *Storage) updateTwoFactor(id string, update func(*models.TwoFactor) bool) (bool, error) {
+	var (
+		storage WalletStorage
+		changed bool
+	)
+	err := s.updateDataFile(walletsFile, &storage, func() (bool, error) {
+		for i := range storage.Wallets {
+			wallet := &storage.Wallets[i]
+			if wallet.ID != id {
+				continue
+			}
+			if !wallet.HasTwoFactor() {
+				return false, ErrTwoFactorMissing
+			}
+			changed = update(wallet.Keystore.TwoFactor)
+			return changed, nil
+		}
+		return false, fmt.Errorf("wallet not found")
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to save second factor: %w", err)
+	}
+	return changed, nil
+}
+
+// keepTwoFactor carries the stored second factor over to a rebuilt keystore.
+// The stored recovery codes, last code and failed attempts win, since codes
+// tried since the wallet was unlocked are only recorded in the stored record.
+func keepTwoFactor(keystore, existing *Keystore) error {
+	if existing == nil || existing.TwoFactor == nil {
+		keystore.TwoFactor = nil
+		return nil
+	}
+	if keystore.TwoFactor == nil {
+		// Dropping the factor here would disable it without a code
+		return fmt.Errorf("wallet has a second factor that was not loaded; unlock it before saving")
+	}
+	keystore.TwoFactor.RecoveryCodes = append([]string(nil), existing.TwoFactor.RecoveryCodes...)
+	keystore.TwoFactor.LastCounter = max(keystore.TwoFactor.LastCounter, existing.TwoFactor.LastCounter)
+	keystore.TwoFactor.FailedAttempts = existing.TwoFactor.FailedAttempts
+	keystore.TwoFactor.LockedUntil = existing.TwoFactor.LockedUntil
+	return nil
+}
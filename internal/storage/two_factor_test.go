@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+func TestTwoFactorStoredInKeystore(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+
+	twoFactor, codes, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := store.SetTwoFactor(wallet.ID, testPassword, twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(store.DataDir(), walletsFile))
+	if strings.Contains(string(data), twoFactor.Secret) {
+		t.Fatal("TOTP secret must not be stored in plaintext")
+	}
+
+	wallets, err := store.ListWallets()
+	if err != nil || len(wallets) != 1 || !wallets[0].HasTwoFactor() {
+		t.Fatalf("Expected the wallet record to show a second factor, got %v", err)
+	}
+
+	loaded, err := store.LoadWallet(wallet.ID, testPassword)
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	if loaded.TwoFactor == nil || loaded.TwoFactor.Secret != twoFactor.Secret {
+		t.Fatal("Expected the TOTP secret to be restored on unlock")
+	}
+
+	// A recovery code spent while the wallet is unlocked must stay spent when
+	// the unlocked copy is saved again
+	if ok, err := store.UseRecoveryCode(wallet.ID, codes[0]); err != nil || !ok {
+		t.Fatalf("Expected recovery code to be accepted, got %v, %v", ok, err)
+	}
+	if ok, _ := store.UseRecoveryCode(wallet.ID, codes[0]); ok {
+		t.Error("Expected a recovery code to be accepted only once")
+	}
+	if err := store.ChangeWalletPassword(wallet.ID, testPassword, "another-password-123"); err != nil {
+		t.Fatalf("Failed to change password: %v", err)
+	}
+	if err := store.SaveWallet(loaded, "another-password-123"); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	if ok, _ := store.UseRecoveryCode(wallet.ID, codes[0]); ok {
+		t.Error("Expected a spent recovery code to stay spent after saving")
+	}
+	if ok, _ := store.UseRecoveryCode(wallet.ID, codes[1]); !ok {
+		t.Error("Expected unused recovery codes to survive a password change")
+	}
+
+	// Saving a copy without the factor must not silently remove it
+	loaded.TwoFactor = nil
+	if err := store.SaveWallet(loaded, "another-password-123"); err == nil {
+		t.Error("Expected saving without the second factor to fail")
+	}
+
+	if err := store.SetTwoFactor(wallet.ID, "another-password-123", nil); err != nil {
+		t.Fatalf("Failed to remove second factor: %v", err)
+	}
+	reloaded, err := store.LoadWallet(wallet.ID, "another-password-123")
+	if err != nil {
+		t.Fatalf("Failed to load wallet: %v", err)
+	}
+	if reloaded.TwoFactor != nil {
+		t.Error("Expected the second factor to be removed")
+	}
+}
+
+func TestRemovedTwoFactorRecordRefusesUnlock(t *testing.T) {
+	store, err := NewStorageAt(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	twoFactor, _, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := store.SetTwoFactor(wallet.ID, testPassword, twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+
+	// Deleting the plaintext record must not turn the second factor off
+	wallets, err := store.loadWalletStorage()
+	if err != nil {
+		t.Fatalf("Failed to load wallets: %v", err)
+	}
+	wallets.Wallets[0].Keystore.TwoFactor = nil
+	if err := store.saveWalletStorage(wallets); err != nil {
+		t.Fatalf("Failed to save wallets: %v", err)
+	}
+
+	if _, err := store.LoadWallet(wallet.ID, testPassword); !errors.Is(err, ErrTwoFactorRemoved) {
+		t.Errorf("Expected unlocking to be refused, got %v", err)
+	}
+}
+
+func TestTwoFactorCodesAcceptedOnceAcrossProcesses(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStorageAt(dir)
+	if err != nil {
+		t.Fatalf("Failed to open storage: %v", err)
+	}
+	wallet, err := models.NewWallet("Main", testMnemonic)
+	if err != nil {
+		t.Fatalf("Failed to create wallet: %v", err)
+	}
+	if err := store.SaveWallet(wallet, testPassword); err != nil {
+		t.Fatalf("Failed to save wallet: %v", err)
+	}
+	twoFactor, codes, err := models.NewTwoFactor()
+	if err != nil {
+		t.Fatalf("Failed to create second factor: %v", err)
+	}
+	if err := store.SetTwoFactor(wallet.ID, testPassword, twoFactor); err != nil {
+		t.Fatalf("Failed to enrol second factor: %v", err)
+	}
+
+	// Each storage stands in for a process sharing the profile, such as the
+	// TUI and the daemon
+	stores := []*Storage{store}
+	for i := 0; i < 7; i++ {
+		other, err := NewStorageAt(dir)
+		if err != nil {
+			t.Fatalf("Failed to open storage: %v", err)
+		}
+		stores = append(stores, other)
+	}
+
+	const rounds = 5
+	accepted := make(chan bool, 2*rounds*len(stores))
+	var wg sync.WaitGroup
+	for _, s := range stores {
+		wg.Add(1)
+		go func(s *Storage) {
+			defer wg.Done()
+			for round := 0; round < rounds; round++ {
+				ok, err := s.UseTOTPCode(wallet.ID, int64(100+round))
+				if err != nil {
+					t.Errorf("UseTOTPCode failed: %v", err)
+				}
+				accepted <- ok
+				ok, err = s.UseRecoveryCode(wallet.ID, codes[round])
+				if err != nil {
+					t.Errorf("UseRecoveryCode failed: %v", err)
+				}
+				accepted <- ok
+			}
+		}(s)
+	}
+	wg.Wait()
+	close(accepted)
+
+	count := 0
+	for ok := range accepted {
+		if ok {
+			count++
+		}
+	}
+	if count > 2*rounds {
+		t.Errorf("Expected each TOTP step and recovery code to be accepted at most once, got %d acceptances for %d codes", count, 2*rounds)
+	}
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"rhystmorgan/veWallet/internal/models"
+)
+
+// IsWatchOnly reports whether the record tracks an address without a key
+func (w EncryptedWallet) IsWatchOnly() bool {
+	return w.Source == models.KeySourceWatchOnly
+}
+
+// NeedsReimport reports whether the record lost its signing key and must be
+// imported again before it can be used. Watch-only wallets never had one.
+func (w EncryptedWallet) NeedsReimport() bool {
+	return !w.HasKeystore() && !w.IsWatchOnly()
+}
+
+// SaveWatchOnlyWallets stores wallets that only track an address. They hold
+// no secret, so nothing is encrypted and no password is needed to open them.
+// Either every wallet is saved or none is; an address that is already
+// stored is an error.
+func (s *Storage) SaveWatchOnlyWallets(wallets []*models.Wallet) error {
+	storage, err := s.loadWalletStorage()
+	if err != nil {
+		return err
+	}
+
+	stored := make(map[string]string, len(storage.Wallets))
+	for _, existing := range storage.Wallets {
+		stored[strings.ToLower(existing.Address)] = existing.Name
+	}
+
+	for _, wallet := range wallets {
+		if !wallet.IsWatchOnly() || wallet.Mnemonic != "" || wallet.PrivateKey != nil {
+			return fmt.Errorf("wallet %s is not watch-only", wallet.Name)
+		}
+		address := strings.ToLower(wallet.Address)
+		if name, ok := stored[address]; ok {
+			return fmt.Errorf("address %s is already stored as %s", wallet.Address, name)
+		}
+		stored[address] = wallet.Name
+
+		storage.Wallets = append(storage.Wallets, EncryptedWallet{
+			ID:        wallet.ID,
+			Name:      wallet.Name,
+			Address:   wallet.Address,
+			Source:    models.KeySourceWatchOnly,
+			Accounts:  wallet.Accounts,
+			CreatedAt: wallet.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	return s.saveWalletStorage(storage)
+}
+
+// LoadWatchOnlyWallet opens a watch-only wallet. It has no key, so no
+// password is asked for.
+func (s *Storage) LoadWatchOnlyWallet(id string) (*models.Wallet, error) {
+	storage, err := s.loadWalletStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, encWallet := range storage.Wallets {
+		if encWallet.ID != id {
+			continue
+		}
+		if !encWallet.IsWatchOnly() {
+			return nil, fmt.Errorf("wallet %s is not watch-only", encWallet.Name)
+		}
+
+		wallet := &models.Wallet{
+			ID:       encWallet.ID,
+			Name:     encWallet.Name,
+			Address:  encWallet.Address,
+			Source:   models.KeySourceWatchOnly,
+			Accounts: encWallet.Accounts,
+		}
+		if createdAt, err := time.Parse(time.RFC3339, encWallet.CreatedAt); err == nil {
+			wallet.CreatedAt = createdAt
+		}
+		return wallet, nil
+	}
+
+	return nil, fmt.Errorf("wallet not found")
+}
+
+// ReadWatchListFile loads watch-only wallets from a file listing addresses;
+// see models.ParseWatchList for the format
+func ReadWatchListFile(path, name string) ([]*models.Wallet, error) {
+	file, err := os.Open(expandPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open address list: %w", err)
+	}
+	defer file.Close()
+
+	return models.ParseWatchList(file, name)
+}
+
+// ExportUnsignedTransaction writes a built transaction for address to the
+// profile's exports directory as JSON, for signing on the machine that holds
+// the key. It returns the path written.
+func (s *Storage) ExportUnsignedTransaction(address string, unsigned interface{}) (string, error) {
+	data, err := json.MarshalIndent(unsigned, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal transaction: %w", err)
+	}
+
+	timestamp := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	name := fmt.Sprintf("unsigned-tx--%s--%s.json", timestamp, strings.ToLower(strings.TrimPrefix(address, "0x")))
+	path := filepath.Join(s.dataDir, exportsDir, name)
+
+	if err := writeExportFile(path, data); err != nil {
+		return "", fmt.Errorf("failed to write transaction file: %w", err)
+	}
+
+	return path, nil
+}
@@ -1,5 +1,7 @@
 package utils
 
+import "fmt"
+
 // ColourScheme defines the Catppuccin color scheme used throughout the application
 type ColourScheme struct {
 	Rosewater string
@@ -30,11 +32,17 @@ type ColourScheme struct {
 	Crust     string
 }
 
-// Colours provides the default Catppuccin color scheme
-var Colours = ColourScheme{
+// DefaultTheme is the theme used when none is configured
+const DefaultTheme = "catppuccin"
+
+// Colours provides the active color scheme, Catppuccin Mocha by default
+var Colours = catppuccinMocha
+
+var catppuccinMocha = ColourScheme{
 	Rosewater: "#f5e0dc",
 	Flamingo:  "#f2cdcd",
 	Pink:      "#f5c2e7",
+	Mauve:     "#cba6f7",
 	Red:       "#f38ba8",
 	Maroon:    "#eba0ac",
 	Peach:     "#fab387",
@@ -58,3 +66,122 @@ var Colours = ColourScheme{
 	Mantle:    "#181825",
 	Crust:     "#11111b",
 }
+
+var catppuccinMacchiato = ColourScheme{
+	Rosewater: "#f4dbd6",
+	Flamingo:  "#f0c6c6",
+	Pink:      "#f5bde6",
+	Mauve:     "#c6a0f6",
+	Red:       "#ed8796",
+	Maroon:    "#ee99a0",
+	Peach:     "#f5a97f",
+	Yellow:    "#eed49f",
+	Green:     "#a6da95",
+	Teal:      "#8bd5ca",
+	Sky:       "#91d7e3",
+	Sapphire:  "#7dc4e4",
+	Blue:      "#8aadf4",
+	Lavender:  "#b7bdf8",
+	Text:      "#cad3f5",
+	Subtext1:  "#b8c0e0",
+	Subtext0:  "#a5adcb",
+	Overlay2:  "#939ab7",
+	Overlay1:  "#8087a2",
+	Overlay0:  "#6e738d",
+	Surface2:  "#5b6078",
+	Surface1:  "#494d64",
+	Surface0:  "#363a4f",
+	Base:      "#24273a",
+	Mantle:    "#1e2030",
+	Crust:     "#181926",
+}
+
+var catppuccinFrappe = ColourScheme{
+	Rosewater: "#f2d5cf",
+	Flamingo:  "#eebebe",
+	Pink:      "#f4b8e4",
+	Mauve:     "#ca9ee6",
+	Red:       "#e78284",
+	Maroon:    "#ea999c",
+	Peach:     "#ef9f76",
+	Yellow:    "#e5c890",
+	Green:     "#a6d189",
+	Teal:      "#81c8be",
+	Sky:       "#99d1db",
+	Sapphire:  "#85c1dc",
+	Blue:      "#8caaee",
+	Lavender:  "#babbf1",
+	Text:      "#c6d0f5",
+	Subtext1:  "#b5bfe2",
+	Subtext0:  "#a5adce",
+	Overlay2:  "#949cbb",
+	Overlay1:  "#838ba7",
+	Overlay0:  "#737994",
+	Surface2:  "#626880",
+	Surface1:  "#51576d",
+	Surface0:  "#414559",
+	Base:      "#303446",
+	Mantle:    "#292c3c",
+	Crust:     "#232634",
+}
+
+var catppuccinLatte = ColourScheme{
+	Rosewater: "#dc8a78",
+	Flamingo:  "#dd7878",
+	Pink:      "#ea76cb",
+	Mauve:     "#8839ef",
+	Red:       "#d20f39",
+	Maroon:    "#e64553",
+	Peach:     "#fe640b",
+	Yellow:    "#df8e1d",
+	Green:     "#40a02b",
+	Teal:      "#179299",
+	Sky:       "#04a5e5",
+	Sapphire:  "#209fb5",
+	Blue:      "#1e66f5",
+	Lavender:  "#7287fd",
+	Text:      "#4c4f69",
+	Subtext1:  "#5c5f77",
+	Subtext0:  "#6c6f85",
+	Overlay2:  "#7c7f93",
+	Overlay1:  "#8c8fa1",
+	Overlay0:  "#9ca0b0",
+	Surface2:  "#acb0be",
+	Surface1:  "#bcc0cc",
+	Surface0:  "#ccd0da",
+	Base:      "#eff1f5",
+	Mantle:    "#e6e9ef",
+	Crust:     "#dce0e8",
+}
+
+// ThemeNames lists the available themes in display order
+var ThemeNames = []string{
+	"catppuccin",
+	"catppuccin-macchiato",
+	"catppuccin-frappe",
+	"catppuccin-latte",
+}
+
+var themes = map[string]ColourScheme{
+	"catppuccin":           catppuccinMocha,
+	"catppuccin-macchiato": catppuccinMacchiato,
+	"catppuccin-frappe":    catppuccinFrappe,
+	"catppuccin-latte":     catppuccinLatte,
+}
+
+// IsValidTheme reports whether name is a known theme
+func IsValidTheme(name string) bool {
+	_, exists := themes[name]
+	return exists
+}
+
+// ApplyTheme switches the active colour scheme. Views build their styles from
+// Colours on every render, so the change is visible on the next frame.
+func ApplyTheme(name string) error {
+	scheme, exists := themes[name]
+	if !exists {
+		return fmt.Errorf("unknown theme: %s", name)
+	}
+	Colours = scheme
+	return nil
+}
@@ -1,15 +1,18 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
 )
 
 type ExportFormat int
@@ -25,7 +28,11 @@ type ImportExportOptions struct {
 	IncludeMetadata bool
 	IncludeUsage    bool
 	IncludeTags     bool
-	Encrypt         bool
+	// Encrypt writes exports with age using Age. Imports detect age files on
+	// their own and decrypt them with AgeKeys.
+	Encrypt bool
+	Age     storage.AgeOptions
+	AgeKeys storage.AgeKeys
 }
 
 type ImportResult struct {
@@ -116,7 +123,8 @@ func NewContactImporter(options ImportExportOptions) *ContactImporter {
 	}
 }
 
-// ExportContacts exports contacts to the specified format
+// ExportContacts exports contacts to the specified format, encrypted with
+// age when the options ask for it
 func (e *ContactExporter) ExportContacts(contacts []models.Contact) error {
 	// Ensure directory exists
 	dir := filepath.Dir(e.options.FilePath)
@@ -124,18 +132,35 @@ func (e *ContactExporter) ExportContacts(contacts []models.Contact) error {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
+	var buf bytes.Buffer
+	var err error
 	switch e.options.Format {
 	case FormatJSON:
-		return e.exportJSON(contacts)
+		err = e.exportJSON(&buf, contacts)
 	case FormatCSV:
-		return e.exportCSV(contacts)
+		err = e.exportCSV(&buf, contacts)
 	default:
 		return fmt.Errorf("unsupported export format")
 	}
+	if err != nil {
+		return err
+	}
+
+	data := buf.Bytes()
+	if e.options.Encrypt {
+		if data, err = storage.EncryptAge(data, e.options.Age); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(e.options.FilePath, data, 0600); err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	return nil
 }
 
 // exportJSON exports contacts to JSON format
-func (e *ContactExporter) exportJSON(contacts []models.Contact) error {
+func (e *ContactExporter) exportJSON(w io.Writer, contacts []models.Contact) error {
 	exportData := make([]ContactImportData, 0, len(contacts))
 
 	for _, contact := range contacts {
@@ -179,13 +204,7 @@ func (e *ContactExporter) exportJSON(contacts []models.Contact) error {
 		Contacts:      exportData,
 	}
 
-	file, err := os.Create(e.options.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
+	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
 
 	if err := encoder.Encode(exportWrapper); err != nil {
@@ -196,14 +215,8 @@ func (e *ContactExporter) exportJSON(contacts []models.Contact) error {
 }
 
 // exportCSV exports contacts to CSV format
-func (e *ContactExporter) exportCSV(contacts []models.Contact) error {
-	file, err := os.Create(e.options.FilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
+func (e *ContactExporter) exportCSV(w io.Writer, contacts []models.Contact) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Write header
@@ -253,26 +266,31 @@ func (e *ContactExporter) exportCSV(contacts []models.Contact) error {
 	return nil
 }
 
-// ImportContacts imports contacts from the specified format
+// ImportContacts imports contacts from the specified format, decrypting
+// age-encrypted files first
 func (i *ContactImporter) ImportContacts() (*ImportResult, []ContactImportData, error) {
+	data, err := os.ReadFile(i.options.FilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	if storage.IsAgeEncrypted(data) {
+		if data, err = storage.DecryptAge(data, i.options.AgeKeys); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	switch i.options.Format {
 	case FormatJSON:
-		return i.importJSON()
+		return i.importJSON(bytes.NewReader(data))
 	case FormatCSV:
-		return i.importCSV()
+		return i.importCSV(bytes.NewReader(data))
 	default:
 		return nil, nil, fmt.Errorf("unsupported import format")
 	}
 }
 
 // importJSON imports contacts from JSON format
-func (i *ContactImporter) importJSON() (*ImportResult, []ContactImportData, error) {
-	file, err := os.Open(i.options.FilePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
+func (i *ContactImporter) importJSON(r io.Reader) (*ImportResult, []ContactImportData, error) {
 	var importWrapper struct {
 		ExportedAt    time.Time           `json:"exported_at"`
 		Version       string              `json:"version"`
@@ -280,7 +298,7 @@ func (i *ContactImporter) importJSON() (*ImportResult, []ContactImportData, erro
 		Contacts      []ContactImportData `json:"contacts"`
 	}
 
-	decoder := json.NewDecoder(file)
+	decoder := json.NewDecoder(r)
 	if err := decoder.Decode(&importWrapper); err != nil {
 		return nil, nil, fmt.Errorf("failed to decode JSON: %w", err)
 	}
@@ -314,14 +332,8 @@ func (i *ContactImporter) importJSON() (*ImportResult, []ContactImportData, erro
 }
 
 // importCSV imports contacts from CSV format
-func (i *ContactImporter) importCSV() (*ImportResult, []ContactImportData, error) {
-	file, err := os.Open(i.options.FilePath)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	reader := csv.NewReader(file)
+func (i *ContactImporter) importCSV(r io.Reader) (*ImportResult, []ContactImportData, error) {
+	reader := csv.NewReader(r)
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
@@ -605,12 +617,12 @@ func GenerateBackupFilename(format ExportFormat) string {
 
 // GetDefaultExportPath returns the default export path for the user's system
 func GetDefaultExportPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	homeDir, err := storage.HomeDir("")
 	if err != nil {
 		return "", err
 	}
 
-	exportDir := filepath.Join(homeDir, ".veterm", "exports")
+	exportDir := filepath.Join(homeDir, "exports")
 	if err := os.MkdirAll(exportDir, 0755); err != nil {
 		return "", err
 	}
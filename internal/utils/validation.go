@@ -9,7 +9,8 @@ import (
 	"unicode"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/tyler-smith/go-bip39"
+
+	"rhystmorgan/veWallet/internal/models"
 )
 
 // PasswordStrength represents the strength level of a password
@@ -84,22 +85,20 @@ func ValidatePassword(password string) (PasswordStrength, []string) {
 	return strength, issues
 }
 
-// ValidateMnemonic validates a BIP39 mnemonic phrase
+// ValidateMnemonic validates a BIP39 mnemonic phrase in any supported language
 func ValidateMnemonic(mnemonic string) bool {
-	return bip39.IsMnemonicValid(mnemonic)
+	_, err := models.ValidateMnemonic(mnemonic)
+	return err == nil
 }
 
-// ValidateMnemonicWords validates individual words against BIP39 wordlist
+// ValidateMnemonicWords validates individual words against the BIP39
+// wordlist the words as a whole are detected to be from
 func ValidateMnemonicWords(words []string) []bool {
-	wordList := bip39.GetWordList()
-	wordMap := make(map[string]bool)
-	for _, word := range wordList {
-		wordMap[word] = true
-	}
+	language, _ := models.DetectMnemonicLanguage(words)
 
 	results := make([]bool, len(words))
 	for i, word := range words {
-		results[i] = wordMap[strings.ToLower(strings.TrimSpace(word))]
+		results[i] = strings.TrimSpace(word) != "" && models.CheckMnemonicWord(language, word) == nil
 	}
 	return results
 }
@@ -134,9 +133,13 @@ func ValidateWalletName(name string) []string {
 // SplitMnemonic splits a mnemonic string into individual words
 func SplitMnemonic(mnemonic string) []string {
 	words := strings.Fields(strings.TrimSpace(mnemonic))
-	// Ensure we have exactly 12 words
-	result := make([]string, 12)
-	for i := 0; i < 12; i++ {
+	// Ensure we have exactly 12 words, or 24 for longer phrases
+	count := 12
+	if len(words) > 12 {
+		count = 24
+	}
+	result := make([]string, count)
+	for i := 0; i < count; i++ {
 		if i < len(words) {
 			result[i] = strings.ToLower(strings.TrimSpace(words[i]))
 		} else {
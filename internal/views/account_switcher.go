@@ -0,0 +1,267 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+type accountSwitcherMode int
+
+const (
+	accountModeList accountSwitcherMode = iota
+	accountModeRename
+	accountModeCustomPath
+)
+
+// AccountSwitcherModel lists a wallet's accounts, switches between them and,
+// for seed wallets, derives new ones
+type AccountSwitcherModel struct {
+	storage *storage.Storage
+	wallet  *models.Wallet
+
+	accounts []models.Account
+	cursor   int
+	mode     accountSwitcherMode
+	input    textinput.Model
+	saving   bool
+	err      string
+	visible  bool
+}
+
+// AccountSelectedMsg asks the app to act as another account of the wallet
+type AccountSelectedMsg struct {
+	Index int
+}
+
+// AccountsSavedMsg reports the wallet's account list after it was saved
+type AccountsSavedMsg struct {
+	Accounts []models.Account
+	Err      error
+}
+
+func NewAccountSwitcherModel(store *storage.Storage, wallet *models.Wallet) *AccountSwitcherModel {
+	input := textinput.New()
+	input.CharLimit = 64
+	input.Width = 40
+
+	return &AccountSwitcherModel{
+		storage:  store,
+		wallet:   wallet,
+		accounts: wallet.AccountList(),
+		cursor:   wallet.ActiveAccount,
+		input:    input,
+		visible:  true,
+	}
+}
+
+func (m *AccountSwitcherModel) IsVisible() bool {
+	return m.visible
+}
+
+// IsEditing reports whether a label or path is being typed
+func (m *AccountSwitcherModel) IsEditing() bool {
+	return m.visible && m.mode != accountModeList
+}
+
+func (m AccountSwitcherModel) Update(msg tea.Msg) (AccountSwitcherModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case AccountsSavedMsg:
+		m.saving = false
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.accounts = msg.Accounts
+		m.err = ""
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.saving {
+			return m, nil
+		}
+		if m.mode != accountModeList {
+			return m.updateInput(msg)
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.visible = false
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.accounts)-1 {
+				m.cursor++
+			}
+		case "enter", " ":
+			m.visible = false
+			index := m.cursor
+			return m, func() tea.Msg { return AccountSelectedMsg{Index: index} }
+		case "n":
+			return m.addAccount("")
+		case "c":
+			if !m.wallet.Source.CanDeriveAccounts() {
+				m.err = fmt.Sprintf("%s wallets have a single account", m.wallet.Source.Label())
+				return m, nil
+			}
+			m.mode = accountModeCustomPath
+			m.input.Placeholder = models.AccountPath(len(m.accounts))
+			m.input.SetValue(models.AccountPathPrefix)
+			m.input.CursorEnd()
+			m.err = ""
+			return m, m.input.Focus()
+		case "e":
+			m.mode = accountModeRename
+			m.input.Placeholder = "account label"
+			m.input.SetValue(m.accounts[m.cursor].Label)
+			m.input.CursorEnd()
+			m.err = ""
+			return m, m.input.Focus()
+		}
+	}
+
+	return m, nil
+}
+
+func (m AccountSwitcherModel) updateInput(msg tea.KeyMsg) (AccountSwitcherModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = accountModeList
+		m.input.Blur()
+		m.err = ""
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.input.Value())
+		mode := m.mode
+		m.mode = accountModeList
+		m.input.Blur()
+		if mode == accountModeCustomPath {
+			return m.addAccount(value)
+		}
+		return m.renameAccount(value)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m AccountSwitcherModel) addAccount(path string) (AccountSwitcherModel, tea.Cmd) {
+	wallet := *m.wallet
+	wallet.Accounts = m.accounts
+	if _, err := wallet.AddAccount(path, ""); err != nil {
+		m.err = err.Error()
+		return m, nil
+	}
+	m.cursor = len(wallet.Accounts) - 1
+	return m, m.save(wallet.Accounts)
+}
+
+func (m AccountSwitcherModel) renameAccount(label string) (AccountSwitcherModel, tea.Cmd) {
+	wallet := *m.wallet
+	wallet.Accounts = m.accounts
+	if err := wallet.RenameAccount(m.cursor, label); err != nil {
+		m.err = err.Error()
+		return m, nil
+	}
+	return m, m.save(wallet.Accounts)
+}
+
+func (m *AccountSwitcherModel) save(accounts []models.Account) tea.Cmd {
+	m.saving = true
+	m.err = ""
+	store, id := m.storage, m.wallet.ID
+	return func() tea.Msg {
+		if err := store.SaveAccounts(id, accounts); err != nil {
+			return AccountsSavedMsg{Err: fmt.Errorf("failed to save accounts: %w", err)}
+		}
+		return AccountsSavedMsg{Accounts: accounts}
+	}
+}
+
+func (m AccountSwitcherModel) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(utils.Colours.Mauve)).
+		Padding(1, 2)
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Mauve)).
+		Bold(true)
+
+	itemStyle := lipgloss.NewStyle().
+		Padding(0, 1)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Green)).
+		Background(lipgloss.Color(utils.Colours.Surface0)).
+		Padding(0, 1)
+
+	subtleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	helpStyle := subtleStyle.Copy().Italic(true)
+
+	var content string
+	content += titleStyle.Render("Accounts") + "\n\n"
+
+	for i, account := range m.accounts {
+		cursor := " "
+		style := itemStyle
+		if i == m.cursor {
+			cursor = ">"
+			style = selectedStyle
+		}
+
+		label := fmt.Sprintf("%s %s  %s", cursor, account.Label, utils.FormatAddress(account.Address, 10, 8))
+		if i == m.wallet.ActiveAccount {
+			label += " (active)"
+		}
+		content += style.Render(label)
+		if account.Path != "" {
+			content += " " + subtleStyle.Render(account.Path)
+		}
+		content += "\n"
+	}
+
+	switch m.mode {
+	case accountModeRename:
+		content += "\nNew label:\n" + m.input.View() + "\n"
+	case accountModeCustomPath:
+		content += "\nDerivation path:\n" + m.input.View() + "\n"
+	}
+
+	if m.saving {
+		content += "\n" + subtleStyle.Render("Saving...") + "\n"
+	}
+	if m.err != "" {
+		content += "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render(m.err) + "\n"
+	}
+
+	content += "\n"
+	switch {
+	case m.mode != accountModeList:
+		content += helpStyle.Render("Enter: save • Esc: cancel")
+	case m.wallet.Source.CanDeriveAccounts():
+		content += helpStyle.Render("Enter: switch • n: new account • c: custom path • e: rename • Esc: close")
+	default:
+		content += helpStyle.Render("Enter: switch • e: rename • Esc: close")
+	}
+
+	return boxStyle.Render(content)
+}
@@ -2,13 +2,16 @@ package views
 
 import (
 	"fmt"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"rhystmorgan/veWallet/internal/audit"
 	"rhystmorgan/veWallet/internal/blockchain"
 	"rhystmorgan/veWallet/internal/config"
 	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
 	"rhystmorgan/veWallet/internal/security"
 	"rhystmorgan/veWallet/internal/storage"
 	"rhystmorgan/veWallet/internal/utils"
@@ -25,14 +28,27 @@ const (
 	ViewTransactionHistory
 	ViewContacts
 	ViewSettings
+	ViewProfileSelector
+	ViewKeystoreExport
+	ViewShamirBackup
+	ViewPasswordChange
+	ViewBackup
+	ViewTemplates
+	ViewSchedules
+	ViewSpendingPolicy
+	ViewTwoFactor
 )
 
 type AppModel struct {
 	state            ViewState
 	width            int
 	height           int
+	options          AppOptions
+	home             string
 	storage          *storage.Storage
 	config           *storage.Config
+	configLoader     *config.Loader
+	effectiveConfig  *config.Effective
 	blockchainClient *blockchain.Client
 	networkStatus    blockchain.NetworkStatus
 	currentWallet    *models.Wallet
@@ -51,10 +67,38 @@ type AppModel struct {
 	sendTransaction    *SendTransactionModel
 	transactionHistory *TransactionHistoryModel
 	contactsView       *ContactsModel
+	settingsView       *SettingsModel
+	profileSelector    *ProfileSelectorModel
+	unlockPrompt       *PasswordPromptModel
+	keystoreExport     *KeystoreExportModel
+	shamirBackup       *ShamirBackupModel
+	passwordChange     *PasswordChangeModel
+	backupView         *BackupModel
+	accountSwitcher    *AccountSwitcherModel
+	templateManager    *TemplateManagerModel
+	scheduledPayments  *ScheduledPaymentsModel
+	spendingPolicy     *SpendingPolicyModel
+	twoFactor          *TwoFactorModel
+
+	// scheduler records due scheduled payments when a profile opens and
+	// pays them on approval
+	scheduler *scheduler.Scheduler
+	// spendingGuard checks payments against wallets' spending policies
+	spendingGuard *security.SpendingGuard
 
 	err error
 }
 
+// AppOptions carries startup options from the command line
+type AppOptions struct {
+	// Home overrides the data directory root (VETERM_HOME otherwise)
+	Home string
+	// Profile opens a profile directly, skipping the profile picker
+	Profile string
+	// ConfigOverrides maps config flag names to values given on the command line
+	ConfigOverrides map[string]string
+}
+
 type NavigateMsg struct {
 	State ViewState
 	Data  interface{}
@@ -68,65 +112,169 @@ type WalletLoadedMsg struct {
 	Wallet *models.Wallet
 }
 
-func NewAppModel() (*AppModel, error) {
-	storage, err := storage.NewStorage()
+// SchedulesCheckedMsg reports the scheduled payments that fell due
+type SchedulesCheckedMsg struct {
+	Runs []models.ScheduledRun
+	Err  error
+}
+
+// profileContext holds everything loaded from a profile's data directory
+type profileContext struct {
+	storage          *storage.Storage
+	configLoader     *config.Loader
+	effectiveConfig  *config.Effective
+	contacts         *models.ContactList
+	wallets          []storage.EncryptedWallet
+	blockchainClient *blockchain.Client
+}
+
+// ProfileLoadedMsg reports the result of opening a profile
+type ProfileLoadedMsg struct {
+	context *profileContext
+	Name    string
+	Err     error
+}
+
+func NewAppModel(options AppOptions) (*AppModel, error) {
+	home, err := storage.HomeDir(options.Home)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve data directory: %w", err)
+	}
+
+	app := &AppModel{
+		options: options,
+		home:    home,
+	}
+
+	if profile := storage.ProfileName(options.Profile); profile != "" {
+		ctx, err := loadProfile(home, profile, false, options.ConfigOverrides)
+		if err != nil {
+			return nil, err
+		}
+		app.applyProfile(ctx)
+		return app, nil
+	}
+
+	profiles, err := storage.ListProfiles(home)
+	if err != nil {
+		return nil, err
+	}
+	app.profileSelector = NewProfileSelectorModel(home, profiles, "")
+	app.state = ViewProfileSelector
+
+	return app, nil
+}
+
+// loadProfile opens a profile's storage, resolves its config and connects to
+// its configured node
+func loadProfile(home, name string, create bool, overrides map[string]string) (*profileContext, error) {
+	var store *storage.Storage
+	var err error
+	if create {
+		store, err = storage.CreateProfile(home, name)
+	} else {
+		store, err = storage.OpenProfile(home, name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
-	storageConfig, err := storage.LoadConfig()
+	configLoader := config.NewLoader(store, overrides)
+	effectiveConfig, err := configLoader.Load()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
-	contacts, err := storage.LoadContacts()
+	contacts, err := store.LoadContacts()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load contacts: %w", err)
 	}
 
-	wallets, err := storage.ListWallets()
+	wallets, err := store.ListWallets()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list wallets: %w", err)
 	}
 
 	// Initialize blockchain client
-	blockchainConfig, err := config.LoadBlockchainConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load blockchain config: %w", err)
-	}
-
+	blockchainConfig := effectiveConfig.BlockchainConfig()
 	blockchainClient, err := blockchain.NewClient(blockchainConfig.ToBlockchainConfig())
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize blockchain client: %w", err)
 	}
 
-	// Initialize session management
-	sessionManager := security.NewSessionManager(storage)
-	securityManager := security.NewSecurityManager(sessionManager)
-	activityMonitor := security.NewActivityMonitor(sessionManager)
-
-	app := &AppModel{
-		state:            ViewWalletSelector,
-		storage:          storage,
-		config:           storageConfig,
-		blockchainClient: blockchainClient,
-		networkStatus:    blockchainClient.GetStatus(),
+	return &profileContext{
+		storage:          store,
+		configLoader:     configLoader,
+		effectiveConfig:  effectiveConfig,
 		contacts:         contacts,
 		wallets:          wallets,
-		sessionManager:   sessionManager,
-		securityManager:  securityManager,
-		activityMonitor:  activityMonitor,
+		blockchainClient: blockchainClient,
+	}, nil
+}
+
+func (m *AppModel) loadProfileCmd(name string, create bool) tea.Cmd {
+	home := m.home
+	overrides := m.options.ConfigOverrides
+	return func() tea.Msg {
+		ctx, err := loadProfile(home, name, create, overrides)
+		return ProfileLoadedMsg{context: ctx, Name: name, Err: err}
 	}
+}
 
-	app.walletSelector = NewWalletSelectorModel(wallets)
-	app.walletCreate = NewWalletCreateModel()
-	app.walletImport = NewWalletImportModel()
+// applyProfile switches the app to a loaded profile, releasing the previous
+// profile's sessions and connection
+func (m *AppModel) applyProfile(ctx *profileContext) {
+	m.Shutdown()
+	if m.blockchainClient != nil {
+		m.blockchainClient.Close()
+	}
 
-	return app, nil
+	m.storage = ctx.storage
+	m.configLoader = ctx.configLoader
+	m.effectiveConfig = ctx.effectiveConfig
+	m.config = ctx.effectiveConfig.Config
+	m.contacts = ctx.contacts
+	m.wallets = ctx.wallets
+	m.blockchainClient = ctx.blockchainClient
+	m.networkStatus = ctx.blockchainClient.GetStatus()
+
+	// Initialize session management
+	m.sessionManager = security.NewSessionManager(ctx.storage)
+	m.securityManager = security.NewSecurityManager(m.sessionManager)
+	m.activityMonitor = security.NewActivityMonitor(m.sessionManager)
+
+	m.applyDisplaySettings()
+	m.applySessionSettings()
+	m.applyStorageSettings()
+
+	m.currentWallet = nil
+	m.walletDashboard = nil
+	m.sendTransaction = nil
+	m.transactionHistory = nil
+	m.contactsView = nil
+	m.settingsView = nil
+	m.unlockPrompt = nil
+	m.keystoreExport = nil
+	m.shamirBackup = nil
+	m.passwordChange = nil
+	m.backupView = nil
+	m.accountSwitcher = nil
+	m.templateManager = nil
+	m.scheduledPayments = nil
+	m.spendingPolicy = nil
+	m.twoFactor = nil
+	m.spendingGuard = newSpendingGuard(ctx.storage)
+	m.newScheduler()
+
+	m.walletSelector = NewWalletSelectorModel(ctx.wallets)
+	m.walletSelector.SetDefaultWallet(m.config.DefaultWallet)
+	m.walletCreate = NewWalletCreateModel()
+	m.walletImport = NewWalletImportModel()
+	m.state = ViewWalletSelector
 }
 
 func (m AppModel) Init() tea.Cmd {
-	return nil
+	return m.checkSchedules()
 }
 
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -140,10 +288,14 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
 			return m, tea.Quit
+		case "q":
+			if !m.isEditingText() {
+				return m, tea.Quit
+			}
 		case "esc":
-			if m.state != ViewWalletSelector {
+			if m.state != ViewWalletSelector && m.storage != nil && !m.isEditingText() {
 				return m.navigateTo(ViewWalletSelector, nil)
 			}
 		}
@@ -155,16 +307,45 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = msg.Err
 		return m, nil
 
+	case WalletSelectedMsg:
+		return m, m.promptUnlock(msg.Wallet)
+
 	case WalletLoadedMsg:
-		m.currentWallet = msg.Wallet
-		m.walletDashboard = NewWalletDashboardModel(msg.Wallet)
-		m.walletDashboard.SetBlockchainClient(m.blockchainClient)
+		m.unlockPrompt = nil
+		m.setCurrentWallet(msg.Wallet)
+		// Automatic schedules can pay now the wallet is unlocked
+		model, cmd := m.navigateTo(ViewWalletDashboard, nil)
+		return model, tea.Batch(cmd, m.checkSchedules())
+
+	case OpenAccountSwitcherMsg:
+		if m.currentWallet != nil {
+			m.accountSwitcher = NewAccountSwitcherModel(m.storage, m.currentWallet)
+		}
+		return m, nil
+
+	case AccountSelectedMsg:
+		if m.currentWallet == nil {
+			return m, nil
+		}
+		wallet, err := m.currentWallet.UseAccount(msg.Index)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.setCurrentWallet(wallet)
 		return m.navigateTo(ViewWalletDashboard, nil)
 
+	case AccountsSavedMsg:
+		if msg.Err == nil && m.currentWallet != nil {
+			m.currentWallet.Accounts = msg.Accounts
+		}
+		if m.accountSwitcher != nil {
+			*m.accountSwitcher, cmd = m.accountSwitcher.Update(msg)
+		}
+		return m, cmd
+
 	case WalletCreatedMsg:
-		m.currentWallet = msg.Wallet
-		m.walletDashboard = NewWalletDashboardModel(msg.Wallet)
-		m.walletDashboard.SetBlockchainClient(m.blockchainClient)
+		m.setCurrentWallet(msg.Wallet)
 		// Refresh wallet list
 		wallets, err := m.storage.ListWallets()
 		if err == nil {
@@ -174,9 +355,14 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.navigateTo(ViewWalletDashboard, nil)
 
 	case WalletImportedMsg:
-		m.currentWallet = msg.Wallet
-		m.walletDashboard = NewWalletDashboardModel(msg.Wallet)
-		m.walletDashboard.SetBlockchainClient(m.blockchainClient)
+		m.setCurrentWallet(msg.Wallet)
+		if msg.Imported > 1 {
+			m.walletDashboard.showFeedback(FeedbackInfo, fmt.Sprintf("Imported %d watch-only wallets", msg.Imported), 10*time.Second)
+		} else if msg.DiscoveryErr != nil {
+			m.walletDashboard.showFeedback(FeedbackWarning, fmt.Sprintf("Account discovery incomplete: %s", msg.DiscoveryErr), 10*time.Second)
+		} else if accounts := len(msg.Wallet.Accounts); accounts > 1 {
+			m.walletDashboard.showFeedback(FeedbackInfo, fmt.Sprintf("Found %d used accounts (press a to switch)", accounts), 10*time.Second)
+		}
 		// Refresh wallet list
 		wallets, err := m.storage.ListWallets()
 		if err == nil {
@@ -184,11 +370,52 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.walletSelector = NewWalletSelectorModel(wallets)
 		}
 		return m.navigateTo(ViewWalletDashboard, nil)
+
+	case ProfileSelectedMsg:
+		if m.profileSelector != nil {
+			m.profileSelector.SetLoading(msg.Name)
+		}
+		return m, m.loadProfileCmd(msg.Name, msg.Create)
+
+	case ProfileLoadedMsg:
+		if msg.Err != nil {
+			if m.profileSelector != nil {
+				m.profileSelector.SetError(msg.Err)
+			}
+			return m, nil
+		}
+		m.applyProfile(msg.context)
+		model, cmd := m.navigateTo(ViewWalletSelector, nil)
+		return model, tea.Batch(cmd, m.checkSchedules())
+
+	case SchedulesCheckedMsg:
+		if msg.Err != nil {
+			m.err = fmt.Errorf("scheduled payments: %w", msg.Err)
+		}
+		m.updatePendingPayments()
+		return m, nil
+
+	case DataRestoredMsg:
+		// Reload everything the restore may have replaced, settings included
+		return m, m.loadProfileCmd(m.storage.Profile(), false)
+
+	case SettingsSavedMsg:
+		if msg.Error == nil {
+			cmd = m.applySettings(msg.Effective, msg.Client)
+		}
+		if m.settingsView != nil {
+			var settingsCmd tea.Cmd
+			*m.settingsView, settingsCmd = m.settingsView.Update(msg)
+			cmd = tea.Batch(cmd, settingsCmd)
+		}
+		return m, cmd
 	}
 
 	switch m.state {
 	case ViewWalletSelector:
-		if m.walletSelector != nil {
+		if m.unlockPrompt != nil && m.unlockPrompt.IsVisible() {
+			*m.unlockPrompt, cmd = m.unlockPrompt.Update(msg)
+		} else if m.walletSelector != nil {
 			*m.walletSelector, cmd = m.walletSelector.Update(msg)
 		}
 	case ViewWalletCreate:
@@ -200,7 +427,9 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			*m.walletImport, cmd = m.walletImport.Update(msg)
 		}
 	case ViewWalletDashboard:
-		if m.walletDashboard != nil {
+		if m.accountSwitcher != nil && m.accountSwitcher.IsVisible() {
+			*m.accountSwitcher, cmd = m.accountSwitcher.Update(msg)
+		} else if m.walletDashboard != nil {
 			*m.walletDashboard, cmd = m.walletDashboard.Update(msg)
 		}
 	case ViewSendTransaction:
@@ -219,6 +448,46 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmd = updateCmd
 			}
 		}
+	case ViewSettings:
+		if m.settingsView != nil {
+			*m.settingsView, cmd = m.settingsView.Update(msg)
+		}
+	case ViewProfileSelector:
+		if m.profileSelector != nil {
+			*m.profileSelector, cmd = m.profileSelector.Update(msg)
+		}
+	case ViewKeystoreExport:
+		if m.keystoreExport != nil {
+			*m.keystoreExport, cmd = m.keystoreExport.Update(msg)
+		}
+	case ViewShamirBackup:
+		if m.shamirBackup != nil {
+			*m.shamirBackup, cmd = m.shamirBackup.Update(msg)
+		}
+	case ViewPasswordChange:
+		if m.passwordChange != nil {
+			*m.passwordChange, cmd = m.passwordChange.Update(msg)
+		}
+	case ViewBackup:
+		if m.backupView != nil {
+			*m.backupView, cmd = m.backupView.Update(msg)
+		}
+	case ViewTemplates:
+		if m.templateManager != nil {
+			*m.templateManager, cmd = m.templateManager.Update(msg)
+		}
+	case ViewSchedules:
+		if m.scheduledPayments != nil {
+			*m.scheduledPayments, cmd = m.scheduledPayments.Update(msg)
+		}
+	case ViewSpendingPolicy:
+		if m.spendingPolicy != nil {
+			*m.spendingPolicy, cmd = m.spendingPolicy.Update(msg)
+		}
+	case ViewTwoFactor:
+		if m.twoFactor != nil {
+			*m.twoFactor, cmd = m.twoFactor.Update(msg)
+		}
 	}
 
 	return m, cmd
@@ -236,6 +505,9 @@ func (m AppModel) View() string {
 		if m.walletSelector != nil {
 			content = m.walletSelector.View()
 		}
+		if m.unlockPrompt != nil && m.unlockPrompt.IsVisible() {
+			content += "\n\n" + m.unlockPrompt.View()
+		}
 	case ViewWalletCreate:
 		if m.walletCreate != nil {
 			content = m.walletCreate.View()
@@ -248,6 +520,9 @@ func (m AppModel) View() string {
 		if m.walletDashboard != nil {
 			content = m.walletDashboard.View()
 		}
+		if m.accountSwitcher != nil && m.accountSwitcher.IsVisible() {
+			content += "\n\n" + m.accountSwitcher.View()
+		}
 	case ViewSendTransaction:
 		if m.sendTransaction != nil {
 			content = m.sendTransaction.View()
@@ -260,6 +535,46 @@ func (m AppModel) View() string {
 		if m.contactsView != nil {
 			content = m.contactsView.View()
 		}
+	case ViewSettings:
+		if m.settingsView != nil {
+			content = m.settingsView.View()
+		}
+	case ViewProfileSelector:
+		if m.profileSelector != nil {
+			content = m.profileSelector.View()
+		}
+	case ViewKeystoreExport:
+		if m.keystoreExport != nil {
+			content = m.keystoreExport.View()
+		}
+	case ViewShamirBackup:
+		if m.shamirBackup != nil {
+			content = m.shamirBackup.View()
+		}
+	case ViewPasswordChange:
+		if m.passwordChange != nil {
+			content = m.passwordChange.View()
+		}
+	case ViewBackup:
+		if m.backupView != nil {
+			content = m.backupView.View()
+		}
+	case ViewTemplates:
+		if m.templateManager != nil {
+			content = m.templateManager.View()
+		}
+	case ViewSchedules:
+		if m.scheduledPayments != nil {
+			content = m.scheduledPayments.View()
+		}
+	case ViewSpendingPolicy:
+		if m.spendingPolicy != nil {
+			content = m.spendingPolicy.View()
+		}
+	case ViewTwoFactor:
+		if m.twoFactor != nil {
+			content = m.twoFactor.View()
+		}
 	default:
 		content = "Unknown view"
 	}
@@ -272,6 +587,10 @@ func (m AppModel) View() string {
 		content += "\n" + errorStyle.Render(fmt.Sprintf("Error: %s", m.err.Error()))
 	}
 
+	if m.storage != nil && m.state != ViewProfileSelector {
+		content = m.renderHeader() + "\n" + content
+	}
+
 	return lipgloss.NewStyle().
 		Width(m.width).
 		Height(m.height).
@@ -297,15 +616,25 @@ func (m AppModel) navigateTo(state ViewState, data interface{}) (tea.Model, tea.
 			m.walletImport = NewWalletImportModel()
 		}
 		m.walletImport.SetStorage(m.storage)
+		if m.config != nil {
+			m.walletImport.SetAccountDiscovery(m.blockchainClient, m.config.AccountGapLimit)
+		}
 	case ViewWalletDashboard:
 		if m.walletDashboard != nil {
 			m.walletDashboard.SetSessionManager(m.sessionManager)
+			m.updatePendingPayments()
+			return m, m.walletDashboard.Start()
 		}
 	case ViewSendTransaction:
 		if m.sendTransaction == nil && m.currentWallet != nil {
 			m.sendTransaction = NewSendTransactionModel(m.currentWallet)
 			m.sendTransaction.SetBlockchainClient(m.blockchainClient)
 			m.sendTransaction.SetStorage(m.storage)
+			m.sendTransaction.SetSpendingGuard(m.spendingGuard)
+			m.sendTransaction.SetSecurityManager(m.securityManager)
+		} else if m.sendTransaction != nil {
+			// Templates may have been edited since the screen was built
+			m.sendTransaction.LoadTemplates()
 		}
 		if m.sendTransaction != nil {
 			m.sendTransaction.SetSessionManager(m.sessionManager)
@@ -328,11 +657,397 @@ func (m AppModel) navigateTo(state ViewState, data interface{}) (tea.Model, tea.
 			m.contactsView.width = m.width
 			m.contactsView.height = m.height
 		}
+	case ViewSettings:
+		fileConfig, err := m.storage.LoadConfig()
+		if err != nil {
+			m.err = fmt.Errorf("failed to load config: %w", err)
+			return m, nil
+		}
+		m.settingsView = NewSettingsModel(m.storage, m.configLoader, fileConfig, m.effectiveConfig, m.wallets)
+		m.settingsView.SetSize(m.width, m.height)
+	case ViewProfileSelector:
+		current := ""
+		if m.storage != nil {
+			current = m.storage.Profile()
+		}
+		profiles, err := storage.ListProfiles(m.home)
+		if err != nil {
+			m.err = err
+			profiles = []string{storage.DefaultProfile}
+		}
+		m.profileSelector = NewProfileSelectorModel(m.home, profiles, current)
+	case ViewKeystoreExport:
+		wallet, ok := data.(storage.EncryptedWallet)
+		if !ok {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		if wallet.IsWatchOnly() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s: %w", wallet.Name, models.ErrWatchOnly)
+			return m, nil
+		}
+		if !wallet.HasKeystore() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s: %w", wallet.Name, storage.ErrKeystoreMissing)
+			return m, nil
+		}
+		m.keystoreExport = NewKeystoreExportModel(m.storage, m.securityManager, wallet)
+		return m, m.keystoreExport.Init()
+	case ViewShamirBackup:
+		wallet, ok := data.(storage.EncryptedWallet)
+		if !ok {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		if wallet.IsWatchOnly() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s: %w", wallet.Name, models.ErrWatchOnly)
+			return m, nil
+		}
+		if wallet.Source != "" && !wallet.Source.CanDeriveAccounts() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s was imported from a %s and has no recovery phrase to split", wallet.Name, wallet.Source.Label())
+			return m, nil
+		}
+		if !wallet.HasKeystore() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s: %w", wallet.Name, storage.ErrKeystoreMissing)
+			return m, nil
+		}
+		m.shamirBackup = NewShamirBackupModel(m.storage, m.securityManager, wallet)
+		return m, m.shamirBackup.Init()
+	case ViewPasswordChange:
+		wallet, ok := data.(storage.EncryptedWallet)
+		if !ok {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		if wallet.IsWatchOnly() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s: %w", wallet.Name, models.ErrWatchOnly)
+			return m, nil
+		}
+		if !wallet.HasKeystore() {
+			m.state = ViewWalletSelector
+			m.err = fmt.Errorf("%s: %w", wallet.Name, storage.ErrKeystoreMissing)
+			return m, nil
+		}
+		m.passwordChange = NewPasswordChangeModel(m.storage, m.securityManager, wallet)
+		return m, m.passwordChange.Init()
+	case ViewBackup:
+		m.backupView = NewBackupModel(m.storage, m.securityManager)
+		return m, m.backupView.Init()
+	case ViewTemplates:
+		if m.currentWallet == nil {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		m.templateManager = NewTemplateManagerModel(m.storage, m.currentWallet)
+		return m, m.templateManager.Init()
+	case ViewSchedules:
+		if m.currentWallet == nil {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		m.scheduledPayments = NewScheduledPaymentsModel(m.storage, m.scheduler, m.securityManager, m.currentWallet)
+		return m, m.scheduledPayments.Init()
+	case ViewSpendingPolicy:
+		if m.currentWallet == nil {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		m.spendingPolicy = NewSpendingPolicyModel(m.storage, m.spendingGuard, m.securityManager, m.currentWallet)
+		return m, m.spendingPolicy.Init()
+	case ViewTwoFactor:
+		if m.currentWallet == nil {
+			m.state = ViewWalletSelector
+			return m, nil
+		}
+		m.twoFactor = NewTwoFactorModel(m.storage, m.securityManager, m.currentWallet)
+		return m, m.twoFactor.Init()
 	}
 
 	return m, nil
 }
 
+// renderHeader shows the active profile and network above every view
+func (m AppModel) renderHeader() string {
+	profileStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Base)).
+		Background(lipgloss.Color(utils.Colours.Mauve)).
+		Bold(true).
+		Padding(0, 1)
+
+	networkStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Padding(0, 1)
+
+	header := profileStyle.Render("profile: " + m.storage.Profile())
+	if m.config != nil {
+		header += networkStyle.Render(m.config.Network)
+	}
+	return header
+}
+
+// promptUnlock asks for the password of a stored wallet. Watch-only wallets
+// have nothing to unlock and open directly; wallets saved without a signing
+// key are sent to the import flow instead.
+func (m *AppModel) promptUnlock(encWallet storage.EncryptedWallet) tea.Cmd {
+	if encWallet.IsWatchOnly() {
+		wallet, err := m.storage.LoadWatchOnlyWallet(encWallet.ID)
+		if err != nil {
+			m.err = err
+			return nil
+		}
+		m.err = nil
+		return LoadWallet(wallet)
+	}
+	if !encWallet.HasKeystore() {
+		m.err = fmt.Errorf("%s: %w (choose Import Wallet)", encWallet.Name, storage.ErrKeystoreMissing)
+		return nil
+	}
+
+	m.err = nil
+	m.unlockPrompt = NewPasswordPromptModel()
+	m.unlockPrompt.SetStorage(m.storage)
+	m.unlockPrompt.SetWallet(&models.Wallet{
+		ID:      encWallet.ID,
+		Name:    encWallet.Name,
+		Address: encWallet.Address,
+	})
+	m.unlockPrompt.SetCallbacks(
+		func(wallet *models.Wallet) tea.Cmd { return LoadWallet(wallet) },
+		nil,
+		func(err error) tea.Cmd { return func() tea.Msg { return ErrorMsg{Err: err} } },
+	)
+	m.unlockPrompt.Show("Unlock "+encWallet.Name, "Enter the wallet password")
+	return nil
+}
+
+// isEditingText reports whether the active view has a focused text field, in
+// which case global shortcuts such as q and esc belong to the field.
+func (m *AppModel) isEditingText() bool {
+	switch m.state {
+	case ViewSettings:
+		return m.settingsView != nil && m.settingsView.IsEditing()
+	case ViewWalletSelector:
+		return m.unlockPrompt != nil && m.unlockPrompt.IsVisible()
+	case ViewProfileSelector:
+		return m.profileSelector != nil && m.profileSelector.IsEditing()
+	case ViewWalletCreate:
+		return m.walletCreate != nil && m.walletCreate.IsEditing()
+	case ViewWalletImport:
+		return m.walletImport != nil && m.walletImport.IsEditing()
+	case ViewWalletDashboard:
+		return m.accountSwitcher != nil && m.accountSwitcher.IsVisible()
+	case ViewSendTransaction:
+		return m.sendTransaction != nil && m.sendTransaction.IsEditing()
+	case ViewKeystoreExport:
+		return m.keystoreExport != nil && m.keystoreExport.IsEditing()
+	case ViewShamirBackup:
+		return m.shamirBackup != nil && m.shamirBackup.IsEditing()
+	case ViewPasswordChange:
+		return m.passwordChange != nil && m.passwordChange.IsEditing()
+	case ViewBackup:
+		return m.backupView != nil && m.backupView.IsEditing()
+	case ViewTemplates:
+		return m.templateManager != nil && m.templateManager.IsEditing()
+	case ViewSchedules:
+		return m.scheduledPayments != nil && m.scheduledPayments.IsEditing()
+	case ViewSpendingPolicy:
+		return m.spendingPolicy != nil && m.spendingPolicy.IsEditing()
+	case ViewTwoFactor:
+		return m.twoFactor != nil && m.twoFactor.IsEditing()
+	case ViewContacts:
+		return m.contactsView != nil && m.contactsView.IsEditing()
+	}
+	return false
+}
+
+// setCurrentWallet makes wallet, acting as one of its accounts, the wallet
+// shown on the dashboard. Views bound to the previous account are rebuilt on
+// next navigation.
+func (m *AppModel) setCurrentWallet(wallet *models.Wallet) {
+	m.currentWallet = wallet
+	m.setSchedulerKeys(wallet)
+	m.walletDashboard = m.newWalletDashboard(wallet)
+	m.accountSwitcher = nil
+	m.sendTransaction = nil
+	m.transactionHistory = nil
+	m.contactsView = nil
+}
+
+func (m *AppModel) newWalletDashboard(wallet *models.Wallet) *WalletDashboardModel {
+	dashboard := NewWalletDashboardModel(wallet)
+	dashboard.SetBlockchainClient(m.blockchainClient)
+	if m.scheduler != nil {
+		if pending, err := m.scheduler.Pending(wallet.ID); err == nil {
+			dashboard.SetPendingPayments(len(pending))
+		}
+	}
+	if m.config != nil {
+		dashboard.SetRefreshInterval(time.Duration(m.config.AutoRefresh) * time.Second)
+		dashboard.SetDisplayPrecision(m.config.DisplayPrecision)
+	}
+	return dashboard
+}
+
+// applySettings makes saved settings take effect without a restart
+func (m *AppModel) applySettings(effective *config.Effective, client *blockchain.Client) tea.Cmd {
+	cfg := effective.Config
+	m.config = cfg
+	m.effectiveConfig = effective
+
+	if client != nil {
+		if m.blockchainClient != nil {
+			m.blockchainClient.Close()
+		}
+		m.blockchainClient = client
+		m.networkStatus = client.GetStatus()
+
+		// Views holding the old client are rebuilt on next navigation
+		m.sendTransaction = nil
+		m.transactionHistory = nil
+		if m.walletDashboard != nil {
+			m.walletDashboard.SetBlockchainClient(client)
+		}
+
+		m.scheduledPayments = nil
+		m.newScheduler()
+		if m.currentWallet != nil {
+			m.setSchedulerKeys(m.currentWallet)
+		}
+	}
+
+	m.applyDisplaySettings()
+	m.applySessionSettings()
+	m.applyStorageSettings()
+	m.applyScheduleSettings()
+
+	if m.walletSelector != nil {
+		m.walletSelector.SetDefaultWallet(cfg.DefaultWallet)
+	}
+
+	if m.walletDashboard != nil {
+		m.walletDashboard.SetDisplayPrecision(cfg.DisplayPrecision)
+		return m.walletDashboard.SetRefreshInterval(time.Duration(cfg.AutoRefresh) * time.Second)
+	}
+
+	return nil
+}
+
+func (m *AppModel) applyDisplaySettings() {
+	if m.config == nil || m.config.Theme == "" {
+		return
+	}
+	if err := utils.ApplyTheme(m.config.Theme); err != nil {
+		utils.ApplyTheme(utils.DefaultTheme)
+	}
+}
+
+// applySessionSettings applies the security level first and then the session
+// timeout and limit the user set, which take precedence over the level's
+// defaults.
+func (m *AppModel) applySessionSettings() {
+	if m.config == nil || m.effectiveConfig == nil || m.securityManager == nil || m.sessionManager == nil {
+		return
+	}
+
+	level, err := security.ParseSecurityLevel(m.config.SecurityLevel)
+	if err != nil {
+		return
+	}
+	m.securityManager.ApplySessionPolicy(level, m.effectiveConfig.SessionTimeout(), m.config.MaxSessions)
+}
+
+// applyStorageSettings sets the key derivation cost and contact encryption
+// used from now on; existing wallets and contacts move to them as wallets are
+// unlocked
+func (m *AppModel) applyStorageSettings() {
+	if m.config == nil || m.storage == nil {
+		return
+	}
+	m.storage.SetKDFParams(m.config.KDFParams())
+	m.storage.SetContactEncryption(storage.ContactEncryption(m.config.ContactEncryption))
+}
+
+// newScheduler replaces the profile's payment scheduler with one on the
+// current client
+func (m *AppModel) newScheduler() {
+	var payer scheduler.Payer
+	if m.blockchainClient != nil {
+		payer = scheduler.NewChainPayer(m.blockchainClient, nil)
+	}
+	// Runs are still kept in the schedules file without an audit log
+	auditor, err := audit.NewPaymentAuditor(m.storage.AuditLogDir())
+	if err != nil {
+		auditor = nil
+	}
+	m.scheduler = scheduler.New(m.storage, payer, auditor)
+	m.scheduler.SetGuard(m.spendingGuard)
+	m.applyScheduleSettings()
+}
+
+// newSpendingGuard creates the profile's spending guard. Decisions are still
+// enforced if the audit log cannot be opened.
+func newSpendingGuard(store *storage.Storage) *security.SpendingGuard {
+	auditor, err := audit.NewPolicyAuditor(store.AuditLogDir())
+	if err != nil {
+		auditor = nil
+	}
+	return security.NewSpendingGuard(store, auditor)
+}
+
+// applyScheduleSettings sets the budget for automatic scheduled payments
+func (m *AppModel) applyScheduleSettings() {
+	if m.config == nil || m.scheduler == nil {
+		return
+	}
+	vet, vtho, err := m.config.ScheduleBudget()
+	if err != nil {
+		return
+	}
+	m.scheduler.SetBudget(scheduler.Budget{VET: vet, VTHO: vtho})
+}
+
+// setSchedulerKeys lets automatic schedules sign with the unlocked wallet
+func (m *AppModel) setSchedulerKeys(wallet *models.Wallet) {
+	if m.scheduler == nil {
+		return
+	}
+	m.scheduler.SetKeys(func(walletID string) *models.Wallet {
+		if wallet != nil && walletID == wallet.ID && wallet.PrivateKey != nil {
+			return wallet
+		}
+		return nil
+	})
+}
+
+// checkSchedules records scheduled payments that have fallen due since the
+// last check, paying automatic ones the unlocked wallet can cover
+func (m *AppModel) checkSchedules() tea.Cmd {
+	payments := m.scheduler
+	if payments == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		runs, err := payments.RunDue()
+		return SchedulesCheckedMsg{Runs: runs, Err: err}
+	}
+}
+
+// updatePendingPayments refreshes the dashboard's count of payments waiting
+// for approval
+func (m *AppModel) updatePendingPayments() {
+	if m.walletDashboard == nil || m.currentWallet == nil || m.scheduler == nil {
+		return
+	}
+	if pending, err := m.scheduler.Pending(m.currentWallet.ID); err == nil {
+		m.walletDashboard.SetPendingPayments(len(pending))
+	}
+}
+
 func (m *AppModel) getViewName(state ViewState) string {
 	switch state {
 	case ViewWalletSelector:
@@ -351,6 +1066,24 @@ func (m *AppModel) getViewName(state ViewState) string {
 		return "contacts"
 	case ViewSettings:
 		return "settings"
+	case ViewProfileSelector:
+		return "profile_selector"
+	case ViewKeystoreExport:
+		return "keystore_export"
+	case ViewShamirBackup:
+		return "shamir_backup"
+	case ViewPasswordChange:
+		return "password_change"
+	case ViewBackup:
+		return "backup"
+	case ViewTemplates:
+		return "templates"
+	case ViewSchedules:
+		return "schedules"
+	case ViewSpendingPolicy:
+		return "spending_policy"
+	case ViewTwoFactor:
+		return "two_factor"
 	default:
 		return "unknown"
 	}
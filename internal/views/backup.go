@@ -0,0 +1,655 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+type BackupStep int
+
+const (
+	BackupStepMenu BackupStep = iota
+	BackupStepCreate
+	BackupStepCreated
+	BackupStepOpen
+	BackupStepPreview
+	BackupStepVerify
+	BackupStepRestored
+)
+
+const (
+	backupFieldPassword = iota
+	backupFieldConfirm
+	backupFieldPath
+	backupFieldCode
+	backupFieldCount
+)
+
+// BackupModel writes the profile to an encrypted backup file, or previews
+// and restores one section by section
+type BackupModel struct {
+	storage         *storage.Storage
+	securityManager *security.SecurityManager
+	step            BackupStep
+	cursor          int
+
+	inputs  [backupFieldCount]textinput.Model
+	focused int
+
+	busy    bool
+	written string
+	err     string
+
+	backup   *storage.Backup
+	sections map[storage.BackupSection]bool
+	plan     *storage.RestorePlan
+
+	// verified holds the wallets whose authentication code was checked for
+	// this restore, and verifying the one being asked for
+	verified  map[string]bool
+	verifying storage.EncryptedWallet
+}
+
+// BackupCreatedMsg reports the result of writing a backup
+type BackupCreatedMsg struct {
+	Path string
+	Err  error
+}
+
+// BackupOpenedMsg carries a decrypted and verified backup
+type BackupOpenedMsg struct {
+	Backup *storage.Backup
+	Err    error
+}
+
+// RestorePlannedMsg carries the preview for the selected sections
+type RestorePlannedMsg struct {
+	Plan *storage.RestorePlan
+	Err  error
+}
+
+// BackupRestoredMsg reports a finished restore
+type BackupRestoredMsg struct {
+	Plan *storage.RestorePlan
+	Err  error
+}
+
+// RestoreVerifiedMsg reports the check of a wallet's authentication code
+// before a restore changes its spending policy or second factor
+type RestoreVerifiedMsg struct {
+	WalletID string
+	Err      error
+}
+
+// DataRestoredMsg asks the app to reload the profile after a restore
+type DataRestoredMsg struct{}
+
+func NewBackupModel(store *storage.Storage, securityManager *security.SecurityManager) *BackupModel {
+	m := &BackupModel{storage: store, securityManager: securityManager}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Width = 64
+		m.inputs[i] = input
+	}
+	return m
+}
+
+// IsEditing reports whether a text field has focus
+func (m *BackupModel) IsEditing() bool {
+	return m.step == BackupStepCreate || m.step == BackupStepOpen || m.step == BackupStepVerify
+}
+
+func (m BackupModel) Init() tea.Cmd {
+	return nil
+}
+
+// resetInputs prepares the fields for creating a backup or opening one
+func (m *BackupModel) resetInputs(step BackupStep) tea.Cmd {
+	m.step = step
+	m.err = ""
+	for i := range m.inputs {
+		m.inputs[i].SetValue("")
+		m.inputs[i].Blur()
+		m.inputs[i].EchoMode = textinput.EchoNormal
+	}
+
+	if step == BackupStepCreate {
+		m.inputs[backupFieldPassword].Placeholder = "password for the backup file"
+		m.inputs[backupFieldConfirm].Placeholder = "repeat the backup password"
+		m.inputs[backupFieldPath].Placeholder = m.storage.DefaultBackupPath()
+		for _, field := range []int{backupFieldPassword, backupFieldConfirm} {
+			m.inputs[field].EchoMode = textinput.EchoPassword
+			m.inputs[field].EchoCharacter = '*'
+		}
+		m.focused = backupFieldPassword
+	} else if step == BackupStepVerify {
+		m.inputs[backupFieldPassword].Placeholder = "wallet password"
+		m.inputs[backupFieldCode].Placeholder = "authenticator or recovery code"
+		m.inputs[backupFieldPassword].EchoMode = textinput.EchoPassword
+		m.inputs[backupFieldPassword].EchoCharacter = '*'
+		m.focused = backupFieldPassword
+	} else {
+		m.inputs[backupFieldPath].Placeholder = "path to a .vtbak file"
+		m.inputs[backupFieldPassword].Placeholder = "backup password"
+		m.inputs[backupFieldPassword].EchoMode = textinput.EchoPassword
+		m.inputs[backupFieldPassword].EchoCharacter = '*'
+		m.focused = backupFieldPath
+	}
+	return tea.Batch(m.inputs[m.focused].Focus(), textinput.Blink)
+}
+
+// fields lists the inputs shown on the current step, in tab order
+func (m BackupModel) fields() []int {
+	switch m.step {
+	case BackupStepCreate:
+		return []int{backupFieldPassword, backupFieldConfirm, backupFieldPath}
+	case BackupStepVerify:
+		return []int{backupFieldPassword, backupFieldCode}
+	}
+	return []int{backupFieldPath, backupFieldPassword}
+}
+
+func (m *BackupModel) focusNext(delta int) tea.Cmd {
+	fields := m.fields()
+	current := 0
+	for i, field := range fields {
+		if field == m.focused {
+			current = i
+		}
+	}
+	m.inputs[m.focused].Blur()
+	m.focused = fields[(current+delta+len(fields))%len(fields)]
+	return m.inputs[m.focused].Focus()
+}
+
+func (m BackupModel) Update(msg tea.Msg) (BackupModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case BackupCreatedMsg:
+		m.busy = false
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.written = msg.Path
+		m.step = BackupStepCreated
+		m.clearSecrets()
+		return m, nil
+
+	case BackupOpenedMsg:
+		m.busy = false
+		m.inputs[backupFieldPassword].SetValue("")
+		if errors.Is(msg.Err, storage.ErrInvalidPassword) {
+			m.err = "Incorrect backup password"
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.backup = msg.Backup
+		m.verified = make(map[string]bool)
+		m.sections = make(map[storage.BackupSection]bool)
+		for _, section := range storage.BackupSections() {
+			m.sections[section] = true
+		}
+		m.step = BackupStepPreview
+		m.cursor = 0
+		m.err = ""
+		return m, m.planCmd()
+
+	case RestorePlannedMsg:
+		m.busy = false
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.plan = msg.Plan
+		return m, nil
+
+	case RestoreVerifiedMsg:
+		m.busy = false
+		m.clearSecrets()
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, m.focusField(backupFieldPassword)
+		}
+		m.verified[msg.WalletID] = true
+		return m.restore()
+
+	case BackupRestoredMsg:
+		m.busy = false
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.plan = msg.Plan
+		m.step = BackupStepRestored
+		m.backup = nil
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.busy {
+			return m, nil
+		}
+		switch m.step {
+		case BackupStepMenu:
+			return m.handleMenuKeys(msg)
+		case BackupStepCreate, BackupStepOpen, BackupStepVerify:
+			return m.handleFormKeys(msg)
+		case BackupStepPreview:
+			return m.handlePreviewKeys(msg)
+		case BackupStepCreated:
+			if msg.String() == "enter" || msg.String() == "esc" {
+				return m, NavigateTo(ViewWalletSelector, nil)
+			}
+		case BackupStepRestored:
+			if msg.String() == "enter" || msg.String() == "esc" {
+				return m, func() tea.Msg { return DataRestoredMsg{} }
+			}
+		}
+		return m, nil
+	}
+
+	if m.IsEditing() {
+		var cmd tea.Cmd
+		m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m BackupModel) handleMenuKeys(msg tea.KeyMsg) (BackupModel, tea.Cmd) {
+	switch msg.String() {
+	case "up", "k", "down", "j":
+		m.cursor = 1 - m.cursor
+	case "enter":
+		if m.cursor == 0 {
+			return m, m.resetInputs(BackupStepCreate)
+		}
+		return m, m.resetInputs(BackupStepOpen)
+	case "esc":
+		return m, NavigateTo(ViewWalletSelector, nil)
+	}
+	return m, nil
+}
+
+func (m BackupModel) handleFormKeys(msg tea.KeyMsg) (BackupModel, tea.Cmd) {
+	fields := m.fields()
+	switch msg.String() {
+	case "esc":
+		m.clearSecrets()
+		m.err = ""
+		if m.step == BackupStepVerify {
+			m.step = BackupStepPreview
+			return m, nil
+		}
+		m.step = BackupStepMenu
+		return m, nil
+	case "tab", "down":
+		return m, m.focusNext(1)
+	case "shift+tab", "up":
+		return m, m.focusNext(-1)
+	case "enter":
+		if m.focused != fields[len(fields)-1] {
+			return m, m.focusNext(1)
+		}
+		switch m.step {
+		case BackupStepCreate:
+			return m.submitCreate()
+		case BackupStepVerify:
+			return m.submitVerify()
+		}
+		return m.submitOpen()
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return m, cmd
+}
+
+func (m BackupModel) handlePreviewKeys(msg tea.KeyMsg) (BackupModel, tea.Cmd) {
+	sections := storage.BackupSections()
+	switch msg.String() {
+	case "left", "h":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "right", "l":
+		if m.cursor < len(sections)-1 {
+			m.cursor++
+		}
+	case " ":
+		section := sections[m.cursor]
+		m.sections[section] = !m.sections[section]
+		m.busy = true
+		return m, m.planCmd()
+	case "enter":
+		if m.plan == nil || m.plan.Count(storage.RestoreAdd)+m.plan.Count(storage.RestoreOverwrite) == 0 {
+			m.err = "Nothing to restore in the selected sections"
+			return m, nil
+		}
+		return m.restore()
+	case "esc":
+		m.backup = nil
+		m.plan = nil
+		return m, m.resetInputs(BackupStepOpen)
+	}
+	return m, nil
+}
+
+// restore asks for the authentication code of the next wallet whose
+// spending policy or second factor the plan changes, and restores once every
+// one has been checked
+func (m BackupModel) restore() (BackupModel, tea.Cmd) {
+	for _, id := range m.plan.SecondFactor {
+		if m.verified[id] {
+			continue
+		}
+		wallets, err := m.storage.ListWallets()
+		if err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		for _, wallet := range wallets {
+			if wallet.ID == id {
+				m.verifying = wallet
+			}
+		}
+		cmd := m.resetInputs(BackupStepVerify)
+		return m, cmd
+	}
+
+	m.busy = true
+	store, backup, selected := m.storage, m.backup, m.selectedSections()
+	verified := maps.Clone(m.verified)
+	return m, func() tea.Msg {
+		plan, err := store.Restore(backup, selected, func(walletID string) error {
+			if !verified[walletID] {
+				return storage.ErrRestoreNeedsSecondFactor
+			}
+			return nil
+		})
+		return BackupRestoredMsg{Plan: plan, Err: err}
+	}
+}
+
+// submitVerify unlocks the wallet being asked for and checks its code.
+// Wrong codes count toward the wallet's lockout.
+func (m BackupModel) submitVerify() (BackupModel, tea.Cmd) {
+	password := m.inputs[backupFieldPassword].Value()
+	code := m.inputs[backupFieldCode].Value()
+	if password == "" {
+		m.err = "Wallet password is required"
+		return m, m.focusField(backupFieldPassword)
+	}
+	if strings.TrimSpace(code) == "" {
+		m.err = "Authentication code is required"
+		return m, m.focusField(backupFieldCode)
+	}
+
+	m.err = ""
+	m.busy = true
+	store, securityManager, walletID := m.storage, m.securityManager, m.verifying.ID
+	return m, func() tea.Msg {
+		wallet, err := store.LoadWallet(walletID, password)
+		if errors.Is(err, storage.ErrInvalidPassword) {
+			return RestoreVerifiedMsg{WalletID: walletID, Err: errors.New("incorrect wallet password")}
+		}
+		if err != nil {
+			return RestoreVerifiedMsg{WalletID: walletID, Err: err}
+		}
+		return RestoreVerifiedMsg{WalletID: walletID, Err: securityManager.VerifySecondFactor(store, wallet, code)}
+	}
+}
+
+func (m BackupModel) selectedSections() []storage.BackupSection {
+	var selected []storage.BackupSection
+	for _, section := range storage.BackupSections() {
+		if m.sections[section] {
+			selected = append(selected, section)
+		}
+	}
+	return selected
+}
+
+func (m BackupModel) planCmd() tea.Cmd {
+	store, backup, selected := m.storage, m.backup, m.selectedSections()
+	return func() tea.Msg {
+		plan, err := store.PlanRestore(backup, selected)
+		return RestorePlannedMsg{Plan: plan, Err: err}
+	}
+}
+
+func (m BackupModel) submitCreate() (BackupModel, tea.Cmd) {
+	password := m.inputs[backupFieldPassword].Value()
+	if strength, issues := utils.ValidatePassword(password); strength == utils.PasswordWeak {
+		m.err = "Backup password is too weak: " + strings.Join(issues, ", ")
+		return m, m.focusField(backupFieldPassword)
+	}
+	if password != m.inputs[backupFieldConfirm].Value() {
+		m.err = "Backup passwords do not match"
+		return m, m.focusField(backupFieldConfirm)
+	}
+
+	m.err = ""
+	m.busy = true
+	store, path := m.storage, m.inputs[backupFieldPath].Value()
+	return m, func() tea.Msg {
+		written, err := store.CreateBackup(path, password)
+		return BackupCreatedMsg{Path: written, Err: err}
+	}
+}
+
+func (m BackupModel) submitOpen() (BackupModel, tea.Cmd) {
+	path := strings.TrimSpace(m.inputs[backupFieldPath].Value())
+	password := m.inputs[backupFieldPassword].Value()
+	if path == "" {
+		m.err = "Backup file is required"
+		return m, m.focusField(backupFieldPath)
+	}
+	if password == "" {
+		m.err = "Backup password is required"
+		return m, m.focusField(backupFieldPassword)
+	}
+
+	m.err = ""
+	m.busy = true
+	return m, func() tea.Msg {
+		backup, err := storage.OpenBackup(path, password)
+		return BackupOpenedMsg{Backup: backup, Err: err}
+	}
+}
+
+func (m *BackupModel) focusField(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	return m.inputs[field].Focus()
+}
+
+func (m *BackupModel) clearSecrets() {
+	m.inputs[backupFieldPassword].SetValue("")
+	m.inputs[backupFieldConfirm].SetValue("")
+	m.inputs[backupFieldCode].SetValue("")
+}
+
+func (m BackupModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Yellow))
+
+	successStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Green))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	var content string
+	content += titleStyle.Render("Backup & Restore") + "\n"
+	content += helpStyle.Render("profile: "+m.storage.Profile()) + "\n\n"
+
+	switch m.step {
+	case BackupStepMenu:
+		options := []string{"Create encrypted backup", "Restore from backup"}
+		for i, option := range options {
+			if i == m.cursor {
+				content += successStyle.Render("▶ "+option) + "\n"
+			} else {
+				content += "  " + option + "\n"
+			}
+		}
+		content += "\n" + helpStyle.Render("↑/↓: choose • Enter: select • Esc: back")
+
+	case BackupStepCreate:
+		content += warningStyle.Render("The backup holds every wallet, contact, transaction record, the settings and audit logs.") + "\n"
+		content += warningStyle.Render("Wallets inside stay encrypted with their own passwords.") + "\n\n"
+		labels := map[int]string{
+			backupFieldPassword: "Backup Password:",
+			backupFieldConfirm:  "Confirm Backup Password:",
+			backupFieldPath:     "Save To (blank for default):",
+		}
+		for _, field := range m.fields() {
+			content += labelStyle.Render(labels[field]) + "\n"
+			content += m.inputs[field].View() + "\n\n"
+		}
+		if m.busy {
+			content += warningStyle.Render("Encrypting backup...") + "\n\n"
+		}
+		content += m.renderError()
+		content += helpStyle.Render("Tab/↑/↓: move • Enter: next / create • Esc: back")
+
+	case BackupStepCreated:
+		content += successStyle.Render("✓ Backup written to "+m.written) + "\n\n"
+		content += warningStyle.Render("Keep the backup password somewhere other than the backup file.") + "\n\n"
+		content += helpStyle.Render("Press Enter to return to wallet selection")
+
+	case BackupStepOpen:
+		labels := map[int]string{
+			backupFieldPath:     "Backup File:",
+			backupFieldPassword: "Backup Password:",
+		}
+		for _, field := range m.fields() {
+			content += labelStyle.Render(labels[field]) + "\n"
+			content += m.inputs[field].View() + "\n\n"
+		}
+		if m.busy {
+			content += warningStyle.Render("Decrypting and verifying backup...") + "\n\n"
+		}
+		content += m.renderError()
+		content += helpStyle.Render("Tab/↑/↓: move • Enter: next / open • Esc: back")
+
+	case BackupStepPreview:
+		content += successStyle.Render(fmt.Sprintf("✓ Backup of %s from %s verified", m.backup.Profile, m.backup.CreatedAt.Local().Format("2006-01-02 15:04"))) + "\n\n"
+		content += m.renderSections(labelStyle) + "\n\n"
+		content += m.renderPlan(helpStyle)
+		content += m.renderError()
+		content += helpStyle.Render("←/→: section • Space: include / exclude • Enter: restore • Esc: back")
+
+	case BackupStepVerify:
+		content += warningStyle.Render(fmt.Sprintf("This restore changes the spending policy or second factor of %s.", m.verifying.Name)) + "\n"
+		content += helpStyle.Render("Enter its password and authentication code to continue.") + "\n\n"
+		labels := map[int]string{
+			backupFieldPassword: "Wallet Password:",
+			backupFieldCode:     "Authentication Code:",
+		}
+		for _, field := range m.fields() {
+			content += labelStyle.Render(labels[field]) + "\n"
+			content += m.inputs[field].View() + "\n\n"
+		}
+		if m.busy {
+			content += warningStyle.Render("Checking code...") + "\n\n"
+		}
+		content += m.renderError()
+		content += helpStyle.Render("Tab/↑/↓: move • Enter: next / verify • Esc: back")
+
+	case BackupStepRestored:
+		content += successStyle.Render(fmt.Sprintf("✓ Restored %d items", m.plan.Count(storage.RestoreAdd)+m.plan.Count(storage.RestoreOverwrite))) + "\n\n"
+		content += helpStyle.Render("Replaced files were kept in the profile's backups directory.") + "\n"
+		content += helpStyle.Render("Restored wallets unlock with the passwords they had when backed up.") + "\n\n"
+		content += helpStyle.Render("Press Enter to reload the profile")
+	}
+
+	return content
+}
+
+func (m BackupModel) renderSections(labelStyle lipgloss.Style) string {
+	cursorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Base)).
+		Background(lipgloss.Color(utils.Colours.Blue))
+
+	var parts []string
+	for i, section := range storage.BackupSections() {
+		box := "[ ]"
+		if m.sections[section] {
+			box = "[x]"
+		}
+		part := fmt.Sprintf("%s %s", box, section)
+		if i == m.cursor {
+			part = cursorStyle.Render(part)
+		}
+		parts = append(parts, part)
+	}
+	return labelStyle.Render("Restore:") + " " + strings.Join(parts, "  ")
+}
+
+func (m BackupModel) renderPlan(helpStyle lipgloss.Style) string {
+	if m.plan == nil {
+		return helpStyle.Render("Working out changes...") + "\n\n"
+	}
+	if len(m.plan.Items) == 0 {
+		return helpStyle.Render("Nothing in the selected sections") + "\n\n"
+	}
+
+	actionStyles := map[storage.RestoreAction]lipgloss.Style{
+		storage.RestoreAdd:       lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Green)),
+		storage.RestoreOverwrite: lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Yellow)),
+		storage.RestoreSkip:      lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Subtext0)),
+	}
+
+	var content string
+	const maxShown = 12
+	for i, item := range m.plan.Items {
+		if i == maxShown {
+			content += helpStyle.Render(fmt.Sprintf("... and %d more", len(m.plan.Items)-maxShown)) + "\n"
+			break
+		}
+		label := item.Label
+		if item.Reason != "" {
+			label += " (" + item.Reason + ")"
+		}
+		content += actionStyles[item.Action].Render(fmt.Sprintf("%-9s %-8s %s", item.Action, item.Section, label)) + "\n"
+	}
+	content += "\n" + fmt.Sprintf("%d to add, %d to overwrite, %d to skip",
+		m.plan.Count(storage.RestoreAdd), m.plan.Count(storage.RestoreOverwrite), m.plan.Count(storage.RestoreSkip)) + "\n\n"
+	if len(m.plan.SecondFactor) > 0 {
+		content += helpStyle.Render(fmt.Sprintf("Authentication codes are needed for %d wallets with a second factor", len(m.plan.SecondFactor))) + "\n\n"
+	}
+	return content
+}
+
+func (m BackupModel) renderError() string {
+	if m.err == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Red)).
+		Render("✗ "+m.err) + "\n\n"
+}
@@ -0,0 +1,424 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+type contactTransferMode int
+
+const (
+	contactTransferExport contactTransferMode = iota
+	contactTransferImport
+)
+
+const (
+	transferFieldMode = iota
+	transferFieldFormat
+	transferFieldPath
+	transferFieldRecipients
+	transferFieldIdentity
+	transferFieldPassphrase
+	transferFieldConfirm
+	transferFieldCount
+)
+
+// contactTransfer is the contacts import and export form. Exports can be
+// encrypted with age to recipients or a passphrase; imports decrypt age
+// files with an identity file or the passphrase.
+type contactTransfer struct {
+	mode   contactTransferMode
+	format utils.ExportFormat
+
+	// inputs holds the text fields; the mode and format slots are choices
+	// and their inputs are never shown
+	inputs  [transferFieldCount]textinput.Model
+	focused int
+
+	busy bool
+	err  string
+}
+
+// ContactsExportedMsg reports a finished contacts export
+type ContactsExportedMsg struct {
+	Path      string
+	Count     int
+	Encrypted bool
+	Err       error
+}
+
+// ContactsImportedMsg reports the contacts added from an import
+type ContactsImportedMsg struct {
+	Added int
+	Total int
+	Err   error
+}
+
+func newContactTransfer() contactTransfer {
+	t := contactTransfer{format: utils.FormatJSON, focused: transferFieldMode}
+	placeholders := [transferFieldCount]string{
+		transferFieldPath:       "contacts.json",
+		transferFieldRecipients: "age1... public keys, comma-separated (optional)",
+		transferFieldIdentity:   "age identity file (optional)",
+		transferFieldPassphrase: "age passphrase (optional)",
+		transferFieldConfirm:    "repeat the passphrase",
+	}
+	for i := range t.inputs {
+		input := textinput.New()
+		input.Width = 64
+		input.Placeholder = placeholders[i]
+		input.PromptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Blue))
+		input.TextStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Text))
+		t.inputs[i] = input
+	}
+	for _, field := range []int{transferFieldPassphrase, transferFieldConfirm} {
+		t.inputs[field].EchoMode = textinput.EchoPassword
+		t.inputs[field].EchoCharacter = '*'
+	}
+	return t
+}
+
+// IsEditing reports whether the import/export form is open; its text fields
+// take every key
+func (m *ContactsModel) IsEditing() bool {
+	return m.currentView == ContactViewImportExport
+}
+
+// fields lists the fields shown for the current mode, in tab order
+func (t *contactTransfer) fields() []int {
+	if t.mode == contactTransferImport {
+		return []int{transferFieldMode, transferFieldFormat, transferFieldPath, transferFieldIdentity, transferFieldPassphrase}
+	}
+	return []int{transferFieldMode, transferFieldFormat, transferFieldPath, transferFieldRecipients, transferFieldPassphrase, transferFieldConfirm}
+}
+
+func (t *contactTransfer) focusNext(delta int) tea.Cmd {
+	fields := t.fields()
+	current := 0
+	for i, field := range fields {
+		if field == t.focused {
+			current = i
+		}
+	}
+	return t.focusField(fields[(current+delta+len(fields))%len(fields)])
+}
+
+func (t *contactTransfer) focusField(field int) tea.Cmd {
+	t.inputs[t.focused].Blur()
+	t.focused = field
+	if field == transferFieldMode || field == transferFieldFormat {
+		return nil
+	}
+	return t.inputs[field].Focus()
+}
+
+// toggle flips the choice under the cursor
+func (t *contactTransfer) toggle() {
+	switch t.focused {
+	case transferFieldMode:
+		if t.mode == contactTransferExport {
+			t.mode = contactTransferImport
+		} else {
+			t.mode = contactTransferExport
+		}
+	case transferFieldFormat:
+		if t.format == utils.FormatJSON {
+			t.format = utils.FormatCSV
+			t.inputs[transferFieldPath].Placeholder = "contacts.csv"
+		} else {
+			t.format = utils.FormatJSON
+			t.inputs[transferFieldPath].Placeholder = "contacts.json"
+		}
+	}
+	t.err = ""
+}
+
+// clearSecrets empties the passphrase fields
+func (t *contactTransfer) clearSecrets() {
+	t.inputs[transferFieldPassphrase].SetValue("")
+	t.inputs[transferFieldConfirm].SetValue("")
+}
+
+func (m *ContactsModel) updateImportExportView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := &m.transfer
+	if t.busy {
+		return m, nil
+	}
+	defer m.invalidateCache()
+
+	choice := t.focused == transferFieldMode || t.focused == transferFieldFormat
+	switch msg.String() {
+	case "esc":
+		t.clearSecrets()
+		m.currentView = ContactViewList
+		m.clearMessages()
+		return m, nil
+	case "tab", "down":
+		return m, t.focusNext(1)
+	case "shift+tab", "up":
+		return m, t.focusNext(-1)
+	case "enter":
+		fields := t.fields()
+		if t.focused != fields[len(fields)-1] {
+			return m, t.focusNext(1)
+		}
+		return m.submitImportExport()
+	case "left", "right", " ":
+		if choice {
+			t.toggle()
+			return m, nil
+		}
+	}
+	if choice {
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	t.inputs[t.focused], cmd = t.inputs[t.focused].Update(msg)
+	return m, cmd
+}
+
+// submitImportExport checks the form and runs the export or import
+func (m *ContactsModel) submitImportExport() (tea.Model, tea.Cmd) {
+	t := &m.transfer
+	if m.locked {
+		t.err = "Contacts are encrypted. Unlock a wallet with its password first."
+		return m, nil
+	}
+
+	options := utils.ImportExportOptions{
+		Format:   t.format,
+		FilePath: strings.TrimSpace(t.inputs[transferFieldPath].Value()),
+	}
+	if options.FilePath == "" {
+		t.err = "File path is required"
+		return m, t.focusField(transferFieldPath)
+	}
+	passphrase := t.inputs[transferFieldPassphrase].Value()
+
+	if t.mode == contactTransferImport {
+		options.AgeKeys = storage.AgeKeys{
+			IdentityFile: strings.TrimSpace(t.inputs[transferFieldIdentity].Value()),
+			Passphrase:   passphrase,
+		}
+		t.clearSecrets()
+		t.err = ""
+		t.busy = true
+		return m, m.importContacts(options)
+	}
+
+	recipients := strings.FieldsFunc(t.inputs[transferFieldRecipients].Value(), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	switch {
+	case len(recipients) > 0 && passphrase != "":
+		t.err = "Use age recipients or a passphrase, not both"
+		return m, nil
+	case passphrase != "" && passphrase != t.inputs[transferFieldConfirm].Value():
+		t.err = "Passphrases do not match"
+		t.clearSecrets()
+		return m, t.focusField(transferFieldPassphrase)
+	}
+	options.Age = storage.AgeOptions{Recipients: recipients, Passphrase: passphrase}
+	options.Encrypt = options.Age.Enabled()
+
+	t.clearSecrets()
+	t.err = ""
+	t.busy = true
+	return m, m.exportContacts(options)
+}
+
+func (m *ContactsModel) exportContacts(options utils.ImportExportOptions) tea.Cmd {
+	return func() tea.Msg {
+		contactList, err := m.storage.LoadContacts()
+		if err != nil {
+			return ContactsExportedMsg{Err: fmt.Errorf("failed to load contacts: %w", err)}
+		}
+		if err := utils.NewContactExporter(options).ExportContacts(contactList.Contacts); err != nil {
+			return ContactsExportedMsg{Err: err}
+		}
+		return ContactsExportedMsg{Path: options.FilePath, Count: len(contactList.Contacts), Encrypted: options.Encrypt}
+	}
+}
+
+// importContacts adds the contacts in a file, leaving any already saved
+// under the same address as they are
+func (m *ContactsModel) importContacts(options utils.ImportExportOptions) tea.Cmd {
+	return func() tea.Msg {
+		result, imported, err := utils.NewContactImporter(options).ImportContacts()
+		if errors.Is(err, storage.ErrInvalidPassword) {
+			return ContactsImportedMsg{Err: errors.New("incorrect export passphrase or age identity")}
+		}
+		if err != nil {
+			return ContactsImportedMsg{Err: err}
+		}
+
+		contactList, err := m.storage.LoadContacts()
+		if err != nil {
+			return ContactsImportedMsg{Err: fmt.Errorf("failed to load contacts: %w", err)}
+		}
+		added := 0
+		for _, contact := range utils.ConvertToContacts(imported) {
+			if contactList.FindByAddress(contact.Address) != nil {
+				continue
+			}
+			if err := contactList.Add(&contact, nil, "", ""); err != nil {
+				return ContactsImportedMsg{Err: fmt.Errorf("failed to add contact: %w", err)}
+			}
+			added++
+		}
+		if added > 0 {
+			if err := m.storage.SaveContacts(contactList); err != nil {
+				return ContactsImportedMsg{Err: fmt.Errorf("failed to save contacts: %w", err)}
+			}
+		}
+		return ContactsImportedMsg{Added: added, Total: result.TotalContacts}
+	}
+}
+
+func (m *ContactsModel) renderImportExportView() string {
+	t := &m.transfer
+	var content strings.Builder
+
+	// Header
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Background(lipgloss.Color(utils.Colours.Surface0)).
+		Padding(0, 1).
+		Width(m.width)
+
+	content.WriteString(headerStyle.Render("Import / Export Contacts"))
+	content.WriteString("\n\n")
+
+	fieldStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Padding(0, 2)
+	label := func(field int, text string) string {
+		if t.focused == field {
+			return lipgloss.NewStyle().
+				Foreground(lipgloss.Color(utils.Colours.Blue)).
+				Bold(true).
+				Render("▶ " + text)
+		}
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Text)).
+			Render("  " + text)
+	}
+
+	mode := "Export"
+	if t.mode == contactTransferImport {
+		mode = "Import"
+	}
+	format := "JSON"
+	if t.format == utils.FormatCSV {
+		format = "CSV"
+	}
+	content.WriteString(fieldStyle.Render(label(transferFieldMode, "Action: ◀ "+mode+" ▶")))
+	content.WriteString("\n")
+	content.WriteString(fieldStyle.Render(label(transferFieldFormat, "Format: ◀ "+format+" ▶")))
+	content.WriteString("\n\n")
+
+	titles := map[int]string{
+		transferFieldPath:       "File: *Required",
+		transferFieldRecipients: "Encrypt to age recipients:",
+		transferFieldIdentity:   "Age identity file:",
+		transferFieldPassphrase: "Age passphrase:",
+		transferFieldConfirm:    "Confirm passphrase:",
+	}
+	for _, field := range t.fields()[2:] {
+		content.WriteString(fieldStyle.Render(label(field, titles[field])))
+		content.WriteString("\n")
+		content.WriteString(fieldStyle.Render(t.inputs[field].View()))
+		content.WriteString("\n\n")
+	}
+
+	hintStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Overlay1)).
+		Padding(0, 2)
+	if t.mode == contactTransferImport {
+		content.WriteString(hintStyle.Render("Age files are detected and decrypted; contacts already saved under the same address are skipped."))
+	} else {
+		content.WriteString(hintStyle.Render("Leave the age fields empty to write a plain file."))
+	}
+	content.WriteString("\n\n")
+
+	if m.locked {
+		lockedStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Yellow)).
+			Padding(0, 2)
+		content.WriteString(lockedStyle.Render("🔒 Contacts are encrypted. Unlock a wallet with its password to import or export them."))
+		content.WriteString("\n\n")
+	}
+
+	if t.busy {
+		busyStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Yellow)).
+			Padding(0, 2)
+		content.WriteString(busyStyle.Render("Working..."))
+		content.WriteString("\n\n")
+	}
+
+	if t.err != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(utils.Colours.Red)).
+			Padding(0, 1)
+		content.WriteString(errorStyle.Render("✗ " + t.err))
+		content.WriteString("\n\n")
+	}
+
+	// Controls
+	controlsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Overlay1)).
+		Padding(0, 1)
+
+	controls := "[Tab] Next Field [Shift+Tab] Previous [←/→] Change [Enter] Submit [Esc] Cancel"
+	content.WriteString(controlsStyle.Render(controls))
+
+	return content.String()
+}
+
+// handleTransferResult returns to the list after an export or import, or
+// shows the error on the form
+func (m *ContactsModel) handleTransferResult(msg tea.Msg) tea.Cmd {
+	t := &m.transfer
+	t.busy = false
+	m.invalidateCache()
+
+	switch msg := msg.(type) {
+	case ContactsExportedMsg:
+		if msg.Err != nil {
+			t.err = msg.Err.Error()
+			return nil
+		}
+		m.currentView = ContactViewList
+		m.successMessage = fmt.Sprintf("Exported %d contacts to %s", msg.Count, msg.Path)
+		if msg.Encrypted {
+			m.successMessage += ", encrypted with age"
+		}
+
+	case ContactsImportedMsg:
+		if msg.Err != nil {
+			t.err = msg.Err.Error()
+			return nil
+		}
+		m.currentView = ContactViewList
+		if msg.Added == 0 {
+			m.successMessage = "No new contacts to import"
+			return nil
+		}
+		m.successMessage = fmt.Sprintf("Imported %d of %d contacts", msg.Added, msg.Total)
+		return m.loadContacts()
+	}
+	return nil
+}
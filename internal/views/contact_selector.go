@@ -160,8 +160,7 @@ func (m *ContactSelectorModel) Update(msg tea.Msg) (*ContactSelectorModel, tea.C
 
 		case " ":
 			// Toggle favorite for selected contact
-			m.toggleSelectedContactFavorite()
-			return m, nil
+			return m, m.toggleSelectedContactFavorite()
 
 		case "backspace":
 			if len(m.searchQuery) > 0 {
@@ -624,7 +623,7 @@ func (m *ContactSelectorModel) isAllContactSelected(index int) bool {
 	return m.selectedIndex == offset+index
 }
 
-func (m *ContactSelectorModel) toggleSelectedContactFavorite() {
+func (m *ContactSelectorModel) toggleSelectedContactFavorite() tea.Cmd {
 	// Determine which contact is selected and toggle its favorite status
 	currentIndex := 0
 
@@ -636,7 +635,7 @@ func (m *ContactSelectorModel) toggleSelectedContactFavorite() {
 		}
 		if m.selectedIndex < maxRecent {
 			// Can't favorite recent addresses
-			return
+			return nil
 		}
 		currentIndex += maxRecent
 	}
@@ -655,12 +654,11 @@ func (m *ContactSelectorModel) toggleSelectedContactFavorite() {
 				// Find the contact in the main list and toggle favorite
 				for i := range m.contacts.Contacts {
 					if m.contacts.Contacts[i].ID == favorites[contactIndex].ID {
-						m.contacts.Contacts[i].ToggleFavorite()
-						return
+						return m.toggleFavorite(&m.contacts.Contacts[i])
 					}
 				}
 			}
-			return
+			return nil
 		}
 		currentIndex += maxFavorites
 	}
@@ -672,9 +670,18 @@ func (m *ContactSelectorModel) toggleSelectedContactFavorite() {
 		selectedContact := m.filteredContacts[contactIndex]
 		for i := range m.contacts.Contacts {
 			if m.contacts.Contacts[i].ID == selectedContact.ID {
-				m.contacts.Contacts[i].ToggleFavorite()
-				return
+				return m.toggleFavorite(&m.contacts.Contacts[i])
 			}
 		}
 	}
+	return nil
+}
+
+func (m *ContactSelectorModel) toggleFavorite(contact *models.Contact) tea.Cmd {
+	if err := contact.ToggleFavorite(nil, "", ""); err != nil {
+		return func() tea.Msg {
+			return ErrorMsg{Err: fmt.Errorf("failed to toggle favorite: %w", err)}
+		}
+	}
+	return nil
 }
@@ -77,6 +77,7 @@ type ContactsModel struct {
 	createForm ContactForm
 	editForm   ContactForm
 	formErrors map[string]string
+	transfer   contactTransfer
 
 	// Loading and error states
 	loading        bool
@@ -84,6 +85,10 @@ type ContactsModel struct {
 	error          error
 	successMessage string
 
+	// locked is set while the contacts file is encrypted and no wallet
+	// holding its key has been unlocked
+	locked bool
+
 	// Performance optimization
 	renderCache string
 	cacheValid  bool
@@ -144,6 +149,7 @@ type ContactDeletedMsg struct {
 
 type ContactsLoadedMsg struct {
 	Contacts []models.Contact
+	Locked   bool
 }
 
 func NewContactsModel(sessionManager *security.SessionManager, storage *storage.Storage, wallet *models.Wallet) *ContactsModel {
@@ -221,6 +227,11 @@ func (m *ContactsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.invalidateCache()
 
 	case tea.KeyMsg:
+		// The import/export form's text fields take every key, q included
+		if m.currentView == ContactViewImportExport {
+			return m.updateImportExportView(msg)
+		}
+
 		// Handle global shortcuts first
 		switch msg.String() {
 		case "ctrl+c", "q":
@@ -242,6 +253,9 @@ func (m *ContactsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+i":
 			if m.currentView == ContactViewList {
 				m.currentView = ContactViewImportExport
+				m.transfer = newContactTransfer()
+				m.clearMessages()
+				m.invalidateCache()
 				return m, nil
 			}
 
@@ -291,12 +305,11 @@ func (m *ContactsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateEditView(msg)
 		case ContactViewDeleteConfirm:
 			return m.updateDeleteConfirmView(msg)
-		case ContactViewImportExport:
-			return m.updateImportExportView(msg)
 		}
 
 	case ContactsLoadedMsg:
 		m.contacts = msg.Contacts
+		m.locked = msg.Locked
 		m.applyFiltersAndSort()
 		m.loading = false
 		m.invalidateCache()
@@ -335,6 +348,9 @@ func (m *ContactsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.invalidateCache()
 
+	case ContactsExportedMsg, ContactsImportedMsg:
+		return m, m.handleTransferResult(msg)
+
 	case ErrorMsg:
 		m.error = msg.Err
 		m.loading = false
@@ -568,16 +584,6 @@ func (m *ContactsModel) updateDeleteConfirmView(msg tea.KeyMsg) (tea.Model, tea.
 	return m, nil
 }
 
-func (m *ContactsModel) updateImportExportView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "esc":
-		m.currentView = ContactViewList
-		m.clearMessages()
-	}
-
-	return m, nil
-}
-
 func (m *ContactsModel) View() string {
 	if !m.cacheValid {
 		m.renderCache = m.renderView()
@@ -629,6 +635,13 @@ func (m *ContactsModel) renderListView() string {
 	content.WriteString(searchBar)
 	content.WriteString("\n")
 
+	if m.locked && !m.loading {
+		lockedStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Yellow))
+		content.WriteString(lockedStyle.Render("🔒 Contacts are encrypted. Unlock a wallet with its password to see notes and make changes."))
+		content.WriteString("\n")
+	}
+
 	// Contact list
 	if m.loading {
 		loadingStyle := lipgloss.NewStyle().
@@ -857,7 +870,7 @@ func (m *ContactsModel) loadContacts() tea.Cmd {
 			return ErrorMsg{Err: fmt.Errorf("failed to load contacts: %w", err)}
 		}
 
-		return ContactsLoadedMsg{Contacts: contactList.Contacts}
+		return ContactsLoadedMsg{Contacts: contactList.Contacts, Locked: contactList.Locked}
 	}
 }
 
@@ -1402,10 +1415,6 @@ func (m *ContactsModel) renderDeleteConfirmView() string {
 	return content.String()
 }
 
-func (m *ContactsModel) renderImportExportView() string {
-	return "Import/Export View - Coming Soon in Phase 3.3.3"
-}
-
 // Form operations
 func (m *ContactsModel) populateEditForm(contact models.Contact) ContactForm {
 	form := newContactForm()
@@ -0,0 +1,268 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	exportFieldWalletPassword = iota
+	exportFieldCode
+	exportFieldFilePassword
+	exportFieldConfirm
+	exportFieldPath
+	exportFieldCount
+)
+
+// KeystoreExportModel writes a stored wallet's key to a keystore v3 file
+// after the wallet password has been re-entered
+type KeystoreExportModel struct {
+	storage         *storage.Storage
+	securityManager *security.SecurityManager
+	wallet          storage.EncryptedWallet
+
+	inputs  [exportFieldCount]textinput.Model
+	focused int
+
+	attempts    int
+	maxAttempts int
+	exporting   bool
+	exported    string
+	err         string
+}
+
+// KeystoreExportedMsg reports the result of a keystore file export
+type KeystoreExportedMsg struct {
+	Path string
+	Err  error
+}
+
+func NewKeystoreExportModel(store *storage.Storage, securityManager *security.SecurityManager, wallet storage.EncryptedWallet) *KeystoreExportModel {
+	m := &KeystoreExportModel{
+		storage:         store,
+		securityManager: securityManager,
+		wallet:          wallet,
+		maxAttempts:     3,
+	}
+
+	placeholders := [exportFieldCount]string{
+		"current wallet password",
+		"authenticator or recovery code",
+		"password for the keystore file",
+		"repeat the keystore file password",
+		store.DefaultKeystoreExportPath(wallet.Address),
+	}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.Width = 64
+		if i != exportFieldPath && i != exportFieldCode {
+			input.EchoMode = textinput.EchoPassword
+			input.EchoCharacter = '*'
+		}
+		m.inputs[i] = input
+	}
+	m.inputs[exportFieldWalletPassword].Focus()
+
+	return m
+}
+
+// IsEditing reports whether a field has focus; it always does until the
+// export has finished
+func (m *KeystoreExportModel) IsEditing() bool {
+	return m.exported == ""
+}
+
+func (m KeystoreExportModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m KeystoreExportModel) Update(msg tea.Msg) (KeystoreExportModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case KeystoreExportedMsg:
+		m.exporting = false
+		if msg.Err == nil {
+			m.exported = msg.Path
+			m.clearSecrets()
+			return m, nil
+		}
+
+		m.err = msg.Err.Error()
+		if errors.Is(msg.Err, security.ErrInvalidSecondFactor) || errors.Is(msg.Err, security.ErrLockedOut) {
+			m.inputs[exportFieldCode].SetValue("")
+			return m, m.focus(exportFieldCode)
+		}
+		if !errors.Is(msg.Err, storage.ErrInvalidPassword) {
+			return m, nil
+		}
+		m.attempts++
+		m.inputs[exportFieldWalletPassword].SetValue("")
+		if m.attempts >= m.maxAttempts {
+			m.err = "Too many failed attempts"
+		} else {
+			m.err = fmt.Sprintf("Incorrect wallet password (%d/%d attempts)", m.attempts, m.maxAttempts)
+		}
+		return m, m.focus(exportFieldWalletPassword)
+
+	case tea.KeyMsg:
+		if m.exporting {
+			return m, nil
+		}
+		if m.exported != "" {
+			if msg.String() == "enter" || msg.String() == "esc" {
+				return m, NavigateTo(ViewWalletSelector, nil)
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.clearSecrets()
+			return m, NavigateTo(ViewWalletSelector, nil)
+		case "tab", "down":
+			return m, m.step(1)
+		case "shift+tab", "up":
+			return m, m.step(-1)
+		case "enter":
+			if m.focused < exportFieldPath {
+				return m, m.step(1)
+			}
+			return m.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return m, cmd
+}
+
+func (m *KeystoreExportModel) focus(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	return m.inputs[field].Focus()
+}
+
+// step moves the focus by delta, passing over the code field for wallets
+// without a second factor
+func (m *KeystoreExportModel) step(delta int) tea.Cmd {
+	field := m.focused
+	for {
+		field = (field + delta + exportFieldCount) % exportFieldCount
+		if field != exportFieldCode || m.wallet.HasTwoFactor() {
+			return m.focus(field)
+		}
+	}
+}
+
+func (m KeystoreExportModel) submit() (KeystoreExportModel, tea.Cmd) {
+	if m.attempts >= m.maxAttempts {
+		m.err = "Too many failed attempts"
+		return m, nil
+	}
+
+	walletPassword := m.inputs[exportFieldWalletPassword].Value()
+	filePassword := m.inputs[exportFieldFilePassword].Value()
+	if walletPassword == "" {
+		m.err = "Wallet password is required"
+		return m, m.focus(exportFieldWalletPassword)
+	}
+	if strength, issues := utils.ValidatePassword(filePassword); strength == utils.PasswordWeak {
+		m.err = "Keystore password is too weak: " + strings.Join(issues, ", ")
+		return m, m.focus(exportFieldFilePassword)
+	}
+	if filePassword != m.inputs[exportFieldConfirm].Value() {
+		m.err = "Keystore passwords do not match"
+		return m, m.focus(exportFieldConfirm)
+	}
+	code := m.inputs[exportFieldCode].Value()
+	if m.wallet.HasTwoFactor() && strings.TrimSpace(code) == "" {
+		m.err = "Authentication code is required"
+		return m, m.focus(exportFieldCode)
+	}
+
+	m.err = ""
+	m.exporting = true
+	store, securityManager, id, path := m.storage, m.securityManager, m.wallet.ID, m.inputs[exportFieldPath].Value()
+	return m, func() tea.Msg {
+		wallet, err := store.LoadWallet(id, walletPassword)
+		if err != nil {
+			return KeystoreExportedMsg{Err: err}
+		}
+		if err := securityManager.VerifySecondFactor(store, wallet, code); err != nil {
+			return KeystoreExportedMsg{Err: err}
+		}
+		written, err := store.ExportKeystore(wallet, filePassword, path)
+		return KeystoreExportedMsg{Path: written, Err: err}
+	}
+}
+
+func (m *KeystoreExportModel) clearSecrets() {
+	for i := range m.inputs {
+		if i != exportFieldPath {
+			m.inputs[i].SetValue("")
+		}
+	}
+}
+
+func (m KeystoreExportModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Yellow))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	var content string
+	content += titleStyle.Render("Export Keystore File") + "\n"
+	content += helpStyle.Render(fmt.Sprintf("%s (%s)", m.wallet.Name, m.wallet.Address)) + "\n\n"
+
+	if m.exported != "" {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Green)).
+			Render("✓ Keystore written to "+m.exported) + "\n\n"
+		content += helpStyle.Render("Press Enter to return to wallet selection")
+		return content
+	}
+
+	content += warningStyle.Render("Anyone with this file and its password can spend from this wallet.") + "\n\n"
+
+	labels := [exportFieldCount]string{"Wallet Password:", "Authentication Code:", "Keystore Password:", "Confirm Keystore Password:", "Save To (blank for default):"}
+	for i, label := range labels {
+		if i == exportFieldCode && !m.wallet.HasTwoFactor() {
+			continue
+		}
+		content += labelStyle.Render(label) + "\n"
+		content += m.inputs[i].View() + "\n\n"
+	}
+
+	if m.exporting {
+		content += warningStyle.Render("Encrypting keystore...") + "\n\n"
+	}
+
+	if m.err != "" {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ "+m.err) + "\n\n"
+	}
+
+	content += helpStyle.Render("Tab/↑/↓: move • Enter: next / export • Esc: cancel")
+	return content
+}
@@ -0,0 +1,235 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	changeFieldCurrent = iota
+	changeFieldNew
+	changeFieldConfirm
+	changeFieldCount
+)
+
+// PasswordChangeModel re-encrypts a stored wallet under a new password once
+// the current one has been entered. Wrong current passwords count toward the
+// wallet's lockout in the security manager.
+type PasswordChangeModel struct {
+	storage         *storage.Storage
+	securityManager *security.SecurityManager
+	wallet          storage.EncryptedWallet
+
+	inputs  [changeFieldCount]textinput.Model
+	focused int
+
+	changing bool
+	changed  bool
+	err      string
+}
+
+// PasswordChangedMsg reports the result of a wallet password change
+type PasswordChangedMsg struct {
+	Err error
+}
+
+func NewPasswordChangeModel(store *storage.Storage, securityManager *security.SecurityManager, wallet storage.EncryptedWallet) *PasswordChangeModel {
+	m := &PasswordChangeModel{
+		storage:         store,
+		securityManager: securityManager,
+		wallet:          wallet,
+	}
+
+	placeholders := [changeFieldCount]string{
+		"current wallet password",
+		"new wallet password",
+		"repeat the new password",
+	}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.Width = 40
+		input.EchoMode = textinput.EchoPassword
+		input.EchoCharacter = '*'
+		m.inputs[i] = input
+	}
+	m.inputs[changeFieldCurrent].Focus()
+
+	return m
+}
+
+// IsEditing reports whether a field has focus; it always does until the
+// password has been changed
+func (m *PasswordChangeModel) IsEditing() bool {
+	return !m.changed
+}
+
+func (m PasswordChangeModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m PasswordChangeModel) Update(msg tea.Msg) (PasswordChangeModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case PasswordChangedMsg:
+		m.changing = false
+		if msg.Err == nil {
+			m.securityManager.RecordSuccessfulAttempt(m.wallet.ID)
+			m.changed = true
+			m.err = ""
+			m.clearSecrets()
+			return m, nil
+		}
+
+		m.err = msg.Err.Error()
+		if !errors.Is(msg.Err, storage.ErrInvalidPassword) {
+			return m, nil
+		}
+		m.securityManager.RecordFailedAttempt(m.wallet.ID)
+		m.inputs[changeFieldCurrent].SetValue("")
+		if m.securityManager.IsAccountLocked(m.wallet.ID) {
+			m.err = m.lockedMessage()
+		} else {
+			m.err = fmt.Sprintf("Incorrect wallet password (%d failed attempts)", m.securityManager.GetFailedAttemptCount(m.wallet.ID))
+		}
+		return m, m.focus(changeFieldCurrent)
+
+	case tea.KeyMsg:
+		if m.changing {
+			return m, nil
+		}
+		if m.changed {
+			if msg.String() == "enter" || msg.String() == "esc" {
+				return m, NavigateTo(ViewWalletSelector, nil)
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.clearSecrets()
+			return m, NavigateTo(ViewWalletSelector, nil)
+		case "tab", "down":
+			return m, m.focus((m.focused + 1) % changeFieldCount)
+		case "shift+tab", "up":
+			return m, m.focus((m.focused + changeFieldCount - 1) % changeFieldCount)
+		case "enter":
+			if m.focused < changeFieldConfirm {
+				return m, m.focus(m.focused + 1)
+			}
+			return m.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return m, cmd
+}
+
+func (m *PasswordChangeModel) focus(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	return m.inputs[field].Focus()
+}
+
+func (m PasswordChangeModel) lockedMessage() string {
+	remaining := m.securityManager.GetRemainingLockoutTime(m.wallet.ID)
+	return fmt.Sprintf("Wallet is locked for %s after too many failed attempts", utils.FormatDuration(remaining))
+}
+
+func (m PasswordChangeModel) submit() (PasswordChangeModel, tea.Cmd) {
+	if m.securityManager.IsAccountLocked(m.wallet.ID) {
+		m.err = m.lockedMessage()
+		return m, nil
+	}
+
+	current := m.inputs[changeFieldCurrent].Value()
+	newPassword := m.inputs[changeFieldNew].Value()
+	if current == "" {
+		m.err = "Current password is required"
+		return m, m.focus(changeFieldCurrent)
+	}
+	if strength, issues := utils.ValidatePassword(newPassword); strength == utils.PasswordWeak {
+		m.err = "New password is too weak: " + strings.Join(issues, ", ")
+		return m, m.focus(changeFieldNew)
+	}
+	if newPassword == current {
+		m.err = "New password must differ from the current one"
+		return m, m.focus(changeFieldNew)
+	}
+	if newPassword != m.inputs[changeFieldConfirm].Value() {
+		m.err = "New passwords do not match"
+		return m, m.focus(changeFieldConfirm)
+	}
+
+	m.err = ""
+	m.changing = true
+	store, id := m.storage, m.wallet.ID
+	return m, func() tea.Msg {
+		return PasswordChangedMsg{Err: store.ChangeWalletPassword(id, current, newPassword)}
+	}
+}
+
+func (m *PasswordChangeModel) clearSecrets() {
+	for i := range m.inputs {
+		m.inputs[i].SetValue("")
+	}
+}
+
+func (m PasswordChangeModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Yellow))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	var content string
+	content += titleStyle.Render("Change Wallet Password") + "\n"
+	content += helpStyle.Render(fmt.Sprintf("%s (%s)", m.wallet.Name, m.wallet.Address)) + "\n\n"
+
+	if m.changed {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Green)).
+			Render("✓ Password changed; the old password no longer unlocks this wallet") + "\n\n"
+		content += warningStyle.Render("Keystore files exported earlier keep the password they were written with.") + "\n\n"
+		content += helpStyle.Render("Press Enter to return to wallet selection")
+		return content
+	}
+
+	labels := [changeFieldCount]string{"Current Password:", "New Password:", "Confirm New Password:"}
+	for i, label := range labels {
+		content += labelStyle.Render(label) + "\n"
+		content += m.inputs[i].View() + "\n\n"
+	}
+
+	if m.changing {
+		content += warningStyle.Render("Re-encrypting wallet...") + "\n\n"
+	}
+
+	if m.err != "" {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ "+m.err) + "\n\n"
+	}
+
+	content += helpStyle.Render("Tab/↑/↓: move • Enter: next / change • Esc: cancel")
+	return content
+}
@@ -1,6 +1,7 @@
 package views
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/security"
 	"rhystmorgan/veWallet/internal/storage"
 	"rhystmorgan/veWallet/internal/utils"
 )
@@ -34,6 +36,12 @@ type PasswordPromptModel struct {
 	sessionTimeout time.Duration
 	lastActivity   time.Time
 
+	// Second factor, asked for after the password when securityManager is set
+	// and the wallet has one
+	securityManager *security.SecurityManager
+	unlocked        *models.Wallet
+	code            string
+
 	// Callbacks
 	onSuccess func(*models.Wallet) tea.Cmd
 	onCancel  func() tea.Cmd
@@ -51,6 +59,12 @@ type PasswordVerificationMsg struct {
 	Error   error
 }
 
+// SecondFactorVerificationMsg reports the result of checking an
+// authentication code
+type SecondFactorVerificationMsg struct {
+	Error error
+}
+
 func NewPasswordPromptModel() *PasswordPromptModel {
 	return &PasswordPromptModel{
 		masked:         true,
@@ -68,6 +82,13 @@ func (m *PasswordPromptModel) SetStorage(storage *storage.Storage) {
 	m.storage = storage
 }
 
+// RequireSecondFactor makes the prompt ask wallets with a second factor for
+// an authentication code after the password, counting wrong codes toward the
+// wallet's lockout
+func (m *PasswordPromptModel) RequireSecondFactor(securityManager *security.SecurityManager) {
+	m.securityManager = securityManager
+}
+
 func (m *PasswordPromptModel) SetCallbacks(onSuccess func(*models.Wallet) tea.Cmd, onCancel func() tea.Cmd, onError func(error) tea.Cmd) {
 	m.onSuccess = onSuccess
 	m.onCancel = onCancel
@@ -82,6 +103,8 @@ func (m *PasswordPromptModel) Show(title, description string) {
 	m.error = ""
 	m.loading = false
 	m.lastActivity = time.Now()
+	m.unlocked = nil
+	m.code = ""
 }
 
 func (m *PasswordPromptModel) Hide() {
@@ -89,6 +112,8 @@ func (m *PasswordPromptModel) Hide() {
 	m.password = ""
 	m.error = ""
 	m.loading = false
+	m.unlocked = nil
+	m.code = ""
 }
 
 func (m *PasswordPromptModel) IsVisible() bool {
@@ -123,6 +148,16 @@ func (m PasswordPromptModel) Update(msg tea.Msg) (PasswordPromptModel, tea.Cmd)
 			return m, nil
 
 		case "enter":
+			if m.unlocked != nil {
+				if strings.TrimSpace(m.code) == "" {
+					m.error = "Authentication code cannot be empty"
+					return m, nil
+				}
+				m.loading = true
+				m.error = ""
+				return m, m.verifyCode()
+			}
+
 			if len(m.password) == 0 {
 				m.error = "Password cannot be empty"
 				return m, nil
@@ -138,17 +173,26 @@ func (m PasswordPromptModel) Update(msg tea.Msg) (PasswordPromptModel, tea.Cmd)
 			return m, m.verifyPassword()
 
 		case "backspace":
-			if len(m.password) > 0 {
+			if m.unlocked != nil {
+				if len(m.code) > 0 {
+					m.code = m.code[:len(m.code)-1]
+				}
+			} else if len(m.password) > 0 {
 				m.password = m.password[:len(m.password)-1]
 			}
 
 		case "ctrl+u":
 			m.password = ""
+			m.code = ""
 
 		default:
-			// Add character to password
+			// Add character to password or code
 			if len(msg.String()) == 1 && msg.String() != " " {
-				m.password += msg.String()
+				if m.unlocked != nil {
+					m.code += msg.String()
+				} else {
+					m.password += msg.String()
+				}
 			}
 		}
 
@@ -156,11 +200,21 @@ func (m PasswordPromptModel) Update(msg tea.Msg) (PasswordPromptModel, tea.Cmd)
 
 	case PasswordVerificationMsg:
 		m.loading = false
-		if msg.Success {
+		if msg.Success && m.securityManager != nil && msg.Wallet.TwoFactor != nil {
+			// The password was right; the code is still needed
+			m.unlocked = msg.Wallet
+			m.password = ""
+			m.code = ""
+			m.error = ""
+		} else if msg.Success {
 			m.Hide()
 			if m.onSuccess != nil {
 				return m, m.onSuccess(msg.Wallet)
 			}
+		} else if errors.Is(msg.Error, storage.ErrKeystoreMissing) || errors.Is(msg.Error, storage.ErrAddressMismatch) || errors.Is(msg.Error, storage.ErrTwoFactorRemoved) {
+			// The password was right but the wallet cannot sign; retrying won't help
+			m.error = msg.Error.Error()
+			m.password = ""
 		} else {
 			m.attempts++
 			if m.attempts >= m.maxAttempts {
@@ -174,6 +228,25 @@ func (m PasswordPromptModel) Update(msg tea.Msg) (PasswordPromptModel, tea.Cmd)
 			m.password = ""
 		}
 
+	case SecondFactorVerificationMsg:
+		m.loading = false
+		if m.unlocked == nil {
+			return m, nil
+		}
+		if msg.Error == nil {
+			wallet := m.unlocked
+			m.Hide()
+			if m.onSuccess != nil {
+				return m, m.onSuccess(wallet)
+			}
+			return m, nil
+		}
+		m.code = ""
+		m.error = msg.Error.Error()
+		if errors.Is(msg.Error, security.ErrInvalidSecondFactor) {
+			m.error = fmt.Sprintf("Incorrect authentication code (%d failed attempts)", m.securityManager.GetFailedAttemptCount(m.unlocked.ID))
+		}
+
 	case PasswordPromptMsg:
 		switch msg.Action {
 		case "show":
@@ -251,9 +324,16 @@ func (m *PasswordPromptModel) View() string {
 		passwordDisplay = m.password
 	}
 
-	if m.loading {
+	switch {
+	case m.loading && m.unlocked != nil:
+		content.WriteString(inputStyle.Render("Verifying code..."))
+	case m.loading:
 		content.WriteString(inputStyle.Render("Verifying password..."))
-	} else {
+	case m.unlocked != nil:
+		content.WriteString(descStyle.Render("Enter the code from your authenticator app, or a recovery code"))
+		content.WriteString("\n")
+		content.WriteString(inputStyle.Render("Code: " + m.code))
+	default:
 		content.WriteString(inputStyle.Render("Password: " + passwordDisplay))
 	}
 	content.WriteString("\n\n")
@@ -298,6 +378,15 @@ func (m *PasswordPromptModel) verifyPassword() tea.Cmd {
 	}
 }
 
+// verifyCode checks the authentication code for the unlocked wallet. A
+// recovery code is spent in storage, so this runs as a command.
+func (m *PasswordPromptModel) verifyCode() tea.Cmd {
+	securityManager, store, wallet, code := m.securityManager, m.storage, m.unlocked, m.code
+	return func() tea.Msg {
+		return SecondFactorVerificationMsg{Error: securityManager.VerifySecondFactor(store, wallet, code)}
+	}
+}
+
 // Helper function to show password prompt
 func ShowPasswordPrompt(title, description string) tea.Cmd {
 	return func() tea.Msg {
@@ -0,0 +1,209 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+type ProfileSelectorModel struct {
+	home     string
+	profiles []string
+	current  string
+	cursor   int
+
+	creating bool
+	input    textinput.Model
+	err      string
+	loading  string
+}
+
+// ProfileSelectedMsg asks the app to open a profile, creating it first when
+// Create is set
+type ProfileSelectedMsg struct {
+	Name   string
+	Create bool
+}
+
+func NewProfileSelectorModel(home string, profiles []string, current string) *ProfileSelectorModel {
+	input := textinput.New()
+	input.Placeholder = "profile name"
+	input.CharLimit = 32
+	input.Width = 32
+
+	m := &ProfileSelectorModel{
+		home:     home,
+		profiles: profiles,
+		current:  current,
+		input:    input,
+	}
+
+	for i, profile := range profiles {
+		if profile == current {
+			m.cursor = i
+		}
+	}
+
+	return m
+}
+
+// IsEditing reports whether the new profile name input has focus
+func (m *ProfileSelectorModel) IsEditing() bool {
+	return m.creating
+}
+
+// SetLoading shows progress while a profile is opened
+func (m *ProfileSelectorModel) SetLoading(name string) {
+	m.loading = name
+	m.err = ""
+}
+
+// SetError reports a failure to open or create a profile
+func (m *ProfileSelectorModel) SetError(err error) {
+	m.loading = ""
+	m.err = err.Error()
+}
+
+func (m ProfileSelectorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ProfileSelectorModel) Update(msg tea.Msg) (ProfileSelectorModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.loading != "" {
+		return m, nil
+	}
+
+	if m.creating {
+		switch keyMsg.String() {
+		case "esc":
+			m.creating = false
+			m.err = ""
+			m.input.Blur()
+			m.input.SetValue("")
+		case "enter":
+			name := strings.TrimSpace(m.input.Value())
+			if err := storage.ValidateProfileName(name); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			for _, profile := range m.profiles {
+				if profile == name {
+					m.err = fmt.Sprintf("profile %q already exists", name)
+					return m, nil
+				}
+			}
+			m.creating = false
+			m.input.Blur()
+			return m, func() tea.Msg { return ProfileSelectedMsg{Name: name, Create: true} }
+		default:
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(keyMsg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.profiles) {
+			m.cursor++
+		}
+	case "enter", " ":
+		if m.cursor < len(m.profiles) {
+			name := m.profiles[m.cursor]
+			return m, func() tea.Msg { return ProfileSelectedMsg{Name: name} }
+		}
+		m.creating = true
+		m.err = ""
+		m.input.SetValue("")
+		return m, m.input.Focus()
+	}
+
+	return m, nil
+}
+
+func (m ProfileSelectorModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	itemStyle := lipgloss.NewStyle().
+		Padding(0, 2)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Green)).
+		Background(lipgloss.Color(utils.Colours.Surface0)).
+		Padding(0, 2)
+
+	subtleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	var content string
+	content += titleStyle.Render("VeTerm - Select Profile") + "\n"
+	content += subtleStyle.Render(fmt.Sprintf("Data directory: %s", m.home)) + "\n\n"
+
+	for i, profile := range m.profiles {
+		cursor := " "
+		style := itemStyle
+		if m.cursor == i {
+			cursor = ">"
+			style = selectedStyle
+		}
+
+		label := profile
+		if profile == m.current {
+			label += " (active)"
+		}
+		content += style.Render(fmt.Sprintf("%s %s", cursor, label)) + "\n"
+	}
+
+	content += "\n"
+	cursor := " "
+	style := itemStyle
+	if m.cursor == len(m.profiles) {
+		cursor = ">"
+		style = selectedStyle
+	}
+	content += style.Render(fmt.Sprintf("%s Create New Profile", cursor)) + "\n"
+
+	if m.creating {
+		content += "\n  " + m.input.View() + "\n"
+	}
+
+	if m.loading != "" {
+		content += "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Yellow)).
+			Render(fmt.Sprintf("Opening profile %s...", m.loading)) + "\n"
+	}
+
+	if m.err != "" {
+		content += "\n" + lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render(m.err) + "\n"
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	content += "\n"
+	if m.creating {
+		content += helpStyle.Render("Enter: create • Esc: cancel")
+	} else {
+		content += helpStyle.Render("Use ↑/↓ to navigate, Enter to select, q to quit")
+	}
+
+	return content
+}
@@ -0,0 +1,672 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	scheduleFieldTemplate = iota
+	scheduleFieldName
+	scheduleFieldRecurrence
+	scheduleFieldStart
+	scheduleFieldEnd
+	scheduleFieldMaxRuns
+	scheduleFieldMode
+	scheduleFieldMissed
+	scheduleFieldValues
+	scheduleFieldCount
+)
+
+type scheduledPaymentsMode int
+
+const (
+	scheduleModeList scheduledPaymentsMode = iota
+	scheduleModeCreate
+	scheduleModeConfirmDelete
+	// scheduleModeCode asks for the authentication code of a wallet with a
+	// second factor before approving
+	scheduleModeCode
+)
+
+// recentRunsShown is how many finished runs are listed under the schedules
+const recentRunsShown = 5
+
+// ScheduledPaymentMsg reports the result of approving a scheduled payment
+type ScheduledPaymentMsg struct {
+	Run *models.ScheduledRun
+	Err error
+}
+
+// ScheduledPaymentsModel lists the current wallet's payments waiting for
+// approval, its schedules and their recent runs. Waiting payments are
+// approved with one key from the unlocked wallet.
+type ScheduledPaymentsModel struct {
+	storage         *storage.Storage
+	scheduler       *scheduler.Scheduler
+	securityManager *security.SecurityManager
+	wallet          *models.Wallet
+
+	templates []models.TransactionTemplate
+	schedules []models.PaymentSchedule
+	pending   []models.ScheduledRun
+	recent    []models.ScheduledRun
+	// selected indexes pending runs first, then schedules
+	selected int
+	mode     scheduledPaymentsMode
+	sending  bool
+
+	inputs   [scheduleFieldCount]textinput.Model
+	code     textinput.Model
+	focused  int
+	template int
+	autoPay  bool
+	skipMiss bool
+
+	message string
+	err     string
+}
+
+func NewScheduledPaymentsModel(store *storage.Storage, payments *scheduler.Scheduler, securityManager *security.SecurityManager, wallet *models.Wallet) *ScheduledPaymentsModel {
+	m := &ScheduledPaymentsModel{
+		storage:         store,
+		scheduler:       payments,
+		securityManager: securityManager,
+		wallet:          wallet,
+	}
+
+	placeholders := [scheduleFieldCount]string{
+		"",
+		"defaults to the template's name",
+		`every 30d, every 2w, "0 9 1 * *" or @monthly`,
+		"YYYY-MM-DD HH:MM, empty for now",
+		"YYYY-MM-DD, empty for no end",
+		"0 for no limit",
+		"",
+		"",
+		"amount=50% of VTHO, contact=Alice",
+	}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.Width = 50
+		m.inputs[i] = input
+	}
+	m.code = textinput.New()
+	m.code.Placeholder = "authenticator or recovery code"
+	m.code.Width = 34
+
+	m.reload()
+	return m
+}
+
+// IsEditing reports whether a text field has focus
+func (m *ScheduledPaymentsModel) IsEditing() bool {
+	return m.mode == scheduleModeCreate || m.mode == scheduleModeCode
+}
+
+func (m ScheduledPaymentsModel) Init() tea.Cmd {
+	return nil
+}
+
+// reload reads the wallet's schedules and runs, keeping the selection in
+// range
+func (m *ScheduledPaymentsModel) reload() {
+	templates, err := m.storage.LoadTemplates(m.wallet.ID)
+	if err != nil {
+		m.err = fmt.Sprintf("Failed to load templates: %s", err.Error())
+	}
+	m.templates = templates
+
+	schedules, err := m.storage.LoadSchedules()
+	if err != nil {
+		m.err = fmt.Sprintf("Failed to load schedules: %s", err.Error())
+	}
+	m.schedules = nil
+	for _, schedule := range schedules {
+		if schedule.WalletID == m.wallet.ID {
+			m.schedules = append(m.schedules, schedule)
+		}
+	}
+
+	runs, err := m.storage.LoadScheduledRuns()
+	if err != nil {
+		m.err = fmt.Sprintf("Failed to load scheduled payments: %s", err.Error())
+	}
+	m.pending, m.recent = nil, nil
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.WalletID != m.wallet.ID {
+			continue
+		}
+		if run.Status == models.RunPending {
+			m.pending = append(m.pending, run)
+		} else if len(m.recent) < recentRunsShown {
+			m.recent = append(m.recent, run)
+		}
+	}
+
+	if count := len(m.pending) + len(m.schedules); m.selected >= count {
+		m.selected = count - 1
+	}
+	if m.selected < 0 {
+		m.selected = 0
+	}
+}
+
+func (m *ScheduledPaymentsModel) currentRun() *models.ScheduledRun {
+	if m.selected < len(m.pending) {
+		return &m.pending[m.selected]
+	}
+	return nil
+}
+
+func (m *ScheduledPaymentsModel) currentSchedule() *models.PaymentSchedule {
+	index := m.selected - len(m.pending)
+	if index >= 0 && index < len(m.schedules) {
+		return &m.schedules[index]
+	}
+	return nil
+}
+
+func (m ScheduledPaymentsModel) Update(msg tea.Msg) (ScheduledPaymentsModel, tea.Cmd) {
+	if result, ok := msg.(ScheduledPaymentMsg); ok {
+		m.sending = false
+		if result.Err != nil {
+			m.err = "Payment failed: " + result.Err.Error()
+		} else {
+			m.message = fmt.Sprintf("Sent %s (%s)", result.Run.ScheduleName, utils.FormatAddress(result.Run.TxHash, 8, 6))
+		}
+		m.reload()
+		return m, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		switch m.mode {
+		case scheduleModeCreate:
+			m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+		case scheduleModeCode:
+			m.code, cmd = m.code.Update(msg)
+		}
+		return m, cmd
+	}
+
+	switch m.mode {
+	case scheduleModeCreate:
+		return m.updateForm(keyMsg)
+	case scheduleModeCode:
+		return m.updateCode(keyMsg)
+	case scheduleModeConfirmDelete:
+		if schedule := m.currentSchedule(); schedule != nil && (keyMsg.String() == "y" || keyMsg.String() == "Y") {
+			if err := m.scheduler.RemoveSchedule(schedule); err != nil {
+				m.err = "Failed to remove schedule: " + err.Error()
+			} else {
+				m.message = fmt.Sprintf("Removed %s", schedule.Name)
+			}
+			m.reload()
+		}
+		m.mode = scheduleModeList
+		return m, nil
+	}
+
+	if m.sending {
+		return m, nil
+	}
+
+	m.message, m.err = "", ""
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.pending)+len(m.schedules)-1 {
+			m.selected++
+		}
+	case "enter", "a":
+		if run := m.currentRun(); run != nil {
+			if m.wallet.TwoFactor != nil {
+				m.mode = scheduleModeCode
+				m.code.SetValue("")
+				return m, m.code.Focus()
+			}
+			m.sending = true
+			m.message = fmt.Sprintf("Sending %s...", run.ScheduleName)
+			return m, m.approve(run.ID, "")
+		}
+	case "x":
+		if run := m.currentRun(); run != nil {
+			if _, err := m.scheduler.Reject(run.ID, "rejected in the wallet"); err != nil {
+				m.err = err.Error()
+			} else {
+				m.message = fmt.Sprintf("Rejected %s", run.ScheduleName)
+			}
+			m.reload()
+		}
+	case "p":
+		if schedule := m.currentSchedule(); schedule != nil {
+			m.togglePause(schedule)
+		}
+	case "d", "delete":
+		if m.currentSchedule() != nil {
+			m.mode = scheduleModeConfirmDelete
+		}
+	case "n":
+		if len(m.templates) == 0 {
+			m.err = "Save a transaction template first; schedules pay templates"
+			return m, nil
+		}
+		return m, m.startCreate()
+	case "esc":
+		return m, NavigateTo(ViewWalletDashboard, nil)
+	}
+	return m, nil
+}
+
+// updateCode reads the authentication code for the selected payment
+func (m ScheduledPaymentsModel) updateCode(keyMsg tea.KeyMsg) (ScheduledPaymentsModel, tea.Cmd) {
+	switch keyMsg.String() {
+	case "esc":
+		m.mode = scheduleModeList
+		m.code.Blur()
+		m.code.SetValue("")
+		return m, nil
+	case "enter":
+		run := m.currentRun()
+		code := strings.TrimSpace(m.code.Value())
+		if run == nil || code == "" {
+			return m, nil
+		}
+		m.mode = scheduleModeList
+		m.code.Blur()
+		m.code.SetValue("")
+		m.sending = true
+		m.message = fmt.Sprintf("Sending %s...", run.ScheduleName)
+		return m, m.approve(run.ID, code)
+	}
+
+	var cmd tea.Cmd
+	m.code, cmd = m.code.Update(keyMsg)
+	return m, cmd
+}
+
+func (m *ScheduledPaymentsModel) approve(runID, code string) tea.Cmd {
+	payments, securityManager, store, wallet := m.scheduler, m.securityManager, m.storage, m.wallet
+	return func() tea.Msg {
+		if err := securityManager.VerifySecondFactor(store, wallet, code); err != nil {
+			return ScheduledPaymentMsg{Err: err}
+		}
+		run, err := payments.Approve(runID, wallet)
+		return ScheduledPaymentMsg{Run: run, Err: err}
+	}
+}
+
+func (m *ScheduledPaymentsModel) togglePause(schedule *models.PaymentSchedule) {
+	if schedule.Paused {
+		if err := schedule.Resume(time.Now()); err != nil {
+			m.err = err.Error()
+			return
+		}
+	} else {
+		schedule.Paused = true
+	}
+
+	if err := m.scheduler.UpdateSchedule(schedule); err != nil {
+		m.err = "Failed to save schedule: " + err.Error()
+	} else if schedule.Paused {
+		m.message = fmt.Sprintf("Paused %s", schedule.Name)
+	} else {
+		m.message = fmt.Sprintf("Resumed %s, next due %s", schedule.Name, schedule.NextRunAt.Format("2006-01-02 15:04"))
+	}
+	m.reload()
+}
+
+func (m *ScheduledPaymentsModel) startCreate() tea.Cmd {
+	for i := range m.inputs {
+		m.inputs[i].SetValue("")
+	}
+	m.inputs[scheduleFieldRecurrence].SetValue("@monthly")
+	m.inputs[scheduleFieldMaxRuns].SetValue("0")
+	m.template, m.autoPay, m.skipMiss = 0, false, false
+	m.mode = scheduleModeCreate
+	m.err = ""
+	m.inputs[m.focused].Blur()
+	m.focused = scheduleFieldTemplate
+	return nil
+}
+
+// scheduleChoiceField reports whether field is picked with ←/→ rather than
+// typed
+func scheduleChoiceField(field int) bool {
+	return field == scheduleFieldTemplate || field == scheduleFieldMode || field == scheduleFieldMissed
+}
+
+func (m *ScheduledPaymentsModel) focusField(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	if scheduleChoiceField(field) {
+		return nil
+	}
+	return m.inputs[field].Focus()
+}
+
+func (m ScheduledPaymentsModel) updateForm(keyMsg tea.KeyMsg) (ScheduledPaymentsModel, tea.Cmd) {
+	switch keyMsg.String() {
+	case "esc":
+		m.mode = scheduleModeList
+		m.err = ""
+		return m, nil
+	case "tab", "down":
+		return m, m.focusField((m.focused + 1) % scheduleFieldCount)
+	case "shift+tab", "up":
+		return m, m.focusField((m.focused + scheduleFieldCount - 1) % scheduleFieldCount)
+	case "left", "right":
+		if scheduleChoiceField(m.focused) {
+			switch m.focused {
+			case scheduleFieldTemplate:
+				step := 1
+				if keyMsg.String() == "left" {
+					step = len(m.templates) - 1
+				}
+				m.template = (m.template + step) % len(m.templates)
+			case scheduleFieldMode:
+				m.autoPay = !m.autoPay
+			case scheduleFieldMissed:
+				m.skipMiss = !m.skipMiss
+			}
+			return m, nil
+		}
+	case "enter":
+		if m.focused < scheduleFieldValues {
+			return m, m.focusField(m.focused + 1)
+		}
+		m.create()
+		return m, nil
+	}
+
+	if scheduleChoiceField(m.focused) {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(keyMsg)
+	return m, cmd
+}
+
+// create builds a schedule from the form and saves it, reporting the first
+// problem on screen
+func (m *ScheduledPaymentsModel) create() {
+	fail := func(field int, format string, args ...interface{}) {
+		m.err = fmt.Sprintf(format, args...)
+		m.focusField(field)
+	}
+	value := func(field int) string {
+		return strings.TrimSpace(m.inputs[field].Value())
+	}
+	template := m.templates[m.template]
+
+	start := time.Now()
+	if value(scheduleFieldStart) != "" {
+		parsed, err := models.ParseScheduleTime(value(scheduleFieldStart))
+		if err != nil {
+			fail(scheduleFieldStart, "Start: %s", err.Error())
+			return
+		}
+		start = parsed
+	}
+
+	name := value(scheduleFieldName)
+	if name == "" {
+		name = template.Name
+	}
+	schedule, err := models.NewPaymentSchedule(name, m.wallet.ID, template.ID, value(scheduleFieldRecurrence), start)
+	if err != nil {
+		fail(scheduleFieldRecurrence, "Recurrence: %s", err.Error())
+		return
+	}
+
+	if value(scheduleFieldEnd) != "" {
+		end, err := models.ParseScheduleEnd(value(scheduleFieldEnd))
+		if err != nil {
+			fail(scheduleFieldEnd, "End: %s", err.Error())
+			return
+		}
+		schedule.EndAt = &end
+	}
+
+	if value(scheduleFieldMaxRuns) != "" {
+		maxRuns, err := strconv.Atoi(value(scheduleFieldMaxRuns))
+		if err != nil {
+			fail(scheduleFieldMaxRuns, "Max payments must be a number")
+			return
+		}
+		schedule.MaxRuns = maxRuns
+	}
+	if m.autoPay {
+		schedule.Mode = models.ScheduleAuto
+	}
+	if m.skipMiss {
+		schedule.OnMissed = models.MissedSkip
+	}
+
+	byName := make(map[string]string)
+	for _, pair := range strings.Split(value(scheduleFieldValues), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			fail(scheduleFieldValues, "Values: %q must be name=value", strings.TrimSpace(pair))
+			return
+		}
+		byName[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	contacts, _ := m.storage.LoadContacts()
+	if schedule.Values, err = template.ScheduleValues(byName, contacts); err != nil {
+		fail(scheduleFieldValues, "Values: %s", err.Error())
+		return
+	}
+
+	if err := m.scheduler.AddSchedule(schedule); err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.mode = scheduleModeList
+	m.message = fmt.Sprintf("Scheduled %s, first due %s", schedule.Name, schedule.NextRunAt.Format("2006-01-02 15:04"))
+	m.reload()
+}
+
+func (m ScheduledPaymentsModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Base)).
+		Background(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	helpStyle := mutedStyle.Italic(true)
+
+	var content string
+	content += titleStyle.Render("Scheduled Payments") + "\n"
+	content += helpStyle.Render(fmt.Sprintf("%s (%s)", m.wallet.Name, utils.FormatAddress(m.wallet.Address, 6, 4))) + "\n\n"
+
+	if m.mode == scheduleModeCreate {
+		return content + m.renderForm(labelStyle, mutedStyle, helpStyle)
+	}
+
+	render := func(index int, line string) string {
+		if index == m.selected {
+			return selectedStyle.Render("▶ "+line) + "\n"
+		}
+		return "  " + line + "\n"
+	}
+
+	content += labelStyle.Render("Awaiting approval") + "\n"
+	if len(m.pending) == 0 {
+		content += mutedStyle.Render("  Nothing to approve") + "\n"
+	}
+	for i, run := range m.pending {
+		line := fmt.Sprintf("%-24s %s %s to %s", run.ScheduleName, utils.FormatAmount(run.Amount, 4), run.Asset, recipientLabel(run.ContactName, run.To))
+		content += render(i, line)
+		detail := "due " + run.DueAt.Format("2006-01-02 15:04")
+		if run.Reason != "" {
+			detail += " • " + run.Reason
+		}
+		content += mutedStyle.Render("      "+detail) + "\n"
+	}
+	content += "\n"
+
+	content += labelStyle.Render("Schedules") + "\n"
+	if len(m.schedules) == 0 {
+		content += mutedStyle.Render("  No schedules yet. Press n to schedule a template.") + "\n"
+	}
+	for i, schedule := range m.schedules {
+		next := "next " + schedule.NextRunAt.Format("2006-01-02 15:04")
+		switch {
+		case schedule.Finished():
+			next = "finished"
+		case schedule.Paused:
+			next = "paused"
+		}
+		content += render(len(m.pending)+i, fmt.Sprintf("%-24s %-28s %s", schedule.Name, schedule.Describe(), next))
+		content += mutedStyle.Render(fmt.Sprintf("      %s • %s", schedule.Mode, schedule.OnMissed)) + "\n"
+	}
+	content += "\n"
+
+	if len(m.recent) > 0 {
+		content += labelStyle.Render("Recent") + "\n"
+		for _, run := range m.recent {
+			line := fmt.Sprintf("  %s  %-24s %-8s", run.DueAt.Format("2006-01-02"), run.ScheduleName, run.Status)
+			if run.Amount != nil {
+				line += fmt.Sprintf(" %s %s", utils.FormatAmount(run.Amount, 4), run.Asset)
+			}
+			if run.Reason != "" && run.Status != models.RunSent {
+				line += " • " + run.Reason
+			}
+			content += mutedStyle.Render(line) + "\n"
+		}
+		content += "\n"
+	}
+
+	if m.mode == scheduleModeCode {
+		if run := m.currentRun(); run != nil {
+			content += labelStyle.Render(fmt.Sprintf("Authentication code to approve %s:", run.ScheduleName)) + "\n"
+			content += m.code.View() + "\n"
+			content += helpStyle.Render("Enter: approve • Esc: cancel") + "\n\n"
+		}
+	}
+
+	if m.mode == scheduleModeConfirmDelete {
+		if schedule := m.currentSchedule(); schedule != nil {
+			content += lipgloss.NewStyle().
+				Foreground(lipgloss.Color(utils.Colours.Yellow)).
+				Bold(true).
+				Render(fmt.Sprintf("Remove %s? (y/n)", schedule.Name)) + "\n\n"
+		}
+	}
+
+	content += m.renderStatus()
+	content += helpStyle.Render("↑/↓: navigate • Enter/a: approve • x: reject • n: new • p: pause/resume • d: remove • Esc: back")
+	return content
+}
+
+func (m ScheduledPaymentsModel) renderForm(labelStyle, mutedStyle, helpStyle lipgloss.Style) string {
+	choice := func(field int, text string) string {
+		if field == m.focused {
+			return lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Blue)).Bold(true).Render("◀ " + text + " ▶")
+		}
+		return text
+	}
+
+	template := m.templates[m.template]
+	variables, _ := template.Variables()
+	var names []string
+	for _, variable := range variables {
+		names = append(names, variable.Name)
+	}
+
+	mode := "approve each payment"
+	if m.autoPay {
+		mode = "pay automatically within the budget while unlocked"
+	}
+	missed := "pay the latest missed payment"
+	if m.skipMiss {
+		missed = "skip missed payments"
+	}
+
+	labels := [scheduleFieldCount]string{"Template:", "Name:", "Recurrence:", "Start:", "End:", "Max payments:", "Mode:", "Missed payments:", "Values:"}
+	var content string
+	for i, label := range labels {
+		marker := "  "
+		if i == m.focused {
+			marker = "▶ "
+		}
+		content += labelStyle.Render(marker+label) + "\n  "
+		switch i {
+		case scheduleFieldTemplate:
+			content += choice(i, template.Name)
+		case scheduleFieldMode:
+			content += choice(i, mode)
+		case scheduleFieldMissed:
+			content += choice(i, missed)
+		default:
+			content += m.inputs[i].View()
+		}
+		if i == scheduleFieldValues {
+			if len(names) == 0 {
+				content += "\n  " + mutedStyle.Render("the template has no placeholders")
+			} else {
+				content += "\n  " + mutedStyle.Render("placeholders: "+strings.Join(names, ", "))
+			}
+		}
+		content += "\n\n"
+	}
+
+	content += m.renderStatus()
+	content += helpStyle.Render("Tab/↑/↓: move • ←/→: choose • Enter: next / save • Esc: cancel")
+	return content
+}
+
+func (m ScheduledPaymentsModel) renderStatus() string {
+	if m.err != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ "+m.err) + "\n\n"
+	}
+	if m.message != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Green)).
+			Render("✓ "+m.message) + "\n\n"
+	}
+	return ""
+}
+
+func recipientLabel(contactName, address string) string {
+	if contactName != "" {
+		return contactName
+	}
+	return utils.FormatAddress(address, 6, 4)
+}
@@ -53,6 +53,8 @@ type SendTransactionModel struct {
 	finalBalance  *big.Int
 	transaction   *blockchain.Transaction
 	transactionID string
+	// exportPath is where a watch-only wallet's unsigned transaction went
+	exportPath string
 
 	// UI state
 	loading         bool
@@ -86,6 +88,22 @@ type SendTransactionModel struct {
 	notes    string
 	tags     []string
 	category string
+
+	// Spending policy: the decision for the payment on review, and whether
+	// its second confirmation has been given
+	guard            *security.SpendingGuard
+	policyDecision   *models.PolicyDecision
+	policyErr        error
+	policyConfirmed  bool
+	confirmingPolicy bool
+
+	// Address poisoning: the warning raised for the recipient before review,
+	// the address being retyped to get past it, and the recipient that was
+	// last retyped correctly
+	recipientWarning  *models.RecipientWarning
+	checkingRecipient bool
+	recipientRetype   string
+	verifiedRecipient string
 }
 
 type GasEstimateMsg struct {
@@ -98,11 +116,28 @@ type TransactionBroadcastMsg struct {
 	Error error
 }
 
+// UnsignedTransactionExportedMsg reports where a watch-only wallet's
+// unsigned transaction was written
+type UnsignedTransactionExportedMsg struct {
+	Path  string
+	Error error
+}
+
 type TransactionStatusMsg struct {
 	Status string
 	Error  error
 }
 
+// ReceivedTransfersMsg reports that the account's incoming transfers were
+// recorded, so the recipient can be checked against them
+type ReceivedTransfersMsg struct {
+	Error error
+}
+
+// receivedTransferLimit is how many of the account's latest incoming
+// transfers are recorded before the recipient is checked
+const receivedTransferLimit = 100
+
 func NewSendTransactionModel(wallet *models.Wallet) *SendTransactionModel {
 	passwordPrompt := NewPasswordPromptModel()
 	contactSelector := NewContactSelectorModel()
@@ -160,9 +195,57 @@ func (m *SendTransactionModel) SetBlockchainClient(client *blockchain.Client) {
 	m.blockchainClient = client
 }
 
+// SetSpendingGuard checks payments against the wallet's spending policy on
+// review and again before signing
+func (m *SendTransactionModel) SetSpendingGuard(guard *security.SpendingGuard) {
+	m.guard = guard
+}
+
+// SetSecurityManager asks wallets with a second factor for an
+// authentication code after the password before signing
+func (m *SendTransactionModel) SetSecurityManager(securityManager *security.SecurityManager) {
+	m.passwordPrompt.RequireSecondFactor(securityManager)
+}
+
 func (m *SendTransactionModel) SetStorage(storage *storage.Storage) {
 	m.storage = storage
 	m.passwordPrompt.SetStorage(storage)
+	m.templateSelector.SetStorage(storage)
+	m.LoadTemplates()
+}
+
+// IsEditing reports whether the template selector has the keyboard, so
+// global shortcuts are left to it
+func (m *SendTransactionModel) IsEditing() bool {
+	return m.templateSelector.IsVisible()
+}
+
+// LoadTemplates reads the wallet's saved templates and recent addresses,
+// picking up changes made on the template management screen
+func (m *SendTransactionModel) LoadTemplates() {
+	if m.storage == nil || m.wallet == nil {
+		return
+	}
+	templates, err := m.storage.LoadTemplates(m.wallet.ID)
+	if err != nil {
+		m.showFeedback(FeedbackError, fmt.Sprintf("Failed to load templates: %s", err.Error()), 5*time.Second)
+		return
+	}
+	m.templateManager.Import(templates)
+
+	addresses, err := m.storage.LoadRecentAddresses(m.wallet.ID)
+	if err != nil {
+		m.showFeedback(FeedbackError, fmt.Sprintf("Failed to load recent addresses: %s", err.Error()), 5*time.Second)
+		return
+	}
+	m.recentAddresses.Import(addresses)
+}
+
+func (m *SendTransactionModel) saveTemplates() error {
+	if m.storage == nil || m.wallet == nil {
+		return nil
+	}
+	return m.storage.SaveTemplates(m.wallet.ID, m.templateManager.Export())
 }
 
 func (m SendTransactionModel) Init() tea.Cmd {
@@ -218,6 +301,14 @@ func (m SendTransactionModel) Update(msg tea.Msg) (SendTransactionModel, tea.Cmd
 		m.terminalHeight = msg.Height
 
 	case tea.KeyMsg:
+		if m.confirmingPolicy {
+			return m, m.handlePolicyConfirmKey(msg.String())
+		}
+		if m.checkingRecipient {
+			cmds = append(cmds, m.handleRecipientCheckKey(msg.String()))
+			break
+		}
+
 		switch msg.String() {
 		case "esc":
 			if m.step == StepRecipient {
@@ -235,6 +326,11 @@ func (m SendTransactionModel) Update(msg tea.Msg) (SendTransactionModel, tea.Cmd
 				m.clearGasEstimate()
 			}
 
+		case "ctrl+f":
+			if m.step == StepRecipient {
+				cmds = append(cmds, m.nextAccount())
+			}
+
 		case "ctrl+k":
 			if m.step == StepRecipient {
 				cmds = append(cmds, m.openContactSelector())
@@ -248,6 +344,7 @@ func (m SendTransactionModel) Update(msg tea.Msg) (SendTransactionModel, tea.Cmd
 
 		case "ctrl+t":
 			if m.step == StepRecipient {
+				m.templateSelector.SetBalance(m.wallet.CachedBalance)
 				m.templateSelector.Show()
 				m.showTemplateSelector = true
 			}
@@ -264,6 +361,20 @@ func (m SendTransactionModel) Update(msg tea.Msg) (SendTransactionModel, tea.Cmd
 			cmds = append(cmds, m.handleTextInput(msg.String()))
 		}
 
+	case BalanceUpdateMsg:
+		if msg.Error == nil && msg.Balance != nil {
+			m.wallet.SetBalance(msg.Balance.VET, msg.Balance.VTHO)
+			m.calculateTotalFee()
+		}
+
+	case ReceivedTransfersMsg:
+		if msg.Error != nil {
+			m.showFeedback(FeedbackWarning, fmt.Sprintf("Recipient not checked against received transfers: %s", msg.Error.Error()), 5*time.Second)
+		}
+		if m.step == StepMetadata {
+			m.goToNextStep()
+		}
+
 	case GasEstimateMsg:
 		m.loading = false
 		if msg.Error != nil {
@@ -283,16 +394,36 @@ func (m SendTransactionModel) Update(msg tea.Msg) (SendTransactionModel, tea.Cmd
 			m.step = StepCompleteTransaction
 			m.showFeedback(FeedbackSuccess, "Transaction sent successfully!", 5*time.Second)
 
-			// Add to recent addresses
+			// Add to history, where spending limits are counted from, and
+			// to recent addresses
 			if amountWei, err := utils.ValidateAmount(m.amount, 18); err == nil {
 				contactName := ""
 				if m.selectedContact != nil {
 					contactName = m.selectedContact.Name
 				}
+				if err := m.saveToHistory(msg.TxID, amountWei, contactName); err != nil {
+					m.showFeedback(FeedbackWarning, fmt.Sprintf("Transaction sent, but not added to history: %s", err.Error()), 5*time.Second)
+				}
 				m.recentAddresses.AddAddress(m.recipientAddress, contactName, string(m.selectedAsset), amountWei)
+				if m.storage != nil {
+					if err := m.storage.SaveRecentAddresses(m.wallet.ID, m.recentAddresses.Export()); err != nil {
+						m.showFeedback(FeedbackWarning, fmt.Sprintf("Transaction sent, but recent addresses were not saved: %s", err.Error()), 5*time.Second)
+					}
+				}
 			}
 		}
 
+	case UnsignedTransactionExportedMsg:
+		m.loading = false
+		if msg.Error != nil {
+			m.showFeedback(FeedbackError, fmt.Sprintf("Export failed: %s", msg.Error.Error()), 10*time.Second)
+			m.step = StepReview
+		} else {
+			m.exportPath = msg.Path
+			m.step = StepCompleteTransaction
+			m.showFeedback(FeedbackSuccess, "Unsigned transaction exported", 5*time.Second)
+		}
+
 	case TransactionStatusMsg:
 		if msg.Error == nil {
 			m.showFeedback(FeedbackInfo, fmt.Sprintf("Transaction status: %s", msg.Status), 3*time.Second)
@@ -304,6 +435,7 @@ func (m SendTransactionModel) Update(msg tea.Msg) (SendTransactionModel, tea.Cmd
 
 	// Auto-validate current step
 	m.validateCurrentStep()
+	m.updatePolicyDecision()
 
 	// Auto-estimate gas when ready
 	if m.shouldEstimateGas() && !m.loading {
@@ -327,20 +459,22 @@ func (m SendTransactionModel) View() string {
 	content.WriteString("\n\n")
 
 	// Current step content
-	switch m.step {
-	case StepRecipient:
+	switch {
+	case m.checkingRecipient:
+		content.WriteString(m.renderRecipientCheck())
+	case m.step == StepRecipient:
 		content.WriteString(m.renderRecipientStep())
-	case StepAmount:
+	case m.step == StepAmount:
 		content.WriteString(m.renderAmountStep())
-	case StepAssetSelection:
+	case m.step == StepAssetSelection:
 		content.WriteString(m.renderAssetStep())
-	case StepMetadata:
+	case m.step == StepMetadata:
 		content.WriteString(m.renderMetadataStep())
-	case StepReview:
+	case m.step == StepReview:
 		content.WriteString(m.renderReviewStep())
-	case StepSending:
+	case m.step == StepSending:
 		content.WriteString(m.renderSendingStep())
-	case StepCompleteTransaction:
+	case m.step == StepCompleteTransaction:
 		content.WriteString(m.renderCompleteStep())
 	}
 
@@ -414,6 +548,14 @@ func (m *SendTransactionModel) renderHeader() string {
 	content.WriteString(titleStyle.Render("Send Transaction"))
 	content.WriteString("\n")
 	content.WriteString(stepStyle.Render(stepIndicator))
+	if accounts := m.wallet.AccountList(); len(accounts) > 1 {
+		content.WriteString("\n")
+		content.WriteString(stepStyle.Render(fmt.Sprintf("From %s (%s)", m.wallet.ActiveAccountLabel(), utils.FormatAddress(m.wallet.Address, 10, 8))))
+	}
+	if m.wallet.IsWatchOnly() {
+		content.WriteString("\n")
+		content.WriteString(stepStyle.Render("Watch-only wallet: the transaction is exported unsigned"))
+	}
 
 	return content.String()
 }
@@ -692,6 +834,18 @@ func (m *SendTransactionModel) renderReviewStep() string {
 
 	content.WriteString(cardStyle.Render(details.String()))
 
+	if m.recipientWarning != nil {
+		content.WriteString("\n\n")
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Yellow)).
+			Render("⚠ Recipient retyped after an address poisoning warning:\n" + m.recipientAddress))
+	}
+
+	if policy := m.renderPolicyDecision(); policy != "" {
+		content.WriteString("\n\n")
+		content.WriteString(policy)
+	}
+
 	// Final balance
 	if m.finalBalance != nil {
 		content.WriteString("\n\n")
@@ -709,6 +863,10 @@ func (m *SendTransactionModel) renderSendingStep() string {
 		Align(lipgloss.Center)
 
 	var content strings.Builder
+	if m.wallet.IsWatchOnly() {
+		content.WriteString(loadingStyle.Render("Exporting Unsigned Transaction..."))
+		return content.String()
+	}
 	content.WriteString(loadingStyle.Render("Sending Transaction..."))
 	content.WriteString("\n\n")
 	content.WriteString(loadingStyle.Render("Please wait while your transaction is broadcast to the network."))
@@ -728,6 +886,17 @@ func (m *SendTransactionModel) renderCompleteStep() string {
 		Padding(0, 1)
 
 	var content strings.Builder
+	if m.exportPath != "" {
+		content.WriteString(successStyle.Render("✓ Unsigned Transaction Exported"))
+		content.WriteString("\n\n")
+		content.WriteString("Saved to:")
+		content.WriteString("\n")
+		content.WriteString(txStyle.Render(m.exportPath))
+		content.WriteString("\n\n")
+		content.WriteString("Sign it on the machine that holds this address's key, then broadcast it.")
+		return content.String()
+	}
+
 	content.WriteString(successStyle.Render("✓ Transaction Sent Successfully!"))
 	content.WriteString("\n\n")
 
@@ -755,14 +924,26 @@ func (m *SendTransactionModel) renderHelpText() string {
 	switch m.step {
 	case StepRecipient:
 		helpText = "Enter recipient address • Ctrl+K: select contact • Ctrl+T: use template • Ctrl+A: add to contacts • Enter: next • Esc: back"
+		if len(m.wallet.AccountList()) > 1 {
+			helpText += " • Ctrl+F: change account"
+		}
 	case StepAmount:
 		helpText = "Enter amount • Enter: next • Esc: back"
 	case StepAssetSelection:
 		helpText = "Tab: toggle asset • Enter: next • Esc: back"
 	case StepMetadata:
 		helpText = "Enter notes (optional) • Enter: next • Esc: back"
+		if m.checkingRecipient {
+			helpText = "Type the full recipient address • Enter: confirm • Esc: change recipient"
+		}
 	case StepReview:
 		helpText = "Enter: send transaction • Ctrl+S: save as template • Esc: back"
+		if m.wallet.IsWatchOnly() {
+			helpText = "Enter: export unsigned transaction • Ctrl+S: save as template • Esc: back"
+		}
+		if m.confirmingPolicy {
+			helpText = "Y: confirm payment • N/Esc: cancel"
+		}
 	case StepCompleteTransaction:
 		helpText = "Enter: return to dashboard • Esc: back"
 	default:
@@ -803,8 +984,23 @@ func (m *SendTransactionModel) renderFeedbackMessage() string {
 
 // Password prompt callback methods
 func (m *SendTransactionModel) onPasswordSuccess(wallet *models.Wallet) tea.Cmd {
-	m.unlockedWallet = wallet
 	m.passwordPrompt.Hide()
+
+	if wallet.IsWatchOnly() {
+		m.step = StepReview
+		m.showFeedback(FeedbackError, models.ErrWatchOnly.Error(), 10*time.Second)
+		return nil
+	}
+
+	// The unlocked wallet holds the first account's key; sign as the
+	// account chosen for this transaction
+	account, err := wallet.UseAccount(m.wallet.ActiveAccount)
+	if err != nil {
+		m.step = StepReview
+		m.showFeedback(FeedbackError, fmt.Sprintf("Failed to unlock account: %s", err.Error()), 5*time.Second)
+		return nil
+	}
+	m.unlockedWallet = account
 	m.step = StepSending
 	return m.broadcastTransaction()
 }
@@ -820,6 +1016,31 @@ func (m *SendTransactionModel) onPasswordError(err error) tea.Cmd {
 	return nil
 }
 
+// nextAccount sends from the wallet's next account and fetches its balance
+func (m *SendTransactionModel) nextAccount() tea.Cmd {
+	accounts := m.wallet.AccountList()
+	if len(accounts) < 2 {
+		return nil
+	}
+
+	wallet, err := m.wallet.UseAccount((m.wallet.ActiveAccount + 1) % len(accounts))
+	if err != nil {
+		m.showFeedback(FeedbackError, err.Error(), 5*time.Second)
+		return nil
+	}
+	m.wallet = wallet
+	m.clearGasEstimate()
+
+	if m.blockchainClient == nil {
+		return nil
+	}
+	client, address := m.blockchainClient, wallet.Address
+	return func() tea.Msg {
+		balance, err := client.GetBalance(address)
+		return BalanceUpdateMsg{Balance: balance, Error: err}
+	}
+}
+
 // Navigation methods
 func (m *SendTransactionModel) goToPreviousStep() {
 	if m.step > StepRecipient {
@@ -840,8 +1061,16 @@ func (m *SendTransactionModel) goToNextStep() {
 	case StepAssetSelection:
 		m.step = StepMetadata
 	case StepMetadata:
+		if m.recipientNeedsCheck() {
+			m.checkingRecipient = true
+			m.recipientRetype = ""
+			return
+		}
 		m.step = StepReview
 	case StepReview:
+		if m.wallet.IsWatchOnly() {
+			return
+		}
 		m.passwordPrompt.SetWallet(m.wallet)
 		m.passwordPrompt.Show("Unlock Wallet", "Enter your wallet password to sign the transaction")
 	case StepCompleteTransaction:
@@ -854,12 +1083,49 @@ func (m *SendTransactionModel) handleEnterKey() tea.Cmd {
 	switch m.step {
 	case StepCompleteTransaction:
 		return NavigateTo(ViewWalletDashboard, nil)
+	case StepReview:
+		if !m.policyAllows() {
+			return nil
+		}
+		if m.wallet.IsWatchOnly() {
+			return m.exportUnsignedTransaction()
+		}
+		m.goToNextStep()
+		return nil
+	case StepMetadata:
+		return m.recordReceivedTransfers()
 	default:
 		m.goToNextStep()
 		return nil
 	}
 }
 
+// recordReceivedTransfers fetches the account's latest incoming transfers
+// and records them in the wallet's history before the recipient is checked.
+// Only the send view records what the wallet receives, so without this a
+// recipient that only ever sent dust would not be noticed.
+func (m *SendTransactionModel) recordReceivedTransfers() tea.Cmd {
+	if m.blockchainClient == nil || m.storage == nil {
+		m.goToNextStep()
+		return nil
+	}
+
+	client, store := m.blockchainClient, m.storage
+	walletID, address := m.wallet.ID, m.wallet.Address
+	return func() tea.Msg {
+		transfers, err := client.IncomingTransfers(address, receivedTransferLimit)
+		if err != nil {
+			return ReceivedTransfersMsg{Error: err}
+		}
+		received := make([]models.Transaction, 0, len(transfers))
+		for _, t := range transfers {
+			received = append(received, *models.NewReceivedTransaction(t.TxID, t.ClauseIndex, t.Sender, t.Recipient, t.Amount, t.BlockNumber, t.Timestamp))
+		}
+		_, err = store.RecordReceived(walletID, received)
+		return ReceivedTransfersMsg{Error: err}
+	}
+}
+
 func (m *SendTransactionModel) handleBackspace() tea.Cmd {
 	switch m.step {
 	case StepRecipient:
@@ -1047,6 +1313,7 @@ func (m *SendTransactionModel) broadcastTransaction() tea.Cmd {
 		}
 	}
 
+	guard, confirmed := m.guard, m.policyConfirmed
 	return func() tea.Msg {
 		// Parse amount
 		amountWei, err := utils.ValidateAmount(m.amount, 18)
@@ -1054,6 +1321,14 @@ func (m *SendTransactionModel) broadcastTransaction() tea.Cmd {
 			return TransactionBroadcastMsg{Error: err}
 		}
 
+		// Check the spending policy again at signing, in case other
+		// payments were made since the review
+		if guard != nil {
+			if err := guard.Check(m.wallet.ID, m.wallet.Address, m.recipientAddress, amountWei, string(m.selectedAsset), confirmed); err != nil {
+				return TransactionBroadcastMsg{Error: err}
+			}
+		}
+
 		// Build transaction
 		tx, err := m.blockchainClient.BuildTransaction(m.wallet.Address, m.recipientAddress, amountWei, m.selectedAsset)
 		if err != nil {
@@ -1061,6 +1336,9 @@ func (m *SendTransactionModel) broadcastTransaction() tea.Cmd {
 		}
 
 		// Sign transaction
+		if m.unlockedWallet.IsWatchOnly() {
+			return TransactionBroadcastMsg{Error: models.ErrWatchOnly}
+		}
 		signedTx, err := m.blockchainClient.SignTransaction(tx, m.unlockedWallet.PrivateKey)
 		if err != nil {
 			return TransactionBroadcastMsg{Error: fmt.Errorf("failed to sign transaction: %w", err)}
@@ -1076,6 +1354,268 @@ func (m *SendTransactionModel) broadcastTransaction() tea.Cmd {
 	}
 }
 
+// saveToHistory records a sent payment in the wallet's history
+func (m *SendTransactionModel) saveToHistory(txID string, amount *big.Int, contactName string) error {
+	if m.storage == nil {
+		return nil
+	}
+	record := models.NewTransaction(m.wallet.Address, m.recipientAddress, amount, string(m.selectedAsset))
+	record.Hash = txID
+	record.Direction = models.TransactionDirectionSent
+	record.ContactName = contactName
+	record.Notes = m.notes
+	record.Tags = m.tags
+	return m.storage.SaveTransaction(m.wallet.ID, record)
+}
+
+// updatePolicyDecision checks the payment against the spending policy when
+// it reaches review, and forgets the decision and any confirmation once it
+// leaves review so an edited payment is checked afresh
+func (m *SendTransactionModel) updatePolicyDecision() {
+	if m.step != StepReview {
+		m.policyDecision = nil
+		m.policyErr = nil
+		m.policyConfirmed = false
+		m.confirmingPolicy = false
+		return
+	}
+	if m.guard == nil || m.policyDecision != nil || m.policyErr != nil || !m.addressValid || !m.amountValid {
+		return
+	}
+
+	amountWei, err := utils.ValidateAmount(m.amount, 18)
+	if err != nil {
+		return
+	}
+	m.policyDecision, m.policyErr = m.guard.Evaluate(m.wallet.ID, m.wallet.Address, m.recipientAddress, amountWei, string(m.selectedAsset))
+}
+
+// policyAllows reports whether the payment on review may go ahead, asking
+// for the second confirmation when the policy requires one
+func (m *SendTransactionModel) policyAllows() bool {
+	if m.guard == nil {
+		return true
+	}
+	if m.policyErr != nil {
+		m.showFeedback(FeedbackError, fmt.Sprintf("Spending policy could not be checked: %s", m.policyErr.Error()), 5*time.Second)
+		return false
+	}
+	if m.policyDecision == nil {
+		return false
+	}
+	if !m.policyDecision.Allowed() {
+		m.showFeedback(FeedbackError, "Blocked by the spending policy", 5*time.Second)
+		return false
+	}
+	if m.policyDecision.NeedsConfirmation() && !m.policyConfirmed {
+		m.confirmingPolicy = true
+		return false
+	}
+	return true
+}
+
+func (m *SendTransactionModel) handlePolicyConfirmKey(key string) tea.Cmd {
+	switch key {
+	case "y", "Y":
+		m.confirmingPolicy = false
+		m.policyConfirmed = true
+		return m.handleEnterKey()
+	case "n", "N", "esc":
+		m.confirmingPolicy = false
+	}
+	return nil
+}
+
+func (m *SendTransactionModel) renderPolicyDecision() string {
+	errorStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(utils.Colours.Red)).
+		Foreground(lipgloss.Color(utils.Colours.Red)).
+		Padding(0, 1)
+
+	warningStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(utils.Colours.Yellow)).
+		Foreground(lipgloss.Color(utils.Colours.Yellow)).
+		Padding(0, 1)
+
+	if m.policyErr != nil {
+		return errorStyle.Render("Spending policy could not be checked:\n" + m.policyErr.Error())
+	}
+	if m.policyDecision == nil {
+		return ""
+	}
+	if !m.policyDecision.Allowed() {
+		var text strings.Builder
+		text.WriteString("Blocked by the spending policy:")
+		for _, violation := range m.policyDecision.Violations {
+			text.WriteString("\n• " + violation)
+		}
+		return errorStyle.Render(text.String())
+	}
+	if m.policyDecision.NeedsConfirmation() {
+		text := "Needs a second confirmation: " + m.policyDecision.ConfirmReason
+		switch {
+		case m.confirmingPolicy:
+			text += "\nPress Y to confirm this payment or N to cancel."
+		case m.policyConfirmed:
+			text += "\nConfirmed."
+		}
+		return warningStyle.Render(text)
+	}
+	return ""
+}
+
+// recipientNeedsCheck looks for signs of address poisoning before review,
+// unless this recipient has already been retyped correctly
+func (m *SendTransactionModel) recipientNeedsCheck() bool {
+	if m.verifiedRecipient != "" && strings.EqualFold(m.verifiedRecipient, m.recipientAddress) {
+		return false
+	}
+	m.recipientWarning = m.checkRecipient()
+	return m.recipientWarning != nil
+}
+
+// checkRecipient compares the recipient with saved contacts, recent
+// addresses and the wallet's history
+func (m *SendTransactionModel) checkRecipient() *models.RecipientWarning {
+	var known []models.KnownAddress
+	addContacts := func(contacts *models.ContactList) {
+		if contacts == nil {
+			return
+		}
+		for _, contact := range contacts.Contacts {
+			known = append(known, models.KnownAddress{Address: contact.Address, Label: "contact " + contact.Name})
+		}
+	}
+	addContacts(m.contacts)
+	for _, recent := range m.recentAddresses.Export() {
+		label := "recent address"
+		if recent.ContactName != "" {
+			label = "recent address " + recent.ContactName
+		}
+		known = append(known, models.KnownAddress{Address: recent.Address, Label: label})
+	}
+
+	var history []models.Transaction
+	if m.storage != nil {
+		contacts, err := m.storage.LoadContacts()
+		if err != nil {
+			m.showFeedback(FeedbackWarning, fmt.Sprintf("Recipient not checked against contacts: %s", err.Error()), 5*time.Second)
+		}
+		addContacts(contacts)
+
+		history, err = m.storage.LoadAccountHistory(m.wallet.ID, m.wallet.Address)
+		if err != nil {
+			m.showFeedback(FeedbackWarning, fmt.Sprintf("Recipient not checked against history: %s", err.Error()), 5*time.Second)
+		}
+	}
+
+	return models.CheckRecipient(m.recipientAddress, known, history)
+}
+
+// handleRecipientCheckKey takes the retyped recipient address. Only typed
+// characters are accepted, so the address cannot simply be pasted again.
+func (m *SendTransactionModel) handleRecipientCheckKey(key string) tea.Cmd {
+	switch key {
+	case "esc":
+		m.checkingRecipient = false
+		m.recipientRetype = ""
+		m.step = StepRecipient
+	case "enter":
+		if !strings.EqualFold(strings.TrimSpace(m.recipientRetype), m.recipientAddress) {
+			m.recipientRetype = ""
+			m.showFeedback(FeedbackError, "That does not match the recipient address; check it character by character", 5*time.Second)
+			return nil
+		}
+		m.checkingRecipient = false
+		m.recipientRetype = ""
+		m.verifiedRecipient = m.recipientAddress
+		m.step = StepReview
+	case "backspace":
+		if len(m.recipientRetype) > 0 {
+			m.recipientRetype = m.recipientRetype[:len(m.recipientRetype)-1]
+		}
+	default:
+		if len(key) == 1 && len(m.recipientRetype) < len(m.recipientAddress) {
+			m.recipientRetype += key
+		}
+	}
+	return nil
+}
+
+func (m *SendTransactionModel) renderRecipientCheck() string {
+	warningStyle := lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(lipgloss.Color(utils.Colours.Red)).
+		Foreground(lipgloss.Color(utils.Colours.Red)).
+		Padding(0, 1)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	inputStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Background(lipgloss.Color(utils.Colours.Surface0)).
+		Padding(0, 1).
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(lipgloss.Color(utils.Colours.Surface1)).
+		Width(50)
+
+	var warning strings.Builder
+	warning.WriteString(lipgloss.NewStyle().Bold(true).Render("⚠ POSSIBLE ADDRESS POISONING"))
+	warning.WriteString("\n\nYou are about to pay:\n" + m.recipientAddress + "\n")
+	for _, reason := range m.recipientWarning.Reasons() {
+		warning.WriteString("\n• " + reason)
+	}
+	warning.WriteString("\n\nScammers send small amounts from addresses that match the start and end of ones you use, hoping you copy them from history.")
+
+	var content strings.Builder
+	content.WriteString(warningStyle.Render(warning.String()))
+	content.WriteString("\n\n")
+	content.WriteString(labelStyle.Render("Type the full recipient address to continue:"))
+	content.WriteString("\n\n")
+	content.WriteString(inputStyle.Render(m.recipientRetype + "█"))
+	return content.String()
+}
+
+// exportUnsignedTransaction builds the transaction for a watch-only wallet
+// and writes it out for signing elsewhere
+func (m *SendTransactionModel) exportUnsignedTransaction() tea.Cmd {
+	if !m.addressValid || !m.amountValid {
+		return nil
+	}
+	if m.blockchainClient == nil || m.storage == nil {
+		m.showFeedback(FeedbackError, "Blockchain client or storage not available", 5*time.Second)
+		return nil
+	}
+
+	m.step = StepSending
+	m.loading = true
+	client, store := m.blockchainClient, m.storage
+	from, to, asset, amount := m.wallet.Address, m.recipientAddress, m.selectedAsset, m.amount
+	return func() tea.Msg {
+		amountWei, err := utils.ValidateAmount(amount, 18)
+		if err != nil {
+			return UnsignedTransactionExportedMsg{Error: err}
+		}
+
+		tx, err := client.BuildTransaction(from, to, amountWei, asset)
+		if err != nil {
+			return UnsignedTransactionExportedMsg{Error: fmt.Errorf("failed to build transaction: %w", err)}
+		}
+
+		unsigned, err := client.BuildUnsignedTransaction(tx)
+		if err != nil {
+			return UnsignedTransactionExportedMsg{Error: fmt.Errorf("failed to build transaction: %w", err)}
+		}
+
+		path, err := store.ExportUnsignedTransaction(from, unsigned)
+		return UnsignedTransactionExportedMsg{Path: path, Error: err}
+	}
+}
+
 func (m *SendTransactionModel) SetSessionManager(sessionManager *security.SessionManager) {
 	m.sessionManager = sessionManager
 }
@@ -1125,15 +1665,27 @@ func (m *SendTransactionModel) onContactSelectorCancel() tea.Cmd {
 }
 
 func (m *SendTransactionModel) onContactCreated(contact *models.Contact) tea.Cmd {
-	// Add the contact to our contact list
+	m.showContactCreate = false
+
+	// Add the contact to our contact list, keeping the saved one for an
+	// address that already has a contact
 	if m.contacts != nil {
-		m.contacts.Add(contact)
+		if existing := m.contacts.FindByAddress(contact.Address); existing != nil {
+			m.recipientAddress = existing.Address
+			m.selectedContact = existing
+			m.validateAddress()
+			m.showFeedback(FeedbackWarning, fmt.Sprintf("This address is already saved as %s", existing.Name), 5*time.Second)
+			return nil
+		}
+		if err := m.contacts.Add(contact, nil, "", ""); err != nil {
+			m.showFeedback(FeedbackError, fmt.Sprintf("Failed to add contact: %s", err.Error()), 5*time.Second)
+			return nil
+		}
 	}
 
 	// Set the created contact as selected
 	m.recipientAddress = contact.Address
 	m.selectedContact = contact
-	m.showContactCreate = false
 	m.validateAddress()
 
 	m.showFeedback(FeedbackSuccess, "Contact created and selected!", 3*time.Second)
@@ -1161,13 +1713,19 @@ func (m *SendTransactionModel) onTemplateSelected(template *models.TransactionTe
 		}
 	}
 
-	// Mark template as used
-	template.Use()
-
 	m.showTemplateSelector = false
 	m.validateAddress()
 	m.validateAmount()
 
+	// Mark template as used; the selector hands over a copy
+	if saved := m.templateManager.FindByID(template.ID); saved != nil {
+		saved.Use()
+		if err := m.saveTemplates(); err != nil {
+			m.showFeedback(FeedbackWarning, fmt.Sprintf("Template applied, but its use was not saved: %s", err.Error()), 5*time.Second)
+			return nil
+		}
+	}
+
 	m.showFeedback(FeedbackSuccess, "Template applied!", 3*time.Second)
 	return nil
 }
@@ -1217,6 +1775,10 @@ func (m *SendTransactionModel) saveAsTemplate() tea.Cmd {
 
 	// Add to template manager
 	m.templateManager.AddTemplate(template)
+	if err := m.saveTemplates(); err != nil {
+		m.showFeedback(FeedbackError, fmt.Sprintf("Failed to save template: %s", err.Error()), 5*time.Second)
+		return nil
+	}
 
 	m.showFeedback(FeedbackSuccess, "Transaction saved as template!", 3*time.Second)
 	return nil
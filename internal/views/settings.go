@@ -0,0 +1,617 @@
+package views
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/config"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+type SettingsField int
+
+const (
+	SettingNetwork SettingsField = iota
+	SettingNodeURL
+	SettingTheme
+	SettingSecurityLevel
+	SettingSessionTimeout
+	SettingAutoRefresh
+	SettingDefaultWallet
+	SettingDisplayPrecision
+	SettingSpendingPolicy
+	SettingTwoFactor
+	SettingSave
+	SettingBack
+)
+
+var settingsLabels = map[SettingsField]string{
+	SettingNetwork:          "Network",
+	SettingNodeURL:          "Node URL",
+	SettingTheme:            "Theme",
+	SettingSecurityLevel:    "Security Level",
+	SettingSessionTimeout:   "Session Timeout",
+	SettingAutoRefresh:      "Auto-Refresh",
+	SettingDefaultWallet:    "Default Wallet",
+	SettingDisplayPrecision: "Display Precision",
+	SettingSpendingPolicy:   "Spending Policy",
+	SettingTwoFactor:        "Two-Factor Authentication",
+	SettingSave:             "Save Settings",
+	SettingBack:             "Back to Dashboard",
+}
+
+var nodeURLPresets = map[string][]string{
+	"mainnet": {"", blockchain.DefaultMainnetURL, "https://mainnet.vechain.org"},
+	"testnet": {"", blockchain.DefaultTestnetURL, "https://testnet.vechain.org"},
+}
+
+// settingsKeys maps fields to their config keys for override lookups
+var settingsKeys = map[SettingsField]string{
+	SettingNetwork:          "network",
+	SettingNodeURL:          "node_url",
+	SettingTheme:            "theme",
+	SettingSecurityLevel:    "security_level",
+	SettingSessionTimeout:   "session_timeout_minutes",
+	SettingAutoRefresh:      "auto_refresh_seconds",
+	SettingDefaultWallet:    "default_wallet",
+	SettingDisplayPrecision: "display_precision",
+}
+
+var autoRefreshSteps = []int{0, 15, 30, 60, 120, 300, 600}
+
+type SettingsModel struct {
+	storage   *storage.Storage
+	loader    *config.Loader
+	effective *config.Effective
+	wallets   []storage.EncryptedWallet
+
+	original *storage.Config
+	config   storage.Config
+
+	// UI state
+	cursor          SettingsField
+	editing         bool
+	input           textinput.Model
+	fieldError      string
+	saving          bool
+	feedbackMessage *FeedbackMessage
+
+	width  int
+	height int
+}
+
+// SettingsSavedMsg carries the persisted settings back to the app. Config is
+// the saved file contents and Effective the result after env and flag
+// overrides. Client is only set when the effective network or node changed
+// and a new connection was made.
+type SettingsSavedMsg struct {
+	Config    *storage.Config
+	Effective *config.Effective
+	Client    *blockchain.Client
+	Error     error
+}
+
+// NewSettingsModel edits the config file contents in current. Fields that the
+// environment or command line override are marked using effective.
+func NewSettingsModel(store *storage.Storage, loader *config.Loader, current *storage.Config, effective *config.Effective, wallets []storage.EncryptedWallet) *SettingsModel {
+	input := textinput.New()
+	input.CharLimit = 256
+	input.Width = 50
+
+	if current == nil {
+		current = storage.DefaultConfig()
+	}
+
+	return &SettingsModel{
+		storage:   store,
+		loader:    loader,
+		effective: effective,
+		wallets:   wallets,
+		original:  current,
+		config:    *current,
+		input:     input,
+	}
+}
+
+func (m SettingsModel) Init() tea.Cmd {
+	return nil
+}
+
+// IsEditing reports whether a text field currently has focus
+func (m *SettingsModel) IsEditing() bool {
+	return m.editing
+}
+
+func (m *SettingsModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+func (m SettingsModel) Update(msg tea.Msg) (SettingsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case SettingsSavedMsg:
+		m.saving = false
+		if msg.Error != nil {
+			m.showFeedback(FeedbackError, msg.Error.Error(), 5*time.Second)
+			return m, nil
+		}
+		m.original = msg.Config
+		m.config = *msg.Config
+		if msg.Effective != nil {
+			m.effective = msg.Effective
+		}
+		m.showFeedback(FeedbackSuccess, "Settings saved and applied", 3*time.Second)
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.saving {
+			return m, nil
+		}
+		if m.editing {
+			return m.handleEditKey(msg)
+		}
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m SettingsModel) handleKey(msg tea.KeyMsg) (SettingsModel, tea.Cmd) {
+	m.fieldError = ""
+
+	switch msg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < SettingBack {
+			m.cursor++
+		}
+	case "left", "h":
+		m.adjust(-1)
+	case "right", "l":
+		m.adjust(1)
+	case "ctrl+s":
+		return m, m.save()
+	case "enter", " ":
+		switch m.cursor {
+		case SettingNodeURL, SettingSessionTimeout, SettingAutoRefresh, SettingDisplayPrecision:
+			m.startEditing()
+		case SettingSpendingPolicy:
+			return m, NavigateTo(ViewSpendingPolicy, nil)
+		case SettingTwoFactor:
+			return m, NavigateTo(ViewTwoFactor, nil)
+		case SettingSave:
+			return m, m.save()
+		case SettingBack:
+			return m, NavigateTo(ViewWalletDashboard, nil)
+		default:
+			m.adjust(1)
+		}
+	}
+
+	return m, nil
+}
+
+func (m SettingsModel) handleEditKey(msg tea.KeyMsg) (SettingsModel, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editing = false
+		m.fieldError = ""
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		if err := m.commitEdit(strings.TrimSpace(m.input.Value())); err != nil {
+			m.fieldError = err.Error()
+			return m, nil
+		}
+		m.editing = false
+		m.fieldError = ""
+		m.input.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *SettingsModel) startEditing() {
+	var value string
+	switch m.cursor {
+	case SettingNodeURL:
+		value = m.config.NodeURL
+		m.input.Placeholder = "https://node.example.org (empty for network default)"
+	case SettingSessionTimeout:
+		value = strconv.Itoa(m.config.SessionTimeout)
+		m.input.Placeholder = "minutes"
+	case SettingAutoRefresh:
+		value = strconv.Itoa(m.config.AutoRefresh)
+		m.input.Placeholder = "seconds (0 to disable)"
+	case SettingDisplayPrecision:
+		value = strconv.Itoa(m.config.DisplayPrecision)
+		m.input.Placeholder = "decimal places"
+	}
+
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	m.input.Focus()
+	m.editing = true
+}
+
+func (m *SettingsModel) commitEdit(value string) error {
+	candidate := m.config
+
+	switch m.cursor {
+	case SettingNodeURL:
+		candidate.NodeURL = value
+	case SettingSessionTimeout:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("session timeout must be a whole number of minutes")
+		}
+		candidate.SessionTimeout = n
+	case SettingAutoRefresh:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("auto-refresh must be a whole number of seconds")
+		}
+		candidate.AutoRefresh = n
+	case SettingDisplayPrecision:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("display precision must be a whole number")
+		}
+		candidate.DisplayPrecision = n
+	}
+
+	if err := candidate.Validate(); err != nil {
+		return err
+	}
+
+	m.config = candidate
+	return nil
+}
+
+// adjust cycles choice fields and steps numeric fields in the given direction
+func (m *SettingsModel) adjust(delta int) {
+	switch m.cursor {
+	case SettingNetwork:
+		networks := []string{"mainnet", "testnet"}
+		m.config.Network = cycleString(networks, m.config.Network, delta)
+		if !containsString(nodeURLPresets[m.config.Network], m.config.NodeURL) {
+			m.config.NodeURL = ""
+			m.showFeedback(FeedbackInfo, "Node URL reset to the network default", 3*time.Second)
+		}
+	case SettingNodeURL:
+		m.config.NodeURL = cycleString(nodeURLPresets[m.config.Network], m.config.NodeURL, delta)
+	case SettingTheme:
+		m.config.Theme = cycleString(utils.ThemeNames, m.config.Theme, delta)
+	case SettingSecurityLevel:
+		levels := make([]string, len(security.SecurityLevels))
+		for i, level := range security.SecurityLevels {
+			levels[i] = string(level)
+		}
+		m.config.SecurityLevel = cycleString(levels, m.config.SecurityLevel, delta)
+		// Follow the level's timeout; it can still be overridden afterwards
+		level, _ := security.ParseSecurityLevel(m.config.SecurityLevel)
+		m.config.SessionTimeout = int(security.SessionConfigForLevel(level).DefaultTimeout / time.Minute)
+	case SettingSessionTimeout:
+		m.config.SessionTimeout = clampInt(m.config.SessionTimeout+delta, storage.MinSessionTimeout, storage.MaxSessionTimeout)
+	case SettingAutoRefresh:
+		steps := make([]string, len(autoRefreshSteps))
+		for i, step := range autoRefreshSteps {
+			steps[i] = strconv.Itoa(step)
+		}
+		next, _ := strconv.Atoi(cycleString(steps, strconv.Itoa(m.config.AutoRefresh), delta))
+		m.config.AutoRefresh = next
+	case SettingDefaultWallet:
+		ids := []string{""}
+		for _, wallet := range m.wallets {
+			ids = append(ids, wallet.ID)
+		}
+		m.config.DefaultWallet = cycleString(ids, m.config.DefaultWallet, delta)
+	case SettingDisplayPrecision:
+		m.config.DisplayPrecision = clampInt(m.config.DisplayPrecision+delta, 0, storage.MaxDisplayPrecision)
+	}
+}
+
+func (m *SettingsModel) save() tea.Cmd {
+	candidate := m.config
+
+	if err := candidate.Validate(); err != nil {
+		m.showFeedback(FeedbackError, err.Error(), 5*time.Second)
+		return nil
+	}
+	if !utils.IsValidTheme(candidate.Theme) {
+		m.showFeedback(FeedbackError, fmt.Sprintf("unknown theme: %s", candidate.Theme), 5*time.Second)
+		return nil
+	}
+	if candidate.DefaultWallet != "" && m.walletName(candidate.DefaultWallet) == "" {
+		m.showFeedback(FeedbackError, "default wallet no longer exists", 5*time.Second)
+		return nil
+	}
+
+	m.saving = true
+	m.showFeedback(FeedbackInfo, "Saving settings...", 10*time.Second)
+
+	store := m.storage
+	loader := m.loader
+	previous := m.effective
+	return func() tea.Msg {
+		effective := &config.Effective{Config: &candidate}
+		if loader != nil {
+			var err error
+			effective, err = loader.LoadWith(&candidate)
+			if err != nil {
+				return SettingsSavedMsg{Error: err}
+			}
+		}
+
+		networkChanged := previous == nil ||
+			effective.Config.Network != previous.Config.Network ||
+			effective.Config.NodeURL != previous.Config.NodeURL
+
+		var client *blockchain.Client
+		if networkChanged {
+			// Connect before persisting so a bad node never becomes the saved default
+			var err error
+			client, err = blockchain.NewClient(effective.BlockchainConfig().ToBlockchainConfig())
+			if err != nil {
+				return SettingsSavedMsg{Error: fmt.Errorf("failed to connect to node: %w", err)}
+			}
+		}
+
+		if store != nil {
+			if err := store.SaveConfig(&candidate); err != nil {
+				if client != nil {
+					client.Close()
+				}
+				return SettingsSavedMsg{Error: fmt.Errorf("failed to save settings: %w", err)}
+			}
+		}
+
+		return SettingsSavedMsg{Config: &candidate, Effective: effective, Client: client}
+	}
+}
+
+func (m *SettingsModel) showFeedback(feedbackType FeedbackType, message string, duration time.Duration) {
+	m.feedbackMessage = &FeedbackMessage{
+		Type:     feedbackType,
+		Message:  message,
+		Duration: duration,
+		ShowTime: time.Now(),
+	}
+}
+
+func (m *SettingsModel) isDirty() bool {
+	return m.original == nil || *m.original != m.config
+}
+
+// overrideNote explains when the saved value is not the one in effect
+func (m *SettingsModel) overrideNote(field SettingsField) string {
+	key, ok := settingsKeys[field]
+	if !ok || m.effective == nil || !m.effective.Overridden(key) {
+		return ""
+	}
+	return "overridden by " + m.effective.SourceLabel(key)
+}
+
+func (m *SettingsModel) walletName(id string) string {
+	for _, wallet := range m.wallets {
+		if wallet.ID == id {
+			return wallet.Name
+		}
+	}
+	return ""
+}
+
+func (m *SettingsModel) displayValue(field SettingsField) string {
+	switch field {
+	case SettingNetwork:
+		return capitalize(m.config.Network)
+	case SettingNodeURL:
+		if m.config.NodeURL == "" {
+			return "Network default"
+		}
+		return m.config.NodeURL
+	case SettingTheme:
+		return m.config.Theme
+	case SettingSecurityLevel:
+		return capitalize(m.config.SecurityLevel)
+	case SettingSessionTimeout:
+		return fmt.Sprintf("%d min", m.config.SessionTimeout)
+	case SettingAutoRefresh:
+		if m.config.AutoRefresh == 0 {
+			return "Off"
+		}
+		return fmt.Sprintf("every %ds", m.config.AutoRefresh)
+	case SettingDefaultWallet:
+		if m.config.DefaultWallet == "" {
+			return "None"
+		}
+		if name := m.walletName(m.config.DefaultWallet); name != "" {
+			return name
+		}
+		return m.config.DefaultWallet + " (missing)"
+	case SettingDisplayPrecision:
+		return fmt.Sprintf("%d decimals", m.config.DisplayPrecision)
+	}
+	return ""
+}
+
+func (m SettingsModel) View() string {
+	containerStyle := lipgloss.NewStyle().
+		Padding(1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(utils.Colours.Blue))
+
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Align(lipgloss.Center)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext1)).
+		Width(20)
+
+	valueStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Green)).
+		Background(lipgloss.Color(utils.Colours.Surface0)).
+		Bold(true)
+
+	actionStyle := lipgloss.NewStyle().
+		Padding(0, 2)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Red))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	var content strings.Builder
+	title := "Settings"
+	if m.isDirty() {
+		title += " (unsaved changes)"
+	}
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n\n")
+
+	for field := SettingNetwork; field <= SettingDisplayPrecision; field++ {
+		cursor := "  "
+		if m.cursor == field {
+			cursor = "> "
+		}
+
+		value := m.displayValue(field)
+		if m.editing && m.cursor == field {
+			value = m.input.View()
+		} else if m.cursor == field {
+			value = selectedStyle.Render("◀ " + value + " ▶")
+		} else {
+			value = valueStyle.Render(value)
+		}
+
+		content.WriteString(cursor + labelStyle.Render(settingsLabels[field]) + value)
+		if note := m.overrideNote(field); note != "" {
+			content.WriteString("  " + helpStyle.Render(note))
+		}
+		content.WriteString("\n")
+
+		if m.cursor == field && m.fieldError != "" {
+			content.WriteString("  " + errorStyle.Render(m.fieldError))
+			content.WriteString("\n")
+		}
+	}
+
+	content.WriteString("\n")
+	for _, field := range []SettingsField{SettingSpendingPolicy, SettingTwoFactor, SettingSave, SettingBack} {
+		cursor := " "
+		style := actionStyle
+		if m.cursor == field {
+			cursor = ">"
+			style = selectedStyle.Padding(0, 2)
+		}
+		content.WriteString(style.Render(fmt.Sprintf("%s %s", cursor, settingsLabels[field])))
+		content.WriteString("\n")
+	}
+
+	content.WriteString("\n")
+	if m.editing {
+		content.WriteString(helpStyle.Render("Enter: apply • Esc: cancel"))
+	} else {
+		content.WriteString(helpStyle.Render("↑/↓: navigate • ←/→: change • Enter: edit/select • Ctrl+S: save • Esc: back"))
+	}
+
+	if m.feedbackMessage != nil && time.Since(m.feedbackMessage.ShowTime) < m.feedbackMessage.Duration {
+		content.WriteString("\n\n")
+		content.WriteString(renderFeedback(m.feedbackMessage))
+	}
+
+	return containerStyle.Render(content.String())
+}
+
+func renderFeedback(feedback *FeedbackMessage) string {
+	var color string
+	switch feedback.Type {
+	case FeedbackSuccess:
+		color = utils.Colours.Green
+	case FeedbackError:
+		color = utils.Colours.Red
+	case FeedbackWarning:
+		color = utils.Colours.Yellow
+	case FeedbackInfo:
+		color = utils.Colours.Blue
+	default:
+		color = utils.Colours.Text
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(color)).
+		Background(lipgloss.Color(utils.Colours.Surface0)).
+		Padding(0, 1).
+		Bold(true).
+		Render(feedback.Message)
+}
+
+func cycleString(options []string, current string, delta int) string {
+	if len(options) == 0 {
+		return current
+	}
+
+	index := 0
+	for i, option := range options {
+		if option == current {
+			index = i
+			break
+		}
+	}
+
+	index = (index + delta + len(options)) % len(options)
+	return options[index]
+}
+
+func containsString(options []string, value string) bool {
+	for _, option := range options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
@@ -0,0 +1,370 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/slip39"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	shamirFieldPassword = iota
+	shamirFieldCode
+	shamirFieldThreshold
+	shamirFieldCount
+	shamirFieldTotal
+)
+
+// ShamirBackupModel splits a stored wallet's recovery phrase into SLIP-39
+// shares after the wallet password has been re-entered, then shows the
+// shares one at a time so each can be written down by its holder
+type ShamirBackupModel struct {
+	storage         *storage.Storage
+	securityManager *security.SecurityManager
+	wallet          storage.EncryptedWallet
+
+	inputs  [shamirFieldTotal]textinput.Model
+	focused int
+
+	attempts    int
+	maxAttempts int
+	splitting   bool
+	err         string
+
+	shares    []string
+	threshold int
+	current   int
+	written   map[int]string
+}
+
+// ShamirSharesMsg carries the shares of a split recovery phrase
+type ShamirSharesMsg struct {
+	Shares    []string
+	Threshold int
+	Err       error
+}
+
+// ShamirShareExportedMsg reports a share written to a file
+type ShamirShareExportedMsg struct {
+	Index int
+	Path  string
+	Err   error
+}
+
+func NewShamirBackupModel(store *storage.Storage, securityManager *security.SecurityManager, wallet storage.EncryptedWallet) *ShamirBackupModel {
+	m := &ShamirBackupModel{
+		storage:         store,
+		securityManager: securityManager,
+		wallet:          wallet,
+		maxAttempts:     3,
+		written:         make(map[int]string),
+	}
+
+	placeholders := [shamirFieldTotal]string{"current wallet password", "authenticator or recovery code", "2", "3"}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.Width = 40
+		switch i {
+		case shamirFieldPassword:
+			input.EchoMode = textinput.EchoPassword
+			input.EchoCharacter = '*'
+		case shamirFieldCode:
+			input.CharLimit = 24
+		default:
+			input.CharLimit = 2
+		}
+		m.inputs[i] = input
+	}
+	m.inputs[shamirFieldPassword].Focus()
+
+	return m
+}
+
+// IsEditing is always true: the view handles Esc itself, so shares on
+// screen are cleared before it closes
+func (m *ShamirBackupModel) IsEditing() bool {
+	return true
+}
+
+func (m ShamirBackupModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m ShamirBackupModel) Update(msg tea.Msg) (ShamirBackupModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ShamirSharesMsg:
+		m.splitting = false
+		m.inputs[shamirFieldPassword].SetValue("")
+		m.inputs[shamirFieldCode].SetValue("")
+		if msg.Err == nil {
+			m.shares = msg.Shares
+			m.threshold = msg.Threshold
+			m.current = 0
+			m.err = ""
+			return m, nil
+		}
+
+		m.err = msg.Err.Error()
+		if errors.Is(msg.Err, storage.ErrInvalidPassword) {
+			m.attempts++
+			if m.attempts >= m.maxAttempts {
+				m.err = "Too many failed attempts"
+			} else {
+				m.err = fmt.Sprintf("Incorrect wallet password (%d/%d attempts)", m.attempts, m.maxAttempts)
+			}
+		}
+		return m, m.focus(shamirFieldPassword)
+
+	case ShamirShareExportedMsg:
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			return m, nil
+		}
+		m.err = ""
+		m.written[msg.Index] = msg.Path
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.splitting {
+			return m, nil
+		}
+		if m.shares != nil {
+			return m.handleShareKeys(msg)
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.inputs[shamirFieldPassword].SetValue("")
+			m.inputs[shamirFieldCode].SetValue("")
+			return m, NavigateTo(ViewWalletSelector, nil)
+		case "tab", "down":
+			return m, m.step(1)
+		case "shift+tab", "up":
+			return m, m.step(-1)
+		case "enter":
+			if m.focused < shamirFieldCount {
+				return m, m.step(1)
+			}
+			return m.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return m, cmd
+}
+
+func (m ShamirBackupModel) handleShareKeys(msg tea.KeyMsg) (ShamirBackupModel, tea.Cmd) {
+	switch msg.String() {
+	case "right", "l", "n":
+		if m.current < len(m.shares)-1 {
+			m.current++
+		}
+	case "left", "h", "b":
+		if m.current > 0 {
+			m.current--
+		}
+	case "enter":
+		if m.current < len(m.shares)-1 {
+			m.current++
+			return m, nil
+		}
+		m.clearShares()
+		return m, NavigateTo(ViewWalletSelector, nil)
+	case "w":
+		store, address, share := m.storage, m.wallet.Address, m.shares[m.current]
+		index, threshold, count := m.current+1, m.threshold, len(m.shares)
+		return m, func() tea.Msg {
+			path, err := store.ExportShamirShare(address, share, index, threshold, count)
+			return ShamirShareExportedMsg{Index: index, Path: path, Err: err}
+		}
+	case "esc":
+		m.clearShares()
+		return m, NavigateTo(ViewWalletSelector, nil)
+	}
+	return m, nil
+}
+
+func (m *ShamirBackupModel) focus(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	return m.inputs[field].Focus()
+}
+
+// step moves the focus by delta, passing over the code field for wallets
+// without a second factor
+func (m *ShamirBackupModel) step(delta int) tea.Cmd {
+	field := m.focused
+	for {
+		field = (field + delta + shamirFieldTotal) % shamirFieldTotal
+		if field != shamirFieldCode || m.wallet.HasTwoFactor() {
+			return m.focus(field)
+		}
+	}
+}
+
+// shareCounts reads the threshold and share count, using the placeholders
+// when left blank
+func (m ShamirBackupModel) shareCounts() (int, int, error) {
+	var values [2]int
+	for i, field := range []int{shamirFieldThreshold, shamirFieldCount} {
+		text := strings.TrimSpace(m.inputs[field].Value())
+		if text == "" {
+			text = m.inputs[field].Placeholder
+		}
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%q is not a number", text)
+		}
+		values[i] = value
+	}
+
+	threshold, count := values[0], values[1]
+	if count < 2 || count > slip39.MaxShareCount {
+		return 0, 0, fmt.Errorf("number of shares must be between 2 and %d", slip39.MaxShareCount)
+	}
+	if threshold < 2 || threshold > count {
+		return 0, 0, fmt.Errorf("shares needed must be between 2 and %d", count)
+	}
+	return threshold, count, nil
+}
+
+func (m ShamirBackupModel) submit() (ShamirBackupModel, tea.Cmd) {
+	if m.attempts >= m.maxAttempts {
+		m.err = "Too many failed attempts"
+		return m, nil
+	}
+
+	password := m.inputs[shamirFieldPassword].Value()
+	if password == "" {
+		m.err = "Wallet password is required"
+		return m, m.focus(shamirFieldPassword)
+	}
+	threshold, count, err := m.shareCounts()
+	if err != nil {
+		m.err = err.Error()
+		return m, m.focus(shamirFieldThreshold)
+	}
+
+	code := m.inputs[shamirFieldCode].Value()
+	if m.wallet.HasTwoFactor() && strings.TrimSpace(code) == "" {
+		m.err = "Authentication code is required"
+		return m, m.focus(shamirFieldCode)
+	}
+
+	m.err = ""
+	m.splitting = true
+	store, securityManager, id := m.storage, m.securityManager, m.wallet.ID
+	return m, func() tea.Msg {
+		wallet, err := store.LoadWallet(id, password)
+		if err != nil {
+			return ShamirSharesMsg{Err: err}
+		}
+		if err := securityManager.VerifySecondFactor(store, wallet, code); err != nil {
+			return ShamirSharesMsg{Err: err}
+		}
+		if wallet.Mnemonic == "" {
+			return ShamirSharesMsg{Err: fmt.Errorf("%s has no recovery phrase to split", wallet.Name)}
+		}
+
+		shares, err := models.SplitMnemonicShares(wallet.Mnemonic, threshold, count)
+		return ShamirSharesMsg{Shares: shares, Threshold: threshold, Err: err}
+	}
+}
+
+func (m *ShamirBackupModel) clearShares() {
+	for i := range m.shares {
+		m.shares[i] = ""
+	}
+	m.shares = nil
+}
+
+func (m ShamirBackupModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Yellow))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Red))
+
+	var content string
+	content += titleStyle.Render("Split Recovery Phrase Into Shares") + "\n"
+	content += helpStyle.Render(fmt.Sprintf("%s (%s)", m.wallet.Name, m.wallet.Address)) + "\n\n"
+
+	if m.shares != nil {
+		content += m.renderShare(labelStyle, warningStyle, helpStyle)
+		if m.err != "" {
+			content += errorStyle.Render("✗ "+m.err) + "\n\n"
+		}
+		content += helpStyle.Render("←/→: previous / next share • w: write this share to a file • Enter: next / done • Esc: done")
+		return content
+	}
+
+	content += warningStyle.Render("Each share is useless alone; the chosen number of them together restore the recovery phrase.") + "\n"
+	content += warningStyle.Render("Give each share to a different person. A BIP39 passphrase, if used, is not part of the shares.") + "\n\n"
+
+	labels := [shamirFieldTotal]string{"Wallet Password:", "Authentication Code:", "Shares Needed To Restore:", "Number Of Shares:"}
+	for i, label := range labels {
+		if i == shamirFieldCode && !m.wallet.HasTwoFactor() {
+			continue
+		}
+		content += labelStyle.Render(label) + "\n"
+		content += m.inputs[i].View() + "\n\n"
+	}
+
+	if m.splitting {
+		content += warningStyle.Render("Unlocking wallet and splitting phrase...") + "\n\n"
+	}
+	if m.err != "" {
+		content += errorStyle.Render("✗ "+m.err) + "\n\n"
+	}
+
+	content += helpStyle.Render("Tab/↑/↓: move • Enter: next / split • Esc: cancel")
+	return content
+}
+
+func (m ShamirBackupModel) renderShare(labelStyle, warningStyle, helpStyle lipgloss.Style) string {
+	wordStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Green))
+
+	var content string
+	content += labelStyle.Render(fmt.Sprintf("Share %d of %d (any %d restore the wallet)", m.current+1, len(m.shares), m.threshold)) + "\n\n"
+
+	words := strings.Fields(m.shares[m.current])
+	for i := 0; i < len(words); i += 4 {
+		for j := i; j < i+4 && j < len(words); j++ {
+			content += wordStyle.Render(fmt.Sprintf("%2d. %-10s", j+1, words[j])) + " "
+		}
+		content += "\n"
+	}
+	content += "\n"
+
+	if path, ok := m.written[m.current+1]; ok {
+		content += warningStyle.Render("Written to "+path+" - delete it once printed") + "\n\n"
+	}
+	content += helpStyle.Render("Write these words down in order. Shares are restored from Import Wallet → SLIP-39 shares.") + "\n\n"
+	return content
+}
@@ -0,0 +1,383 @@
+package views
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	policyFieldEnabled = iota
+	policyFieldVETPerTx
+	policyFieldVETDaily
+	policyFieldVETWeekly
+	policyFieldVETConfirm
+	policyFieldVTHOPerTx
+	policyFieldVTHODaily
+	policyFieldVTHOWeekly
+	policyFieldVTHOConfirm
+	policyFieldRecipients
+	policyFieldCategories
+	policyFieldCooldown
+	// policyFieldCode is only shown for wallets with a second factor
+	policyFieldCode
+	policyFieldCount
+)
+
+var policyFieldLabels = [policyFieldCount]string{
+	"Policy:",
+	"VET per-transaction maximum:",
+	"VET daily cap:",
+	"VET weekly cap:",
+	"VET confirm above:",
+	"VTHO per-transaction maximum:",
+	"VTHO daily cap:",
+	"VTHO weekly cap:",
+	"VTHO confirm above:",
+	"Recipients:",
+	"Allowed contact categories:",
+	"New contact cool-down (hours):",
+	"Authentication code:",
+}
+
+// SpendingPolicyModel edits the current wallet's spending policy. It is
+// reached from Settings.
+type SpendingPolicyModel struct {
+	storage         *storage.Storage
+	guard           *security.SpendingGuard
+	securityManager *security.SecurityManager
+	wallet          *models.Wallet
+
+	enabled      bool
+	contactsOnly bool
+	inputs       [policyFieldCount]textinput.Model
+	focused      int
+
+	message string
+	err     string
+}
+
+func NewSpendingPolicyModel(store *storage.Storage, guard *security.SpendingGuard, securityManager *security.SecurityManager, wallet *models.Wallet) *SpendingPolicyModel {
+	m := &SpendingPolicyModel{
+		storage:         store,
+		guard:           guard,
+		securityManager: securityManager,
+		wallet:          wallet,
+	}
+
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Width = 30
+		switch i {
+		case policyFieldCategories:
+			input.Placeholder = "e.g. payroll, suppliers; empty for all contacts"
+			input.Width = 50
+		case policyFieldCooldown:
+			input.Placeholder = "0 for none"
+		case policyFieldCode:
+			input.Placeholder = "authenticator or recovery code"
+			input.Width = 34
+		default:
+			input.Placeholder = "no limit"
+		}
+		m.inputs[i] = input
+	}
+
+	policy, err := guard.Policy(wallet.ID)
+	if err != nil {
+		m.err = fmt.Sprintf("Failed to load spending policy: %s", err.Error())
+		policy = models.NewSpendingPolicy(wallet.ID)
+	}
+	m.load(policy)
+	return m
+}
+
+// IsEditing reports true while the screen is open; every key belongs to the
+// form
+func (m *SpendingPolicyModel) IsEditing() bool {
+	return true
+}
+
+func (m SpendingPolicyModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *SpendingPolicyModel) load(policy *models.SpendingPolicy) {
+	m.enabled = policy.Enabled
+	m.contactsOnly = policy.ContactsOnly
+
+	limits := map[int]*big.Int{
+		policyFieldVETPerTx:    policy.VET.PerTx,
+		policyFieldVETDaily:    policy.VET.Daily,
+		policyFieldVETWeekly:   policy.VET.Weekly,
+		policyFieldVETConfirm:  policy.VET.ConfirmAbove,
+		policyFieldVTHOPerTx:   policy.VTHO.PerTx,
+		policyFieldVTHODaily:   policy.VTHO.Daily,
+		policyFieldVTHOWeekly:  policy.VTHO.Weekly,
+		policyFieldVTHOConfirm: policy.VTHO.ConfirmAbove,
+	}
+	for field, limit := range limits {
+		m.inputs[field].SetValue(formatLimit(limit))
+	}
+	m.inputs[policyFieldCategories].SetValue(strings.Join(policy.AllowedCategories, ", "))
+	if policy.ContactCooldownHours > 0 {
+		m.inputs[policyFieldCooldown].SetValue(strconv.Itoa(policy.ContactCooldownHours))
+	}
+}
+
+// policyChoiceField reports whether field is picked with ←/→ rather than
+// typed
+func policyChoiceField(field int) bool {
+	return field == policyFieldEnabled || field == policyFieldRecipients
+}
+
+// fieldCount is the number of fields on the form; the authentication code is
+// only asked for when the wallet has a second factor
+func (m *SpendingPolicyModel) fieldCount() int {
+	if m.wallet.TwoFactor != nil {
+		return policyFieldCount
+	}
+	return policyFieldCode
+}
+
+func (m *SpendingPolicyModel) focusField(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	if policyChoiceField(field) {
+		return nil
+	}
+	return m.inputs[field].Focus()
+}
+
+func (m SpendingPolicyModel) Update(msg tea.Msg) (SpendingPolicyModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "esc":
+		return m, NavigateTo(ViewSettings, nil)
+	case "tab", "down":
+		return m, m.focusField((m.focused + 1) % m.fieldCount())
+	case "shift+tab", "up":
+		return m, m.focusField((m.focused + m.fieldCount() - 1) % m.fieldCount())
+	case "ctrl+s":
+		m.save()
+		return m, nil
+	case "left", "right", " ":
+		if policyChoiceField(m.focused) {
+			if m.focused == policyFieldEnabled {
+				m.enabled = !m.enabled
+			} else {
+				m.contactsOnly = !m.contactsOnly
+			}
+			return m, nil
+		}
+	case "enter":
+		if m.focused < m.fieldCount()-1 {
+			return m, m.focusField(m.focused + 1)
+		}
+		m.save()
+		return m, nil
+	}
+
+	if policyChoiceField(m.focused) {
+		return m, nil
+	}
+	m.message, m.err = "", ""
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(keyMsg)
+	return m, cmd
+}
+
+// save builds the policy from the form and saves it, reporting the first
+// problem on screen
+func (m *SpendingPolicyModel) save() {
+	m.message, m.err = "", ""
+	fail := func(field int, format string, args ...interface{}) {
+		m.err = fmt.Sprintf(format, args...)
+		m.focusField(field)
+	}
+
+	policy := models.NewSpendingPolicy(m.wallet.ID)
+	policy.Enabled = m.enabled
+	policy.ContactsOnly = m.contactsOnly
+
+	limits := []struct {
+		field  int
+		asset  string
+		target **big.Int
+	}{
+		{policyFieldVETPerTx, "VET", &policy.VET.PerTx},
+		{policyFieldVETDaily, "VET", &policy.VET.Daily},
+		{policyFieldVETWeekly, "VET", &policy.VET.Weekly},
+		{policyFieldVETConfirm, "VET", &policy.VET.ConfirmAbove},
+		{policyFieldVTHOPerTx, "VTHO", &policy.VTHO.PerTx},
+		{policyFieldVTHODaily, "VTHO", &policy.VTHO.Daily},
+		{policyFieldVTHOWeekly, "VTHO", &policy.VTHO.Weekly},
+		{policyFieldVTHOConfirm, "VTHO", &policy.VTHO.ConfirmAbove},
+	}
+	for _, limit := range limits {
+		amount, err := parseLimit(m.inputs[limit.field].Value(), limit.asset)
+		if err != nil {
+			fail(limit.field, "%s %s", policyFieldLabels[limit.field], err.Error())
+			return
+		}
+		*limit.target = amount
+	}
+
+	for _, category := range strings.Split(m.inputs[policyFieldCategories].Value(), ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			policy.AllowedCategories = append(policy.AllowedCategories, category)
+		}
+	}
+
+	if value := strings.TrimSpace(m.inputs[policyFieldCooldown].Value()); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours < 0 {
+			fail(policyFieldCooldown, "Cool-down must be a whole number of hours")
+			return
+		}
+		policy.ContactCooldownHours = hours
+	}
+
+	if err := m.securityManager.VerifySecondFactor(m.storage, m.wallet, m.inputs[policyFieldCode].Value()); err != nil {
+		fail(policyFieldCode, "%s", err.Error())
+		m.inputs[policyFieldCode].SetValue("")
+		return
+	}
+	m.inputs[policyFieldCode].SetValue("")
+
+	if err := m.guard.UpdatePolicy(policy); err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.message = "Spending policy saved: " + policy.Describe()
+}
+
+// parseLimit reads a limit in whole tokens. Empty means no limit and 0 blocks
+// the asset entirely.
+func parseLimit(value, asset string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if zero, ok := new(big.Rat).SetString(value); ok && zero.Sign() == 0 {
+		return new(big.Int), nil
+	}
+	amount, resolved, err := models.ResolveAmount(value, asset, nil)
+	if err != nil {
+		return nil, fmt.Errorf("must be an amount such as 100 or 2.5")
+	}
+	if resolved != asset {
+		return nil, fmt.Errorf("must be in %s", asset)
+	}
+	return amount, nil
+}
+
+// formatLimit shows a limit exactly, in whole tokens
+func formatLimit(limit *big.Int) string {
+	if limit == nil {
+		return ""
+	}
+	text := new(big.Rat).SetFrac(limit, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)).FloatString(18)
+	text = strings.TrimRight(text, "0")
+	return strings.TrimSuffix(text, ".")
+}
+
+func (m SpendingPolicyModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	choice := func(field int, text string) string {
+		if field == m.focused {
+			return lipgloss.NewStyle().Foreground(lipgloss.Color(utils.Colours.Blue)).Bold(true).Render("◀ " + text + " ▶")
+		}
+		return text
+	}
+
+	content := titleStyle.Render(fmt.Sprintf("Spending Policy: %s", m.wallet.Name)) + "\n"
+	content += mutedStyle.Render("Checked before every payment from this wallet is signed. Caps cover the last 24 hours and 7 days.") + "\n\n"
+
+	for i, label := range policyFieldLabels[:m.fieldCount()] {
+		marker := "  "
+		if i == m.focused {
+			marker = "▶ "
+		}
+		switch i {
+		case policyFieldVETPerTx:
+			content += mutedStyle.Render("VET") + "\n"
+		case policyFieldVTHOPerTx:
+			content += "\n" + mutedStyle.Render("VTHO") + "\n"
+		case policyFieldRecipients:
+			content += "\n" + mutedStyle.Render("Recipients") + "\n"
+		case policyFieldCode:
+			content += "\n"
+		}
+
+		content += labelStyle.Render(fmt.Sprintf("%s%-31s", marker, label))
+		switch i {
+		case policyFieldEnabled:
+			state := "off"
+			if m.enabled {
+				state = "on"
+			}
+			content += choice(i, state)
+			content += "\n"
+		case policyFieldRecipients:
+			recipients := "anyone"
+			if m.contactsOnly {
+				recipients = "saved contacts only"
+			}
+			content += choice(i, recipients)
+		default:
+			content += m.inputs[i].View()
+		}
+		content += "\n"
+	}
+	content += "\n"
+
+	content += m.renderStatus()
+	content += helpStyle.Render("Tab/↑/↓: move • ←/→: choose • Enter: next / save • Ctrl+S: save • Esc: back to settings")
+	return content
+}
+
+func (m SpendingPolicyModel) renderStatus() string {
+	if m.err != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ "+m.err) + "\n\n"
+	}
+	if m.message != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Green)).
+			Render("✓ "+m.message) + "\n\n"
+	}
+	return ""
+}
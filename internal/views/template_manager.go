@@ -0,0 +1,487 @@
+package views
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	templateFieldName = iota
+	templateFieldDescription
+	templateFieldAddress
+	templateFieldAmount
+	templateFieldAsset
+	templateFieldNotes
+	templateFieldTags
+	templateFieldCount
+)
+
+type templateManagerMode int
+
+const (
+	templateModeList templateManagerMode = iota
+	templateModeSearch
+	templateModeEdit
+	templateModeConfirmDelete
+)
+
+// TemplateManagerModel lists the current wallet's saved transaction
+// templates for editing, tagging, favoriting and deleting outside the send
+// flow. Every change is saved straight away.
+type TemplateManagerModel struct {
+	storage *storage.Storage
+	wallet  *models.Wallet
+	manager *models.TransactionTemplateManager
+
+	templates []models.TransactionTemplate
+	selected  int
+	mode      templateManagerMode
+
+	search  textinput.Model
+	inputs  [templateFieldCount]textinput.Model
+	focused int
+	editID  string
+
+	message string
+	err     string
+}
+
+func NewTemplateManagerModel(store *storage.Storage, wallet *models.Wallet) *TemplateManagerModel {
+	m := &TemplateManagerModel{
+		storage: store,
+		wallet:  wallet,
+		manager: models.NewTransactionTemplateManager(),
+	}
+
+	m.search = textinput.New()
+	m.search.Placeholder = "name, address, notes or tag"
+	m.search.Width = 40
+
+	placeholders := [templateFieldCount]string{
+		"template name",
+		"what the template is for",
+		"0x... or a placeholder such as {{contact:category=payroll}}",
+		"fixed amount, {{amount}}, or empty to enter when sending",
+		"VET or VTHO",
+		"notes",
+		"comma-separated tags",
+	}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.Width = 50
+		m.inputs[i] = input
+	}
+
+	templates, err := store.LoadTemplates(wallet.ID)
+	if err != nil {
+		m.err = fmt.Sprintf("Failed to load templates: %s", err.Error())
+	}
+	m.manager.Import(templates)
+	m.refresh()
+
+	return m
+}
+
+// IsEditing reports whether a text field has focus
+func (m *TemplateManagerModel) IsEditing() bool {
+	return m.mode == templateModeSearch || m.mode == templateModeEdit
+}
+
+func (m TemplateManagerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m TemplateManagerModel) Update(msg tea.Msg) (TemplateManagerModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m.updateInput(msg)
+	}
+
+	switch m.mode {
+	case templateModeSearch:
+		switch keyMsg.String() {
+		case "esc":
+			m.search.SetValue("")
+			fallthrough
+		case "enter":
+			m.search.Blur()
+			m.mode = templateModeList
+			m.refresh()
+			return m, nil
+		}
+		model, cmd := m.updateInput(msg)
+		model.refresh()
+		return model, cmd
+
+	case templateModeEdit:
+		switch keyMsg.String() {
+		case "esc":
+			m.mode = templateModeList
+			m.err = ""
+			return m, nil
+		case "tab", "down":
+			return m, m.focus((m.focused + 1) % templateFieldCount)
+		case "shift+tab", "up":
+			return m, m.focus((m.focused + templateFieldCount - 1) % templateFieldCount)
+		case "enter":
+			if m.focused < templateFieldTags {
+				return m, m.focus(m.focused + 1)
+			}
+			m.saveEdit()
+			return m, nil
+		}
+		return m.updateInput(msg)
+
+	case templateModeConfirmDelete:
+		switch keyMsg.String() {
+		case "y", "Y":
+			m.deleteSelected()
+		}
+		m.mode = templateModeList
+		return m, nil
+	}
+
+	m.message, m.err = "", ""
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.selected > 0 {
+			m.selected--
+		}
+	case "down", "j":
+		if m.selected < len(m.templates)-1 {
+			m.selected++
+		}
+	case "enter", "e":
+		if template := m.current(); template != nil {
+			return m, m.startEdit(template)
+		}
+	case "f":
+		if template := m.current(); template != nil {
+			m.manager.FindByID(template.ID).ToggleFavorite()
+			if m.save() {
+				m.message = fmt.Sprintf("Updated %s", template.Name)
+			}
+		}
+	case "d", "delete":
+		if m.current() != nil {
+			m.mode = templateModeConfirmDelete
+		}
+	case "/":
+		m.mode = templateModeSearch
+		return m, m.search.Focus()
+	case "esc":
+		return m, NavigateTo(ViewWalletDashboard, nil)
+	}
+	return m, nil
+}
+
+func (m TemplateManagerModel) updateInput(msg tea.Msg) (TemplateManagerModel, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.mode {
+	case templateModeSearch:
+		m.search, cmd = m.search.Update(msg)
+	case templateModeEdit:
+		m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	}
+	return m, cmd
+}
+
+func (m *TemplateManagerModel) focus(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	return m.inputs[field].Focus()
+}
+
+// refresh rebuilds the visible list from the manager, keeping the selection
+// in range
+func (m *TemplateManagerModel) refresh() {
+	m.templates = m.manager.SearchTemplates(m.search.Value())
+	if m.selected >= len(m.templates) {
+		m.selected = len(m.templates) - 1
+	}
+	if m.selected < 0 {
+		m.selected = 0
+	}
+}
+
+func (m *TemplateManagerModel) current() *models.TransactionTemplate {
+	if m.selected < 0 || m.selected >= len(m.templates) {
+		return nil
+	}
+	return &m.templates[m.selected]
+}
+
+func (m *TemplateManagerModel) startEdit(template *models.TransactionTemplate) tea.Cmd {
+	amount := template.AmountPlaceholder
+	if amount == "" && template.Amount != nil {
+		amount = utils.FormatAmount(template.Amount, 18)
+	}
+	values := [templateFieldCount]string{
+		template.Name,
+		template.Description,
+		template.ToAddress,
+		amount,
+		template.Asset,
+		template.Notes,
+		strings.Join(template.Tags, ", "),
+	}
+	for i := range m.inputs {
+		m.inputs[i].SetValue(values[i])
+	}
+
+	m.editID = template.ID
+	m.mode = templateModeEdit
+	m.err = ""
+	m.inputs[m.focused].Blur()
+	m.focused = templateFieldName
+	return m.inputs[templateFieldName].Focus()
+}
+
+func (m *TemplateManagerModel) saveEdit() {
+	template := m.manager.FindByID(m.editID)
+	if template == nil {
+		m.err = "Template no longer exists"
+		m.mode = templateModeList
+		return
+	}
+
+	name := strings.TrimSpace(m.inputs[templateFieldName].Value())
+	if name == "" {
+		m.err = "Name is required"
+		m.focus(templateFieldName)
+		return
+	}
+
+	address := strings.TrimSpace(m.inputs[templateFieldAddress].Value())
+	if models.HasPlaceholders(address) {
+		if _, err := models.ParsePlaceholders(address); err != nil {
+			m.err = "Invalid recipient: " + err.Error()
+			m.focus(templateFieldAddress)
+			return
+		}
+	} else if err := utils.ValidateVeChainAddress(address); err != nil {
+		m.err = "Invalid recipient: " + err.Error()
+		m.focus(templateFieldAddress)
+		return
+	}
+
+	notes := strings.TrimSpace(m.inputs[templateFieldNotes].Value())
+	if _, err := models.ParsePlaceholders(notes); err != nil {
+		m.err = "Invalid notes: " + err.Error()
+		m.focus(templateFieldNotes)
+		return
+	}
+
+	var amount *big.Int
+	var amountPlaceholder string
+	if value := strings.TrimSpace(m.inputs[templateFieldAmount].Value()); models.HasPlaceholders(value) {
+		variables, err := models.ParsePlaceholders(value)
+		if err == nil && (len(variables) != 1 || variables[0].Kind != models.VariableAmount) {
+			err = fmt.Errorf("use a single amount placeholder such as {{amount}}")
+		}
+		if err != nil {
+			m.err = "Invalid amount: " + err.Error()
+			m.focus(templateFieldAmount)
+			return
+		}
+		amountPlaceholder = value
+	} else if value != "" {
+		parsed, err := utils.ValidateAmount(value, 18)
+		if err != nil {
+			m.err = "Invalid amount: " + err.Error()
+			m.focus(templateFieldAmount)
+			return
+		}
+		amount = parsed
+	}
+
+	asset := strings.ToUpper(strings.TrimSpace(m.inputs[templateFieldAsset].Value()))
+	if asset != string(blockchain.VET) && asset != string(blockchain.VTHO) {
+		m.err = "Asset must be VET or VTHO"
+		m.focus(templateFieldAsset)
+		return
+	}
+
+	// The contact name belongs to the old recipient
+	contactName := template.ContactName
+	if !strings.EqualFold(address, template.ToAddress) {
+		contactName = ""
+	}
+
+	template.Update(
+		name,
+		strings.TrimSpace(m.inputs[templateFieldDescription].Value()),
+		address,
+		contactName,
+		asset,
+		notes,
+		amount,
+		parseTags(m.inputs[templateFieldTags].Value()),
+	)
+	// Update keeps the old value for empty fields; these may be cleared
+	template.Amount = amount
+	template.AmountPlaceholder = amountPlaceholder
+	template.ContactName = contactName
+	if m.save() {
+		m.mode = templateModeList
+		m.message = fmt.Sprintf("Saved %s", name)
+	}
+}
+
+func (m *TemplateManagerModel) deleteSelected() {
+	template := m.current()
+	if template == nil {
+		return
+	}
+	name := template.Name
+	m.manager.RemoveTemplate(template.ID)
+	if m.save() {
+		m.message = fmt.Sprintf("Deleted %s", name)
+	}
+}
+
+// save writes the templates and refreshes the list, reporting failures on
+// screen
+func (m *TemplateManagerModel) save() bool {
+	defer m.refresh()
+	if err := m.storage.SaveTemplates(m.wallet.ID, m.manager.Export()); err != nil {
+		m.err = fmt.Sprintf("Failed to save templates: %s", err.Error())
+		return false
+	}
+	m.err = ""
+	return true
+}
+
+func parseTags(value string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func (m TemplateManagerModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Base)).
+		Background(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	helpStyle := mutedStyle.Italic(true)
+
+	var content string
+	content += titleStyle.Render("Transaction Templates") + "\n"
+	content += helpStyle.Render(fmt.Sprintf("%s (%s)", m.wallet.Name, utils.FormatAddress(m.wallet.Address, 6, 4))) + "\n\n"
+
+	if m.mode == templateModeEdit {
+		labels := [templateFieldCount]string{"Name:", "Description:", "Recipient:", "Amount:", "Asset:", "Notes:", "Tags:"}
+		for i, label := range labels {
+			content += labelStyle.Render(label) + "\n"
+			content += m.inputs[i].View() + "\n\n"
+		}
+		content += m.renderStatus()
+		content += helpStyle.Render("Tab/↑/↓: move • Enter: next / save • Esc: cancel")
+		return content
+	}
+
+	if m.mode == templateModeSearch || m.search.Value() != "" {
+		content += labelStyle.Render("Search: ") + m.search.View() + "\n\n"
+	}
+
+	if len(m.templates) == 0 {
+		if m.search.Value() != "" {
+			content += mutedStyle.Render("No templates match the search") + "\n\n"
+		} else {
+			content += mutedStyle.Render("No templates yet. Press Ctrl+S on the send screen to save one.") + "\n\n"
+		}
+	}
+
+	for i, template := range m.templates {
+		star := "  "
+		if template.IsFavorite {
+			star = "★ "
+		}
+		amount := "any amount"
+		if template.AmountPlaceholder != "" {
+			amount = template.AmountPlaceholder + " " + template.Asset
+		} else if template.Amount != nil {
+			amount = utils.FormatAmount(template.Amount, 18) + " " + template.Asset
+		}
+		recipient := template.ToAddress
+		if !models.HasPlaceholders(recipient) {
+			recipient = utils.FormatAddress(recipient, 6, 4)
+		}
+		line := fmt.Sprintf("%s%-28s %-16s %s", star, template.Name, recipient, amount)
+		if i == m.selected {
+			content += selectedStyle.Render("▶ "+line) + "\n"
+		} else {
+			content += "  " + line + "\n"
+		}
+
+		details := fmt.Sprintf("used %d times", template.UseCount)
+		if len(template.Tags) > 0 {
+			details += " • #" + strings.Join(template.Tags, " #")
+		}
+		content += mutedStyle.Render("      "+details) + "\n"
+	}
+	content += "\n"
+
+	if m.mode == templateModeConfirmDelete {
+		if template := m.current(); template != nil {
+			content += lipgloss.NewStyle().
+				Foreground(lipgloss.Color(utils.Colours.Yellow)).
+				Bold(true).
+				Render(fmt.Sprintf("Delete %s? (y/n)", template.Name)) + "\n\n"
+		}
+	}
+
+	content += m.renderStatus()
+	if m.mode == templateModeSearch {
+		content += helpStyle.Render("Enter: done • Esc: clear search")
+	} else {
+		content += helpStyle.Render("↑/↓: navigate • Enter/e: edit • f: favorite • d: delete • /: search • Esc: back")
+	}
+	return content
+}
+
+func (m TemplateManagerModel) renderStatus() string {
+	if m.err != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ "+m.err) + "\n\n"
+	}
+	if m.message != "" {
+		return lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Green)).
+			Render("✓ "+m.message) + "\n\n"
+	}
+	return ""
+}
@@ -35,6 +35,10 @@ type TemplateSelectorModel struct {
 	loading bool
 	error   error
 
+	// variables collects placeholder values for the chosen template
+	variables *TemplateVariablesModel
+	balance   *models.CachedBalance
+
 	// Callbacks
 	onTemplateSelected func(template *models.TransactionTemplate) tea.Cmd
 	onCreateTemplate   func() tea.Cmd
@@ -61,6 +65,12 @@ func (m *TemplateSelectorModel) SetStorage(storage *storage.Storage) {
 	m.storage = storage
 }
 
+// SetBalance sets the balance that relative amounts such as "50% of VTHO"
+// are worked out from
+func (m *TemplateSelectorModel) SetBalance(balance *models.CachedBalance) {
+	m.balance = balance
+}
+
 func (m *TemplateSelectorModel) SetTemplateManager(manager *models.TransactionTemplateManager) {
 	m.templateManager = manager
 	m.updateFilteredTemplates()
@@ -80,6 +90,8 @@ func (m *TemplateSelectorModel) Show() {
 	m.selectedIndex = 0
 	m.scrollOffset = 0
 	m.searchQuery = ""
+	m.error = nil
+	m.variables = nil
 	m.updateFilteredTemplates()
 	m.visible = true
 }
@@ -97,6 +109,12 @@ func (m *TemplateSelectorModel) Update(msg tea.Msg) (*TemplateSelectorModel, tea
 		return m, nil
 	}
 
+	if m.variables != nil {
+		var cmd tea.Cmd
+		m.variables, cmd = m.variables.Update(msg)
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -182,6 +200,10 @@ func (m *TemplateSelectorModel) renderContent() string {
 		return m.renderError()
 	}
 
+	if m.variables != nil {
+		return m.variables.View()
+	}
+
 	var content strings.Builder
 
 	// Title
@@ -355,7 +377,9 @@ func (m *TemplateSelectorModel) renderTemplateItem(template models.TransactionTe
 
 	// Format amount if present
 	amountStr := ""
-	if template.Amount != nil {
+	if template.AmountPlaceholder != "" {
+		amountStr = fmt.Sprintf(" %s %s", template.AmountPlaceholder, template.Asset)
+	} else if template.Amount != nil {
 		amountStr = fmt.Sprintf(" %s %s", utils.FormatAmount(template.Amount, 4), template.Asset)
 	} else {
 		amountStr = fmt.Sprintf(" %s", template.Asset)
@@ -368,11 +392,15 @@ func (m *TemplateSelectorModel) renderTemplateItem(template models.TransactionTe
 		template.ContactName)
 
 	if template.ContactName == "" {
+		recipient := template.ToAddress
+		if !models.HasPlaceholders(recipient) {
+			recipient = utils.FormatAddress(recipient, 6, 4)
+		}
 		line = fmt.Sprintf("%s %-20s%s → %s",
 			favoriteIcon,
 			template.Name,
 			amountStr,
-			utils.FormatAddress(template.ToAddress, 6, 4))
+			recipient)
 	}
 
 	// Add use count
@@ -463,12 +491,7 @@ func (m *TemplateSelectorModel) handleSelection() tea.Cmd {
 		}
 
 		if m.selectedIndex < maxFavorites {
-			template := favorites[m.selectedIndex]
-			m.Hide()
-			if m.onTemplateSelected != nil {
-				return m.onTemplateSelected(&template)
-			}
-			return nil
+			return m.choose(favorites[m.selectedIndex])
 		}
 		currentIndex += maxFavorites
 	}
@@ -482,12 +505,7 @@ func (m *TemplateSelectorModel) handleSelection() tea.Cmd {
 		}
 
 		if m.selectedIndex >= currentIndex && m.selectedIndex < currentIndex+maxRecent {
-			template := recent[m.selectedIndex-currentIndex]
-			m.Hide()
-			if m.onTemplateSelected != nil {
-				return m.onTemplateSelected(&template)
-			}
-			return nil
+			return m.choose(recent[m.selectedIndex-currentIndex])
 		}
 		currentIndex += maxRecent
 	}
@@ -495,13 +513,47 @@ func (m *TemplateSelectorModel) handleSelection() tea.Cmd {
 	// Check all templates
 	templateIndex := m.selectedIndex - currentIndex
 	if templateIndex >= 0 && templateIndex < len(m.filteredTemplates) {
-		template := m.filteredTemplates[templateIndex]
-		m.Hide()
-		if m.onTemplateSelected != nil {
-			return m.onTemplateSelected(&template)
+		return m.choose(m.filteredTemplates[templateIndex])
+	}
+
+	return nil
+}
+
+// choose applies template, first asking for the values of any placeholders
+func (m *TemplateSelectorModel) choose(template models.TransactionTemplate) tea.Cmd {
+	if !template.HasVariables() {
+		return m.selectTemplate(&template)
+	}
+
+	contacts := &models.ContactList{}
+	if m.storage != nil {
+		loaded, err := m.storage.LoadContacts()
+		if err != nil {
+			m.error = err
+			return nil
 		}
+		contacts = loaded
+	}
+
+	prompt, err := NewTemplateVariablesModel(template, contacts, m.balance)
+	if err != nil {
+		m.error = err
+		return nil
 	}
+	prompt.SetCallbacks(m.selectTemplate, func() tea.Cmd {
+		m.variables = nil
+		return nil
+	})
+	m.variables = prompt
+	return prompt.Init()
+}
 
+func (m *TemplateSelectorModel) selectTemplate(template *models.TransactionTemplate) tea.Cmd {
+	m.variables = nil
+	m.Hide()
+	if m.onTemplateSelected != nil {
+		return m.onTemplateSelected(template)
+	}
 	return nil
 }
 
@@ -0,0 +1,282 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+// TemplateVariablesModel collects the values for a template's placeholders
+// before it is applied. Contact placeholders pick from the saved contacts,
+// limited to the placeholder's category; the rest are typed, starting from
+// their defaults.
+type TemplateVariablesModel struct {
+	template  models.TransactionTemplate
+	variables []models.TemplateVariable
+	balance   *models.CachedBalance
+
+	inputs []textinput.Model
+	// candidates and choice hold each contact variable's matching contacts
+	// and the one picked
+	candidates [][]models.Contact
+	choice     []int
+	contacts   *models.ContactList
+	focused    int
+
+	err string
+
+	onSubmit func(template *models.TransactionTemplate) tea.Cmd
+	onCancel func() tea.Cmd
+}
+
+func NewTemplateVariablesModel(template models.TransactionTemplate, contacts *models.ContactList, balance *models.CachedBalance) (*TemplateVariablesModel, error) {
+	variables, err := template.Variables()
+	if err != nil {
+		return nil, err
+	}
+	if len(variables) == 0 {
+		return nil, fmt.Errorf("template %s has no placeholders to fill", template.Name)
+	}
+	if contacts == nil {
+		contacts = &models.ContactList{}
+	}
+
+	m := &TemplateVariablesModel{
+		template:   template,
+		variables:  variables,
+		balance:    balance,
+		contacts:   contacts,
+		inputs:     make([]textinput.Model, len(variables)),
+		candidates: make([][]models.Contact, len(variables)),
+		choice:     make([]int, len(variables)),
+	}
+
+	for i, variable := range variables {
+		input := textinput.New()
+		input.Width = 40
+		input.SetValue(variable.Default)
+		switch variable.Kind {
+		case models.VariableAmount:
+			input.Placeholder = "12.5, 12.5 VTHO or 50% of VTHO"
+		case models.VariableAddress:
+			input.Placeholder = "0x..."
+		case models.VariableContact:
+			m.candidates[i] = contactsInCategory(contacts, variable.Category)
+			for j, contact := range m.candidates[i] {
+				if strings.EqualFold(contact.Name, variable.Default) {
+					m.choice[i] = j
+				}
+			}
+		}
+		m.inputs[i] = input
+	}
+	m.focusField(0)
+
+	return m, nil
+}
+
+func contactsInCategory(contacts *models.ContactList, category string) []models.Contact {
+	var matching []models.Contact
+	for _, contact := range contacts.Contacts {
+		if category == "" || strings.EqualFold(contact.Category, category) {
+			matching = append(matching, contact)
+		}
+	}
+	return matching
+}
+
+func (m *TemplateVariablesModel) SetCallbacks(onSubmit func(template *models.TransactionTemplate) tea.Cmd, onCancel func() tea.Cmd) {
+	m.onSubmit = onSubmit
+	m.onCancel = onCancel
+}
+
+func (m *TemplateVariablesModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *TemplateVariablesModel) Update(msg tea.Msg) (*TemplateVariablesModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, m.updateInput(msg)
+	}
+
+	count := len(m.variables)
+	switch keyMsg.String() {
+	case "esc":
+		if m.onCancel != nil {
+			return m, m.onCancel()
+		}
+		return m, nil
+	case "tab", "down":
+		return m, m.focusField((m.focused + 1) % count)
+	case "shift+tab", "up":
+		return m, m.focusField((m.focused + count - 1) % count)
+	case "left", "right":
+		if m.variables[m.focused].Kind == models.VariableContact {
+			if options := len(m.candidates[m.focused]); options > 0 {
+				step := 1
+				if keyMsg.String() == "left" {
+					step = options - 1
+				}
+				m.choice[m.focused] = (m.choice[m.focused] + step) % options
+			}
+			return m, nil
+		}
+	case "enter":
+		if m.focused < count-1 {
+			return m, m.focusField(m.focused + 1)
+		}
+		return m, m.submit()
+	}
+
+	if m.variables[m.focused].Kind == models.VariableContact {
+		return m, nil
+	}
+	return m, m.updateInput(msg)
+}
+
+func (m *TemplateVariablesModel) updateInput(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return cmd
+}
+
+func (m *TemplateVariablesModel) focusField(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	if m.variables[field].Kind == models.VariableContact {
+		return nil
+	}
+	return m.inputs[field].Focus()
+}
+
+// submit validates every value and hands the filled template on
+func (m *TemplateVariablesModel) submit() tea.Cmd {
+	values := make(map[string]string, len(m.variables))
+	for i, variable := range m.variables {
+		value, err := m.value(i)
+		if err != nil {
+			m.err = fmt.Sprintf("%s: %s", variable.Name, err.Error())
+			return m.focusField(i)
+		}
+		values[variable.Placeholder] = value
+	}
+
+	filled, err := m.template.Fill(values, m.balance)
+	if err != nil {
+		m.err = err.Error()
+		return nil
+	}
+	if err := utils.ValidateVeChainAddress(filled.ToAddress); err != nil {
+		m.err = "Recipient: " + err.Error()
+		return nil
+	}
+	filled.ContactName = ""
+	if contact := m.contacts.FindByAddress(filled.ToAddress); contact != nil {
+		filled.ContactName = contact.Name
+	}
+
+	m.err = ""
+	if m.onSubmit != nil {
+		return m.onSubmit(filled)
+	}
+	return nil
+}
+
+// value returns variable i's value, checked against its kind
+func (m *TemplateVariablesModel) value(i int) (string, error) {
+	variable := m.variables[i]
+	if variable.Kind == models.VariableContact {
+		if len(m.candidates[i]) == 0 {
+			if variable.Category != "" {
+				return "", fmt.Errorf("no contacts in category %s", variable.Category)
+			}
+			return "", fmt.Errorf("no contacts saved")
+		}
+		return m.candidates[i][m.choice[i]].Address, nil
+	}
+
+	value := strings.TrimSpace(m.inputs[i].Value())
+	switch variable.Kind {
+	case models.VariableAmount:
+		if _, _, err := models.ResolveAmount(value, m.template.Asset, m.balance); err != nil {
+			return "", err
+		}
+	case models.VariableAddress:
+		if err := utils.ValidateVeChainAddress(value); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+func (m *TemplateVariablesModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Mauve)).
+		Bold(true)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true)
+
+	var content strings.Builder
+	content.WriteString(titleStyle.Render(m.template.Name))
+	content.WriteString("\n\n")
+
+	for i, variable := range m.variables {
+		marker := "  "
+		if i == m.focused {
+			marker = "▶ "
+		}
+		content.WriteString(labelStyle.Render(marker + variable.Name + ":"))
+		content.WriteString("\n  ")
+
+		if variable.Kind == models.VariableContact {
+			if len(m.candidates[i]) == 0 {
+				content.WriteString(mutedStyle.Render("no matching contacts"))
+			} else {
+				contact := m.candidates[i][m.choice[i]]
+				text := fmt.Sprintf("◀ %s (%s) ▶", contact.Name, utils.FormatAddress(contact.Address, 6, 4))
+				if i == m.focused {
+					text = selectedStyle.Render(text)
+				}
+				content.WriteString(text)
+				if variable.Category != "" {
+					content.WriteString(mutedStyle.Render("  " + variable.Category))
+				}
+			}
+		} else {
+			content.WriteString(m.inputs[i].View())
+			if variable.Kind == models.VariableAmount {
+				value := strings.TrimSpace(m.inputs[i].Value())
+				if amount, asset, err := models.ResolveAmount(value, m.template.Asset, m.balance); err == nil && value != "" {
+					content.WriteString(mutedStyle.Render(fmt.Sprintf("  = %s %s", utils.FormatAmount(amount, 18), asset)))
+				}
+			}
+		}
+		content.WriteString("\n\n")
+	}
+
+	if m.err != "" {
+		content.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ " + m.err))
+		content.WriteString("\n\n")
+	}
+
+	content.WriteString(mutedStyle.Italic(true).Render("Tab/↑/↓: move • ←/→: pick contact • Enter: next / apply • Esc: back"))
+	return content.String()
+}
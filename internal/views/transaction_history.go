@@ -2,7 +2,6 @@ package views
 
 import (
 	"fmt"
-	"math/big"
 	"strings"
 	"time"
 
@@ -398,12 +397,16 @@ func (m TransactionHistoryModel) loadTransactionHistory() tea.Cmd {
 
 func (m TransactionHistoryModel) loadTransactionHistoryPage(page int) tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
-		// For now, create mock transaction data
-		// In a real implementation, this would fetch from the blockchain with pagination
-		allMockTransactions := m.createAllMockTransactions()
+		if m.storage == nil {
+			return TransactionHistoryErrorMsg{Err: fmt.Errorf("storage not available")}
+		}
+		transactions, err := m.accountTransactions()
+		if err != nil {
+			return TransactionHistoryErrorMsg{Err: fmt.Errorf("failed to load transaction history: %w", err)}
+		}
 
 		// Apply filters
-		filteredTransactions := m.applyFilters(allMockTransactions)
+		filteredTransactions := m.applyFilters(transactions)
 
 		// Calculate pagination on filtered results
 		pageSize := 20
@@ -423,7 +426,7 @@ func (m TransactionHistoryModel) loadTransactionHistoryPage(page int) tea.Cmd {
 
 		history := &models.TransactionHistory{
 			Transactions:  pageTransactions,
-			TotalCount:    len(allMockTransactions),
+			TotalCount:    len(transactions),
 			CurrentPage:   page,
 			PageSize:      pageSize,
 			HasMore:       hasMore,
@@ -435,138 +438,31 @@ func (m TransactionHistoryModel) loadTransactionHistoryPage(page int) tea.Cmd {
 	})
 }
 
-func (m TransactionHistoryModel) createAllMockTransactions() []models.Transaction {
-	// Create a larger set of mock transactions for pagination testing
-	transactions := []models.Transaction{}
-
-	// Add the original mock transactions
-	originalTransactions := m.createMockTransactions()
-	transactions = append(transactions, originalTransactions...)
-
-	// Add more mock transactions to test pagination
-	for i := 5; i <= 50; i++ {
-		tx := models.Transaction{
-			ID:             fmt.Sprintf("tx_%03d", i),
-			Hash:           fmt.Sprintf("0x%064d", i),
-			From:           m.wallet.Address,
-			To:             fmt.Sprintf("0x%040d", i),
-			Amount:         big.NewInt(int64(i * 100000000000000000)), // Variable amounts
-			Asset:          "VET",
-			Timestamp:      time.Now().Add(-time.Duration(i) * time.Hour),
-			Status:         models.TransactionStatusConfirmed,
-			Confirmations:  i,
-			GasUsed:        big.NewInt(21000),
-			GasPrice:       big.NewInt(1000000000000000),
-			BlockNumber:    uint64(15234567 - i),
-			BlockHash:      fmt.Sprintf("0x%064d", 15234567-i),
-			TransactionFee: big.NewInt(21000000000000000),
-			Direction:      models.TransactionDirectionSent,
-			ContactName:    fmt.Sprintf("Contact %d", i),
-		}
-
-		// Vary some properties for diversity
-		if i%3 == 0 {
+// accountTransactions returns the active account's recorded transactions,
+// most recent first. Transfers between the wallet's own accounts are
+// recorded as sent, so their direction is worked out for this account.
+func (m TransactionHistoryModel) accountTransactions() ([]models.Transaction, error) {
+	recorded, err := m.storage.LoadAccountHistory(m.wallet.ID, m.wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]models.Transaction, 0, len(recorded))
+	for i := len(recorded) - 1; i >= 0; i-- {
+		tx := recorded[i]
+		fromAccount := strings.EqualFold(tx.From, m.wallet.Address)
+		toAccount := strings.EqualFold(tx.To, m.wallet.Address)
+		switch {
+		case fromAccount && toAccount:
+			tx.Direction = models.TransactionDirectionSelf
+		case toAccount:
 			tx.Direction = models.TransactionDirectionReceived
-			tx.From, tx.To = tx.To, tx.From
+		case fromAccount:
+			tx.Direction = models.TransactionDirectionSent
 		}
-		if i%5 == 0 {
-			tx.Asset = "VTHO"
-			tx.Amount = func() *big.Int {
-				val, _ := new(big.Int).SetString(fmt.Sprintf("%d000000000000000000", i*10), 10)
-				return val
-			}()
-		}
-		if i%7 == 0 {
-			tx.Status = models.TransactionStatusPending
-			tx.Confirmations = 0
-			tx.BlockNumber = 0
-			tx.BlockHash = ""
-		}
-
 		transactions = append(transactions, tx)
 	}
-
-	return transactions
-}
-
-func (m TransactionHistoryModel) createMockTransactions() []models.Transaction {
-	// Create some mock transactions for demonstration
-	transactions := []models.Transaction{
-		{
-			ID:             "tx_001",
-			Hash:           "0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef",
-			From:           m.wallet.Address,
-			To:             "0x9876543210987654321098765432109876543210",
-			Amount:         big.NewInt(1000000000000000000), // 1 VET
-			Asset:          "VET",
-			Timestamp:      time.Now().Add(-2 * time.Hour),
-			Status:         models.TransactionStatusConfirmed,
-			Confirmations:  12,
-			GasUsed:        big.NewInt(21000),
-			GasPrice:       big.NewInt(1000000000000000),
-			BlockNumber:    15234567,
-			BlockHash:      "0xabcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890",
-			TransactionFee: big.NewInt(21000000000000000),
-			Direction:      models.TransactionDirectionSent,
-			ContactName:    "Alice",
-		},
-		{
-			ID:             "tx_002",
-			Hash:           "0x2345678901bcdef12345678901bcdef12345678901bcdef12345678901bcdef1",
-			From:           "0x8765432109876543210987654321098765432109",
-			To:             m.wallet.Address,
-			Amount:         big.NewInt(500000000000000000), // 0.5 VET
-			Asset:          "VET",
-			Timestamp:      time.Now().Add(-4 * time.Hour),
-			Status:         models.TransactionStatusConfirmed,
-			Confirmations:  24,
-			GasUsed:        big.NewInt(21000),
-			GasPrice:       big.NewInt(1000000000000000),
-			BlockNumber:    15234550,
-			BlockHash:      "0xbcdef12345678901bcdef12345678901bcdef12345678901bcdef12345678901",
-			TransactionFee: big.NewInt(21000000000000000),
-			Direction:      models.TransactionDirectionReceived,
-			ContactName:    "Bob",
-		},
-		{
-			ID:             "tx_003",
-			Hash:           "0x3456789012cdef123456789012cdef123456789012cdef123456789012cdef12",
-			From:           m.wallet.Address,
-			To:             "0x7654321098765432109876543210987654321098",
-			Amount:         big.NewInt(250000000000000000), // 0.25 VET
-			Asset:          "VET",
-			Timestamp:      time.Now().Add(-6 * time.Hour),
-			Status:         models.TransactionStatusPending,
-			Confirmations:  0,
-			GasUsed:        big.NewInt(21000),
-			GasPrice:       big.NewInt(1000000000000000),
-			BlockNumber:    0,
-			BlockHash:      "",
-			TransactionFee: big.NewInt(21000000000000000),
-			Direction:      models.TransactionDirectionSent,
-			ContactName:    "Charlie",
-		},
-		{
-			ID:             "tx_004",
-			Hash:           "0x456789013def1234567890123def1234567890123def1234567890123def123",
-			From:           m.wallet.Address,
-			To:             "0x6543210987654321098765432109876543210987",
-			Amount:         func() *big.Int { val, _ := new(big.Int).SetString("75000000000000000000", 10); return val }(), // 75 VTHO
-			Asset:          "VTHO",
-			Timestamp:      time.Now().Add(-8 * time.Hour),
-			Status:         models.TransactionStatusConfirmed,
-			Confirmations:  36,
-			GasUsed:        big.NewInt(80000),
-			GasPrice:       big.NewInt(1000000000000000),
-			BlockNumber:    15234520,
-			BlockHash:      "0xcdef123456789012cdef123456789012cdef123456789012cdef123456789012",
-			TransactionFee: big.NewInt(80000000000000000),
-			Direction:      models.TransactionDirectionSent,
-			ContactName:    "David",
-		},
-	}
-
-	return transactions
+	return transactions, nil
 }
 
 func (m *TransactionHistoryModel) View() string {
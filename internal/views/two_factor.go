@@ -0,0 +1,404 @@
+package views
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/qrcode"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const (
+	twoFactorFieldPassword = iota
+	twoFactorFieldCode
+	twoFactorFieldCount
+)
+
+type twoFactorStage int
+
+const (
+	// twoFactorStageUnlock asks for the wallet password, and the current code
+	// when the wallet is already enrolled
+	twoFactorStageUnlock twoFactorStage = iota
+	// twoFactorStageSetup shows a new secret and waits for a code from the
+	// authenticator app to confirm it was added
+	twoFactorStageSetup
+	// twoFactorStageDone shows the outcome and any new recovery codes
+	twoFactorStageDone
+)
+
+type twoFactorAction int
+
+const (
+	twoFactorActionRecoveryCodes twoFactorAction = iota
+	twoFactorActionDisable
+)
+
+// TwoFactorModel enrols the current wallet in a TOTP second factor, or
+// replaces its recovery codes or removes the factor. It is reached from
+// Settings.
+type TwoFactorModel struct {
+	storage         *storage.Storage
+	securityManager *security.SecurityManager
+	wallet          *models.Wallet
+
+	stage   twoFactorStage
+	action  twoFactorAction
+	inputs  [twoFactorFieldCount]textinput.Model
+	focused int
+
+	// password is kept between unlocking and confirming a new secret, since
+	// the secret is sealed with it
+	password      string
+	pending       *models.TwoFactor
+	qr            string
+	recoveryCodes []string
+
+	working bool
+	message string
+	err     string
+}
+
+// TwoFactorSetupMsg carries a new, not yet confirmed, second factor
+type TwoFactorSetupMsg struct {
+	TwoFactor     *models.TwoFactor
+	RecoveryCodes []string
+	Err           error
+}
+
+// TwoFactorSavedMsg reports the result of saving a change to the second
+// factor. TwoFactor is nil when it was removed.
+type TwoFactorSavedMsg struct {
+	TwoFactor     *models.TwoFactor
+	RecoveryCodes []string
+	Err           error
+}
+
+func NewTwoFactorModel(store *storage.Storage, securityManager *security.SecurityManager, wallet *models.Wallet) *TwoFactorModel {
+	m := &TwoFactorModel{
+		storage:         store,
+		securityManager: securityManager,
+		wallet:          wallet,
+	}
+
+	placeholders := [twoFactorFieldCount]string{"current wallet password", "authenticator or recovery code"}
+	for i := range m.inputs {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		input.Width = 40
+		if i == twoFactorFieldPassword {
+			input.EchoMode = textinput.EchoPassword
+			input.EchoCharacter = '*'
+		}
+		m.inputs[i] = input
+	}
+	m.inputs[twoFactorFieldPassword].Focus()
+
+	return m
+}
+
+// IsEditing reports whether a field has focus; one does until the change
+// has been saved
+func (m *TwoFactorModel) IsEditing() bool {
+	return m.stage != twoFactorStageDone
+}
+
+func (m TwoFactorModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *TwoFactorModel) enrolled() bool {
+	return m.wallet.TwoFactor != nil
+}
+
+func (m TwoFactorModel) Update(msg tea.Msg) (TwoFactorModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case TwoFactorSetupMsg:
+		m.working = false
+		if msg.Err != nil {
+			m.password = ""
+			m.err = msg.Err.Error()
+			return m, m.focus(twoFactorFieldPassword)
+		}
+		code, err := qrcode.Encode(msg.TwoFactor.URI(m.wallet.Name))
+		if err == nil {
+			m.qr = code.Terminal()
+		}
+		m.pending = msg.TwoFactor
+		m.recoveryCodes = msg.RecoveryCodes
+		m.stage = twoFactorStageSetup
+		m.err = ""
+		return m, m.focus(twoFactorFieldCode)
+
+	case TwoFactorSavedMsg:
+		m.working = false
+		m.inputs[twoFactorFieldCode].SetValue("")
+		if msg.Err != nil {
+			m.err = msg.Err.Error()
+			if errors.Is(msg.Err, storage.ErrInvalidPassword) {
+				m.inputs[twoFactorFieldPassword].SetValue("")
+				return m, m.focus(twoFactorFieldPassword)
+			}
+			return m, m.focus(twoFactorFieldCode)
+		}
+		m.clearSecrets()
+		m.wallet.TwoFactor = msg.TwoFactor
+		m.recoveryCodes = msg.RecoveryCodes
+		m.stage = twoFactorStageDone
+		m.err = ""
+		switch {
+		case msg.TwoFactor == nil:
+			m.message = "Two-factor authentication removed"
+		case m.pending != nil:
+			m.message = "Two-factor authentication enabled"
+		default:
+			m.message = "New recovery codes created; the old ones no longer work"
+		}
+		m.pending = nil
+		m.qr = ""
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.working {
+			return m, nil
+		}
+		if m.stage == twoFactorStageDone {
+			if msg.String() == "enter" || msg.String() == "esc" {
+				m.recoveryCodes = nil
+				return m, NavigateTo(ViewSettings, nil)
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "esc":
+			m.clearSecrets()
+			return m, NavigateTo(ViewSettings, nil)
+		case "tab", "down", "shift+tab", "up":
+			if m.stage == twoFactorStageUnlock && m.enrolled() {
+				return m, m.focus((m.focused + 1) % twoFactorFieldCount)
+			}
+			return m, nil
+		case "left", "right":
+			if m.stage == twoFactorStageUnlock && m.enrolled() {
+				m.action = 1 - m.action
+				return m, nil
+			}
+		case "enter":
+			if m.stage == twoFactorStageSetup {
+				return m.confirm()
+			}
+			if m.enrolled() && m.focused == twoFactorFieldPassword {
+				return m, m.focus(twoFactorFieldCode)
+			}
+			return m.unlock()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focused], cmd = m.inputs[m.focused].Update(msg)
+	return m, cmd
+}
+
+func (m *TwoFactorModel) focus(field int) tea.Cmd {
+	m.inputs[m.focused].Blur()
+	m.focused = field
+	return m.inputs[field].Focus()
+}
+
+// unlock checks the password, then either creates a new secret or applies
+// the chosen change to the existing one
+func (m TwoFactorModel) unlock() (TwoFactorModel, tea.Cmd) {
+	password := m.inputs[twoFactorFieldPassword].Value()
+	if password == "" {
+		m.err = "Wallet password is required"
+		return m, m.focus(twoFactorFieldPassword)
+	}
+
+	store, id := m.storage, m.wallet.ID
+	m.err = ""
+	m.working = true
+
+	if !m.enrolled() {
+		m.password = password
+		m.inputs[twoFactorFieldPassword].SetValue("")
+		return m, func() tea.Msg {
+			if _, err := store.LoadWallet(id, password); err != nil {
+				return TwoFactorSetupMsg{Err: err}
+			}
+			twoFactor, codes, err := models.NewTwoFactor()
+			return TwoFactorSetupMsg{TwoFactor: twoFactor, RecoveryCodes: codes, Err: err}
+		}
+	}
+
+	code := m.inputs[twoFactorFieldCode].Value()
+	if strings.TrimSpace(code) == "" {
+		m.working = false
+		m.err = "Authentication code is required"
+		return m, m.focus(twoFactorFieldCode)
+	}
+	securityManager, action := m.securityManager, m.action
+	return m, func() tea.Msg {
+		wallet, err := store.LoadWallet(id, password)
+		if err != nil {
+			return TwoFactorSavedMsg{Err: err}
+		}
+		if err := securityManager.VerifySecondFactor(store, wallet, code); err != nil {
+			return TwoFactorSavedMsg{Err: err}
+		}
+
+		if action == twoFactorActionDisable {
+			return TwoFactorSavedMsg{Err: store.SetTwoFactor(id, password, nil)}
+		}
+		twoFactor := wallet.TwoFactor
+		codes, err := twoFactor.ResetRecoveryCodes()
+		if err != nil {
+			return TwoFactorSavedMsg{Err: err}
+		}
+		if err := store.SetTwoFactor(id, password, twoFactor); err != nil {
+			return TwoFactorSavedMsg{Err: err}
+		}
+		return TwoFactorSavedMsg{TwoFactor: twoFactor, RecoveryCodes: codes}
+	}
+}
+
+// confirm saves the new secret once the authenticator app produces a
+// matching code, proving it was added correctly
+func (m TwoFactorModel) confirm() (TwoFactorModel, tea.Cmd) {
+	code := strings.TrimSpace(m.inputs[twoFactorFieldCode].Value())
+	if !m.pending.Verify(code, time.Now()) {
+		m.err = "That code does not match; check the authenticator app and the clock on this device"
+		m.inputs[twoFactorFieldCode].SetValue("")
+		return m, nil
+	}
+
+	m.err = ""
+	m.working = true
+	store, id, password, twoFactor, codes := m.storage, m.wallet.ID, m.password, m.pending, m.recoveryCodes
+	return m, func() tea.Msg {
+		if err := store.SetTwoFactor(id, password, twoFactor); err != nil {
+			return TwoFactorSavedMsg{Err: err}
+		}
+		return TwoFactorSavedMsg{TwoFactor: twoFactor, RecoveryCodes: codes}
+	}
+}
+
+func (m *TwoFactorModel) clearSecrets() {
+	m.password = ""
+	for i := range m.inputs {
+		m.inputs[i].SetValue("")
+	}
+}
+
+func (m TwoFactorModel) View() string {
+	titleStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Blue)).
+		Bold(true).
+		Padding(1, 0)
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Text)).
+		Bold(true)
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0))
+
+	warningStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Yellow))
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
+		Italic(true)
+
+	content := titleStyle.Render(fmt.Sprintf("Two-Factor Authentication: %s", m.wallet.Name)) + "\n"
+
+	switch m.stage {
+	case twoFactorStageDone:
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Green)).
+			Render("✓ "+m.message) + "\n\n"
+		content += m.renderRecoveryCodes(labelStyle, warningStyle)
+		content += helpStyle.Render("Press Enter to return to settings")
+		return content
+
+	case twoFactorStageSetup:
+		content += mutedStyle.Render("Scan the code with an authenticator app, or enter the secret by hand.") + "\n\n"
+		if m.qr != "" {
+			content += m.qr + "\n"
+		}
+		content += labelStyle.Render("Secret: ") + formatTOTPSecret(m.pending.Secret) + "\n"
+		content += mutedStyle.Render(m.pending.URI(m.wallet.Name)) + "\n\n"
+		content += m.renderRecoveryCodes(labelStyle, warningStyle)
+		content += labelStyle.Render("Code from the app:") + "\n"
+		content += m.inputs[twoFactorFieldCode].View() + "\n\n"
+
+	default:
+		if m.enrolled() {
+			content += mutedStyle.Render("Enabled. Sending, exporting keys and changing the spending policy ask for a code.") + "\n\n"
+			actions := []string{"new recovery codes", "turn off"}
+			content += labelStyle.Render("Action: ") + lipgloss.NewStyle().
+				Foreground(lipgloss.Color(utils.Colours.Blue)).
+				Bold(true).
+				Render("◀ "+actions[m.action]+" ▶") + "\n\n"
+		} else {
+			content += mutedStyle.Render("Off. Once enabled, sending, exporting keys and changing the spending policy ask for a code from an authenticator app.") + "\n\n"
+		}
+		content += labelStyle.Render("Wallet Password:") + "\n"
+		content += m.inputs[twoFactorFieldPassword].View() + "\n\n"
+		if m.enrolled() {
+			content += labelStyle.Render("Authentication Code:") + "\n"
+			content += m.inputs[twoFactorFieldCode].View() + "\n\n"
+		}
+	}
+
+	if m.working {
+		content += warningStyle.Render("Working...") + "\n\n"
+	}
+	if m.err != "" {
+		content += lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Red)).
+			Render("✗ "+m.err) + "\n\n"
+	}
+
+	if m.stage == twoFactorStageUnlock && m.enrolled() {
+		content += helpStyle.Render("Tab: move • ←/→: choose action • Enter: next / apply • Esc: back to settings")
+	} else {
+		content += helpStyle.Render("Enter: continue • Esc: cancel")
+	}
+	return content
+}
+
+func (m TwoFactorModel) renderRecoveryCodes(labelStyle, warningStyle lipgloss.Style) string {
+	if len(m.recoveryCodes) == 0 {
+		return ""
+	}
+	content := labelStyle.Render("Recovery codes") + "\n"
+	content += warningStyle.Render("Write these down. Each one works once in place of a code if the app is lost; they are not shown again.") + "\n"
+	for i := 0; i < len(m.recoveryCodes); i += 2 {
+		line := "  " + m.recoveryCodes[i]
+		if i+1 < len(m.recoveryCodes) {
+			line += "    " + m.recoveryCodes[i+1]
+		}
+		content += line + "\n"
+	}
+	return content + "\n"
+}
+
+// formatTOTPSecret groups the secret in fours so it can be typed into an app
+func formatTOTPSecret(secret string) string {
+	var groups []string
+	for len(secret) > 4 {
+		groups = append(groups, secret[:4])
+		secret = secret[4:]
+	}
+	return strings.Join(append(groups, secret), " ")
+}
@@ -19,6 +19,8 @@ const (
 	StepName WalletCreateStep = iota
 	StepPassword
 	StepConfirmPassword
+	StepPassphrase
+	StepPassphraseConfirm
 	StepMnemonicDisplay
 	StepMnemonicVerification
 	StepCreating
@@ -30,6 +32,9 @@ type WalletCreateModel struct {
 	name                string
 	password            string
 	confirm             string
+	wordCount           int
+	passphrase          string
+	passphraseConfirm   string
 	mnemonic            string
 	mnemonicWords       []string
 	verificationWords   []string
@@ -46,6 +51,7 @@ type WalletCreateModel struct {
 	nameError     string
 	passwordError string
 	confirmError  string
+	passphraseErr string
 
 	// UI state
 	cursor int
@@ -59,6 +65,7 @@ type WalletCreatedMsg struct {
 func NewWalletCreateModel() *WalletCreateModel {
 	return &WalletCreateModel{
 		step:                StepName,
+		wordCount:           12,
 		cursor:              0,
 		verificationIndices: []int{2, 5, 8}, // Ask for 3rd, 6th, and 9th words
 		verificationWords:   make([]string, 3),
@@ -69,6 +76,15 @@ func (m *WalletCreateModel) SetStorage(storage *storage.Storage) {
 	m.storage = storage
 }
 
+// IsEditing reports whether the current step takes typed input
+func (m *WalletCreateModel) IsEditing() bool {
+	switch m.step {
+	case StepName, StepPassword, StepConfirmPassword, StepPassphrase, StepPassphraseConfirm, StepMnemonicVerification:
+		return true
+	}
+	return false
+}
+
 func (m WalletCreateModel) Init() tea.Cmd {
 	return nil
 }
@@ -81,8 +97,11 @@ func (m WalletCreateModel) Update(msg tea.Msg) (WalletCreateModel, tea.Cmd) {
 			if m.step == StepName {
 				return m, NavigateTo(ViewWalletSelector, nil)
 			} else {
-				// Go back one step
+				// Go back one step; there is nothing to confirm without a passphrase
 				m.step--
+				if m.step == StepPassphraseConfirm && m.passphrase == "" {
+					m.step--
+				}
 				m.err = nil
 				return m, nil
 			}
@@ -94,6 +113,13 @@ func (m WalletCreateModel) Update(msg tea.Msg) (WalletCreateModel, tea.Cmd) {
 			return m.handleBackspace()
 
 		case "tab":
+			if m.step == StepName {
+				if m.wordCount == 12 {
+					m.wordCount = 24
+				} else {
+					m.wordCount = 12
+				}
+			}
 			if m.step == StepMnemonicVerification {
 				m.currentWordIndex = (m.currentWordIndex + 1) % 3
 			}
@@ -125,16 +151,25 @@ func (m WalletCreateModel) handleEnter() (WalletCreateModel, tea.Cmd) {
 
 	case StepConfirmPassword:
 		if m.validateConfirmPassword() {
-			// Generate mnemonic
-			mnemonic, err := hdwallet.NewMnemonic(128) // 12 words
-			if err != nil {
-				m.err = fmt.Errorf("failed to generate mnemonic: %w", err)
-				return m, nil
-			}
-			m.mnemonic = mnemonic
-			m.mnemonicWords = utils.SplitMnemonic(mnemonic)
-			m.step = StepMnemonicDisplay
+			m.step = StepPassphrase
+		}
+
+	case StepPassphrase:
+		if m.passphrase != "" {
+			m.passphraseConfirm = ""
+			m.passphraseErr = ""
+			m.step = StepPassphraseConfirm
+			return m, nil
 		}
+		return m.generateMnemonic(), nil
+
+	case StepPassphraseConfirm:
+		if m.passphraseConfirm != m.passphrase {
+			m.passphraseErr = "Passphrases do not match"
+			return m, nil
+		}
+		m.passphraseErr = ""
+		return m.generateMnemonic(), nil
 
 	case StepMnemonicDisplay:
 		m.step = StepMnemonicVerification
@@ -152,6 +187,30 @@ func (m WalletCreateModel) handleEnter() (WalletCreateModel, tea.Cmd) {
 	return m, nil
 }
 
+// generateMnemonic creates the recovery phrase with the chosen word count
+// and picks the words to verify, spread across the phrase
+func (m WalletCreateModel) generateMnemonic() WalletCreateModel {
+	bits := 128 // 12 words
+	m.verificationIndices = []int{2, 5, 8}
+	if m.wordCount == 24 {
+		bits = 256
+		m.verificationIndices = []int{3, 11, 19}
+	}
+
+	mnemonic, err := hdwallet.NewMnemonic(bits)
+	if err != nil {
+		m.err = fmt.Errorf("failed to generate mnemonic: %w", err)
+		return m
+	}
+	m.mnemonic = mnemonic
+	m.mnemonicWords = utils.SplitMnemonic(mnemonic)
+	for i := range m.verificationWords {
+		m.verificationWords[i] = ""
+	}
+	m.step = StepMnemonicDisplay
+	return m
+}
+
 func (m WalletCreateModel) handleBackspace() (WalletCreateModel, tea.Cmd) {
 	switch m.step {
 	case StepName:
@@ -172,6 +231,16 @@ func (m WalletCreateModel) handleBackspace() (WalletCreateModel, tea.Cmd) {
 			m.validateConfirmPassword()
 		}
 
+	case StepPassphrase:
+		if len(m.passphrase) > 0 {
+			m.passphrase = m.passphrase[:len(m.passphrase)-1]
+		}
+
+	case StepPassphraseConfirm:
+		if len(m.passphraseConfirm) > 0 {
+			m.passphraseConfirm = m.passphraseConfirm[:len(m.passphraseConfirm)-1]
+		}
+
 	case StepMnemonicVerification:
 		if len(m.verificationWords[m.currentWordIndex]) > 0 {
 			m.verificationWords[m.currentWordIndex] = m.verificationWords[m.currentWordIndex][:len(m.verificationWords[m.currentWordIndex])-1]
@@ -195,6 +264,13 @@ func (m WalletCreateModel) handleCharInput(char string) (WalletCreateModel, tea.
 		m.confirm += char
 		m.validateConfirmPassword()
 
+	case StepPassphrase:
+		m.passphrase += char
+
+	case StepPassphraseConfirm:
+		m.passphraseConfirm += char
+		m.passphraseErr = ""
+
 	case StepMnemonicVerification:
 		m.verificationWords[m.currentWordIndex] += char
 	}
@@ -256,7 +332,7 @@ func (m *WalletCreateModel) validateMnemonicVerification() bool {
 func (m WalletCreateModel) createWallet() tea.Cmd {
 	return func() tea.Msg {
 		// Create wallet from mnemonic
-		wallet, err := models.NewWallet(m.name, m.mnemonic)
+		wallet, err := models.NewWalletWithPassphrase(m.name, m.mnemonic, m.passphrase)
 		if err != nil {
 			return ErrorMsg{Err: fmt.Errorf("failed to create wallet: %w", err)}
 		}
@@ -271,6 +347,8 @@ func (m WalletCreateModel) createWallet() tea.Cmd {
 		// Clear sensitive data from memory
 		m.password = ""
 		m.confirm = ""
+		m.passphrase = ""
+		m.passphraseConfirm = ""
 		m.mnemonic = ""
 		for i := range m.mnemonicWords {
 			m.mnemonicWords[i] = ""
@@ -316,7 +394,7 @@ func (m WalletCreateModel) View() string {
 	content += titleStyle.Render("Create New Wallet") + "\n\n"
 
 	// Progress indicator
-	progress := fmt.Sprintf("Step %d of 5", int(m.step)+1)
+	progress := fmt.Sprintf("Step %d of 7", int(m.step)+1)
 	if m.step >= StepCreating {
 		progress = "Creating wallet..."
 	}
@@ -333,7 +411,9 @@ func (m WalletCreateModel) View() string {
 				content += errorStyle.Render("✗ "+m.nameError) + "\n"
 			}
 		}
-		content += "\n" + helpStyle.Render("Enter a descriptive name for your wallet")
+		content += "\n" + labelStyle.Render("Recovery Phrase Length:") + "\n"
+		content += inputStyle.Render(fmt.Sprintf("%d words", m.wordCount)) + "\n"
+		content += "\n" + helpStyle.Render("Enter a descriptive name for your wallet, Tab to switch between 12 and 24 words")
 
 	case StepPassword:
 		content += labelStyle.Render("Password:") + "\n"
@@ -359,9 +439,24 @@ func (m WalletCreateModel) View() string {
 		}
 		content += "\n" + helpStyle.Render("Re-enter your password to confirm")
 
+	case StepPassphrase:
+		content += labelStyle.Render("BIP39 Passphrase (optional):") + "\n"
+		content += inputStyle.Render(hidePassword(m.passphrase)+"_") + "\n\n"
+		content += helpStyle.Render("A passphrase (\"25th word\") is combined with the recovery phrase to derive the wallet.") + "\n"
+		content += warningStyle.Render("It cannot be recovered: without it the recovery phrase alone opens a different, empty wallet.") + "\n"
+		content += helpStyle.Render("Leave empty for none and press Enter.")
+
+	case StepPassphraseConfirm:
+		content += labelStyle.Render("Confirm Passphrase:") + "\n"
+		content += inputStyle.Render(hidePassword(m.passphraseConfirm)+"_") + "\n"
+		if m.passphraseErr != "" {
+			content += errorStyle.Render("✗ "+m.passphraseErr) + "\n"
+		}
+		content += "\n" + helpStyle.Render("Re-enter your passphrase to confirm")
+
 	case StepMnemonicDisplay:
 		content += warningStyle.Render("⚠️  IMPORTANT: Backup Your Recovery Phrase") + "\n\n"
-		content += labelStyle.Render("Your 12-word recovery phrase:") + "\n\n"
+		content += labelStyle.Render(fmt.Sprintf("Your %d-word recovery phrase:", len(m.mnemonicWords))) + "\n\n"
 
 		// Display mnemonic in a grid
 		mnemonicStyle := lipgloss.NewStyle().
@@ -378,6 +473,9 @@ func (m WalletCreateModel) View() string {
 		}
 
 		content += "\n\n" + warningStyle.Render("Write this down and store it safely!") + "\n"
+		if m.passphrase != "" {
+			content += warningStyle.Render("You will also need your passphrase, which is not part of this phrase.") + "\n"
+		}
 		content += helpStyle.Render("Anyone with this phrase can access your wallet.\nPress Enter when you have safely recorded it.")
 
 	case StepMnemonicVerification:
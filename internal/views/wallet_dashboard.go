@@ -25,6 +25,12 @@ type WalletDashboardModel struct {
 	lastRefresh      time.Time
 	autoRefreshTimer *time.Timer
 
+	// Refresh settings
+	refreshInterval    time.Duration
+	refreshGeneration  int
+	autoRefreshStarted bool
+	displayPrecision   int
+
 	// UI state
 	selectedMenuItem   int
 	showRefreshSpinner bool
@@ -68,20 +74,34 @@ type BalanceUpdateMsg struct {
 
 type RefreshBalanceMsg struct{}
 
-type AutoRefreshMsg struct{}
+type AutoRefreshMsg struct {
+	Generation int
+}
 
 type CopyAddressMsg struct{}
 
+// OpenAccountSwitcherMsg asks the app to show the account switcher
+type OpenAccountSwitcherMsg struct{}
+
 type FeedbackTimeoutMsg struct{}
 
 func NewWalletDashboardModel(wallet *models.Wallet) *WalletDashboardModel {
+	sendItem := "Send Transaction"
+	if wallet.IsWatchOnly() {
+		sendItem = "Export Unsigned Transaction"
+	}
+
 	return &WalletDashboardModel{
 		wallet:           wallet,
 		selectedMenuItem: 0,
 		balanceLoading:   false,
+		refreshInterval:  30 * time.Second,
+		displayPrecision: 4,
 		menuItems: []string{
-			"Send Transaction",
+			sendItem,
 			"Transaction History",
+			"Transaction Templates",
+			scheduledPaymentsItem,
 			"Contacts",
 			"Settings",
 			"Back to Wallet Selection",
@@ -89,6 +109,17 @@ func NewWalletDashboardModel(wallet *models.Wallet) *WalletDashboardModel {
 	}
 }
 
+const scheduledPaymentsItem = "Scheduled Payments"
+
+// SetPendingPayments shows how many scheduled payments wait for approval
+func (m *WalletDashboardModel) SetPendingPayments(count int) {
+	m.menuItems[3] = scheduledPaymentsItem
+	if count > 0 {
+		m.menuItems[3] = fmt.Sprintf("%s (%d to approve)", scheduledPaymentsItem, count)
+	}
+	m.cacheValid = false
+}
+
 func (m *WalletDashboardModel) SetBlockchainClient(client *blockchain.Client) {
 	m.blockchainClient = client
 	if client != nil {
@@ -103,6 +134,33 @@ func (m WalletDashboardModel) Init() tea.Cmd {
 	)
 }
 
+// Start runs Init the first time the dashboard is shown so that returning to
+// it does not spawn additional auto-refresh loops.
+func (m *WalletDashboardModel) Start() tea.Cmd {
+	if m.autoRefreshStarted {
+		return nil
+	}
+	m.autoRefreshStarted = true
+	return m.Init()
+}
+
+// SetRefreshInterval changes the auto-refresh interval; zero disables it.
+// Any pending tick from the previous interval is ignored.
+func (m *WalletDashboardModel) SetRefreshInterval(interval time.Duration) tea.Cmd {
+	m.refreshInterval = interval
+	m.refreshGeneration++
+	m.cacheValid = false
+	if !m.autoRefreshStarted {
+		return nil
+	}
+	return m.startAutoRefresh()
+}
+
+func (m *WalletDashboardModel) SetDisplayPrecision(decimals int) {
+	m.displayPrecision = decimals
+	m.cacheValid = false
+}
+
 func (m WalletDashboardModel) Update(msg tea.Msg) (WalletDashboardModel, tea.Cmd) {
 	var cmds []tea.Cmd
 
@@ -132,10 +190,14 @@ func (m WalletDashboardModel) Update(msg tea.Msg) (WalletDashboardModel, tea.Cmd
 			case 1:
 				return m, NavigateTo(ViewTransactionHistory, nil)
 			case 2:
-				return m, NavigateTo(ViewContacts, nil)
+				return m, NavigateTo(ViewTemplates, nil)
 			case 3:
-				return m, NavigateTo(ViewSettings, nil)
+				return m, NavigateTo(ViewSchedules, nil)
 			case 4:
+				return m, NavigateTo(ViewContacts, nil)
+			case 5:
+				return m, NavigateTo(ViewSettings, nil)
+			case 6:
 				return m, NavigateTo(ViewWalletSelector, nil)
 			}
 		case "r", "R":
@@ -149,10 +211,12 @@ func (m WalletDashboardModel) Update(msg tea.Msg) (WalletDashboardModel, tea.Cmd
 			}
 		case "c", "C":
 			cmds = append(cmds, m.copyAddress())
+		case "a", "A":
+			return m, func() tea.Msg { return OpenAccountSwitcherMsg{} }
 		case "esc":
 			return m, NavigateTo(ViewWalletSelector, nil)
 		case "?", "F1":
-			m.showFeedback(FeedbackInfo, "r: refresh, c: copy address, ↑/↓: navigate, enter: select", 5*time.Second)
+			m.showFeedback(FeedbackInfo, "r: refresh, c: copy address, a: switch account, ↑/↓: navigate, enter: select", 5*time.Second)
 		}
 
 	case BalanceUpdateMsg:
@@ -178,7 +242,11 @@ func (m WalletDashboardModel) Update(msg tea.Msg) (WalletDashboardModel, tea.Cmd
 		}
 
 	case AutoRefreshMsg:
-		if m.wallet.NeedsBalanceRefresh() && !m.balanceLoading {
+		if msg.Generation != m.refreshGeneration {
+			break
+		}
+		needsRefresh := m.wallet.CachedBalance == nil || m.wallet.GetBalanceAge() >= m.refreshInterval
+		if needsRefresh && !m.balanceLoading {
 			cmds = append(cmds, m.refreshBalance())
 		}
 		cmds = append(cmds, m.startAutoRefresh())
@@ -226,6 +294,12 @@ func (m *WalletDashboardModel) View() string {
 
 	// Title
 	title := fmt.Sprintf("Wallet: %s", m.wallet.Name)
+	if accounts := m.wallet.AccountList(); len(accounts) > 1 {
+		title += fmt.Sprintf(" • %s (%d/%d)", m.wallet.ActiveAccountLabel(), m.wallet.ActiveAccount+1, len(accounts))
+	}
+	if m.wallet.IsWatchOnly() {
+		title += " • watch-only"
+	}
 	content.WriteString(titleStyle.Render(title))
 	content.WriteString("\n\n")
 
@@ -375,7 +449,7 @@ func (m *WalletDashboardModel) renderBalanceCard() string {
 		content.WriteString("\n")
 		content.WriteString(balanceStyle.Render("VTHO: Error"))
 	} else {
-		vetBalance, vthoBalance := m.wallet.GetDisplayBalance()
+		vetBalance, vthoBalance := m.wallet.GetDisplayBalanceWithPrecision(m.displayPrecision)
 		content.WriteString(balanceStyle.Render(fmt.Sprintf("VET:  %s", vetBalance)))
 		content.WriteString("\n")
 		content.WriteString(balanceStyle.Render(fmt.Sprintf("VTHO: %s", vthoBalance)))
@@ -523,7 +597,7 @@ func (m *WalletDashboardModel) renderHelpSection() string {
 		Italic(true).
 		Align(lipgloss.Center)
 
-	helpText := "↑/↓: navigate • Enter: select • r: refresh • c: copy address • ?: help • Esc: back"
+	helpText := "↑/↓: navigate • Enter: select • r: refresh • c: copy address • a: accounts • ?: help • Esc: back"
 	return helpStyle.Render(helpText)
 }
 
@@ -570,8 +644,13 @@ func (m *WalletDashboardModel) refreshBalance() tea.Cmd {
 }
 
 func (m *WalletDashboardModel) startAutoRefresh() tea.Cmd {
-	return tea.Tick(30*time.Second, func(t time.Time) tea.Msg {
-		return AutoRefreshMsg{}
+	if m.refreshInterval <= 0 {
+		return nil
+	}
+
+	generation := m.refreshGeneration
+	return tea.Tick(m.refreshInterval, func(t time.Time) tea.Msg {
+		return AutoRefreshMsg{Generation: generation}
 	})
 }
 
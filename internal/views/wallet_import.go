@@ -1,14 +1,18 @@
 package views
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/darrenvechain/thorgo/crypto/hdwallet"
+	"github.com/ethereum/go-ethereum/crypto"
 
+	"rhystmorgan/veWallet/internal/blockchain"
 	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/slip39"
 	"rhystmorgan/veWallet/internal/storage"
 	"rhystmorgan/veWallet/internal/utils"
 )
@@ -18,6 +22,7 @@ type WalletImportStep int
 const (
 	StepImportName WalletImportStep = iota
 	StepMnemonicInput
+	StepPassphraseInput
 	StepMnemonicValidation
 	StepPasswordSetup
 	StepPasswordConfirm
@@ -25,19 +30,71 @@ const (
 	StepImportComplete
 )
 
+// ImportSource is where the imported wallet's key comes from
+type ImportSource int
+
+const (
+	ImportFromMnemonic ImportSource = iota
+	ImportFromPrivateKey
+	ImportFromKeystore
+	ImportWatchOnly
+	ImportFromShares
+	importSourceCount
+)
+
+const (
+	keystoreFieldPath = iota
+	keystoreFieldPassword
+)
+
 type WalletImportModel struct {
-	step           WalletImportStep
-	name           string
-	mnemonicWords  [12]string
+	step          WalletImportStep
+	source        ImportSource
+	name          string
+	mnemonicWords [24]string
+	wordCount     int
+	// language is the wordlist detected from the words entered so far;
+	// languageKnown is false until one of them is in any list
+	language       models.MnemonicLanguage
+	languageKnown  bool
+	passphrase     string
 	currentWordIdx int
 	password       string
 	confirm        string
 	storage        *storage.Storage
 	previewAddress string
 
+	// Account discovery for imported recovery phrases
+	blockchainClient *blockchain.Client
+	gapLimit         int
+	// reimportID is set when the phrase belongs to an existing wallet that
+	// was saved without its signing key
+	reimportID string
+
+	// Raw hex private key import
+	privateKeyHex string
+	keyError      string
+
+	// Keystore v3 file import
+	keystorePath     string
+	keystorePassword string
+	keystoreField    int
+	privateKey       *ecdsa.PrivateKey
+
+	// Watch-only import from an address or a file listing addresses
+	watchInput   string
+	watchWallets []*models.Wallet
+
+	// SLIP-39 shares collected until enough restore the recovery phrase
+	shareInput  string
+	shares      []string
+	shareStatus string
+	shareError  string
+
 	// Validation states
 	nameValid     bool
-	wordsValid    [12]bool
+	wordsValid    [24]bool
+	wordErrors    [24]string
 	mnemonicValid bool
 	passwordValid bool
 	confirmValid  bool
@@ -55,11 +112,22 @@ type WalletImportModel struct {
 
 type WalletImportedMsg struct {
 	Wallet *models.Wallet
+	// Imported counts the wallets saved; an address list saves several
+	Imported int
+	// DiscoveryErr is set when account discovery stopped early
+	DiscoveryErr error
+}
+
+// clipboardPasteMsg carries clipboard text read for Ctrl+V
+type clipboardPasteMsg struct {
+	Text string
+	Err  error
 }
 
 func NewWalletImportModel() *WalletImportModel {
 	return &WalletImportModel{
 		step:           StepImportName,
+		wordCount:      12,
 		currentWordIdx: 0,
 		cursor:         0,
 	}
@@ -69,6 +137,32 @@ func (m *WalletImportModel) SetStorage(storage *storage.Storage) {
 	m.storage = storage
 }
 
+// SetAccountDiscovery enables scanning for used derived accounts on import,
+// stopping after gapLimit consecutive unused addresses
+func (m *WalletImportModel) SetAccountDiscovery(client *blockchain.Client, gapLimit int) {
+	m.blockchainClient = client
+	m.gapLimit = gapLimit
+}
+
+// addressUsed checks local history first, then the network
+func (m WalletImportModel) addressUsed(address string) (bool, error) {
+	if m.storage != nil {
+		if used, err := m.storage.AddressHasHistory(address); err == nil && used {
+			return true, nil
+		}
+	}
+	return m.blockchainClient.AddressUsed(address)
+}
+
+// IsEditing reports whether the current step takes typed input
+func (m *WalletImportModel) IsEditing() bool {
+	switch m.step {
+	case StepImportName, StepMnemonicInput, StepPassphraseInput, StepPasswordSetup, StepPasswordConfirm:
+		return true
+	}
+	return false
+}
+
 func (m WalletImportModel) Init() tea.Cmd {
 	return nil
 }
@@ -76,13 +170,20 @@ func (m WalletImportModel) Init() tea.Cmd {
 func (m WalletImportModel) Update(msg tea.Msg) (WalletImportModel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if msg.Paste {
+			return m.insertText(string(msg.Runes)), nil
+		}
+
 		switch msg.String() {
 		case "esc":
 			if m.step == StepImportName {
 				return m, NavigateTo(ViewWalletSelector, nil)
 			} else {
-				// Go back one step
+				// Go back one step; only recovery phrases have a passphrase
 				m.step--
+				if m.step == StepPassphraseInput && !m.fromRecoveryPhrase() {
+					m.step--
+				}
 				m.err = nil
 				return m, nil
 			}
@@ -93,29 +194,56 @@ func (m WalletImportModel) Update(msg tea.Msg) (WalletImportModel, tea.Cmd) {
 		case "backspace":
 			return m.handleBackspace()
 
-		case "tab":
-			if m.step == StepMnemonicInput {
-				m.currentWordIdx = (m.currentWordIdx + 1) % 12
+		case "tab", "shift+tab":
+			switch {
+			case m.step == StepImportName && msg.String() == "tab":
+				m.source = (m.source + 1) % importSourceCount
+			case m.step == StepImportName:
+				m.source = (m.source + importSourceCount - 1) % importSourceCount
+			case m.step == StepMnemonicInput && m.source == ImportFromKeystore:
+				m.keystoreField = 1 - m.keystoreField
+			case m.step == StepMnemonicInput && (m.source == ImportFromPrivateKey || m.source == ImportWatchOnly || m.source == ImportFromShares):
+			case m.step == StepMnemonicInput && msg.String() == "tab":
+				if m.completeWord() {
+					break
+				}
+				m.currentWordIdx = (m.currentWordIdx + 1) % m.wordCount
+			case m.step == StepMnemonicInput:
+				m.currentWordIdx = (m.currentWordIdx - 1 + m.wordCount) % m.wordCount
 			}
 
-		case "shift+tab":
-			if m.step == StepMnemonicInput {
-				m.currentWordIdx = (m.currentWordIdx - 1 + 12) % 12
+		case "ctrl+l":
+			if m.step == StepMnemonicInput && m.source == ImportFromMnemonic {
+				if m.wordCount == 12 {
+					m.setWordCount(24)
+				} else {
+					m.setWordCount(12)
+				}
 			}
 
 		case "ctrl+v":
-			if m.step == StepMnemonicInput {
-				return m.handlePaste()
-			}
+			return m.handlePaste()
 
 		default:
-			if len(msg.String()) == 1 {
-				return m.handleCharInput(msg.String())
+			// Runes rather than bytes, so accented and CJK words can be typed
+			if len(msg.String()) == 1 || (msg.Type == tea.KeyRunes && !msg.Alt) {
+				return m.handleCharInput(string(msg.Runes))
 			}
 		}
 
+	case clipboardPasteMsg:
+		if msg.Err != nil {
+			m.err = msg.Err
+			return m, nil
+		}
+		m.err = nil
+		return m.insertText(msg.Text), nil
+
 	case WalletValidatedMsg:
 		m.previewAddress = msg.Address
+		m.reimportID = msg.ReimportID
+		m.privateKey = msg.PrivateKey
+		m.watchWallets = msg.WatchOnly
 		m.mnemonicValid = true
 		m.mnemonicError = ""
 		return m, nil
@@ -143,12 +271,53 @@ func (m WalletImportModel) handleEnter() (WalletImportModel, tea.Cmd) {
 		}
 
 	case StepMnemonicInput:
-		if m.validateAllWords() {
+		if m.source == ImportFromPrivateKey {
+			if !m.validatePrivateKey() {
+				return m, nil
+			}
 			m.step = StepMnemonicValidation
+			m.mnemonicValid = false
+			m.mnemonicError = ""
+			return m, m.validateAndPreviewWallet()
+		}
+		if m.source == ImportFromKeystore {
+			if strings.TrimSpace(m.keystorePath) == "" {
+				m.keystoreField = keystoreFieldPath
+				return m, nil
+			}
+			m.step = StepMnemonicValidation
+			m.mnemonicValid = false
+			m.mnemonicError = ""
+			return m, m.validateAndPreviewWallet()
+		}
+		if m.source == ImportFromShares {
+			return m.addShare()
+		}
+		if m.source == ImportWatchOnly {
+			if strings.TrimSpace(m.watchInput) == "" {
+				return m, nil
+			}
+			m.step = StepMnemonicValidation
+			m.mnemonicValid = false
+			m.mnemonicError = ""
 			return m, m.validateAndPreviewWallet()
 		}
+		if m.validateAllWords() {
+			m.step = StepPassphraseInput
+		}
+
+	case StepPassphraseInput:
+		m.step = StepMnemonicValidation
+		m.mnemonicValid = false
+		m.mnemonicError = ""
+		return m, m.validateAndPreviewWallet()
 
 	case StepMnemonicValidation:
+		// Watch-only wallets hold no secret, so there is no password to set
+		if m.mnemonicValid && m.source == ImportWatchOnly {
+			m.step = StepImporting
+			return m, m.importWallet()
+		}
 		if m.mnemonicValid {
 			m.step = StepPasswordSetup
 		}
@@ -180,11 +349,47 @@ func (m WalletImportModel) handleBackspace() (WalletImportModel, tea.Cmd) {
 		}
 
 	case StepMnemonicInput:
-		if len(m.mnemonicWords[m.currentWordIdx]) > 0 {
-			m.mnemonicWords[m.currentWordIdx] = m.mnemonicWords[m.currentWordIdx][:len(m.mnemonicWords[m.currentWordIdx])-1]
+		if m.source == ImportFromPrivateKey {
+			if len(m.privateKeyHex) > 0 {
+				m.privateKeyHex = m.privateKeyHex[:len(m.privateKeyHex)-1]
+				m.keyError = ""
+			}
+			return m, nil
+		}
+		if m.source == ImportFromKeystore {
+			field := m.keystoreFieldValue()
+			if len(*field) > 0 {
+				*field = (*field)[:len(*field)-1]
+			}
+			return m, nil
+		}
+		if m.source == ImportWatchOnly {
+			if len(m.watchInput) > 0 {
+				m.watchInput = m.watchInput[:len(m.watchInput)-1]
+			}
+			return m, nil
+		}
+		if m.source == ImportFromShares {
+			// An empty line takes back the last share entered
+			if len(m.shareInput) > 0 {
+				m.shareInput = m.shareInput[:len(m.shareInput)-1]
+			} else if len(m.shares) > 0 {
+				m.shares = m.shares[:len(m.shares)-1]
+				m.shareStatus = ""
+			}
+			m.shareError = ""
+			return m, nil
+		}
+		if word := []rune(m.mnemonicWords[m.currentWordIdx]); len(word) > 0 {
+			m.mnemonicWords[m.currentWordIdx] = string(word[:len(word)-1])
 			m.validateWord(m.currentWordIdx)
 		}
 
+	case StepPassphraseInput:
+		if len(m.passphrase) > 0 {
+			m.passphrase = m.passphrase[:len(m.passphrase)-1]
+		}
+
 	case StepPasswordSetup:
 		if len(m.password) > 0 {
 			m.password = m.password[:len(m.password)-1]
@@ -208,9 +413,30 @@ func (m WalletImportModel) handleCharInput(char string) (WalletImportModel, tea.
 		m.validateName()
 
 	case StepMnemonicInput:
+		if m.source == ImportFromPrivateKey {
+			m.privateKeyHex += char
+			m.keyError = ""
+			return m, nil
+		}
+		if m.source == ImportFromKeystore {
+			*m.keystoreFieldValue() += char
+			return m, nil
+		}
+		if m.source == ImportWatchOnly {
+			m.watchInput += char
+			return m, nil
+		}
+		if m.source == ImportFromShares {
+			m.shareInput += char
+			m.shareError = ""
+			return m, nil
+		}
 		m.mnemonicWords[m.currentWordIdx] += char
 		m.validateWord(m.currentWordIdx)
 
+	case StepPassphraseInput:
+		m.passphrase += char
+
 	case StepPasswordSetup:
 		m.password += char
 		m.validatePassword()
@@ -223,12 +449,164 @@ func (m WalletImportModel) handleCharInput(char string) (WalletImportModel, tea.
 	return m, nil
 }
 
-func (m WalletImportModel) handlePaste() (WalletImportModel, tea.Cmd) {
-	// Note: In a real implementation, you'd get clipboard content
-	// For now, this is a placeholder for paste functionality
+// fromRecoveryPhrase reports whether the import ends in a recovery phrase,
+// typed in or restored from shares
+func (m WalletImportModel) fromRecoveryPhrase() bool {
+	return m.source == ImportFromMnemonic || m.source == ImportFromShares
+}
+
+// addShare takes the typed share. Once enough shares are in, the recovery
+// phrase they restore fills the word grid and the import carries on from
+// the passphrase step as for a typed phrase.
+func (m WalletImportModel) addShare() (WalletImportModel, tea.Cmd) {
+	shares := m.shares
+	if share := strings.Join(strings.Fields(strings.ToLower(m.shareInput)), " "); share != "" {
+		if _, err := slip39.ParseShare(share); err != nil {
+			m.shareError = err.Error()
+			return m, nil
+		}
+		for _, entered := range m.shares {
+			if entered == share {
+				m.shareError = "This share has already been entered"
+				return m, nil
+			}
+		}
+		shares = append(append([]string{}, m.shares...), share)
+	}
+	if len(shares) == 0 {
+		return m, nil
+	}
+
+	mnemonic, err := models.MnemonicFromShares(shares)
+	if err != nil && !errors.Is(err, slip39.ErrNotEnoughShares) {
+		m.shareError = err.Error()
+		return m, nil
+	}
+	m.shares = shares
+	m.shareInput = ""
+	m.shareError = ""
+	if err != nil {
+		m.shareStatus = err.Error()
+		return m, nil
+	}
+
+	words := utils.SplitMnemonic(mnemonic)
+	m.setWordCount(len(words))
+	for i := range m.mnemonicWords {
+		m.mnemonicWords[i] = ""
+		if i < len(words) {
+			m.mnemonicWords[i] = words[i]
+		}
+	}
+	m.validateWord(0)
+	m.shareStatus = "Recovery phrase restored"
+	m.step = StepPassphraseInput
 	return m, nil
 }
 
+// setWordCount switches between 12 and 24 word phrases, dropping words past
+// the new length
+func (m *WalletImportModel) setWordCount(count int) {
+	m.wordCount = count
+	for i := count; i < len(m.mnemonicWords); i++ {
+		m.mnemonicWords[i] = ""
+		m.wordsValid[i] = false
+		m.wordErrors[i] = ""
+	}
+	if m.currentWordIdx >= count {
+		m.currentWordIdx = count - 1
+	}
+}
+
+// keystoreFieldValue returns the keystore input that has focus
+func (m *WalletImportModel) keystoreFieldValue() *string {
+	if m.keystoreField == keystoreFieldPassword {
+		return &m.keystorePassword
+	}
+	return &m.keystorePath
+}
+
+func (m WalletImportModel) handlePaste() (WalletImportModel, tea.Cmd) {
+	if !m.IsEditing() {
+		return m, nil
+	}
+	return m, func() tea.Msg {
+		text, err := utils.ReadFromClipboard()
+		return clipboardPasteMsg{Text: text, Err: err}
+	}
+}
+
+// insertText adds pasted text to the focused input. A pasted recovery phrase
+// fills the word slots from the first one.
+func (m WalletImportModel) insertText(text string) WalletImportModel {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return m
+	}
+
+	switch m.step {
+	case StepImportName:
+		m.name += text
+		m.validateName()
+
+	case StepMnemonicInput:
+		switch m.source {
+		case ImportFromPrivateKey:
+			m.privateKeyHex += strings.Join(strings.Fields(text), "")
+			m.keyError = ""
+		case ImportFromKeystore:
+			*m.keystoreFieldValue() += text
+		case ImportWatchOnly:
+			m.watchInput += text
+		case ImportFromShares:
+			m.shareInput += strings.Join(strings.Fields(text), " ")
+			m.shareError = ""
+		default:
+			words := strings.Fields(text)
+			if len(words) == 1 {
+				m.mnemonicWords[m.currentWordIdx] += words[0]
+				m.validateWord(m.currentWordIdx)
+				break
+			}
+			if len(words) > 12 {
+				m.setWordCount(24)
+			} else {
+				m.setWordCount(12)
+			}
+			for i := range m.mnemonicWords {
+				m.mnemonicWords[i] = ""
+				if i < len(words) && i < m.wordCount {
+					m.mnemonicWords[i] = strings.ToLower(words[i])
+				}
+				m.validateWord(i)
+			}
+			m.currentWordIdx = min(len(words), m.wordCount) - 1
+		}
+
+	case StepPassphraseInput:
+		m.passphrase += text
+
+	case StepPasswordSetup:
+		m.password += text
+		m.validatePassword()
+
+	case StepPasswordConfirm:
+		m.confirm += text
+		m.validateConfirmPassword()
+	}
+
+	return m
+}
+
+func (m *WalletImportModel) validatePrivateKey() bool {
+	if _, err := models.ParsePrivateKey(m.privateKeyHex); err != nil {
+		m.keyError = err.Error()
+		return false
+	}
+	m.keyError = ""
+	return true
+}
+
 func (m *WalletImportModel) validateName() bool {
 	issues := utils.ValidateWalletName(m.name)
 	if len(issues) > 0 {
@@ -241,22 +619,74 @@ func (m *WalletImportModel) validateName() bool {
 	return true
 }
 
+// validateWord checks the word at index against the wordlist detected from
+// every word entered. A change can move the detected language, so all words
+// are checked again.
 func (m *WalletImportModel) validateWord(index int) bool {
-	word := strings.ToLower(strings.TrimSpace(m.mnemonicWords[index]))
-	if word == "" {
-		m.wordsValid[index] = false
+	words := m.mnemonicWords[:m.wordCount]
+	m.language, m.languageKnown = models.DetectMnemonicLanguage(words)
+
+	for i, word := range words {
+		m.wordsValid[i] = false
+		m.wordErrors[i] = ""
+		if strings.TrimSpace(word) == "" {
+			continue
+		}
+		if err := models.CheckMnemonicWord(m.language, word); err != nil {
+			m.wordErrors[i] = err.Error()
+			continue
+		}
+		m.wordsValid[i] = true
+	}
+	return m.wordsValid[index]
+}
+
+// wordSuggestions returns the words that complete the focused word, from the
+// detected wordlist or from every list while none is detected
+func (m WalletImportModel) wordSuggestions() []string {
+	word := strings.TrimSpace(m.mnemonicWords[m.currentWordIdx])
+	if word == "" || m.wordsValid[m.currentWordIdx] {
+		return nil
+	}
+	if !m.languageKnown {
+		return models.CompleteMnemonicWord("", word)
+	}
+	return models.CompleteMnemonicWord(m.language, word)
+}
+
+// completeWord completes the focused word for Tab. A single match fills it
+// in; several extend it to the prefix they share. It reports whether the
+// word still needs typing, in which case focus stays on it.
+func (m *WalletImportModel) completeWord() bool {
+	matches := m.wordSuggestions()
+	switch len(matches) {
+	case 0:
+		return false
+	case 1:
+		m.mnemonicWords[m.currentWordIdx] = matches[0]
+		m.validateWord(m.currentWordIdx)
 		return false
 	}
 
-	// Validate against BIP39 wordlist
-	wordList := utils.ValidateMnemonicWords([]string{word})
-	m.wordsValid[index] = wordList[0]
-	return m.wordsValid[index]
+	prefix := []rune(matches[0])
+	for _, match := range matches[1:] {
+		runes := []rune(match)
+		n := 0
+		for n < len(prefix) && n < len(runes) && prefix[n] == runes[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if len(prefix) > len([]rune(m.mnemonicWords[m.currentWordIdx])) {
+		m.mnemonicWords[m.currentWordIdx] = string(prefix)
+		m.validateWord(m.currentWordIdx)
+	}
+	return !m.wordsValid[m.currentWordIdx]
 }
 
 func (m *WalletImportModel) validateAllWords() bool {
 	allValid := true
-	for i := 0; i < 12; i++ {
+	for i := 0; i < m.wordCount; i++ {
 		if !m.validateWord(i) || strings.TrimSpace(m.mnemonicWords[i]) == "" {
 			allValid = false
 		}
@@ -293,34 +723,58 @@ func (m *WalletImportModel) validateConfirmPassword() bool {
 
 func (m WalletImportModel) validateAndPreviewWallet() tea.Cmd {
 	return func() tea.Msg {
-		// Join mnemonic words
-		var words []string
-		for _, word := range m.mnemonicWords {
-			word = strings.TrimSpace(word)
-			if word != "" {
-				words = append(words, word)
+		var (
+			address    string
+			privateKey *ecdsa.PrivateKey
+			watched    []*models.Wallet
+		)
+
+		switch m.source {
+		case ImportFromPrivateKey:
+			key, err := models.ParsePrivateKey(m.privateKeyHex)
+			if err != nil {
+				return ErrorMsg{Err: err}
 			}
-		}
-		mnemonic := strings.Join(words, " ")
-
-		// Validate complete mnemonic
-		if !utils.ValidateMnemonic(mnemonic) {
-			return ErrorMsg{Err: fmt.Errorf("invalid mnemonic phrase: checksum verification failed")}
-		}
+			privateKey = key
+			address = crypto.PubkeyToAddress(key.PublicKey).Hex()
+		case ImportFromKeystore:
+			key, err := storage.ReadKeystoreV3File(m.keystorePath, m.keystorePassword)
+			if err != nil {
+				return ErrorMsg{Err: err}
+			}
+			privateKey = key
+			address = crypto.PubkeyToAddress(key.PublicKey).Hex()
+		case ImportWatchOnly:
+			wallets, err := m.readWatchInput()
+			if err != nil {
+				return ErrorMsg{Err: err}
+			}
+			watched = wallets
+			address = wallets[0].Address
+		default:
+			// Join mnemonic words
+			var words []string
+			for _, word := range m.mnemonicWords {
+				word = strings.TrimSpace(word)
+				if word != "" {
+					words = append(words, word)
+				}
+			}
+			mnemonic := strings.Join(words, " ")
 
-		// Generate preview address
-		derivationPath, err := hdwallet.ParseDerivationPath("m/44'/818'/0'/0/0")
-		if err != nil {
-			return ErrorMsg{Err: fmt.Errorf("failed to parse derivation path: %w", err)}
-		}
+			// Validate complete mnemonic in whichever language it is in
+			if _, err := models.ValidateMnemonic(mnemonic); err != nil {
+				return ErrorMsg{Err: fmt.Errorf("invalid mnemonic phrase: %w", err)}
+			}
 
-		hdWallet, err := hdwallet.FromMnemonicAt(mnemonic, derivationPath)
-		if err != nil {
-			return ErrorMsg{Err: fmt.Errorf("failed to create wallet from mnemonic: %w", err)}
+			// Generate preview address for the first account
+			_, derived, err := models.DeriveAccount(mnemonic, m.passphrase, models.AccountPath(0))
+			if err != nil {
+				return ErrorMsg{Err: fmt.Errorf("failed to create wallet from mnemonic: %w", err)}
+			}
+			address = derived
 		}
 
-		address := hdWallet.Address().Hex()
-
 		// Check for duplicate wallet
 		if m.storage != nil {
 			wallets, err := m.storage.ListWallets()
@@ -329,41 +783,100 @@ func (m WalletImportModel) validateAndPreviewWallet() tea.Cmd {
 			}
 
 			for _, wallet := range wallets {
-				if wallet.Address == address {
+				for _, w := range watched {
+					if strings.EqualFold(wallet.Address, w.Address) {
+						return ErrorMsg{Err: fmt.Errorf("wallet with this address already exists: %s (%s)", w.Address, wallet.Name)}
+					}
+				}
+				if strings.EqualFold(wallet.Address, address) {
+					if wallet.NeedsReimport() {
+						return WalletValidatedMsg{Address: address, ReimportID: wallet.ID, PrivateKey: privateKey}
+					}
 					return ErrorMsg{Err: fmt.Errorf("wallet with this address already exists: %s", address)}
 				}
 			}
 		}
 
-		return WalletValidatedMsg{Address: address}
+		return WalletValidatedMsg{Address: address, PrivateKey: privateKey, WatchOnly: watched}
 	}
 }
 
+// readWatchInput reads the watch-only input as a single address, or else as
+// the path of a file listing addresses
+func (m WalletImportModel) readWatchInput() ([]*models.Wallet, error) {
+	input := strings.TrimSpace(m.watchInput)
+	if strings.HasPrefix(input, "0x") && !strings.ContainsAny(input, "/\\") {
+		wallet, err := models.NewWatchOnlyWallet(m.name, input)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Wallet{wallet}, nil
+	}
+
+	return storage.ReadWatchListFile(input, m.name)
+}
+
 type WalletValidatedMsg struct {
-	Address string
+	Address    string
+	ReimportID string
+	// PrivateKey is the key from a private key or keystore file import
+	PrivateKey *ecdsa.PrivateKey
+	// WatchOnly holds the wallets of a watch-only import
+	WatchOnly []*models.Wallet
 }
 
 func (m WalletImportModel) importWallet() tea.Cmd {
+	if m.source == ImportWatchOnly {
+		return m.importWatchOnly()
+	}
+
 	return func() tea.Msg {
-		// Join mnemonic words
-		var words []string
-		for _, word := range m.mnemonicWords {
-			word = strings.TrimSpace(word)
-			if word != "" {
-				words = append(words, word)
+		var (
+			wallet *models.Wallet
+			err    error
+		)
+
+		if m.fromRecoveryPhrase() {
+			// Join mnemonic words
+			var words []string
+			for _, word := range m.mnemonicWords {
+				word = strings.TrimSpace(word)
+				if word != "" {
+					words = append(words, word)
+				}
 			}
-		}
-		mnemonic := strings.Join(words, " ")
 
-		// Create wallet from mnemonic
-		wallet, err := models.NewWallet(m.name, mnemonic)
+			// Create wallet from mnemonic
+			wallet, err = models.NewWalletWithPassphrase(m.name, strings.Join(words, " "), m.passphrase)
+		} else {
+			wallet, err = models.NewWalletFromPrivateKey(m.name, m.privateKey)
+		}
 		if err != nil {
 			return ErrorMsg{Err: fmt.Errorf("failed to create wallet: %w", err)}
 		}
+		if m.source == ImportFromKeystore {
+			wallet.Source = models.KeySourceKeystore
+		}
+
+		// Find derived accounts that have been used before saving, so they
+		// are stored with the wallet
+		var discoveryErr error
+		if wallet.Source.CanDeriveAccounts() && m.blockchainClient != nil {
+			accounts, err := models.DiscoverAccounts(wallet.Mnemonic, wallet.Passphrase, m.gapLimit, m.addressUsed)
+			if len(accounts) > 1 {
+				wallet.Accounts = accounts
+			}
+			discoveryErr = err
+		}
 
 		// Save encrypted wallet
 		if m.storage != nil {
-			if err := m.storage.SaveWallet(wallet, m.password); err != nil {
+			if m.reimportID != "" {
+				err = m.storage.ReimportWallet(m.reimportID, wallet, m.password)
+			} else {
+				err = m.storage.SaveWallet(wallet, m.password)
+			}
+			if err != nil {
 				return ErrorMsg{Err: fmt.Errorf("failed to save wallet: %w", err)}
 			}
 		}
@@ -371,11 +884,33 @@ func (m WalletImportModel) importWallet() tea.Cmd {
 		// Clear sensitive data from memory
 		m.password = ""
 		m.confirm = ""
+		m.keystorePassword = ""
+		m.privateKeyHex = ""
+		m.passphrase = ""
+		m.privateKey = nil
 		for i := range m.mnemonicWords {
 			m.mnemonicWords[i] = ""
 		}
+		for i := range m.shares {
+			m.shares[i] = ""
+		}
+
+		return WalletImportedMsg{Wallet: wallet, Imported: 1, DiscoveryErr: discoveryErr}
+	}
+}
 
-		return WalletImportedMsg{Wallet: wallet}
+func (m WalletImportModel) importWatchOnly() tea.Cmd {
+	wallets := m.watchWallets
+	return func() tea.Msg {
+		if len(wallets) == 0 {
+			return ErrorMsg{Err: fmt.Errorf("no addresses to watch")}
+		}
+		if m.storage != nil {
+			if err := m.storage.SaveWatchOnlyWallets(wallets); err != nil {
+				return ErrorMsg{Err: fmt.Errorf("failed to save wallet: %w", err)}
+			}
+		}
+		return WalletImportedMsg{Wallet: wallets[0], Imported: len(wallets)}
 	}
 }
 
@@ -408,7 +943,7 @@ func (m WalletImportModel) View() string {
 	content += titleStyle.Render("Import Wallet") + "\n\n"
 
 	// Progress indicator
-	progress := fmt.Sprintf("Step %d of 6", int(m.step)+1)
+	progress := fmt.Sprintf("Step %d of 7", int(m.step)+1)
 	if m.step >= StepImporting {
 		progress = "Importing wallet..."
 	}
@@ -425,13 +960,35 @@ func (m WalletImportModel) View() string {
 				content += errorStyle.Render("✗ "+m.nameError) + "\n"
 			}
 		}
-		content += "\n" + helpStyle.Render("Enter a descriptive name for your wallet")
+		content += "\n" + labelStyle.Render("Import From:") + "\n"
+		content += inputStyle.Render(m.sourceLabel()) + "\n"
+		content += "\n" + helpStyle.Render("Enter a descriptive name for your wallet, Tab to switch import source")
 
 	case StepMnemonicInput:
-		content += labelStyle.Render("Recovery Phrase (12 words):") + "\n\n"
+		if m.source == ImportFromPrivateKey {
+			content += m.renderPrivateKeyInput(labelStyle, inputStyle, errorStyle, helpStyle)
+			break
+		}
+		if m.source == ImportFromKeystore {
+			content += m.renderKeystoreInput(labelStyle, inputStyle, helpStyle)
+			break
+		}
+		if m.source == ImportFromShares {
+			content += m.renderShareInput(labelStyle, inputStyle, successStyle, errorStyle, helpStyle)
+			break
+		}
+		if m.source == ImportWatchOnly {
+			content += labelStyle.Render("Address or Address List File:") + "\n"
+			content += inputStyle.Render(m.watchInput+"_") + "\n\n"
+			content += helpStyle.Render("Enter one 0x address, or the path of a file with one address per line (optionally followed by a name).") + "\n"
+			content += helpStyle.Render("No key is stored: the wallet can show balances and history and export unsigned transactions, but never sign.")
+			break
+		}
+
+		content += labelStyle.Render(fmt.Sprintf("Recovery Phrase (%d words):", m.wordCount)) + "\n\n"
 
-		// Display mnemonic input grid (3x4)
-		for row := 0; row < 3; row++ {
+		// Display mnemonic input grid, four words per row
+		for row := 0; row < m.wordCount/4; row++ {
 			for col := 0; col < 4; col++ {
 				wordIdx := row*4 + col
 				word := m.mnemonicWords[wordIdx]
@@ -450,9 +1007,13 @@ func (m WalletImportModel) View() string {
 					}
 				}
 
+				// The focused word shows the rest of its first completion
 				cursor := ""
 				if wordIdx == m.currentWordIdx {
 					cursor = "_"
+					if suggestions := m.wordSuggestions(); len(suggestions) > 0 && strings.HasPrefix(suggestions[0], word) {
+						cursor += strings.TrimPrefix(suggestions[0], word)
+					}
 				}
 
 				wordDisplay := fmt.Sprintf("%2d. %-8s", wordIdx+1, word+cursor)
@@ -461,21 +1022,76 @@ func (m WalletImportModel) View() string {
 			content += "\n"
 		}
 
-		content += "\n" + helpStyle.Render("Use Tab to move between words, Ctrl+V to paste complete phrase")
+		content += "\n"
+		if m.languageKnown {
+			content += labelStyle.Render("Wordlist: "+m.language.Label()+" (detected)") + "\n"
+		} else {
+			content += helpStyle.Render("Wordlist: detected from the first word you enter") + "\n"
+		}
+		if suggestions := m.wordSuggestions(); len(suggestions) > 0 {
+			if len(suggestions) > 6 {
+				suggestions = append(suggestions[:6], "…")
+			}
+			content += helpStyle.Render("Suggestions: "+strings.Join(suggestions, "  ")) + "\n"
+		}
+		// The focused word is only an error once nothing can complete it
+		for i, wordErr := range m.wordErrors[:m.wordCount] {
+			if wordErr != "" && (i != m.currentWordIdx || len(m.wordSuggestions()) == 0) {
+				content += errorStyle.Render(fmt.Sprintf("✗ Word %d: %s", i+1, wordErr)) + "\n"
+				break
+			}
+		}
 
-	case StepMnemonicValidation:
-		content += labelStyle.Render("Validating Recovery Phrase...") + "\n\n"
+		content += "\n" + helpStyle.Render("Tab completes the word or moves to the next, Ctrl+V to paste complete phrase, Ctrl+L to switch between 12 and 24 words")
 
-		if m.mnemonicValid {
-			content += successStyle.Render("✓ Valid recovery phrase") + "\n"
+	case StepPassphraseInput:
+		content += labelStyle.Render("BIP39 Passphrase (optional):") + "\n"
+		content += inputStyle.Render(hidePassword(m.passphrase)+"_") + "\n\n"
+		content += helpStyle.Render("If the phrase was protected with a passphrase (\"25th word\"), enter it exactly.") + "\n"
+		content += helpStyle.Render("A different passphrase opens a different wallet. Leave empty for none and press Enter.")
+
+	case StepMnemonicValidation:
+		subject := "recovery phrase"
+		switch m.source {
+		case ImportFromPrivateKey:
+			subject = "private key"
+		case ImportFromKeystore:
+			subject = "keystore file"
+		case ImportWatchOnly:
+			subject = "address"
+			if len(m.watchWallets) > 1 {
+				subject = "address list"
+			}
+		}
+		content += labelStyle.Render("Validating "+subject+"...") + "\n\n"
+
+		if m.mnemonicValid && m.source == ImportWatchOnly {
+			content += successStyle.Render("✓ Valid "+subject) + "\n"
+			content += labelStyle.Render("Watch-only Wallets:") + "\n"
+			for i, wallet := range m.watchWallets {
+				if i == 10 {
+					content += helpStyle.Render(fmt.Sprintf("...and %d more", len(m.watchWallets)-i)) + "\n"
+					break
+				}
+				content += inputStyle.Render(fmt.Sprintf("%s  %s", wallet.Address, wallet.Name)) + "\n"
+			}
+			content += "\n" + helpStyle.Render("Press Enter to save (no password is needed)")
+		} else if m.mnemonicValid {
+			content += successStyle.Render("✓ Valid "+subject) + "\n"
 			content += labelStyle.Render("Wallet Address Preview:") + "\n"
 			content += inputStyle.Render(m.previewAddress) + "\n\n"
+			if m.fromRecoveryPhrase() && m.passphrase != "" {
+				content += helpStyle.Render("Derived with your passphrase. Check this is the address you expect.") + "\n\n"
+			}
+			if m.reimportID != "" {
+				content += successStyle.Render("This restores the signing key for an existing wallet.") + "\n\n"
+			}
 			content += helpStyle.Render("Press Enter to continue with password setup")
 		} else if m.mnemonicError != "" {
 			content += errorStyle.Render("✗ "+m.mnemonicError) + "\n\n"
-			content += helpStyle.Render("Press Esc to go back and fix the recovery phrase")
+			content += helpStyle.Render("Press Esc to go back and fix the " + subject)
 		} else {
-			content += helpStyle.Render("Validating recovery phrase and checking for duplicates...")
+			content += helpStyle.Render("Validating " + subject + " and checking for duplicates...")
 		}
 
 	case StepPasswordSetup:
@@ -503,6 +1119,13 @@ func (m WalletImportModel) View() string {
 		content += "\n" + helpStyle.Render("Re-enter your password to confirm")
 
 	case StepImporting:
+		if m.source == ImportWatchOnly {
+			content += helpStyle.Render("Saving watch-only wallets...")
+			break
+		}
+		if m.fromRecoveryPhrase() && m.blockchainClient != nil {
+			content += helpStyle.Render("Discovering used accounts...") + "\n"
+		}
 		content += helpStyle.Render("Importing and encrypting your wallet...") + "\n"
 		content += helpStyle.Render("Please wait...")
 
@@ -520,3 +1143,76 @@ func (m WalletImportModel) View() string {
 
 	return content
 }
+
+func (m WalletImportModel) sourceLabel() string {
+	switch m.source {
+	case ImportFromPrivateKey:
+		return "Private key (64 hex characters)"
+	case ImportFromKeystore:
+		return "Keystore file (v3 JSON)"
+	case ImportWatchOnly:
+		return "Watch-only address (no key)"
+	case ImportFromShares:
+		return "SLIP-39 recovery shares"
+	default:
+		return "Recovery phrase (12 or 24 words)"
+	}
+}
+
+func (m WalletImportModel) renderPrivateKeyInput(labelStyle, inputStyle, errorStyle, helpStyle lipgloss.Style) string {
+	digits := len(strings.TrimPrefix(strings.TrimPrefix(m.privateKeyHex, "0x"), "0X"))
+
+	var content string
+	content += labelStyle.Render("Private Key:") + "\n"
+	content += inputStyle.Render(hidePassword(m.privateKeyHex)+"_") + "\n"
+	content += helpStyle.Render(fmt.Sprintf("%d/64 hex characters", digits)) + "\n"
+	if m.keyError != "" {
+		content += errorStyle.Render("✗ "+m.keyError) + "\n"
+	}
+	content += "\n" + helpStyle.Render("Type or paste (Ctrl+V) the key, with or without 0x. It is never shown on screen.")
+	return content
+}
+
+func (m WalletImportModel) renderKeystoreInput(labelStyle, inputStyle, helpStyle lipgloss.Style) string {
+	focused := inputStyle.Copy().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color(utils.Colours.Blue))
+
+	pathStyle, passwordStyle := inputStyle, inputStyle
+	pathCursor, passwordCursor := "", ""
+	if m.keystoreField == keystoreFieldPath {
+		pathStyle, pathCursor = focused, "_"
+	} else {
+		passwordStyle, passwordCursor = focused, "_"
+	}
+
+	var content string
+	content += labelStyle.Render("Keystore File Path:") + "\n"
+	content += pathStyle.Render(m.keystorePath+pathCursor) + "\n\n"
+	content += labelStyle.Render("Keystore Password:") + "\n"
+	content += passwordStyle.Render(hidePassword(m.keystorePassword)+passwordCursor) + "\n\n"
+	content += helpStyle.Render("The password that protects the keystore file. Tab to switch fields, Enter to decrypt")
+	return content
+}
+
+func (m WalletImportModel) renderShareInput(labelStyle, inputStyle, successStyle, errorStyle, helpStyle lipgloss.Style) string {
+	var content string
+	for i, share := range m.shares {
+		words := strings.Fields(share)
+		content += successStyle.Render(fmt.Sprintf("✓ Share %d: %s ... %s (%d words)", i+1, strings.Join(words[:2], " "), words[len(words)-1], len(words))) + "\n"
+	}
+	if m.shareStatus != "" {
+		content += helpStyle.Render(m.shareStatus) + "\n"
+	}
+	if len(m.shares) > 0 {
+		content += "\n"
+	}
+
+	content += labelStyle.Render(fmt.Sprintf("Share %d:", len(m.shares)+1)) + "\n"
+	content += inputStyle.Render(m.shareInput+"_") + "\n"
+	if m.shareError != "" {
+		content += errorStyle.Render("✗ "+m.shareError) + "\n"
+	}
+
+	content += "\n" + helpStyle.Render("Type or paste one share's words (20 or 33) and press Enter; repeat until enough shares are in.") + "\n"
+	content += helpStyle.Render("Each share's checksum is checked as it is entered. Backspace on an empty line removes the last share.")
+	return content
+}
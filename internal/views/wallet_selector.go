@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"rhystmorgan/veWallet/internal/models"
 	"rhystmorgan/veWallet/internal/storage"
 	"rhystmorgan/veWallet/internal/utils"
 )
@@ -16,6 +17,11 @@ type WalletSelectorModel struct {
 	selected int
 }
 
+// WalletSelectedMsg asks the app to unlock a stored wallet
+type WalletSelectedMsg struct {
+	Wallet storage.EncryptedWallet
+}
+
 func NewWalletSelectorModel(wallets []storage.EncryptedWallet) *WalletSelectorModel {
 	return &WalletSelectorModel{
 		wallets:  wallets,
@@ -24,6 +30,16 @@ func NewWalletSelectorModel(wallets []storage.EncryptedWallet) *WalletSelectorMo
 	}
 }
 
+// SetDefaultWallet places the cursor on the configured default wallet
+func (m *WalletSelectorModel) SetDefaultWallet(id string) {
+	for i, wallet := range m.wallets {
+		if wallet.ID == id {
+			m.cursor = i
+			return
+		}
+	}
+}
+
 func (m WalletSelectorModel) Init() tea.Cmd {
 	return nil
 }
@@ -43,11 +59,38 @@ func (m WalletSelectorModel) Update(msg tea.Msg) (WalletSelectorModel, tea.Cmd)
 		case "enter", " ":
 			if m.cursor < len(m.wallets) {
 				m.selected = m.cursor
+				wallet := m.wallets[m.cursor]
+				return m, func() tea.Msg { return WalletSelectedMsg{Wallet: wallet} }
 			} else if m.cursor == len(m.wallets) {
 				return m, NavigateTo(ViewWalletCreate, nil)
 			} else if m.cursor == len(m.wallets)+1 {
 				return m, NavigateTo(ViewWalletImport, nil)
 			}
+		case "p":
+			return m, NavigateTo(ViewProfileSelector, nil)
+		case "b":
+			return m, NavigateTo(ViewBackup, nil)
+		case "x":
+			if m.cursor < len(m.wallets) {
+				if m.wallets[m.cursor].IsWatchOnly() {
+					return m, ShowError(fmt.Errorf("%s: %w", m.wallets[m.cursor].Name, models.ErrWatchOnly))
+				}
+				return m, NavigateTo(ViewKeystoreExport, m.wallets[m.cursor])
+			}
+		case "s":
+			if m.cursor < len(m.wallets) {
+				if m.wallets[m.cursor].IsWatchOnly() {
+					return m, ShowError(fmt.Errorf("%s: %w", m.wallets[m.cursor].Name, models.ErrWatchOnly))
+				}
+				return m, NavigateTo(ViewShamirBackup, m.wallets[m.cursor])
+			}
+		case "c":
+			if m.cursor < len(m.wallets) {
+				if m.wallets[m.cursor].IsWatchOnly() {
+					return m, ShowError(fmt.Errorf("%s: %w", m.wallets[m.cursor].Name, models.ErrWatchOnly))
+				}
+				return m, NavigateTo(ViewPasswordChange, m.wallets[m.cursor])
+			}
 		}
 	}
 	return m, nil
@@ -79,6 +122,14 @@ func (m WalletSelectorModel) View() string {
 			Foreground(lipgloss.Color(utils.Colours.Text)).
 			Render("Select a wallet:") + "\n\n"
 
+		// Watch-only wallets get a badge of their own so they are never
+		// mistaken for wallets that can sign
+		watchBadgeStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(utils.Colours.Base)).
+			Background(lipgloss.Color(utils.Colours.Peach)).
+			Bold(true).
+			Padding(0, 1)
+
 		for i, wallet := range m.wallets {
 			cursor := " "
 			if m.cursor == i {
@@ -90,7 +141,18 @@ func (m WalletSelectorModel) View() string {
 				style = selectedStyle
 			}
 
-			content += style.Render(fmt.Sprintf("%s %s (%s)", cursor, wallet.Name, wallet.Address[:10]+"...")) + "\n"
+			label := fmt.Sprintf("%s %s (%s)", cursor, wallet.Name, wallet.Address[:10]+"...")
+			if wallet.Source != "" && !wallet.IsWatchOnly() {
+				label += fmt.Sprintf(" [%s]", wallet.Source.Label())
+			}
+			if wallet.NeedsReimport() {
+				label += " - re-import required"
+			}
+			content += style.Render(label)
+			if wallet.IsWatchOnly() {
+				content += " " + watchBadgeStyle.Render("WATCH-ONLY")
+			}
+			content += "\n"
 		}
 		content += "\n"
 	}
@@ -119,7 +181,7 @@ func (m WalletSelectorModel) View() string {
 		Foreground(lipgloss.Color(utils.Colours.Subtext0)).
 		Italic(true)
 
-	content += helpStyle.Render("Use ↑/↓ to navigate, Enter to select, q to quit")
+	content += helpStyle.Render("Use ↑/↓ to navigate, Enter to select, x to export keystore, s to split into recovery shares, c to change password, b to back up or restore, p to switch profile, q to quit")
 
 	return content
 }
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/x/term"
+
+	"rhystmorgan/veWallet/internal/config"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+// runBackup writes the profile's data to an encrypted backup file
+func runBackup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ContinueOnError)
+	out := fs.String("out", "", "backup file to write (default <profile dir>/exports/veterm-backup--<time>--<profile>.vtbak)")
+	var recipients stringList
+	fs.Var(&recipients, "recipient", "encrypt with age to this X25519 public key (age1...); repeat for several")
+	useAge := fs.Bool("age", false, "encrypt with an age passphrase instead of the veterm envelope")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	if len(recipients) > 0 {
+		path, err := store.CreateAgeBackup(*out, storage.AgeOptions{Recipients: recipients})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backup of profile %s written to %s for %d age recipients\n", store.Profile(), path, len(recipients))
+		return nil
+	}
+
+	password, err := readPassword("Backup password: ")
+	if err != nil {
+		return err
+	}
+	if strength, issues := utils.ValidatePassword(password); strength == utils.PasswordWeak {
+		return fmt.Errorf("backup password is too weak: %s", strings.Join(issues, ", "))
+	}
+	confirm, err := readPassword("Confirm backup password: ")
+	if err != nil {
+		return err
+	}
+	if confirm != password {
+		return fmt.Errorf("passwords do not match")
+	}
+
+	var path string
+	if *useAge {
+		path, err = store.CreateAgeBackup(*out, storage.AgeOptions{Passphrase: password})
+	} else {
+		path, err = store.CreateBackup(*out, password)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backup of profile %s written to %s\n", store.Profile(), path)
+	return nil
+}
+
+// runRestore previews a backup against the profile and, once confirmed,
+// restores the selected sections
+func runRestore(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	only := fs.String("only", "", "comma-separated sections to restore: wallets, contacts, history, templates, schedules, policies, config, audit (default all)")
+	dryRun := fs.Bool("dry-run", false, "verify the backup and show the preview without restoring")
+	yes := fs.Bool("yes", false, "restore without asking for confirmation")
+	identity := fs.String("identity", "", "age identity file for backups encrypted to recipients")
+	profile := registerProfileFlags(fs)
+	configOverrides := config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: veterm restore [flags] <backup file>")
+	}
+
+	sections, err := storage.ParseBackupSections(*only)
+	if err != nil {
+		return err
+	}
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	keys := storage.AgeKeys{IdentityFile: *identity}
+	if keys.IdentityFile == "" {
+		if keys.Passphrase, err = readPassword("Backup password: "); err != nil {
+			return err
+		}
+	}
+	backup, err := storage.OpenBackupWith(fs.Arg(0), keys)
+	if errors.Is(err, storage.ErrInvalidPassword) {
+		return fmt.Errorf("incorrect backup password or age identity")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backup of profile %s from %s verified (%d files)\n\n", backup.Profile, backup.CreatedAt.Local().Format("2006-01-02 15:04"), len(backup.Files()))
+
+	plan, err := store.PlanRestore(backup, sections)
+	if err != nil {
+		return err
+	}
+	printRestorePlan(plan)
+
+	changes := plan.Count(storage.RestoreAdd) + plan.Count(storage.RestoreOverwrite)
+	if *dryRun || changes == 0 {
+		return nil
+	}
+	if !*yes {
+		answer, err := readLine(fmt.Sprintf("\nRestore into profile %s? [y/N] ", store.Profile()))
+		if err != nil {
+			return err
+		}
+		if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			fmt.Println("Restore cancelled")
+			return nil
+		}
+	}
+
+	var verify func(walletID string) error
+	if len(plan.SecondFactor) > 0 {
+		// Wrong codes count toward the wallet's lockout, as they do when the
+		// policy or second factor is changed directly
+		sessionManager := security.NewSessionManager(store)
+		defer sessionManager.Shutdown()
+		securityManager := security.NewSecurityManager(sessionManager)
+		if err := applySecurityLevel(store, configOverrides(), securityManager); err != nil {
+			return err
+		}
+		verify = func(walletID string) error {
+			return verifyRestore(store, securityManager, walletID)
+		}
+	}
+	if _, err := store.Restore(backup, sections, verify); err != nil {
+		return err
+	}
+	fmt.Printf("Restored %d items; replaced files were kept in the backups directory\n", changes)
+	return nil
+}
+
+// verifyRestore asks for the password and authentication code of a wallet
+// whose spending policy or second factor a restore changes
+func verifyRestore(store *storage.Storage, securityManager *security.SecurityManager, walletID string) error {
+	record, err := findWallet(store, walletID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nRestoring changes the spending policy or second factor of %s\n", record.Name)
+	password, err := readPassword("Wallet password: ")
+	if err != nil {
+		return err
+	}
+	wallet, err := store.LoadWallet(walletID, password)
+	if err != nil {
+		return err
+	}
+	code, err := readPassword("Authentication code: ")
+	if err != nil {
+		return err
+	}
+	return securityManager.VerifySecondFactor(store, wallet, code)
+}
+
+func printRestorePlan(plan *storage.RestorePlan) {
+	if len(plan.Items) == 0 {
+		fmt.Println("Nothing in the selected sections")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SECTION\tACTION\tITEM")
+	for _, item := range plan.Items {
+		label := item.Label
+		if item.Reason != "" {
+			label += " (" + item.Reason + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", item.Section, item.Action, label)
+	}
+	w.Flush()
+	fmt.Printf("\n%d to add, %d to overwrite, %d to skip\n",
+		plan.Count(storage.RestoreAdd), plan.Count(storage.RestoreOverwrite), plan.Count(storage.RestoreSkip))
+	if len(plan.SecondFactor) > 0 {
+		fmt.Printf("Authentication codes are needed for %d wallets with a second factor\n", len(plan.SecondFactor))
+	}
+}
+
+// readPassword prompts for a password without echo, or reads a line from
+// stdin when it is not a terminal
+func readPassword(prompt string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return readLine("")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(password), nil
+}
+
+// stringList is a flag that may be given more than once
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+var stdinReader = bufio.NewReader(os.Stdin)
+
+func readLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := stdinReader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
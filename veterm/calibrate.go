@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+// runCalibrate measures Argon2id on this machine and suggests, or saves to
+// the profile's config, parameters that take about the target time per
+// unlock. An unlock derives storage.UnlockKeyDerivations keys, so each takes
+// a share of the target.
+func runCalibrate(args []string) error {
+	fs := flag.NewFlagSet("calibrate", flag.ContinueOnError)
+	target := fs.Duration("target", time.Second, "time one wallet unlock should take, across all of its key derivations")
+	save := fs.Bool("save", false, "write the parameters to the profile's config file")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *target <= 0 {
+		return fmt.Errorf("target must be positive, got %v", *target)
+	}
+
+	fmt.Printf("Calibrating argon2id for a %v unlock...\n", *target)
+	params := storage.CalibrateKDF(*target)
+
+	start := time.Now()
+	for i := 0; i < storage.UnlockKeyDerivations; i++ {
+		if _, err := storage.EncryptWithKDF([]byte("calibration"), "calibration", params); err != nil {
+			return fmt.Errorf("failed to check parameters: %w", err)
+		}
+	}
+	fmt.Printf("Selected %s, measured %v per unlock\n", params, time.Since(start).Round(time.Millisecond))
+
+	if !*save {
+		fmt.Printf("Run with -save, or set kdf_time=%d kdf_memory_kib=%d kdf_threads=%d in the config file\n", params.Time, params.Memory, params.Threads)
+		return nil
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	cfg, err := store.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	cfg.KDFTime = int(params.Time)
+	cfg.KDFMemory = int(params.Memory)
+	cfg.KDFThreads = int(params.Threads)
+	if err := store.SaveConfig(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("Saved to %s; wallets move to the new parameters as they are next unlocked\n", store.ConfigPath())
+	return nil
+}
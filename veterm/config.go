@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"rhystmorgan/veWallet/internal/config"
+)
+
+// runConfig prints the effective configuration and where each value came from
+func runConfig(args []string) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print as JSON")
+	profile := registerProfileFlags(fs)
+	configOverrides := config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	fmt.Printf("Profile: %s\n", store.Profile())
+
+	effective, err := config.NewLoader(store, configOverrides()).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if *asJSON {
+		return effective.PrintJSON(os.Stdout)
+	}
+	return effective.Print(os.Stdout)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const contactsUsage = `usage: veterm contacts <command> [flags]
+
+commands:
+  export     write contacts to a JSON or CSV file, optionally encrypted with age
+  import     add contacts from a JSON or CSV file, decrypting age files`
+
+// runContacts exports and imports the profile's contacts
+func runContacts(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", contactsUsage)
+	}
+
+	switch args[0] {
+	case "export":
+		return runContactsExport(args[1:])
+	case "import":
+		return runContactsImport(args[1:])
+	}
+	return fmt.Errorf("unknown contacts command %q\n\n%s", args[0], contactsUsage)
+}
+
+func runContactsExport(args []string) error {
+	fs := flag.NewFlagSet("contacts export", flag.ContinueOnError)
+	out := fs.String("out", "", "file to write")
+	format := fs.String("format", "json", "json or csv")
+	var recipients stringList
+	fs.Var(&recipients, "recipient", "encrypt with age to this X25519 public key (age1...); repeat for several")
+	useAge := fs.Bool("age", false, "encrypt with an age passphrase")
+	usage := fs.Bool("usage", false, "include use counts and last-used times")
+	metadata := fs.Bool("metadata", false, "include sent and received totals")
+	walletName := fs.String("wallet", "", "wallet to unlock when the contacts are encrypted")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("usage: veterm contacts export -out <file> [flags]")
+	}
+	ageOptions, err := exportAgeOptions(recipients, *useAge)
+	if err != nil {
+		return err
+	}
+
+	options := utils.ImportExportOptions{
+		FilePath:        *out,
+		IncludeUsage:    *usage,
+		IncludeMetadata: *metadata,
+	}
+	if options.Format, err = parseContactFormat(*format); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	contacts, err := loadUnlockedContacts(store, *walletName)
+	if err != nil {
+		return err
+	}
+
+	options.Encrypt = ageOptions.Enabled()
+	options.Age = ageOptions
+
+	if err := utils.NewContactExporter(options).ExportContacts(contacts.Contacts); err != nil {
+		return err
+	}
+	if options.Encrypt {
+		fmt.Printf("Exported %d contacts to %s, encrypted with age\n", len(contacts.Contacts), *out)
+	} else {
+		fmt.Printf("Exported %d contacts to %s\n", len(contacts.Contacts), *out)
+	}
+	return nil
+}
+
+func runContactsImport(args []string) error {
+	fs := flag.NewFlagSet("contacts import", flag.ContinueOnError)
+	format := fs.String("format", "json", "json or csv")
+	identity := fs.String("identity", "", "age identity file for exports encrypted to recipients")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported without saving")
+	walletName := fs.String("wallet", "", "wallet to unlock when the contacts are encrypted")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: veterm contacts import [flags] <file>")
+	}
+
+	options := utils.ImportExportOptions{
+		FilePath: fs.Arg(0),
+	}
+	var err error
+	if options.Format, err = parseContactFormat(*format); err != nil {
+		return err
+	}
+
+	if options.AgeKeys, err = importAgeKeys(options.FilePath, *identity); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	contacts, err := loadUnlockedContacts(store, *walletName)
+	if err != nil {
+		return err
+	}
+
+	result, imported, err := utils.NewContactImporter(options).ImportContacts()
+	if errors.Is(err, storage.ErrInvalidPassword) {
+		return fmt.Errorf("incorrect export passphrase or age identity")
+	}
+	if err != nil {
+		return err
+	}
+	for _, importErr := range result.Errors {
+		fmt.Printf("Line %d: %s: %s\n", importErr.LineNumber, importErr.Field, importErr.Message)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+
+	// Contacts already saved under the same address are left as they are
+	added := 0
+	for _, contact := range utils.ConvertToContacts(imported) {
+		if contacts.FindByAddress(contact.Address) != nil {
+			fmt.Printf("Skipping %s: %s is already a contact\n", contact.Name, contact.Address)
+			continue
+		}
+		if err := contacts.Add(&contact, nil, "", ""); err != nil {
+			return fmt.Errorf("failed to add contact: %w", err)
+		}
+		added++
+	}
+
+	if *dryRun {
+		fmt.Printf("%d of %d contacts would be added\n", added, result.TotalContacts)
+		return nil
+	}
+	if added == 0 {
+		fmt.Println("No new contacts to import")
+		return nil
+	}
+	if err := store.SaveContacts(contacts); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d of %d contacts\n", added, result.TotalContacts)
+	return nil
+}
+
+// loadUnlockedContacts reads the profile's contacts, unlocking them with the
+// named wallet's password when they are encrypted
+func loadUnlockedContacts(store *storage.Storage, walletName string) (*models.ContactList, error) {
+	contacts, err := store.LoadContacts()
+	if err != nil {
+		return nil, err
+	}
+	if !contacts.Locked {
+		return contacts, nil
+	}
+	if walletName == "" {
+		return nil, fmt.Errorf("contacts are encrypted; pass -wallet to unlock them")
+	}
+
+	encWallet, err := findWallet(store, walletName)
+	if err != nil {
+		return nil, err
+	}
+	password, err := readPassword("Wallet password: ")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.LoadWallet(encWallet.ID, password); err != nil {
+		return nil, err
+	}
+	if contacts, err = store.LoadContacts(); err != nil {
+		return nil, err
+	}
+	if contacts.Locked {
+		return nil, fmt.Errorf("wallet %s does not hold the contacts key", encWallet.Name)
+	}
+	return contacts, nil
+}
+
+func parseContactFormat(format string) (utils.ExportFormat, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		return utils.FormatJSON, nil
+	case "csv":
+		return utils.FormatCSV, nil
+	}
+	return 0, fmt.Errorf("unsupported format %q; use json or csv", format)
+}
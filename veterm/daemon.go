@@ -0,0 +1,126 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/config"
+	"rhystmorgan/veWallet/internal/daemon"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+// scheduleCheckInterval is how often the daemon looks for due payments
+const scheduleCheckInterval = time.Minute
+
+func runDaemon(args []string) error {
+	var flagConfig daemon.Config
+
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	fs.StringVar(&flagConfig.SocketPath, "socket", "", "unix socket path to serve on (default <profile dir>/veterm.sock)")
+	fs.StringVar(&flagConfig.ListenAddr, "listen", "", "loopback host:port to serve on instead of a unix socket")
+	fs.StringVar(&flagConfig.TokenFile, "token-file", "", "file holding the API token, created if missing (default <profile dir>/daemon.token)")
+	profile := registerProfileFlags(fs)
+	configOverrides := config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	// Socket and token default to the profile's directory so profiles can run
+	// side by side
+	daemonConfig := daemon.DefaultConfig(store.DataDir())
+	if flagConfig.SocketPath != "" {
+		daemonConfig.SocketPath = flagConfig.SocketPath
+	}
+	if flagConfig.TokenFile != "" {
+		daemonConfig.TokenFile = flagConfig.TokenFile
+	}
+	daemonConfig.ListenAddr = flagConfig.ListenAddr
+
+	if daemonConfig.ListenAddr != "" {
+		daemonConfig.SocketPath = ""
+	}
+
+	effective, err := config.NewLoader(store, configOverrides()).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	blockchainClient, err := blockchain.NewClient(effective.BlockchainConfig().ToBlockchainConfig())
+	if err != nil {
+		return fmt.Errorf("failed to initialize blockchain client: %w", err)
+	}
+	defer blockchainClient.Close()
+
+	sessionManager := security.NewSessionManager(store)
+	defer sessionManager.Shutdown()
+	securityManager := security.NewSecurityManager(sessionManager)
+
+	if err := store.SetKDFParams(effective.Config.KDFParams()); err != nil {
+		return fmt.Errorf("invalid key derivation settings: %w", err)
+	}
+	if err := store.SetContactEncryption(storage.ContactEncryption(effective.Config.ContactEncryption)); err != nil {
+		return err
+	}
+
+	level, err := security.ParseSecurityLevel(effective.Config.SecurityLevel)
+	if err != nil {
+		return err
+	}
+	securityManager.ApplySessionPolicy(level, effective.SessionTimeout(), effective.Config.MaxSessions)
+
+	server, err := daemon.NewServer(daemonConfig, store, blockchainClient, sessionManager, securityManager)
+	if err != nil {
+		return err
+	}
+
+	budgetVET, budgetVTHO, err := effective.Config.ScheduleBudget()
+	if err != nil {
+		return err
+	}
+	auditor, err := audit.NewPaymentAuditor(store.AuditLogDir())
+	if err != nil {
+		return err
+	}
+	payments := scheduler.New(store, scheduler.NewChainPayer(blockchainClient, server.CheckScheduledTransaction), auditor)
+	payments.SetBudget(scheduler.Budget{VET: budgetVET, VTHO: budgetVTHO})
+	payments.SetGuard(server.SpendingGuard())
+	payments.SetKeys(func(walletID string) *models.Wallet {
+		if session, ok := sessionManager.GetSession(walletID); ok {
+			return session.UnlockedWallet
+		}
+		return nil
+	})
+	server.SetScheduler(payments)
+	payments.Start(scheduleCheckInterval, func(err error) {
+		fmt.Fprintf(os.Stderr, "scheduled payments: %v\n", err)
+	})
+	defer payments.Stop()
+
+	if err := server.Listen(); err != nil {
+		return err
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		server.Close()
+	}()
+
+	fmt.Printf("veterm daemon for profile %s listening on %s (token: %s)\n", store.Profile(), server.Addr(), daemonConfig.TokenFile)
+	return server.Serve()
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+const templatesUsage = `usage: veterm templates <command> [flags]
+
+commands:
+  export     write a wallet's transaction templates to a file, optionally encrypted with age
+  import     add transaction templates from an export, decrypting age files`
+
+const historyUsage = `usage: veterm history <command> [flags]
+
+commands:
+  export     write a wallet's transaction history to a file, optionally encrypted with age
+  import     add transaction records from an export, decrypting age files`
+
+// runTemplates exports and imports a wallet's transaction templates
+func runTemplates(args []string) error {
+	return runDataExport("templates", "templates", templatesUsage, args,
+		func(store *storage.Storage, walletID, path string, opts storage.AgeOptions) (string, int, error) {
+			return store.ExportTemplates(walletID, path, opts)
+		},
+		func(store *storage.Storage, walletID, path string, keys storage.AgeKeys) (int, error) {
+			return store.ImportTemplates(walletID, path, keys)
+		})
+}
+
+// runHistory exports and imports a wallet's transaction history
+func runHistory(args []string) error {
+	return runDataExport("history", "transactions", historyUsage, args,
+		func(store *storage.Storage, walletID, path string, opts storage.AgeOptions) (string, int, error) {
+			return store.ExportHistory(walletID, path, opts)
+		},
+		func(store *storage.Storage, walletID, path string, keys storage.AgeKeys) (int, error) {
+			return store.ImportHistory(walletID, path, keys)
+		})
+}
+
+type dataExporter func(store *storage.Storage, walletID, path string, opts storage.AgeOptions) (string, int, error)
+
+type dataImporter func(store *storage.Storage, walletID, path string, keys storage.AgeKeys) (int, error)
+
+// runDataExport runs the export or import subcommand of name; noun is what
+// the messages call its records
+func runDataExport(name, noun, usage string, args []string, export dataExporter, importer dataImporter) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", usage)
+	}
+
+	switch args[0] {
+	case "export":
+		fs := flag.NewFlagSet(name+" export", flag.ContinueOnError)
+		walletName := fs.String("wallet", "", "wallet whose "+name+" to export")
+		out := fs.String("out", "", "file to write (default <profile dir>/exports/veterm-"+name+"--<time>--<profile>.json)")
+		var recipients stringList
+		fs.Var(&recipients, "recipient", "encrypt with age to this X25519 public key (age1...); repeat for several")
+		useAge := fs.Bool("age", false, "encrypt with an age passphrase")
+		profile := registerProfileFlags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *walletName == "" {
+			return fmt.Errorf("usage: veterm %s export -wallet <name> [flags]", name)
+		}
+		opts, err := exportAgeOptions(recipients, *useAge)
+		if err != nil {
+			return err
+		}
+
+		store, err := profile.open()
+		if err != nil {
+			return fmt.Errorf("failed to initialize storage: %w", err)
+		}
+		wallet, err := findWallet(store, *walletName)
+		if err != nil {
+			return err
+		}
+		path, count, err := export(store, wallet.ID, *out, opts)
+		if err != nil {
+			return err
+		}
+		if opts.Enabled() {
+			fmt.Printf("Exported %d %s from %s to %s, encrypted with age\n", count, noun, wallet.Name, path)
+		} else {
+			fmt.Printf("Exported %d %s from %s to %s\n", count, noun, wallet.Name, path)
+		}
+		return nil
+
+	case "import":
+		fs := flag.NewFlagSet(name+" import", flag.ContinueOnError)
+		walletName := fs.String("wallet", "", "wallet to add the "+name+" to")
+		identity := fs.String("identity", "", "age identity file for exports encrypted to recipients")
+		profile := registerProfileFlags(fs)
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *walletName == "" || fs.NArg() != 1 {
+			return fmt.Errorf("usage: veterm %s import -wallet <name> [flags] <file>", name)
+		}
+		keys, err := importAgeKeys(fs.Arg(0), *identity)
+		if err != nil {
+			return err
+		}
+
+		store, err := profile.open()
+		if err != nil {
+			return fmt.Errorf("failed to initialize storage: %w", err)
+		}
+		wallet, err := findWallet(store, *walletName)
+		if err != nil {
+			return err
+		}
+		added, err := importer(store, wallet.ID, fs.Arg(0), keys)
+		if errors.Is(err, storage.ErrInvalidPassword) {
+			return fmt.Errorf("incorrect export passphrase or age identity")
+		}
+		if err != nil {
+			return err
+		}
+		if added == 0 {
+			fmt.Printf("No new %s to import\n", noun)
+			return nil
+		}
+		fmt.Printf("Imported %d %s into %s\n", added, noun, wallet.Name)
+		return nil
+	}
+	return fmt.Errorf("unknown %s command %q\n\n%s", name, args[0], usage)
+}
+
+// exportAgeOptions returns the age encryption asked for by the -recipient
+// and -age flags, prompting for the passphrase twice
+func exportAgeOptions(recipients []string, useAge bool) (storage.AgeOptions, error) {
+	switch {
+	case len(recipients) > 0 && useAge:
+		return storage.AgeOptions{}, fmt.Errorf("use -recipient or -age, not both")
+	case len(recipients) > 0:
+		return storage.AgeOptions{Recipients: recipients}, nil
+	case !useAge:
+		return storage.AgeOptions{}, nil
+	}
+
+	passphrase, err := readPassword("Export passphrase: ")
+	if err != nil {
+		return storage.AgeOptions{}, err
+	}
+	confirm, err := readPassword("Confirm export passphrase: ")
+	if err != nil {
+		return storage.AgeOptions{}, err
+	}
+	if confirm != passphrase {
+		return storage.AgeOptions{}, fmt.Errorf("passphrases do not match")
+	}
+	return storage.AgeOptions{Passphrase: passphrase}, nil
+}
+
+// importAgeKeys returns the keys to open path with, prompting for the
+// passphrase when it is an age file and no identity file was given
+func importAgeKeys(path, identity string) (storage.AgeKeys, error) {
+	keys := storage.AgeKeys{IdentityFile: identity}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return keys, fmt.Errorf("failed to open file: %w", err)
+	}
+	if storage.IsAgeEncrypted(data) && identity == "" {
+		if keys.Passphrase, err = readPassword("Export passphrase: "); err != nil {
+			return keys, err
+		}
+	}
+	return keys, nil
+}
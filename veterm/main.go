@@ -1,16 +1,88 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"rhystmorgan/veWallet/internal/config"
+	"rhystmorgan/veWallet/internal/storage"
 	"rhystmorgan/veWallet/internal/views"
 )
 
 func main() {
-	app, err := views.NewAppModel()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "daemon":
+			if err := runDaemon(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error running daemon: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "backup":
+			if err := runBackup(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "restore":
+			if err := runRestore(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "calibrate":
+			if err := runCalibrate(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "schedule":
+			if err := runSchedule(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "contacts":
+			if err := runContacts(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "templates":
+			if err := runTemplates(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "history":
+			if err := runHistory(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "config":
+			if err := runConfig(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	fs := flag.NewFlagSet("veterm", flag.ExitOnError)
+	home := fs.String("home", "", "data directory (env "+storage.HomeEnv+", default ~/.veterm)")
+	profile := fs.String("profile", "", "open this profile directly instead of showing the picker (env "+storage.ProfileEnv+")")
+	configOverrides := config.RegisterFlags(fs)
+	fs.Parse(os.Args[1:])
+
+	app, err := views.NewAppModel(views.AppOptions{
+		Home:            *home,
+		Profile:         *profile,
+		ConfigOverrides: configOverrides(),
+	})
 	if err != nil {
 		fmt.Printf("Error initializing application: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+
+	"rhystmorgan/veWallet/internal/storage"
+)
+
+type profileFlags struct {
+	home    *string
+	profile *string
+}
+
+// registerProfileFlags adds --home and --profile to fs
+func registerProfileFlags(fs *flag.FlagSet) profileFlags {
+	return profileFlags{
+		home:    fs.String("home", "", "data directory (env "+storage.HomeEnv+", default ~/.veterm)"),
+		profile: fs.String("profile", "", "profile to use (env "+storage.ProfileEnv+")"),
+	}
+}
+
+// open opens the selected profile, falling back to the default profile
+func (f profileFlags) open() (*storage.Storage, error) {
+	home, err := storage.HomeDir(*f.home)
+	if err != nil {
+		return nil, err
+	}
+	return storage.OpenProfile(home, storage.ProfileName(*f.profile))
+}
@@ -0,0 +1,489 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"rhystmorgan/veWallet/internal/audit"
+	"rhystmorgan/veWallet/internal/blockchain"
+	"rhystmorgan/veWallet/internal/config"
+	"rhystmorgan/veWallet/internal/models"
+	"rhystmorgan/veWallet/internal/scheduler"
+	"rhystmorgan/veWallet/internal/security"
+	"rhystmorgan/veWallet/internal/storage"
+	"rhystmorgan/veWallet/internal/utils"
+)
+
+const scheduleUsage = `usage: veterm schedule <command> [flags]
+
+commands:
+  add        schedule a template to be paid on a recurrence
+  list       show schedules
+  runs       show scheduled payments and what came of them
+  check      record payments that have come due
+  approve    pay a payment waiting for approval
+  reject     close a payment waiting for approval without paying
+  pause      stop a schedule making payments
+  resume     restart a paused schedule
+  remove     delete a schedule`
+
+// runSchedule manages scheduled payments
+func runSchedule(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%s", scheduleUsage)
+	}
+
+	switch args[0] {
+	case "add":
+		return runScheduleAdd(args[1:])
+	case "list":
+		return runScheduleList(args[1:])
+	case "runs":
+		return runScheduleRuns(args[1:])
+	case "check":
+		return runScheduleCheck(args[1:])
+	case "approve":
+		return runScheduleApprove(args[1:])
+	case "reject":
+		return runScheduleReject(args[1:])
+	case "pause", "resume", "remove":
+		return runScheduleChange(args[0], args[1:])
+	}
+	return fmt.Errorf("unknown schedule command %q\n\n%s", args[0], scheduleUsage)
+}
+
+func runScheduleAdd(args []string) error {
+	fs := flag.NewFlagSet("schedule add", flag.ContinueOnError)
+	walletName := fs.String("wallet", "", "wallet ID or name to pay from")
+	templateName := fs.String("template", "", "transaction template ID or name to pay")
+	name := fs.String("name", "", "schedule name (default the template's name)")
+	every := fs.String("every", "", `recurrence: "every 30d", "every 2w", a cron expression such as "0 9 1 * *", or @daily, @weekly, @monthly`)
+	start := fs.String("start", "", "first date, YYYY-MM-DD or YYYY-MM-DD HH:MM (default now)")
+	end := fs.String("end", "", "last date payments may fall on")
+	maxRuns := fs.Int("max", 0, "stop after this many payments (0 for no limit)")
+	mode := fs.String("mode", string(models.ScheduleApprove), "approve to wait for approval, auto to sign within the auto-pay budget while the wallet is unlocked in the daemon")
+	onMissed := fs.String("on-missed", string(models.MissedPayLatest), "pay_latest to still pay the latest missed payment, skip to pay none")
+	var values stringList
+	fs.Var(&values, "set", "placeholder value as name=value; repeat for several")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *walletName == "" || *templateName == "" || *every == "" {
+		return fmt.Errorf("-wallet, -template and -every are required")
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	wallet, err := findWallet(store, *walletName)
+	if err != nil {
+		return err
+	}
+	template, err := findTemplate(store, wallet.ID, *templateName)
+	if err != nil {
+		return err
+	}
+
+	startAt := time.Now()
+	if *start != "" {
+		if startAt, err = models.ParseScheduleTime(*start); err != nil {
+			return err
+		}
+	}
+	if *name == "" {
+		*name = template.Name
+	}
+	schedule, err := models.NewPaymentSchedule(*name, wallet.ID, template.ID, *every, startAt)
+	if err != nil {
+		return err
+	}
+	if *end != "" {
+		endAt, err := models.ParseScheduleEnd(*end)
+		if err != nil {
+			return err
+		}
+		schedule.EndAt = &endAt
+	}
+	schedule.MaxRuns = *maxRuns
+	schedule.Mode = models.ScheduleMode(*mode)
+	schedule.OnMissed = models.MissedRunPolicy(*onMissed)
+
+	byName := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok {
+			return fmt.Errorf("invalid -set %q: use name=value", value)
+		}
+		byName[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	contacts, _ := store.LoadContacts()
+	if schedule.Values, err = template.ScheduleValues(byName, contacts); err != nil {
+		return err
+	}
+
+	payments, err := newScheduler(store, nil)
+	if err != nil {
+		return err
+	}
+	if err := payments.AddSchedule(schedule); err != nil {
+		return err
+	}
+
+	fmt.Printf("Scheduled %s (%s), first due %s\n", schedule.Name, schedule.ID, schedule.NextRunAt.Local().Format("2006-01-02 15:04"))
+	return nil
+}
+
+func runScheduleList(args []string) error {
+	fs := flag.NewFlagSet("schedule list", flag.ContinueOnError)
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	schedules, err := store.LoadSchedules()
+	if err != nil {
+		return err
+	}
+	if len(schedules) == 0 {
+		fmt.Println("No schedules")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tWALLET\tRECURRENCE\tMODE\tNEXT")
+	for _, schedule := range schedules {
+		next := schedule.NextRunAt.Local().Format("2006-01-02 15:04")
+		switch {
+		case schedule.Finished():
+			next = "finished"
+		case schedule.Paused:
+			next = "paused"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", schedule.ID, schedule.Name, schedule.WalletID, schedule.Describe(), schedule.Mode, next)
+	}
+	return w.Flush()
+}
+
+func runScheduleRuns(args []string) error {
+	fs := flag.NewFlagSet("schedule runs", flag.ContinueOnError)
+	status := fs.String("status", "", "only show runs with this status: pending, sent, missed, rejected or failed")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	runs, err := store.LoadScheduledRuns()
+	if err != nil {
+		return err
+	}
+	printScheduledRuns(runs, models.RunStatus(*status))
+	return nil
+}
+
+func printScheduledRuns(runs []models.ScheduledRun, status models.RunStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSCHEDULE\tDUE\tSTATUS\tPAYMENT\tDETAIL")
+	shown := 0
+	for _, run := range runs {
+		if status != "" && run.Status != status {
+			continue
+		}
+		payment := ""
+		if run.Amount != nil {
+			payment = fmt.Sprintf("%s %s to %s", utils.FormatAmount(run.Amount, 4), run.Asset, utils.FormatAddress(run.To, 6, 4))
+		}
+		detail := run.Reason
+		if run.TxHash != "" {
+			detail = run.TxHash
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", run.ID, run.ScheduleName, run.DueAt.Local().Format("2006-01-02 15:04"), run.Status, payment, detail)
+		shown++
+	}
+	if shown == 0 {
+		fmt.Println("No scheduled payments")
+		return
+	}
+	w.Flush()
+}
+
+// runScheduleCheck records due payments. Without a wallet's password nothing
+// is signed, so payments are left waiting for approval.
+func runScheduleCheck(args []string) error {
+	fs := flag.NewFlagSet("schedule check", flag.ContinueOnError)
+	profile := registerProfileFlags(fs)
+	configOverrides := config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	client, err := newScheduleClient(store, configOverrides())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	payments, err := newScheduler(store, client)
+	if err != nil {
+		return err
+	}
+	runs, err := payments.RunDue()
+	if len(runs) == 0 && err == nil {
+		fmt.Println("Nothing is due")
+		return nil
+	}
+	printScheduledRuns(runs, "")
+	return err
+}
+
+func runScheduleApprove(args []string) error {
+	fs := flag.NewFlagSet("schedule approve", flag.ContinueOnError)
+	profile := registerProfileFlags(fs)
+	configOverrides := config.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: veterm schedule approve [flags] <run id>")
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	run, err := findScheduledRun(store, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s %s to %s\n", run.ScheduleName, utils.FormatAmount(run.Amount, 18), run.Asset, run.To)
+
+	password, err := readPassword("Wallet password: ")
+	if err != nil {
+		return err
+	}
+	wallet, err := store.LoadWallet(run.WalletID, password)
+	if err != nil {
+		return err
+	}
+	if wallet.TwoFactor != nil {
+		code, err := readPassword("Authentication code: ")
+		if err != nil {
+			return err
+		}
+		// Wrong codes count toward the wallet's lockout, which is kept with
+		// the wallet so it also holds for later commands and the daemon
+		sessionManager := security.NewSessionManager(store)
+		defer sessionManager.Shutdown()
+		securityManager := security.NewSecurityManager(sessionManager)
+		if err := applySecurityLevel(store, configOverrides(), securityManager); err != nil {
+			return err
+		}
+		if err := securityManager.VerifySecondFactor(store, wallet, code); err != nil {
+			return err
+		}
+	}
+
+	client, err := newScheduleClient(store, configOverrides())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	payments, err := newScheduler(store, client)
+	if err != nil {
+		return err
+	}
+	paid, err := payments.Approve(run.ID, wallet)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Sent %s\n", paid.TxHash)
+	return nil
+}
+
+func runScheduleReject(args []string) error {
+	fs := flag.NewFlagSet("schedule reject", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the payment was rejected")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: veterm schedule reject [flags] <run id>")
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	payments, err := newScheduler(store, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := payments.Reject(fs.Arg(0), *reason); err != nil {
+		return err
+	}
+	fmt.Printf("Rejected %s\n", fs.Arg(0))
+	return nil
+}
+
+func runScheduleChange(command string, args []string) error {
+	fs := flag.NewFlagSet("schedule "+command, flag.ContinueOnError)
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: veterm schedule %s [flags] <schedule id>", command)
+	}
+
+	store, err := profile.open()
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	schedules, err := store.LoadSchedules()
+	if err != nil {
+		return err
+	}
+	var schedule *models.PaymentSchedule
+	for i := range schedules {
+		if schedules[i].ID == fs.Arg(0) {
+			schedule = &schedules[i]
+		}
+	}
+	if schedule == nil {
+		return fmt.Errorf("schedule not found: %s", fs.Arg(0))
+	}
+
+	payments, err := newScheduler(store, nil)
+	if err != nil {
+		return err
+	}
+	var done string
+	switch command {
+	case "remove":
+		err, done = payments.RemoveSchedule(schedule), "removed"
+	case "pause":
+		schedule.Paused = true
+		err, done = payments.UpdateSchedule(schedule), "paused"
+	case "resume":
+		if err = schedule.Resume(time.Now()); err == nil {
+			err = payments.UpdateSchedule(schedule)
+		}
+		done = "resumed, next due " + schedule.NextRunAt.Local().Format("2006-01-02 15:04")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Schedule %s %s\n", schedule.Name, done)
+	return nil
+}
+
+// newScheduler creates a scheduler for the profile; client may be nil for
+// commands that make no payments
+func newScheduler(store *storage.Storage, client *blockchain.Client) (*scheduler.Scheduler, error) {
+	auditor, err := audit.NewPaymentAuditor(store.AuditLogDir())
+	if err != nil {
+		return nil, err
+	}
+
+	policyAuditor, err := audit.NewPolicyAuditor(store.AuditLogDir())
+	if err != nil {
+		return nil, err
+	}
+
+	var payer scheduler.Payer
+	if client != nil {
+		payer = scheduler.NewChainPayer(client, nil)
+	}
+	payments := scheduler.New(store, payer, auditor)
+	payments.SetGuard(security.NewSpendingGuard(store, policyAuditor))
+	return payments, nil
+}
+
+// applySecurityLevel sets the configured security level's attempt limits on
+// securityManager
+func applySecurityLevel(store *storage.Storage, overrides map[string]string, securityManager *security.SecurityManager) error {
+	effective, err := config.NewLoader(store, overrides).Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	level, err := security.ParseSecurityLevel(effective.Config.SecurityLevel)
+	if err != nil {
+		return err
+	}
+	securityManager.ApplySecurityLevel(level)
+	return nil
+}
+
+func newScheduleClient(store *storage.Storage, overrides map[string]string) (*blockchain.Client, error) {
+	effective, err := config.NewLoader(store, overrides).Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	client, err := blockchain.NewClient(effective.BlockchainConfig().ToBlockchainConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize blockchain client: %w", err)
+	}
+	return client, nil
+}
+
+func findWallet(store *storage.Storage, nameOrID string) (*storage.EncryptedWallet, error) {
+	wallets, err := store.ListWallets()
+	if err != nil {
+		return nil, err
+	}
+	for i := range wallets {
+		if wallets[i].ID == nameOrID || strings.EqualFold(wallets[i].Name, nameOrID) {
+			return &wallets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("wallet not found: %s", nameOrID)
+}
+
+func findTemplate(store *storage.Storage, walletID, nameOrID string) (*models.TransactionTemplate, error) {
+	templates, err := store.LoadTemplates(walletID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		if templates[i].ID == nameOrID || strings.EqualFold(templates[i].Name, nameOrID) {
+			return &templates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("template not found: %s", nameOrID)
+}
+
+func findScheduledRun(store *storage.Storage, id string) (*models.ScheduledRun, error) {
+	runs, err := store.LoadScheduledRuns()
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ID == id {
+			if runs[i].Status != models.RunPending {
+				return nil, fmt.Errorf("run %s is already %s", id, runs[i].Status)
+			}
+			return &runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("scheduled run not found: %s", id)
+}